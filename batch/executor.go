@@ -0,0 +1,295 @@
+/*
+Package batch sends many TransactionStatus, ReverseTransaction and
+DeleteRecurring requests read from a CSV or JSON Lines file, e.g. to check the
+transactions affected by an incident, or to reverse a list of duplicate
+charges.
+
+The [Executor] runs the rows with bounded concurrency and an optional rate
+limit, and appends the [Result] of each row to an output file as soon as it
+completes. If the run is interrupted, running it again with the same output
+file skips the rows that already have a result:
+
+	executor, err := batch.New(batch.Config{Sender: client, Rate: 5})
+	// ...
+	summary, err := executor.RunFile(ctx, "duplicates.csv", "duplicates.out.jsonl", batch.CommandReverse)
+*/
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+)
+
+const (
+	defaultWorkers = 4
+	defaultTimeout = 30 * time.Second
+)
+
+// Config is the configuration required to set up an [Executor].
+type Config struct {
+	// Sender used to send the requests. Required.
+	Sender maib.Sender
+
+	// Source of time, used for the rate limit. Default is [clock.Real].
+	Clock clock.Clock
+
+	// Number of concurrent requests. Default is 4.
+	Workers int
+
+	// Maximum number of requests per second. Default is no limit.
+	Rate float64
+
+	// Timeout of each request. Default is 30 seconds.
+	Timeout time.Duration
+
+	// Send again the rows that failed with an error in the previous run, if
+	// sending them again is safe: the row was rejected without being executed,
+	// see [Result.Rejected], or it is a read-only TransactionStatus. By default
+	// only the rows without a result are sent.
+	//
+	// Other failures, like a ReverseTransaction that failed with a network
+	// error or a 5xx status, or was interrupted, may have been executed, so
+	// they are never sent again. Check such transactions with
+	// TransactionStatus and resolve them manually.
+	RetryErrors bool
+
+	// Called after each row is written to the output. Optional. It is called
+	// from the worker goroutines, so it must be safe for concurrent use.
+	OnResult func(Result)
+}
+
+// Summary counts the rows of a run.
+type Summary struct {
+	// Rows in the input.
+	Total int `json:"total"`
+
+	// Rows that already had a result from a previous run.
+	Skipped int `json:"skipped"`
+
+	// Rows that got a response, whatever its RESULT.
+	Succeeded int `json:"succeeded"`
+
+	// Rows that ended with an error.
+	Failed int `json:"failed"`
+}
+
+// Executor runs batches of rows. It is safe for concurrent use.
+//
+// Must be initiated with [New].
+type Executor struct {
+	sender      maib.Sender
+	clock       clock.Clock
+	workers     int
+	interval    time.Duration
+	timeout     time.Duration
+	retryErrors bool
+	onResult    func(Result)
+}
+
+// New validates the configuration and returns an *[Executor].
+func New(config Config) (*Executor, error) {
+	if config.Sender == nil {
+		return nil, errors.New("sender is required")
+	}
+	if config.Rate < 0 {
+		return nil, errors.New("rate must not be negative")
+	}
+
+	e := &Executor{
+		sender:      config.Sender,
+		clock:       config.Clock,
+		workers:     config.Workers,
+		timeout:     config.Timeout,
+		retryErrors: config.RetryErrors,
+		onResult:    config.OnResult,
+	}
+	if e.clock == nil {
+		e.clock = clock.Real
+	}
+	if e.workers <= 0 {
+		e.workers = defaultWorkers
+	}
+	if e.timeout <= 0 {
+		e.timeout = defaultTimeout
+	}
+	if config.Rate > 0 {
+		e.interval = time.Duration(float64(time.Second) / config.Rate)
+	}
+	return e, nil
+}
+
+// RunFile reads the rows from the input file with [ReadFile], and appends
+// their results to the output file, which is created if needed. Rows that
+// already have a result in the output are skipped, see [Executor.Run].
+func (e *Executor) RunFile(ctx context.Context, inputPath, outputPath string, defaultCommand Command) (Summary, error) {
+	rows, err := ReadFile(inputPath, defaultCommand)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	out, err := os.OpenFile(outputPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return Summary{}, fmt.Errorf("open output: %w", err)
+	}
+	defer out.Close()
+
+	previous, length, err := readResults(out)
+	if err != nil {
+		return Summary{}, err
+	}
+	// Drop the partial line left by an interrupted run, and append after the
+	// complete ones.
+	err = out.Truncate(length)
+	if err != nil {
+		return Summary{}, fmt.Errorf("truncate output: %w", err)
+	}
+	_, err = out.Seek(length, io.SeekStart)
+	if err != nil {
+		return Summary{}, fmt.Errorf("seek output: %w", err)
+	}
+
+	summary, err := e.Run(ctx, rows, previous, out)
+	if err != nil {
+		return summary, err
+	}
+	err = out.Sync()
+	if err != nil {
+		return summary, fmt.Errorf("sync output: %w", err)
+	}
+	return summary, nil
+}
+
+// Run sends the rows, and writes their results to w as JSON Lines in the
+// order they complete. Rows with a result in previous are skipped, unless the
+// result is an error that is safe to retry and RetryErrors is set. Rows that failed to read are
+// written as failed without sending anything.
+//
+// Run fails before sending anything if a previous result belongs to a row
+// with different content, because the input changed between runs. If the
+// context is done, Run waits for the requests in flight and returns the
+// context error. The rows that were not sent have no result, so the run can be
+// resumed. A row interrupted in flight may have been executed, so it is written
+// as failed, and only a TransactionStatus row is sent again with RetryErrors.
+func (e *Executor) Run(ctx context.Context, rows []Row, previous map[int]Result, w io.Writer) (Summary, error) {
+	summary := Summary{Total: len(rows)}
+	var pending []Row
+	for _, row := range rows {
+		result, ok := previous[row.Line]
+		if ok && result.Digest != row.digest() {
+			return summary, fmt.Errorf("row on line %d changed since the previous run", row.Line)
+		}
+		if ok && !(e.retryErrors && retryable(row, result)) {
+			summary.Skipped++
+			continue
+		}
+		pending = append(pending, row)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	jobs := make(chan Row)
+	var mu sync.Mutex
+	enc := json.NewEncoder(w)
+	var wg sync.WaitGroup
+	for i := 0; i < e.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for row := range jobs {
+				if ctx.Err() != nil {
+					// Not started, leave the row to the next run.
+					continue
+				}
+				result := e.execute(ctx, row)
+
+				mu.Lock()
+				err := enc.Encode(result)
+				if err == nil {
+					if result.Failed() {
+						summary.Failed++
+					} else {
+						summary.Succeeded++
+					}
+				}
+				mu.Unlock()
+				if err != nil {
+					cancel(fmt.Errorf("write result: %w", err))
+					continue
+				}
+				if e.onResult != nil {
+					e.onResult(result)
+				}
+			}
+		}()
+	}
+
+	e.dispatch(ctx, pending, jobs)
+	close(jobs)
+	wg.Wait()
+	return summary, context.Cause(ctx)
+}
+
+// dispatch hands the rows to the workers, waiting between requests to respect
+// the rate limit. It returns early if the context is done.
+func (e *Executor) dispatch(ctx context.Context, rows []Row, jobs chan<- Row) {
+	sent := false
+	for _, row := range rows {
+		if row.Err == nil && e.interval > 0 {
+			if sent {
+				select {
+				case <-ctx.Done():
+					return
+				case <-e.clock.After(e.interval):
+				}
+			}
+			sent = true
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case jobs <- row:
+		}
+	}
+}
+
+// execute sends the request of the row, and returns its result.
+func (e *Executor) execute(ctx context.Context, row Row) Result {
+	result := Result{
+		Line:    row.Line,
+		Command: row.Command(),
+		ID:      row.ID(),
+		Digest:  row.digest(),
+	}
+	if row.Err != nil {
+		result.Error = row.Err.Error()
+		result.Rejected = true
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+	res, err := e.sender.Send(ctx, row.Request)
+	if err != nil {
+		result.Error = err.Error()
+		result.Rejected = maib.IsRejected(err)
+		return result
+	}
+	result.Response = res
+	return result
+}
+
+// retryable reports whether the row failed in a previous run, and sending it
+// again can't execute its request twice.
+func retryable(row Row, previous Result) bool {
+	return previous.Failed() && (previous.Rejected || row.Command() == CommandStatus)
+}
@@ -0,0 +1,327 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/maibfake"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+const (
+	transactionA = "aaaaaaaaaaaaaaaaaaaaaaaaaaa="
+	transactionB = "bbbbbbbbbbbbbbbbbbbbbbbbbbb="
+	transactionC = "ccccccccccccccccccccccccccc="
+)
+
+func statusRows(ids ...string) []Row {
+	rows := make([]Row, len(ids))
+	for i, id := range ids {
+		rows[i] = Row{Line: i + 2, Request: requests.TransactionStatus{TransactionID: id, ClientIPAddress: "127.0.0.1"}}
+	}
+	return rows
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.EqualError(t, err, "sender is required")
+
+	_, err = New(Config{Sender: maibfake.New(), Rate: -1})
+	assert.EqualError(t, err, "rate must not be negative")
+}
+
+func TestExecutor_Run(t *testing.T) {
+	fake := maibfake.New()
+	fake.RespondFunc(requests.TransactionStatus{}, func(req maib.Request) maibfake.Response {
+		if req.(requests.TransactionStatus).TransactionID == transactionB {
+			return maibfake.Error(&maib.ECommError{Code: 500})
+		}
+		return maibfake.Result(requests.TransactionStatusResult{Result: maib.ResultOk})
+	})
+	var mu sync.Mutex
+	var reported []Result
+	executor, err := New(Config{
+		Sender: fake,
+		OnResult: func(result Result) {
+			mu.Lock()
+			defer mu.Unlock()
+			reported = append(reported, result)
+		},
+	})
+	require.NoError(t, err)
+
+	rows := append(statusRows(transactionA, transactionB), Row{Line: 4, Err: errors.New("unknown command")})
+	var out bytes.Buffer
+	summary, err := executor.Run(context.Background(), rows, nil, &out)
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Total: 3, Succeeded: 1, Failed: 2}, summary)
+	assert.Len(t, fake.Calls(), 2)
+	assert.Len(t, reported, 3)
+
+	results, _, err := readResults(&out)
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.Equal(t, Result{
+		Line:     2,
+		Command:  CommandStatus,
+		ID:       transactionA,
+		Digest:   rows[0].digest(),
+		Response: map[string]any{"RESULT": "OK"},
+	}, results[2])
+	assert.Equal(t, transactionB, results[3].ID)
+	assert.Contains(t, results[3].Error, "500")
+	assert.False(t, results[3].Rejected)
+	assert.Equal(t, "unknown command", results[4].Error)
+	assert.True(t, results[4].Rejected)
+}
+
+func TestExecutor_Run_Resume(t *testing.T) {
+	fake := maibfake.New()
+	fake.Respond(requests.TransactionStatus{}, maibfake.Result(requests.TransactionStatusResult{Result: maib.ResultOk}))
+	rows := statusRows(transactionA, transactionB, transactionC)
+	previous := map[int]Result{
+		2: {Line: 2, Digest: rows[0].digest(), Response: map[string]any{"RESULT": "OK"}},
+		3: {Line: 3, Digest: rows[1].digest(), Error: "timeout"},
+	}
+
+	t.Run("skips rows with results", func(t *testing.T) {
+		fake.Reset()
+		executor, err := New(Config{Sender: fake})
+		require.NoError(t, err)
+
+		summary, err := executor.Run(context.Background(), rows, previous, &bytes.Buffer{})
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Total: 3, Skipped: 2, Succeeded: 1}, summary)
+		fake.AssertSentOnce(t, requests.TransactionStatus{TransactionID: transactionC})
+		assert.Len(t, fake.Calls(), 1)
+	})
+
+	t.Run("retries errors", func(t *testing.T) {
+		fake.Reset()
+		executor, err := New(Config{Sender: fake, RetryErrors: true})
+		require.NoError(t, err)
+
+		summary, err := executor.Run(context.Background(), rows, previous, &bytes.Buffer{})
+		require.NoError(t, err)
+		assert.Equal(t, Summary{Total: 3, Skipped: 1, Succeeded: 2}, summary)
+		fake.AssertSentOnce(t, requests.TransactionStatus{TransactionID: transactionB})
+	})
+
+	t.Run("changed input", func(t *testing.T) {
+		fake.Reset()
+		executor, err := New(Config{Sender: fake})
+		require.NoError(t, err)
+
+		changed := statusRows(transactionB, transactionA, transactionC)
+		_, err = executor.Run(context.Background(), changed, previous, &bytes.Buffer{})
+		assert.EqualError(t, err, "row on line 2 changed since the previous run")
+		assert.Empty(t, fake.Calls())
+	})
+}
+
+func TestExecutor_Run_RetryReversals(t *testing.T) {
+	fake := maibfake.New()
+	fake.Respond(requests.ReverseTransaction{}, maibfake.Result(requests.ReverseTransactionResult{Result: maib.ResultOk}))
+	rows := []Row{
+		{Line: 2, Request: requests.ReverseTransaction{TransactionID: transactionA, Amount: 100}},
+		{Line: 3, Request: requests.ReverseTransaction{TransactionID: transactionB, Amount: 100}},
+		{Line: 4, Request: requests.ReverseTransaction{TransactionID: transactionC, Amount: 100}},
+	}
+	previous := map[int]Result{
+		2: {Line: 2, Digest: rows[0].digest(), Error: "maib ecomm returned 200: error: wrong amount", Rejected: true},
+		3: {Line: 3, Digest: rows[1].digest(), Error: "maib ecomm returned 502: Bad Gateway"},
+		4: {Line: 4, Digest: rows[2].digest(), Error: "context canceled"},
+	}
+	executor, err := New(Config{Sender: fake, RetryErrors: true})
+	require.NoError(t, err)
+
+	// Only the rejected reversal is sent again, the others may have refunded.
+	summary, err := executor.Run(context.Background(), rows, previous, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Total: 3, Skipped: 2, Succeeded: 1}, summary)
+	fake.AssertSentOnce(t, requests.ReverseTransaction{TransactionID: transactionA, Amount: 100})
+	assert.Len(t, fake.Calls(), 1)
+}
+
+func TestExecutor_Run_Concurrency(t *testing.T) {
+	const workers = 3
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	release := make(chan struct{})
+
+	fake := maibfake.New()
+	fake.RespondFunc(requests.TransactionStatus{}, func(maib.Request) maibfake.Response {
+		mu.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return maibfake.Result(requests.TransactionStatusResult{Result: maib.ResultOk})
+	})
+	executor, err := New(Config{Sender: fake, Workers: workers})
+	require.NoError(t, err)
+
+	done := make(chan Summary)
+	go func() {
+		summary, _ := executor.Run(context.Background(), statusRows(
+			transactionA, transactionB, transactionC, transactionA, transactionB, transactionC,
+		), nil, &bytes.Buffer{})
+		done <- summary
+	}()
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return inFlight == workers
+	}, time.Second, time.Millisecond)
+	close(release)
+	summary := <-done
+	assert.Equal(t, 6, summary.Succeeded)
+	assert.Equal(t, workers, maxInFlight)
+}
+
+func TestExecutor_Run_Rate(t *testing.T) {
+	fakeClock := clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC))
+	fake := maibfake.New()
+	fake.Respond(requests.TransactionStatus{}, maibfake.Result(requests.TransactionStatusResult{Result: maib.ResultOk}))
+	executor, err := New(Config{Sender: fake, Clock: fakeClock, Rate: 2})
+	require.NoError(t, err)
+
+	done := make(chan Summary)
+	go func() {
+		summary, _ := executor.Run(context.Background(), statusRows(transactionA, transactionB, transactionC), nil, &bytes.Buffer{})
+		done <- summary
+	}()
+
+	for sent := 1; sent <= 2; sent++ {
+		fakeClock.BlockUntil(1)
+		assert.Eventually(t, func() bool { return len(fake.Calls()) == sent }, time.Second, time.Millisecond)
+		fakeClock.Advance(499 * time.Millisecond)
+		assert.Len(t, fake.Calls(), sent)
+		fakeClock.Advance(time.Millisecond)
+	}
+	assert.Equal(t, 3, (<-done).Succeeded)
+}
+
+func TestExecutor_Run_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fake := maibfake.New()
+	fake.RespondFunc(requests.TransactionStatus{}, func(req maib.Request) maibfake.Response {
+		if req.(requests.TransactionStatus).TransactionID == transactionB {
+			cancel()
+			return maibfake.Error(context.Canceled)
+		}
+		return maibfake.Result(requests.TransactionStatusResult{Result: maib.ResultOk})
+	})
+	executor, err := New(Config{Sender: fake, Workers: 1})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	summary, err := executor.Run(ctx, statusRows(transactionA, transactionB, transactionC), nil, &out)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, Summary{Total: 3, Succeeded: 1, Failed: 1}, summary)
+
+	// The interrupted row is written as failed, the row that was not sent has
+	// no result.
+	results, _, err := readResults(&out)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.False(t, results[2].Failed())
+	assert.True(t, results[3].Failed())
+	assert.Len(t, fake.Calls(), 2)
+}
+
+// blockingSender waits for the context of each request to be done.
+type blockingSender struct{}
+
+func (blockingSender) Send(ctx context.Context, _ maib.Request) (map[string]any, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestExecutor_Run_Timeout(t *testing.T) {
+	executor, err := New(Config{Sender: blockingSender{}, Timeout: time.Millisecond})
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	summary, err := executor.Run(context.Background(), statusRows(transactionA, transactionB), nil, &out)
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Total: 2, Failed: 2}, summary)
+
+	results, _, err := readResults(&out)
+	require.NoError(t, err)
+	assert.Equal(t, context.DeadlineExceeded.Error(), results[2].Error)
+	assert.False(t, results[2].Rejected)
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestExecutor_Run_WriteError(t *testing.T) {
+	fake := maibfake.New()
+	fake.Respond(requests.TransactionStatus{}, maibfake.Result(requests.TransactionStatusResult{Result: maib.ResultOk}))
+	executor, err := New(Config{Sender: fake, Workers: 1})
+	require.NoError(t, err)
+
+	_, err = executor.Run(context.Background(), statusRows(transactionA, transactionB, transactionC), nil, failingWriter{})
+	assert.EqualError(t, err, "write result: disk full")
+	assert.Less(t, len(fake.Calls()), 3)
+}
+
+func TestExecutor_RunFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "rows.csv")
+	output := filepath.Join(dir, "rows.out.jsonl")
+	require.NoError(t, os.WriteFile(input, []byte(
+		"trans_id,amount\n"+transactionA+",100\n"+transactionB+",200\n"+transactionC+",300\n",
+	), 0o600))
+
+	fake := maibfake.New()
+	fake.Respond(requests.ReverseTransaction{}, maibfake.Result(requests.ReverseTransactionResult{Result: maib.ResultOk}))
+	executor, err := New(Config{Sender: fake})
+	require.NoError(t, err)
+
+	// A previous run that was killed while writing the second result.
+	rows, err := ReadFile(input, CommandReverse)
+	require.NoError(t, err)
+	var previous bytes.Buffer
+	_, err = executor.Run(context.Background(), rows[:1], nil, &previous)
+	require.NoError(t, err)
+	fake.Reset()
+	require.NoError(t, os.WriteFile(output, append(previous.Bytes(), `{"line": 3, "com`...), 0o600))
+
+	summary, err := executor.RunFile(context.Background(), input, output, CommandReverse)
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Total: 3, Skipped: 1, Succeeded: 2}, summary)
+	fake.AssertNotSent(t, requests.ReverseTransaction{TransactionID: transactionA})
+	fake.AssertSentOnce(t, requests.ReverseTransaction{TransactionID: transactionB, Amount: 200})
+
+	results, err := LoadResults(output)
+	require.NoError(t, err)
+	assert.Len(t, results, 3)
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(string(data), "\n"))
+
+	summary, err = executor.RunFile(context.Background(), input, output, CommandReverse)
+	require.NoError(t, err)
+	assert.Equal(t, Summary{Total: 3, Skipped: 3}, summary)
+}
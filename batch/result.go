@@ -0,0 +1,91 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Result is the outcome of a [Row], written to the output as a line of JSON.
+type Result struct {
+	// Line of the input file.
+	Line int `json:"line"`
+
+	// Command of the row. Empty if the row failed to read.
+	Command Command `json:"command,omitempty"`
+
+	// Transaction ID or biller client ID of the row.
+	ID string `json:"id,omitempty"`
+
+	// Identifies the content of the row, to detect an input file that changed
+	// between runs.
+	Digest string `json:"digest"`
+
+	// Fields returned by the ECommerce system, as parsed by maib.Client.Send.
+	// A FAILED or DECLINED RESULT is a response, not an error.
+	Response map[string]any `json:"response,omitempty"`
+
+	// Why the row failed: the error of the Send, or why the row could not be
+	// read.
+	Error string `json:"error,omitempty"`
+
+	// The row failed without its request being executed: it could not be
+	// read, or the error of the Send was rejected, see maib.IsRejected.
+	// Otherwise the outcome of a failed row is unknown.
+	Rejected bool `json:"rejected,omitempty"`
+}
+
+// Failed reports whether the row ended with an error.
+func (r Result) Failed() bool {
+	return r.Error != ""
+}
+
+// LoadResults reads the output of a previous run, keyed by line. If a line was
+// run more than once, the last result is kept. A missing file has no results.
+func LoadResults(path string) (map[int]Result, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[int]Result{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open output: %w", err)
+	}
+	defer f.Close()
+
+	results, _, err := readResults(f)
+	return results, err
+}
+
+// readResults reads results from JSON Lines, and returns the length of the
+// complete lines. A run that was killed may leave a partial last line, which
+// is ignored.
+func readResults(r io.Reader) (map[int]Result, int64, error) {
+	results := make(map[int]Result)
+	br := bufio.NewReader(r)
+	var length int64
+	for line := 1; ; line++ {
+		text, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			// Without the newline, the last write was interrupted.
+			return results, length, nil
+		}
+		if err != nil {
+			return nil, 0, fmt.Errorf("read output: %w", err)
+		}
+		length += int64(len(text))
+		if len(bytes.TrimSpace(text)) == 0 {
+			continue
+		}
+
+		var result Result
+		err = json.Unmarshal(text, &result)
+		if err != nil {
+			return nil, 0, fmt.Errorf("parse output line %d: %w", line, err)
+		}
+		results[result.Line] = result
+	}
+}
@@ -0,0 +1,44 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadResults(t *testing.T) {
+	complete := `{"line": 2, "digest": "a", "error": "timeout"}` + "\n" +
+		`{"line": 3, "digest": "b", "response": {"RESULT": "OK"}}` + "\n" +
+		"\n" +
+		`{"line": 2, "digest": "a", "response": {"RESULT": "OK"}}` + "\n"
+	input := complete + `{"line": 4, "dig`
+
+	results, length, err := readResults(strings.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(complete)), length)
+	require.Len(t, results, 2)
+	assert.False(t, results[2].Failed())
+	assert.Equal(t, "OK", results[3].Response["RESULT"])
+}
+
+func TestReadResults_Malformed(t *testing.T) {
+	_, _, err := readResults(strings.NewReader("{\"line\": 2}\nnot json\n"))
+	assert.ErrorContains(t, err, "parse output line 2")
+}
+
+func TestLoadResults(t *testing.T) {
+	dir := t.TempDir()
+	results, err := LoadResults(filepath.Join(dir, "missing.jsonl"))
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	path := filepath.Join(dir, "out.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"line": 2, "digest": "a", "error": "timeout"}`+"\n"), 0o600))
+	results, err = LoadResults(path)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]Result{2: {Line: 2, Digest: "a", Error: "timeout"}}, results)
+}
@@ -0,0 +1,270 @@
+package batch
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+// Command is the kind of request in a [Row].
+type Command string
+
+const (
+	// CommandStatus sends [requests.TransactionStatus].
+	CommandStatus Command = "status"
+
+	// CommandReverse sends [requests.ReverseTransaction].
+	CommandReverse Command = "reverse"
+
+	// CommandDeleteRecurring sends [requests.DeleteRecurring].
+	CommandDeleteRecurring Command = "delete-recurring"
+)
+
+// Names of the CSV columns and JSON Lines keys. Only the ones used by the
+// command of the row are required.
+const (
+	ColumnCommand         = "command"
+	ColumnTransactionID   = string(maib.FieldTransactionID)
+	ColumnClientIPAddress = string(maib.FieldClientIPAddress)
+	ColumnAmount          = string(maib.FieldAmount)
+	ColumnSuspectedFraud  = "suspected_fraud"
+	ColumnBillerClientID  = string(maib.FieldBillerClientID)
+)
+
+// Row is a request read from the input file.
+type Row struct {
+	// Line of the input file the row was read from. It identifies the row when
+	// resuming.
+	Line int
+
+	// The request to send. Nil if Err is set.
+	Request maib.Request
+
+	// Why the row could not be read, e.g. an unknown command or a malformed
+	// amount. Such rows are recorded as failed without sending anything.
+	Err error
+}
+
+// Command returns the command of the request, or an empty string if the row
+// failed to read.
+func (r Row) Command() Command {
+	switch r.Request.(type) {
+	case requests.TransactionStatus:
+		return CommandStatus
+	case requests.ReverseTransaction:
+		return CommandReverse
+	case requests.DeleteRecurring:
+		return CommandDeleteRecurring
+	}
+	return ""
+}
+
+// ID returns the transaction ID or biller client ID the row refers to.
+func (r Row) ID() string {
+	switch req := r.Request.(type) {
+	case requests.TransactionStatus:
+		return req.TransactionID
+	case requests.ReverseTransaction:
+		return req.TransactionID
+	case requests.DeleteRecurring:
+		return req.BillerClientID
+	}
+	return ""
+}
+
+// digest identifies the content of the row, to detect an input file that
+// changed between runs.
+func (r Row) digest() string {
+	var content string
+	if r.Err != nil {
+		content = "error " + r.Err.Error()
+	} else {
+		content = fmt.Sprintf("%T %+v", r.Request, r.Request)
+	}
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:8])
+}
+
+// fields are the columns of a row, before they are converted to a request.
+type fields struct {
+	Command         Command `json:"command"`
+	TransactionID   string  `json:"trans_id"`
+	ClientIPAddress string  `json:"client_ip_addr"`
+	Amount          int     `json:"amount"`
+	SuspectedFraud  bool    `json:"suspected_fraud"`
+	BillerClientID  string  `json:"biller_client_id"`
+}
+
+// request converts the fields into the request of the command. The command of
+// the row takes precedence over the default.
+func (f fields) request(defaultCommand Command) (maib.Request, error) {
+	command := f.Command
+	if command == "" {
+		command = defaultCommand
+	}
+	switch command {
+	case CommandStatus:
+		return requests.TransactionStatus{
+			TransactionID:   f.TransactionID,
+			ClientIPAddress: f.ClientIPAddress,
+		}, nil
+	case CommandReverse:
+		return requests.ReverseTransaction{
+			TransactionID:  f.TransactionID,
+			Amount:         f.Amount,
+			SuspectedFraud: f.SuspectedFraud,
+		}, nil
+	case CommandDeleteRecurring:
+		return requests.DeleteRecurring{
+			BillerClientID: f.BillerClientID,
+		}, nil
+	case "":
+		return nil, errors.New("command is required")
+	}
+	return nil, fmt.Errorf("unknown command %q", command)
+}
+
+// ReadFile reads the rows of a file by its extension: .csv for [ReadCSV], and
+// .jsonl or .ndjson for [ReadJSONL]. Rows without a command use
+// defaultCommand.
+func ReadFile(path string, defaultCommand Command) ([]Row, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open input: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ReadCSV(f, defaultCommand)
+	case ".jsonl", ".ndjson":
+		return ReadJSONL(f, defaultCommand)
+	}
+	return nil, fmt.Errorf("unknown input format %q, expected .csv or .jsonl", filepath.Ext(path))
+}
+
+// ReadCSV reads rows from CSV with a header line. The header names the
+// columns, e.g. "trans_id,amount". Rows without a command column use
+// defaultCommand.
+//
+// The file is rejected if it has unknown columns. Rows with malformed values
+// are returned with [Row.Err] set.
+func ReadCSV(r io.Reader, defaultCommand Command) ([]Row, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read CSV header: %w", err)
+	}
+	for i, column := range header {
+		header[i] = strings.TrimSpace(column)
+		switch header[i] {
+		case ColumnCommand, ColumnTransactionID, ColumnClientIPAddress,
+			ColumnAmount, ColumnSuspectedFraud, ColumnBillerClientID:
+		default:
+			return nil, fmt.Errorf("unknown CSV column %q", header[i])
+		}
+	}
+
+	var rows []Row
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read CSV: %w", err)
+		}
+		line, _ := cr.FieldPos(0)
+		row := Row{Line: line}
+		f, err := csvFields(header, record)
+		if err == nil {
+			row.Request, err = f.request(defaultCommand)
+		}
+		row.Err = err
+		rows = append(rows, row)
+	}
+}
+
+// csvFields converts a CSV record into fields.
+func csvFields(header, record []string) (fields, error) {
+	var f fields
+	for i, value := range record {
+		value = strings.TrimSpace(value)
+		var err error
+		switch header[i] {
+		case ColumnCommand:
+			f.Command = Command(value)
+		case ColumnTransactionID:
+			f.TransactionID = value
+		case ColumnClientIPAddress:
+			f.ClientIPAddress = value
+		case ColumnAmount:
+			if value != "" {
+				f.Amount, err = strconv.Atoi(value)
+			}
+		case ColumnSuspectedFraud:
+			if value != "" {
+				f.SuspectedFraud, err = strconv.ParseBool(value)
+			}
+		case ColumnBillerClientID:
+			f.BillerClientID = value
+		}
+		if err != nil {
+			return fields{}, fmt.Errorf("malformed %s %q", header[i], value)
+		}
+	}
+	return f, nil
+}
+
+// ReadJSONL reads rows from JSON Lines, one object per line, e.g.
+// {"trans_id": "...", "amount": 100}. Blank lines are skipped. Rows without a
+// command use defaultCommand.
+//
+// Lines that are not valid JSON objects, or have unknown keys, are returned
+// with [Row.Err] set.
+func ReadJSONL(r io.Reader, defaultCommand Command) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+	var rows []Row
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		row := Row{Line: line}
+		var f fields
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.DisallowUnknownFields()
+		err := dec.Decode(&f)
+		if err == nil {
+			row.Request, err = f.request(defaultCommand)
+		} else {
+			err = fmt.Errorf("malformed JSON: %w", err)
+		}
+		row.Err = err
+		rows = append(rows, row)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read JSON Lines: %w", err)
+	}
+	return rows, nil
+}
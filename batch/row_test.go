@@ -0,0 +1,104 @@
+package batch
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+func TestReadCSV(t *testing.T) {
+	input := "command,trans_id,client_ip_addr,amount,suspected_fraud,biller_client_id\n" +
+		"status,id-1,127.0.0.1,,,\n" +
+		"reverse,id-2,,1999,true,\n" +
+		"delete-recurring,,,,,client-1\n" +
+		"refund,id-3,,,,\n" +
+		"reverse,id-4,,19.99,,\n"
+
+	rows, err := ReadCSV(strings.NewReader(input), "")
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+
+	assert.Equal(t, Row{Line: 2, Request: requests.TransactionStatus{TransactionID: "id-1", ClientIPAddress: "127.0.0.1"}}, rows[0])
+	assert.Equal(t, Row{Line: 3, Request: requests.ReverseTransaction{TransactionID: "id-2", Amount: 1999, SuspectedFraud: true}}, rows[1])
+	assert.Equal(t, Row{Line: 4, Request: requests.DeleteRecurring{BillerClientID: "client-1"}}, rows[2])
+	assert.Equal(t, 5, rows[3].Line)
+	assert.EqualError(t, rows[3].Err, `unknown command "refund"`)
+	assert.EqualError(t, rows[4].Err, `malformed amount "19.99"`)
+
+	assert.Equal(t, CommandStatus, rows[0].Command())
+	assert.Equal(t, "id-1", rows[0].ID())
+	assert.Equal(t, CommandDeleteRecurring, rows[2].Command())
+	assert.Equal(t, "client-1", rows[2].ID())
+	assert.Equal(t, Command(""), rows[3].Command())
+}
+
+func TestReadCSV_DefaultCommand(t *testing.T) {
+	rows, err := ReadCSV(strings.NewReader("trans_id\nid-1\nid-2\n"), CommandReverse)
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, requests.ReverseTransaction{TransactionID: "id-2"}, rows[1].Request)
+
+	rows, err = ReadCSV(strings.NewReader("trans_id\nid-1\n"), "")
+	require.NoError(t, err)
+	assert.EqualError(t, rows[0].Err, "command is required")
+}
+
+func TestReadCSV_UnknownColumn(t *testing.T) {
+	_, err := ReadCSV(strings.NewReader("trans_id,currency\nid-1,498\n"), CommandStatus)
+	assert.EqualError(t, err, `unknown CSV column "currency"`)
+}
+
+func TestReadJSONL(t *testing.T) {
+	input := `{"command": "status", "trans_id": "id-1", "client_ip_addr": "127.0.0.1"}
+
+{"command": "reverse", "trans_id": "id-2", "amount": 1999}
+{"trans_id": "id-3", "currency": 498}
+not json
+`
+	rows, err := ReadJSONL(strings.NewReader(input), CommandReverse)
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, Row{Line: 1, Request: requests.TransactionStatus{TransactionID: "id-1", ClientIPAddress: "127.0.0.1"}}, rows[0])
+	assert.Equal(t, Row{Line: 3, Request: requests.ReverseTransaction{TransactionID: "id-2", Amount: 1999}}, rows[1])
+	assert.Equal(t, 4, rows[2].Line)
+	assert.ErrorContains(t, rows[2].Err, "unknown field")
+	assert.Equal(t, 5, rows[3].Line)
+	assert.ErrorContains(t, rows[3].Err, "malformed JSON")
+}
+
+func TestReadFile(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "rows.csv")
+	jsonlPath := filepath.Join(dir, "rows.jsonl")
+	txtPath := filepath.Join(dir, "rows.txt")
+	require.NoError(t, os.WriteFile(csvPath, []byte("trans_id\nid-1\n"), 0o600))
+	require.NoError(t, os.WriteFile(jsonlPath, []byte(`{"trans_id": "id-1"}`+"\n"), 0o600))
+	require.NoError(t, os.WriteFile(txtPath, []byte("id-1\n"), 0o600))
+
+	for _, path := range []string{csvPath, jsonlPath} {
+		rows, err := ReadFile(path, CommandStatus)
+		require.NoError(t, err)
+		assert.Equal(t, []Row{{Line: rows[0].Line, Request: requests.TransactionStatus{TransactionID: "id-1"}}}, rows)
+	}
+
+	_, err := ReadFile(txtPath, CommandStatus)
+	assert.ErrorContains(t, err, "unknown input format")
+	_, err = ReadFile(filepath.Join(dir, "missing.csv"), CommandStatus)
+	assert.ErrorContains(t, err, "open input")
+}
+
+func TestRow_Digest(t *testing.T) {
+	a := Row{Line: 1, Request: requests.ReverseTransaction{TransactionID: "id-1", Amount: 100}}
+	b := Row{Line: 2, Request: requests.ReverseTransaction{TransactionID: "id-1", Amount: 100}}
+	c := Row{Line: 1, Request: requests.ReverseTransaction{TransactionID: "id-1", Amount: 200}}
+
+	assert.Equal(t, a.digest(), b.digest())
+	assert.NotEqual(t, a.digest(), c.digest())
+}
@@ -0,0 +1,123 @@
+package cardexpiry
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Kind is the kind of the saved card registration.
+type Kind string
+
+const (
+	// KindRecurring - the card was saved with RegisterRecurring.
+	KindRecurring Kind = "RECURRING"
+
+	// KindOneClick - the card was saved with RegisterOneClick.
+	KindOneClick Kind = "ONECLICK"
+)
+
+// Card is the local record of a saved card.
+type Card struct {
+	// Identifier of the recurring or oneClick payment.
+	BillerClientID string
+
+	// Kind of the registration.
+	Kind Kind
+
+	// Card expiry in the form "MMYY", as returned in
+	// TransactionStatusResult.RecurringPaymentExpiry.
+	Expiry string
+
+	// When the card lapses: the first moment of the month after Expiry, in UTC.
+	ExpiresAt time.Time
+
+	// When the expiry was last recorded.
+	UpdatedAt time.Time
+
+	// ID of the transaction created by the last renewal request, if the customer
+	// hasn't updated the card yet.
+	RenewalTransactionID string
+
+	// When the last renewal was requested.
+	RenewalRequestedAt time.Time
+}
+
+// ErrNotFound is returned by a [Store] when the card does not exist.
+var ErrNotFound = errors.New("card not found")
+
+// Store persists saved cards.
+type Store interface {
+	// Get returns the card with the given BillerClientID, or [ErrNotFound].
+	Get(ctx context.Context, billerClientID string) (Card, error)
+
+	// Save creates or replaces the card with the same BillerClientID.
+	Save(ctx context.Context, card Card) error
+
+	// ExpiringBefore returns the cards whose ExpiresAt is not after t, ordered by
+	// ExpiresAt.
+	ExpiringBefore(ctx context.Context, t time.Time) ([]Card, error)
+
+	// Delete removes the card with the given BillerClientID. Deleting a missing
+	// card does nothing.
+	Delete(ctx context.Context, billerClientID string) error
+}
+
+// MemoryStore is a [Store] that keeps cards in memory. It is safe for
+// concurrent use.
+type MemoryStore struct {
+	mu    sync.Mutex
+	cards map[string]Card
+}
+
+// NewMemoryStore returns an empty *[MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		cards: make(map[string]Card),
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, billerClientID string) (Card, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	card, ok := s.cards[billerClientID]
+	if !ok {
+		return Card{}, ErrNotFound
+	}
+	return card, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, card Card) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cards[card.BillerClientID] = card
+	return nil
+}
+
+func (s *MemoryStore) ExpiringBefore(_ context.Context, t time.Time) ([]Card, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiring []Card
+	for _, card := range s.cards {
+		if !card.ExpiresAt.After(t) {
+			expiring = append(expiring, card)
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool {
+		if expiring[i].ExpiresAt.Equal(expiring[j].ExpiresAt) {
+			return expiring[i].BillerClientID < expiring[j].BillerClientID
+		}
+		return expiring[i].ExpiresAt.Before(expiring[j].ExpiresAt)
+	})
+	return expiring, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, billerClientID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cards, billerClientID)
+	return nil
+}
@@ -0,0 +1,33 @@
+package cardexpiry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	_, err := store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	a := Card{BillerClientID: "a", ExpiresAt: now}
+	b := Card{BillerClientID: "b", ExpiresAt: now.Add(-time.Hour)}
+	c := Card{BillerClientID: "c", ExpiresAt: now.Add(time.Hour)}
+	for _, card := range []Card{a, b, c} {
+		assert.Nil(t, store.Save(ctx, card))
+	}
+
+	expiring, err := store.ExpiringBefore(ctx, now)
+	assert.Nil(t, err)
+	assert.Equal(t, []Card{b, a}, expiring)
+
+	assert.Nil(t, store.Delete(ctx, "a"))
+	assert.Nil(t, store.Delete(ctx, "a"))
+	_, err = store.Get(ctx, "a")
+	assert.ErrorIs(t, err, ErrNotFound)
+}
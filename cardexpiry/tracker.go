@@ -0,0 +1,236 @@
+/*
+Package cardexpiry tracks the expiry of cards saved with
+[requests.RegisterRecurring] and [requests.RegisterOneClick], so that customers
+can be asked to update their card before a charge is declined.
+
+Record the "MMYY" expiry from [requests.TransactionStatusResult] with
+[Tracker.Record], list the cards that expire soon with [Tracker.Expiring], and
+start a re-registration for each of them with [Tracker.Renew]. The renewal
+registers the new card without payment under the same BillerClientID, with
+OverwriteExisting set, so existing subscriptions keep working.
+*/
+package cardexpiry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/internal/validators"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+// ParseExpiry parses a card expiry in the form "MMYY", and returns the first
+// moment of the following month in UTC, when the card lapses.
+func ParseExpiry(expiry string) (time.Time, error) {
+	err := validators.Validate(validators.WithPerspayeeExpiry(expiry))
+	if err != nil {
+		return time.Time{}, err
+	}
+	// Validated above.
+	month, _ := strconv.Atoi(expiry[0:2])
+	year, _ := strconv.Atoi(expiry[2:4])
+	return time.Date(2000+year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+// Config is the configuration required to set up a [Tracker].
+type Config struct {
+	// Sender used to send the renewal registrations. Required for
+	// [Tracker.Renew].
+	Sender maib.Sender
+
+	// Storage for the cards. Default is a new [MemoryStore].
+	Store Store
+
+	// Source of time. Default is [clock.Real].
+	Clock clock.Clock
+
+	// URL of the client handler issued by MAIB, where the customer is redirected
+	// to enter the new card. Optional. If empty, Renewal.RedirectURL is not set.
+	ClientHandlerURL string
+}
+
+// RenewalOptions contains the fields of the renewal registration that are not
+// stored with the card.
+type RenewalOptions struct {
+	// Currency in ISO4217 3 digit format.
+	Currency maib.Currency
+
+	// Client's IP address in quad-dotted notation, like "127.0.0.1".
+	ClientIPAddress string
+
+	// Transaction details. Optional.
+	Description string
+
+	// Language in which the bank payment page will be displayed.
+	Language maib.Language
+
+	// Validity limit of the regular payment in the format "MMYY".
+	PerspayeeExpiry string
+}
+
+// Renewal is a started re-registration of a card.
+type Renewal struct {
+	// Card being renewed, with the renewal fields set.
+	Card Card
+
+	// ID of the registration transaction. 28 symbols in base64.
+	TransactionID string
+
+	// Client handler URL with the transaction ID, where the customer should be
+	// redirected. Empty if Config.ClientHandlerURL is not set.
+	RedirectURL string
+}
+
+// Tracker records card expiries and starts renewals. Must be initiated with
+// [New].
+type Tracker struct {
+	sender           maib.Sender
+	store            Store
+	clock            clock.Clock
+	clientHandlerURL string
+}
+
+// New validates the configuration and returns a *[Tracker].
+func New(config Config) (*Tracker, error) {
+	if config.ClientHandlerURL != "" {
+		_, err := url.Parse(config.ClientHandlerURL)
+		if err != nil {
+			return nil, fmt.Errorf("parse client handler URL: %w", err)
+		}
+	}
+
+	t := &Tracker{
+		sender:           config.Sender,
+		store:            config.Store,
+		clock:            config.Clock,
+		clientHandlerURL: config.ClientHandlerURL,
+	}
+	if t.store == nil {
+		t.store = NewMemoryStore()
+	}
+	if t.clock == nil {
+		t.clock = clock.Real
+	}
+	return t, nil
+}
+
+// Record stores the expiry of the card saved under billerClientID, taken from
+// status.RecurringPaymentExpiry. It does nothing if the status has no expiry,
+// e.g. when the customer didn't agree to save the card. Any pending renewal of
+// the card is considered complete.
+func (t *Tracker) Record(ctx context.Context, billerClientID string, kind Kind, status requests.TransactionStatusResult) error {
+	if status.RecurringPaymentExpiry == "" {
+		return nil
+	}
+	expiresAt, err := ParseExpiry(status.RecurringPaymentExpiry)
+	if err != nil {
+		return fmt.Errorf("parse expiry: %w", err)
+	}
+
+	err = t.store.Save(ctx, Card{
+		BillerClientID: billerClientID,
+		Kind:           kind,
+		Expiry:         status.RecurringPaymentExpiry,
+		ExpiresAt:      expiresAt,
+		UpdatedAt:      t.clock.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("save card: %w", err)
+	}
+	return nil
+}
+
+// Forget removes the card, e.g. after it was deleted with DeleteRecurring.
+func (t *Tracker) Forget(ctx context.Context, billerClientID string) error {
+	err := t.store.Delete(ctx, billerClientID)
+	if err != nil {
+		return fmt.Errorf("delete card: %w", err)
+	}
+	return nil
+}
+
+// Expiring returns the cards that lapse within the window from now, including
+// the cards that have already lapsed.
+func (t *Tracker) Expiring(ctx context.Context, window time.Duration) ([]Card, error) {
+	cards, err := t.store.ExpiringBefore(ctx, t.clock.Now().Add(window))
+	if err != nil {
+		return nil, fmt.Errorf("get expiring cards: %w", err)
+	}
+	return cards, nil
+}
+
+// RenewalRequest returns the registration request that lets the customer
+// replace the card under the same BillerClientID, without making a payment.
+func RenewalRequest(card Card, opts RenewalOptions) maib.Request {
+	if card.Kind == KindOneClick {
+		return requests.RegisterOneClick{
+			TransactionType:   requests.RegisterOneClickWithoutPayment,
+			Currency:          opts.Currency,
+			ClientIPAddress:   opts.ClientIPAddress,
+			Description:       opts.Description,
+			Language:          opts.Language,
+			BillerClientID:    card.BillerClientID,
+			PerspayeeExpiry:   opts.PerspayeeExpiry,
+			OverwriteExisting: true,
+		}
+	}
+	return requests.RegisterRecurring{
+		TransactionType:   requests.RegisterRecurringWithoutPayment,
+		Currency:          opts.Currency,
+		ClientIPAddress:   opts.ClientIPAddress,
+		Description:       opts.Description,
+		Language:          opts.Language,
+		BillerClientID:    card.BillerClientID,
+		PerspayeeExpiry:   opts.PerspayeeExpiry,
+		OverwriteExisting: true,
+	}
+}
+
+// Renew sends the renewal registration for the card, and stores the created
+// transaction ID with the card. Redirect the customer to Renewal.RedirectURL,
+// then check the transaction with TransactionStatus and pass the result to
+// [Tracker.Record].
+func (t *Tracker) Renew(ctx context.Context, card Card, opts RenewalOptions) (Renewal, error) {
+	if t.sender == nil {
+		return Renewal{}, errors.New("sender is required to renew cards")
+	}
+
+	res, err := t.sender.Send(ctx, RenewalRequest(card, opts))
+	if err != nil {
+		return Renewal{}, fmt.Errorf("register card: %w", err)
+	}
+	// RegisterRecurringResult and RegisterOneClickResult have the same fields.
+	result, err := requests.DecodeResponse[requests.RegisterRecurringResult](res)
+	if err != nil {
+		return Renewal{}, fmt.Errorf("decode response: %w", err)
+	}
+
+	card.RenewalTransactionID = result.TransactionID
+	card.RenewalRequestedAt = t.clock.Now()
+	err = t.store.Save(ctx, card)
+	if err != nil {
+		return Renewal{}, fmt.Errorf("save card: %w", err)
+	}
+
+	renewal := Renewal{
+		Card:          card,
+		TransactionID: result.TransactionID,
+	}
+	if t.clientHandlerURL != "" {
+		redirectURL, err := url.Parse(t.clientHandlerURL)
+		if err != nil {
+			return Renewal{}, fmt.Errorf("parse client handler URL: %w", err)
+		}
+		query := redirectURL.Query()
+		query.Set("trans_id", result.TransactionID)
+		redirectURL.RawQuery = query.Encode()
+		renewal.RedirectURL = redirectURL.String()
+	}
+	return renewal, nil
+}
@@ -0,0 +1,167 @@
+package cardexpiry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+var now = time.Date(2025, 5, 20, 12, 0, 0, 0, time.UTC)
+
+type fakeSender struct {
+	sent []maib.Request
+}
+
+func (f *fakeSender) Send(_ context.Context, req maib.Request) (map[string]any, error) {
+	f.sent = append(f.sent, req)
+	_, err := req.Values()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"TRANSACTION_ID": "abcdefghijklmnopqrstuvwxyz1="}, nil
+}
+
+func TestParseExpiry(t *testing.T) {
+	expiresAt, err := ParseExpiry("0525")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), expiresAt)
+
+	expiresAt, err = ParseExpiry("1229")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC), expiresAt)
+
+	_, err = ParseExpiry("1325")
+	assert.ErrorAs(t, err, new(*maib.ValidationError))
+	_, err = ParseExpiry("525")
+	assert.ErrorAs(t, err, new(*maib.ValidationError))
+}
+
+func newTracker(t *testing.T, sender maib.Sender) (*Tracker, *MemoryStore) {
+	store := NewMemoryStore()
+	tracker, err := New(Config{
+		Sender:           sender,
+		Store:            store,
+		Clock:            clock.NewFake(now),
+		ClientHandlerURL: "https://maib.example.org/ecomm/ClientHandler",
+	})
+	assert.Nil(t, err)
+	return tracker, store
+}
+
+func TestTracker_Expiring(t *testing.T) {
+	tracker, store := newTracker(t, nil)
+	ctx := context.Background()
+
+	record := func(id string, kind Kind, expiry string) {
+		err := tracker.Record(ctx, id, kind, requests.TransactionStatusResult{
+			Result:                 maib.ResultOk,
+			RecurringPaymentExpiry: expiry,
+		})
+		assert.Nil(t, err)
+	}
+	record("expired", KindRecurring, "0425")
+	record("this month", KindOneClick, "0525")
+	record("next month", KindRecurring, "0625")
+	record("later", KindRecurring, "1226")
+
+	// Statuses without expiry are ignored.
+	assert.Nil(t, tracker.Record(ctx, "unsaved", KindRecurring, requests.TransactionStatusResult{}))
+	_, err := store.Get(ctx, "unsaved")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Error(t, tracker.Record(ctx, "bad", KindRecurring, requests.TransactionStatusResult{RecurringPaymentExpiry: "0025"}))
+
+	cards, err := tracker.Expiring(ctx, 30*24*time.Hour)
+	assert.Nil(t, err)
+	var ids []string
+	for _, card := range cards {
+		ids = append(ids, card.BillerClientID)
+	}
+	assert.Equal(t, []string{"expired", "this month"}, ids)
+	assert.Equal(t, KindOneClick, cards[1].Kind)
+	assert.Equal(t, now, cards[1].UpdatedAt)
+
+	cards, err = tracker.Expiring(ctx, 60*24*time.Hour)
+	assert.Nil(t, err)
+	assert.Len(t, cards, 3)
+
+	assert.Nil(t, tracker.Forget(ctx, "expired"))
+	cards, err = tracker.Expiring(ctx, 0)
+	assert.Nil(t, err)
+	assert.Empty(t, cards)
+}
+
+func TestRenewalRequest(t *testing.T) {
+	opts := RenewalOptions{
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageRomanian,
+		PerspayeeExpiry: "1230",
+	}
+
+	req := RenewalRequest(Card{BillerClientID: "client-1", Kind: KindRecurring}, opts)
+	values, err := req.Values()
+	assert.Nil(t, err)
+	assert.Equal(t, "p", values.Get("command"))
+	assert.Equal(t, "client-1", values.Get("biller_client_id"))
+	assert.Equal(t, "1", values.Get("perspayee_overwrite"))
+	assert.Empty(t, values.Get("oneclick"))
+
+	req = RenewalRequest(Card{BillerClientID: "client-2", Kind: KindOneClick}, opts)
+	values, err = req.Values()
+	assert.Nil(t, err)
+	assert.Equal(t, "p", values.Get("command"))
+	assert.Equal(t, "1", values.Get("perspayee_overwrite"))
+	assert.Equal(t, "Y", values.Get("oneclick"))
+}
+
+func TestTracker_Renew(t *testing.T) {
+	sender := &fakeSender{}
+	tracker, store := newTracker(t, sender)
+	ctx := context.Background()
+
+	err := tracker.Record(ctx, "client-1", KindRecurring, requests.TransactionStatusResult{RecurringPaymentExpiry: "0525"})
+	assert.Nil(t, err)
+	card, err := store.Get(ctx, "client-1")
+	assert.Nil(t, err)
+
+	renewal, err := tracker.Renew(ctx, card, RenewalOptions{
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageEnglish,
+		PerspayeeExpiry: "1230",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "abcdefghijklmnopqrstuvwxyz1=", renewal.TransactionID)
+	assert.Equal(t, "https://maib.example.org/ecomm/ClientHandler?trans_id=abcdefghijklmnopqrstuvwxyz1%3D", renewal.RedirectURL)
+	assert.Len(t, sender.sent, 1)
+
+	saved, err := store.Get(ctx, "client-1")
+	assert.Nil(t, err)
+	assert.Equal(t, "abcdefghijklmnopqrstuvwxyz1=", saved.RenewalTransactionID)
+	assert.Equal(t, now, saved.RenewalRequestedAt)
+
+	// Recording the new expiry completes the renewal.
+	err = tracker.Record(ctx, "client-1", KindRecurring, requests.TransactionStatusResult{RecurringPaymentExpiry: "0828"})
+	assert.Nil(t, err)
+	saved, err = store.Get(ctx, "client-1")
+	assert.Nil(t, err)
+	assert.Empty(t, saved.RenewalTransactionID)
+	assert.Equal(t, "0828", saved.Expiry)
+
+	// Validation errors are returned before anything is stored.
+	_, err = tracker.Renew(ctx, card, RenewalOptions{})
+	assert.ErrorAs(t, err, new(*maib.ValidationError))
+}
+
+func TestTracker_Renew_NoSender(t *testing.T) {
+	tracker, _ := newTracker(t, nil)
+	_, err := tracker.Renew(context.Background(), Card{}, RenewalOptions{})
+	assert.Error(t, err)
+}
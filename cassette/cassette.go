@@ -0,0 +1,131 @@
+/*
+Package cassette records exchanges with the ECommerce system into a file, and
+replays them later, e.g. in CI without access to the test merchant account.
+
+A [Recorder] wraps the transport of a client connected to the real system via
+maib.Config.WrapTransport, and writes every request payload and response body to
+the cassette, with sensitive fields scrubbed. A [Replayer] is then used as the
+transport of the client under test. It answers each request with the first
+unused recorded interaction that matches it, and fails on requests that match
+none.
+*/
+package cassette
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Scrubbed replaces the values of scrubbed fields.
+const Scrubbed = "SCRUBBED"
+
+var (
+	// DefaultRequestScrub is the default list of scrubbed request fields.
+	DefaultRequestScrub = []string{"client_ip_addr", "description"}
+
+	// DefaultResponseScrub is the default list of scrubbed response fields.
+	DefaultResponseScrub = []string{"CARD_NUMBER", "APPROVAL_CODE"}
+)
+
+// Interaction is a recorded exchange.
+type Interaction struct {
+	// Payload of the request.
+	Request url.Values `json:"request"`
+
+	// HTTP status code of the response.
+	Status int `json:"status"`
+
+	// Body of the response.
+	Response string `json:"response"`
+}
+
+// Command returns the command of the request.
+func (i Interaction) Command() string {
+	return i.Request.Get("command")
+}
+
+// Cassette is a list of recorded interactions, stored as JSON.
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Load reads the cassette from the file.
+func Load(path string) (Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Cassette{}, fmt.Errorf("read cassette: %w", err)
+	}
+	var c Cassette
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		return Cassette{}, fmt.Errorf("parse cassette: %w", err)
+	}
+	return c, nil
+}
+
+// Save writes the cassette to the file. The file is replaced atomically.
+func (c Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode cassette: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(append(data, '\n'))
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("write cassette: %w", err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("close temporary file: %w", err)
+	}
+	err = os.Rename(tmp.Name(), path)
+	if err != nil {
+		return fmt.Errorf("replace cassette: %w", err)
+	}
+	return nil
+}
+
+// scrubRequest returns a copy of the payload with the fields replaced by
+// [Scrubbed].
+func scrubRequest(values url.Values, fields []string) url.Values {
+	scrubbed := make(url.Values, len(values))
+	for key, value := range values {
+		scrubbed[key] = append([]string(nil), value...)
+	}
+	for _, field := range fields {
+		if _, ok := scrubbed[field]; ok {
+			scrubbed.Set(field, Scrubbed)
+		}
+	}
+	return scrubbed
+}
+
+// scrubResponse replaces the values of the "KEY: value" lines with [Scrubbed].
+// Lines in another format are kept as is.
+func scrubResponse(body string, fields []string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		key, _, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		for _, field := range fields {
+			if key == field {
+				lines[i] = key + ": " + Scrubbed
+				break
+			}
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,55 @@
+package cassette
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCassette_SaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	c := Cassette{
+		Interactions: []Interaction{
+			{
+				Request:  url.Values{"command": {"c"}, "trans_id": {"abc"}},
+				Status:   200,
+				Response: "RESULT: OK\n",
+			},
+		},
+	}
+	assert.Nil(t, c.Save(path))
+
+	loaded, err := Load(path)
+	assert.Nil(t, err)
+	assert.Equal(t, c, loaded)
+	assert.Equal(t, "c", loaded.Interactions[0].Command())
+}
+
+func TestLoad_Errors(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path := filepath.Join(t.TempDir(), "broken.json")
+	assert.Nil(t, os.WriteFile(path, []byte("{"), 0o600))
+	_, err = Load(path)
+	assert.ErrorContains(t, err, "parse cassette")
+}
+
+func TestScrubRequest(t *testing.T) {
+	values := url.Values{"command": {"v"}, "client_ip_addr": {"10.0.0.1"}}
+	scrubbed := scrubRequest(values, []string{"client_ip_addr", "description"})
+	assert.Equal(t, url.Values{"command": {"v"}, "client_ip_addr": {Scrubbed}}, scrubbed)
+	// The original is not modified.
+	assert.Equal(t, "10.0.0.1", values.Get("client_ip_addr"))
+}
+
+func TestScrubResponse(t *testing.T) {
+	body := "RESULT: OK\nCARD_NUMBER: 4111********1111\nAPPROVAL_CODE: 123456\n"
+	assert.Equal(t,
+		"RESULT: OK\nCARD_NUMBER: SCRUBBED\nAPPROVAL_CODE: SCRUBBED\n",
+		scrubResponse(body, DefaultResponseScrub))
+	assert.Equal(t, "error: wrong trans_id", scrubResponse("error: wrong trans_id", DefaultResponseScrub))
+}
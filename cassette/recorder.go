@@ -0,0 +1,114 @@
+package cassette
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// RecorderConfig is the configuration required to set up a [Recorder].
+type RecorderConfig struct {
+	// Path to the cassette file. It is rewritten after every interaction.
+	// Required.
+	Path string
+
+	// Request fields replaced with [Scrubbed]. Default is
+	// [DefaultRequestScrub].
+	RequestScrub []string
+
+	// Response fields replaced with [Scrubbed]. Default is
+	// [DefaultResponseScrub].
+	ResponseScrub []string
+}
+
+// Recorder writes the traffic of a client to a cassette. It is safe for
+// concurrent use.
+//
+// Must be initiated with [NewRecorder].
+type Recorder struct {
+	path          string
+	requestScrub  []string
+	responseScrub []string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder validates the configuration and returns a *[Recorder] with an
+// empty cassette.
+func NewRecorder(config RecorderConfig) (*Recorder, error) {
+	if config.Path == "" {
+		return nil, errors.New("path is required")
+	}
+
+	r := &Recorder{
+		path:          config.Path,
+		requestScrub:  config.RequestScrub,
+		responseScrub: config.ResponseScrub,
+	}
+	if r.requestScrub == nil {
+		r.requestScrub = DefaultRequestScrub
+	}
+	if r.responseScrub == nil {
+		r.responseScrub = DefaultResponseScrub
+	}
+	return r, nil
+}
+
+// Wrap returns an [http.RoundTripper] that sends requests through base, and
+// records them. It is meant for maib.Config.WrapTransport.
+func (r *Recorder) Wrap(base http.RoundTripper) http.RoundTripper {
+	return recording{recorder: r, base: base}
+}
+
+// Cassette returns a copy of the recorded interactions.
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Cassette{
+		Interactions: append([]Interaction(nil), r.cassette.Interactions...),
+	}
+}
+
+// record appends the interaction and saves the cassette.
+func (r *Recorder) record(interaction Interaction) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	return r.cassette.Save(r.path)
+}
+
+type recording struct {
+	recorder *Recorder
+	base     http.RoundTripper
+}
+
+// RoundTrip sends the request and records the exchange. Requests that fail
+// before a response is received are not recorded.
+func (rec recording) RoundTrip(req *http.Request) (*http.Response, error) {
+	values := req.URL.Query()
+	res, err := rec.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("read response body: %w", err)
+	}
+	res.Body = io.NopCloser(bytes.NewReader(body))
+
+	err = rec.recorder.record(Interaction{
+		Request:  scrubRequest(values, rec.recorder.requestScrub),
+		Status:   res.StatusCode,
+		Response: scrubResponse(string(body), rec.recorder.responseScrub),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("record interaction: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,89 @@
+package cassette
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/maibtest"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+var ctx = context.Background()
+
+// record runs a payment against the emulator and returns the recorded cassette
+// and the transaction ID.
+func record(t *testing.T, path string) (Cassette, string) {
+	t.Helper()
+	server := maibtest.NewServer(t, maibtest.Config{})
+	recorder, err := NewRecorder(RecorderConfig{Path: path})
+	assert.Nil(t, err)
+	config := server.Config()
+	config.WrapTransport = recorder.Wrap
+	client, err := maib.NewClient(config)
+	assert.Nil(t, err)
+
+	res, err := client.Send(ctx, requests.RegisterTransaction{
+		Amount:          1999,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "10.0.0.1",
+		Language:        maib.LanguageRomanian,
+		Description:     "Order for John Doe",
+	})
+	assert.Nil(t, err)
+	registered, err := requests.DecodeResponse[requests.RegisterTransactionResult](res)
+	assert.Nil(t, err)
+	assert.Nil(t, server.Approve(registered.TransactionID))
+
+	_, err = client.Send(ctx, requests.TransactionStatus{
+		TransactionID:   registered.TransactionID,
+		ClientIPAddress: "10.0.0.1",
+	})
+	assert.Nil(t, err)
+
+	_, err = client.Send(ctx, requests.ReverseTransaction{
+		TransactionID: registered.TransactionID,
+		Amount:        5000,
+	})
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+
+	assert.Equal(t, recorder.Cassette().Interactions, mustLoad(t, path).Interactions)
+	return recorder.Cassette(), registered.TransactionID
+}
+
+func mustLoad(t *testing.T, path string) Cassette {
+	t.Helper()
+	c, err := Load(path)
+	assert.Nil(t, err)
+	return c
+}
+
+func TestNewRecorder_NoPath(t *testing.T) {
+	_, err := NewRecorder(RecorderConfig{})
+	assert.Error(t, err)
+}
+
+func TestRecorder(t *testing.T) {
+	c, id := record(t, filepath.Join(t.TempDir(), "cassette.json"))
+	assert.Len(t, c.Interactions, 3)
+
+	registration := c.Interactions[0]
+	assert.Equal(t, "v", registration.Command())
+	assert.Equal(t, "1999", registration.Request.Get("amount"))
+	assert.Equal(t, Scrubbed, registration.Request.Get("client_ip_addr"))
+	assert.Equal(t, Scrubbed, registration.Request.Get("description"))
+	assert.Equal(t, "TRANSACTION_ID: "+id+"\n", registration.Response)
+
+	status := c.Interactions[1]
+	assert.Equal(t, id, status.Request.Get("trans_id"))
+	assert.Contains(t, status.Response, "RESULT: OK\n")
+	assert.Contains(t, status.Response, "CARD_NUMBER: SCRUBBED\n")
+	assert.NotContains(t, status.Response, "4111")
+
+	// Error responses are recorded too.
+	assert.Equal(t, 200, c.Interactions[2].Status)
+	assert.Contains(t, c.Interactions[2].Response, "error:")
+}
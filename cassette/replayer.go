@@ -0,0 +1,191 @@
+package cassette
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// ReplayerConfig is the configuration required to set up a [Replayer].
+type ReplayerConfig struct {
+	// Decides whether a request matches a recorded interaction. Default is
+	// [MatchAllExcept] with [DefaultRequestScrub], so that scrubbed fields are
+	// ignored.
+	Matcher Matcher
+
+	// Whether a request that matches only used interactions gets the last of
+	// them again, e.g. for status polling. Default is false: every interaction
+	// is replayed once.
+	Repeat bool
+
+	// If set, unmatched requests also fail the test. Optional.
+	TB testing.TB
+}
+
+// Replayer is an [http.RoundTripper] that answers requests with recorded
+// interactions, without sending them. It is safe for concurrent use.
+//
+// Must be initiated with [NewReplayer].
+type Replayer struct {
+	matcher Matcher
+	repeat  bool
+	tb      testing.TB
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// NewReplayer returns a *[Replayer] of the cassette.
+func NewReplayer(cassette Cassette, config ReplayerConfig) *Replayer {
+	r := &Replayer{
+		matcher:      config.Matcher,
+		repeat:       config.Repeat,
+		tb:           config.TB,
+		interactions: cassette.Interactions,
+		used:         make([]bool, len(cassette.Interactions)),
+	}
+	if r.matcher == nil {
+		r.matcher = MatchAllExcept(DefaultRequestScrub...)
+	}
+	return r
+}
+
+// Wrap returns the replayer, ignoring base. It is meant for
+// maib.Config.WrapTransport.
+func (r *Replayer) Wrap(http.RoundTripper) http.RoundTripper {
+	return r
+}
+
+// RoundTrip answers the request with the first unused matching interaction.
+// It returns *[UnmatchedError] if there is none.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	values := req.URL.Query()
+	if len(values) == 0 {
+		return nil, ErrNoPayload
+	}
+
+	interaction, ok := r.match(values)
+	if !ok {
+		err := &UnmatchedError{Request: values}
+		if r.tb != nil {
+			r.tb.Errorf("%s", err)
+		}
+		return nil, err
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(interaction.Response)),
+		ContentLength: int64(len(interaction.Response)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Replayer) match(values url.Values) (Interaction, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, interaction := range r.interactions {
+		if !r.matcher(interaction.Request, values) {
+			continue
+		}
+		if !r.used[i] {
+			r.used[i] = true
+			return interaction, true
+		}
+		last = i
+	}
+	if r.repeat && last >= 0 {
+		return r.interactions[last], true
+	}
+	return Interaction{}, false
+}
+
+// Unused returns the interactions that were never replayed, in order.
+func (r *Replayer) Unused() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []Interaction
+	for i, interaction := range r.interactions {
+		if !r.used[i] {
+			unused = append(unused, interaction)
+		}
+	}
+	return unused
+}
+
+// Matcher reports whether an incoming request payload matches a recorded one.
+type Matcher func(recorded, incoming url.Values) bool
+
+// MatchFields returns a [Matcher] that compares only the fields, like
+// "command" and "amount".
+func MatchFields(fields ...string) Matcher {
+	return func(recorded, incoming url.Values) bool {
+		for _, field := range fields {
+			if !equal(recorded[field], incoming[field]) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// MatchAllExcept returns a [Matcher] that compares every field, except the
+// listed ones, like "client_ip_addr".
+func MatchAllExcept(fields ...string) Matcher {
+	ignored := make(map[string]bool, len(fields))
+	for _, field := range fields {
+		ignored[field] = true
+	}
+	return func(recorded, incoming url.Values) bool {
+		for key, value := range recorded {
+			if !ignored[key] && !equal(value, incoming[key]) {
+				return false
+			}
+		}
+		for key, value := range incoming {
+			if !ignored[key] && !equal(value, recorded[key]) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// UnmatchedError is returned by the [Replayer] for a request that matches no
+// unused recorded interaction.
+type UnmatchedError struct {
+	// Payload of the request.
+	Request url.Values
+}
+
+func (e *UnmatchedError) Error() string {
+	return fmt.Sprintf("cassette: no recorded interaction matches %s", e.Request.Encode())
+}
+
+// ErrNoPayload is returned for requests that have no payload in the URL query.
+var ErrNoPayload = errors.New("cassette: request has no payload")
@@ -0,0 +1,134 @@
+package cassette
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+	"github.com/NikSays/go-maib-ecomm/v2/testpki"
+)
+
+func replayClient(t *testing.T, replayer *Replayer) *maib.Client {
+	t.Helper()
+	ca, err := testpki.NewCA(testpki.Options{})
+	assert.Nil(t, err)
+	client, err := maib.NewClient(maib.Config{
+		PFXPath:                 ca.ClientPFXFile(t, "password", testpki.Options{}),
+		Passphrase:              "password",
+		MerchantHandlerEndpoint: "https://ecomm.example/ecomm/MerchantHandler",
+		WrapTransport:           replayer.Wrap,
+	})
+	assert.Nil(t, err)
+	return client
+}
+
+func TestReplayer(t *testing.T) {
+	c, id := record(t, filepath.Join(t.TempDir(), "cassette.json"))
+	replayer := NewReplayer(c, ReplayerConfig{})
+	client := replayClient(t, replayer)
+
+	// Different client IP and description than recorded.
+	res, err := client.Send(ctx, requests.RegisterTransaction{
+		Amount:          1999,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "192.168.1.1",
+		Language:        maib.LanguageRomanian,
+		Description:     "Order for Jane Doe",
+	})
+	assert.Nil(t, err)
+	registered, err := requests.DecodeResponse[requests.RegisterTransactionResult](res)
+	assert.Nil(t, err)
+	assert.Equal(t, id, registered.TransactionID)
+
+	status := requests.TransactionStatus{TransactionID: id, ClientIPAddress: "192.168.1.1"}
+	res, err = client.Send(ctx, status)
+	assert.Nil(t, err)
+	result, err := requests.DecodeResponse[requests.TransactionStatusResult](res)
+	assert.Nil(t, err)
+	assert.Equal(t, maib.ResultOk, result.Result)
+
+	// Every interaction is replayed once.
+	_, err = client.Send(ctx, status)
+	unmatched := &UnmatchedError{}
+	assert.ErrorAs(t, err, &unmatched)
+	assert.Equal(t, "c", unmatched.Request.Get("command"))
+
+	assert.Len(t, replayer.Unused(), 1)
+	_, err = client.Send(ctx, requests.ReverseTransaction{TransactionID: id, Amount: 5000})
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+	assert.Empty(t, replayer.Unused())
+}
+
+func TestReplayer_Matcher(t *testing.T) {
+	c := Cassette{Interactions: []Interaction{
+		{Request: url.Values{"command": {"v"}, "amount": {"100"}}, Status: 200, Response: "TRANSACTION_ID: first\n"},
+		{Request: url.Values{"command": {"v"}, "amount": {"200"}}, Status: 200, Response: "TRANSACTION_ID: second\n"},
+		{Request: url.Values{"command": {"b"}}, Status: 500},
+	}}
+	replayer := NewReplayer(c, ReplayerConfig{
+		Matcher: MatchFields("command", "amount"),
+		Repeat:  true,
+	})
+	client := replayClient(t, replayer)
+
+	send := func(amount int) string {
+		res, err := client.Send(ctx, requests.RegisterTransaction{
+			Amount:          amount,
+			Currency:        maib.CurrencyEUR,
+			ClientIPAddress: "127.0.0.1",
+			Language:        maib.LanguageEnglish,
+		})
+		if err != nil {
+			return err.Error()
+		}
+		return fmt.Sprint(res["TRANSACTION_ID"])
+	}
+	assert.Equal(t, "second", send(200))
+	assert.Equal(t, "first", send(100))
+	assert.Equal(t, "first", send(100))
+	assert.Contains(t, send(300), "no recorded interaction")
+
+	_, err := client.Send(ctx, requests.CloseDay{})
+	eCommErr := &maib.ECommError{}
+	assert.ErrorAs(t, err, &eCommErr)
+	assert.Equal(t, 500, eCommErr.Code)
+}
+
+func TestMatchAllExcept(t *testing.T) {
+	match := MatchAllExcept("client_ip_addr")
+	recorded := url.Values{"command": {"c"}, "trans_id": {"a"}, "client_ip_addr": {Scrubbed}}
+	assert.True(t, match(recorded, url.Values{"command": {"c"}, "trans_id": {"a"}, "client_ip_addr": {"1.1.1.1"}}))
+	assert.True(t, match(recorded, url.Values{"command": {"c"}, "trans_id": {"a"}}))
+	assert.False(t, match(recorded, url.Values{"command": {"c"}, "trans_id": {"b"}}))
+	assert.False(t, match(recorded, url.Values{"command": {"c"}, "trans_id": {"a"}, "amount": {"1"}}))
+}
+
+// recordingTB records test failures.
+type recordingTB struct {
+	testing.TB
+	errors []string
+}
+
+func (tb *recordingTB) Errorf(format string, args ...any) {
+	tb.errors = append(tb.errors, fmt.Sprintf(format, args...))
+}
+
+func TestReplayer_FailsTest(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	replayer := NewReplayer(Cassette{}, ReplayerConfig{TB: tb})
+
+	req, _ := http.NewRequest(http.MethodPost, "https://ecomm.example/?command=b", nil)
+	_, err := replayer.RoundTrip(req)
+	assert.ErrorAs(t, err, new(*UnmatchedError))
+	assert.Len(t, tb.errors, 1)
+
+	req, _ = http.NewRequest(http.MethodPost, "https://ecomm.example/", nil)
+	_, err = replayer.RoundTrip(req)
+	assert.ErrorIs(t, err, ErrNoPayload)
+}
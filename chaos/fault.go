@@ -0,0 +1,152 @@
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrRefused is returned by [Refuse]. The request has not reached the
+	// server, so it is safe to repeat.
+	ErrRefused = errors.New("chaos: connection refused")
+
+	// ErrDropped is returned by [DropAfterWrite]. The request has reached the
+	// server, so its outcome is unknown.
+	ErrDropped = errors.New("chaos: connection dropped after the request was written")
+)
+
+// faultKind is the behaviour of a [Fault].
+type faultKind int
+
+const (
+	faultNone faultKind = iota
+	faultLatency
+	faultRefuse
+	faultDrop
+	faultTruncate
+	faultStatus
+	faultErrorBody
+)
+
+// Fault is a misbehaviour injected by the [Transport]. The zero value passes
+// the request through unchanged. Create it with [Latency], [Refuse],
+// [DropAfterWrite], [Truncate], [Status] or [ErrorBody].
+type Fault struct {
+	kind    faultKind
+	delay   time.Duration
+	n       int
+	status  int
+	message string
+}
+
+// None passes the request through unchanged. It is the zero [Fault].
+func None() Fault {
+	return Fault{}
+}
+
+// Latency delays the request by d, then passes it through. Latency faults from
+// several matching rules add up.
+func Latency(d time.Duration) Fault {
+	return Fault{kind: faultLatency, delay: d}
+}
+
+// Refuse fails the request with [ErrRefused] without sending it.
+func Refuse() Fault {
+	return Fault{kind: faultRefuse}
+}
+
+// DropAfterWrite sends the request, discards the response, and fails with
+// [ErrDropped], as if the connection broke while waiting for the response.
+func DropAfterWrite() Fault {
+	return Fault{kind: faultDrop}
+}
+
+// Truncate sends the request and cuts the response body after n bytes.
+// Reading past them fails with [io.ErrUnexpectedEOF], as if the connection
+// broke while reading the response.
+func Truncate(n int) Fault {
+	return Fault{kind: faultTruncate, n: n}
+}
+
+// Status responds with the HTTP status code and an empty body, without
+// sending the request.
+func Status(code int) Fault {
+	return Fault{kind: faultStatus, status: code}
+}
+
+// ErrorBody responds with 200 and an "error: message" body, without sending
+// the request.
+func ErrorBody(message string) Fault {
+	return Fault{kind: faultErrorBody, message: message}
+}
+
+// String describes the fault, e.g. "latency 2s" or "status 503".
+func (f Fault) String() string {
+	switch f.kind {
+	case faultLatency:
+		return fmt.Sprintf("latency %s", f.delay)
+	case faultRefuse:
+		return "refuse"
+	case faultDrop:
+		return "drop after write"
+	case faultTruncate:
+		return fmt.Sprintf("truncate %d", f.n)
+	case faultStatus:
+		return fmt.Sprintf("status %d", f.status)
+	case faultErrorBody:
+		return fmt.Sprintf("error body %q", f.message)
+	default:
+		return "none"
+	}
+}
+
+// IsNone reports whether the fault passes the request through unchanged.
+func (f Fault) IsNone() bool {
+	return f.kind == faultNone
+}
+
+// respond returns a response that was not produced by the server.
+func respond(req *http.Request, status int, body string) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        make(http.Header),
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
+
+// truncatedBody returns n bytes of the underlying body, then
+// [io.ErrUnexpectedEOF].
+type truncatedBody struct {
+	body      io.ReadCloser
+	remaining int
+}
+
+func (b *truncatedBody) Read(p []byte) (int, error) {
+	if b.remaining <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if len(p) > b.remaining {
+		p = p[:b.remaining]
+	}
+	n, err := b.body.Read(p)
+	b.remaining -= n
+	if err == io.EOF {
+		// The body was shorter than n, nothing to cut.
+		return n, io.EOF
+	}
+	return n, err
+}
+
+func (b *truncatedBody) Close() error {
+	return b.body.Close()
+}
@@ -0,0 +1,227 @@
+/*
+Package chaos injects faults into the traffic between [maib.Client] and the
+ECommerce system, to test retries, timeouts and ambiguous outcomes around
+[maib.Client.Send] without a real server.
+
+A [Transport] wraps the transport of the client via maib.Config.WrapTransport:
+
+	faults, err := chaos.New(chaos.Config{
+		Rules: []chaos.Rule{
+			{Probability: 0.2, Fault: chaos.Latency(3 * time.Second)},
+			{Command: "t", Probability: 0.05, Fault: chaos.DropAfterWrite()},
+		},
+	})
+	client, err := maib.NewClient(maib.Config{
+		// ...
+		WrapTransport: faults.Wrap,
+	})
+
+Faults are chosen by a [Script], applied to consecutive requests in order, and
+then by [Rule] probabilities.
+*/
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+)
+
+// Rule injects the fault into a share of the matching requests.
+type Rule struct {
+	// Command of the requests, like "c" for TransactionStatus. Optional. If
+	// empty, every request matches.
+	Command string
+
+	// Share of the matching requests that get the fault, from 0 to 1.
+	Probability float64
+
+	Fault Fault
+}
+
+// Script is a sequence of faults applied to consecutive requests, one per
+// request. Use [None] to let a request through.
+type Script []Fault
+
+// Config is the configuration required to set up a [Transport].
+type Config struct {
+	// Faults for the first requests. Once the script is exhausted, the rules
+	// apply. Optional.
+	Script Script
+
+	// Rules rolled for every request after the script, in order. Latency faults
+	// add up, the first other fault ends the roll. Optional.
+	Rules []Rule
+
+	// Seed of the random source, so that runs are reproducible. Default is 0.
+	Seed int64
+
+	// Source of time for latency. Default is [clock.Real].
+	Clock clock.Clock
+
+	// Transport used by [Transport.RoundTrip]. Default is
+	// [http.DefaultTransport]. Ignored by [Transport.Wrap].
+	Base http.RoundTripper
+}
+
+// Injection is a request that went through the [Transport].
+type Injection struct {
+	// Command of the request.
+	Command string
+
+	// Injected faults. Empty if the request was passed through.
+	Faults []Fault
+}
+
+// Transport is an [http.RoundTripper] that injects faults. It is safe for
+// concurrent use.
+//
+// Must be initiated with [New].
+type Transport struct {
+	clock clock.Clock
+	base  http.RoundTripper
+	rules []Rule
+
+	mu      sync.Mutex
+	script  Script
+	rand    *rand.Rand
+	history []Injection
+}
+
+// New validates the configuration and returns a *[Transport].
+func New(config Config) (*Transport, error) {
+	for i, rule := range config.Rules {
+		if rule.Probability < 0 || rule.Probability > 1 {
+			return nil, fmt.Errorf("rule %d: probability must be between 0 and 1", i)
+		}
+	}
+
+	t := &Transport{
+		clock:  config.Clock,
+		base:   config.Base,
+		rules:  config.Rules,
+		script: append(Script(nil), config.Script...),
+		rand:   rand.New(rand.NewSource(config.Seed)),
+	}
+	if t.clock == nil {
+		t.clock = clock.Real
+	}
+	if t.base == nil {
+		t.base = http.DefaultTransport
+	}
+	return t, nil
+}
+
+// RoundTrip sends the request through Config.Base, injecting the faults.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.roundTrip(t.base, req)
+}
+
+// Wrap returns an [http.RoundTripper] that sends requests through base,
+// injecting the faults. It is meant for maib.Config.WrapTransport. Every
+// wrapped transport shares the script, the random source and the history.
+func (t *Transport) Wrap(base http.RoundTripper) http.RoundTripper {
+	return wrapped{transport: t, base: base}
+}
+
+type wrapped struct {
+	transport *Transport
+	base      http.RoundTripper
+}
+
+func (w wrapped) RoundTrip(req *http.Request) (*http.Response, error) {
+	return w.transport.roundTrip(w.base, req)
+}
+
+// History returns every request that went through the transport, in order.
+func (t *Transport) History() []Injection {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Injection(nil), t.history...)
+}
+
+func (t *Transport) roundTrip(base http.RoundTripper, req *http.Request) (*http.Response, error) {
+	faults := t.choose(req)
+
+	for _, fault := range faults {
+		switch fault.kind {
+		case faultLatency:
+			err := t.sleep(req.Context(), fault.delay)
+			if err != nil {
+				return nil, err
+			}
+		case faultRefuse:
+			return nil, ErrRefused
+		case faultStatus:
+			return respond(req, fault.status, ""), nil
+		case faultErrorBody:
+			return respond(req, http.StatusOK, "error: "+fault.message), nil
+		case faultDrop:
+			res, err := base.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			_ = res.Body.Close()
+			return nil, ErrDropped
+		case faultTruncate:
+			res, err := base.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			res.Body = &truncatedBody{body: res.Body, remaining: fault.n}
+			res.ContentLength = -1
+			return res, nil
+		}
+	}
+	return base.RoundTrip(req)
+}
+
+// choose picks the faults for the request and records them in the history.
+func (t *Transport) choose(req *http.Request) []Fault {
+	command := req.URL.Query().Get("command")
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var faults []Fault
+	if len(t.script) > 0 {
+		if fault := t.script[0]; !fault.IsNone() {
+			faults = append(faults, fault)
+		}
+		t.script = t.script[1:]
+	} else {
+		for _, rule := range t.rules {
+			if rule.Command != "" && rule.Command != command {
+				continue
+			}
+			if rule.Fault.IsNone() || t.rand.Float64() >= rule.Probability {
+				continue
+			}
+			faults = append(faults, rule.Fault)
+			if rule.Fault.kind != faultLatency {
+				break
+			}
+		}
+	}
+
+	t.history = append(t.history, Injection{
+		Command: command,
+		Faults:  faults,
+	})
+	return faults
+}
+
+// sleep waits for d, or until the context is done.
+func (t *Transport) sleep(ctx context.Context, d time.Duration) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.clock.After(d):
+		return nil
+	}
+}
@@ -0,0 +1,196 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/maibtest"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+var ctx = context.Background()
+
+var registerRequest = requests.RegisterTransaction{
+	Amount:          1000,
+	Currency:        maib.CurrencyMDL,
+	ClientIPAddress: "127.0.0.1",
+	Language:        maib.LanguageRomanian,
+}
+
+func newClient(t *testing.T, server *maibtest.Server, faults *Transport) *maib.Client {
+	t.Helper()
+	config := server.Config()
+	config.WrapTransport = faults.Wrap
+	client, err := maib.NewClient(config)
+	assert.Nil(t, err)
+	return client
+}
+
+func TestNew_InvalidProbability(t *testing.T) {
+	_, err := New(Config{Rules: []Rule{{Probability: 1.5, Fault: Refuse()}}})
+	assert.ErrorContains(t, err, "rule 0")
+}
+
+func TestTransport_Script(t *testing.T) {
+	server := maibtest.NewServer(t, maibtest.Config{})
+	faults, err := New(Config{
+		Script: Script{
+			Refuse(),
+			DropAfterWrite(),
+			Status(http.StatusServiceUnavailable),
+			ErrorBody("ecommerce is down"),
+			Truncate(10),
+			None(),
+		},
+	})
+	assert.Nil(t, err)
+	client := newClient(t, server, faults)
+
+	// Not sent.
+	_, err = client.Send(ctx, registerRequest)
+	assert.ErrorIs(t, err, ErrRefused)
+	assert.Len(t, server.Transactions(), 0)
+
+	// Sent, but the outcome is unknown.
+	_, err = client.Send(ctx, registerRequest)
+	assert.ErrorIs(t, err, ErrDropped)
+	assert.Len(t, server.Transactions(), 1)
+
+	_, err = client.Send(ctx, registerRequest)
+	eCommErr := &maib.ECommError{}
+	assert.ErrorAs(t, err, &eCommErr)
+	assert.Equal(t, http.StatusServiceUnavailable, eCommErr.Code)
+
+	_, err = client.Send(ctx, registerRequest)
+	assert.ErrorAs(t, err, &eCommErr)
+	assert.Equal(t, "error: ecommerce is down", eCommErr.Body)
+
+	_, err = client.Send(ctx, registerRequest)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Len(t, server.Transactions(), 2)
+
+	// The script is exhausted, and there are no rules.
+	_, err = client.Send(ctx, registerRequest)
+	assert.Nil(t, err)
+	_, err = client.Send(ctx, registerRequest)
+	assert.Nil(t, err)
+
+	history := faults.History()
+	assert.Len(t, history, 7)
+	assert.Equal(t, "v", history[0].Command)
+	assert.Equal(t, []Fault{Refuse()}, history[0].Faults)
+	assert.Empty(t, history[5].Faults)
+}
+
+func TestTransport_Rules(t *testing.T) {
+	server := maibtest.NewServer(t, maibtest.Config{})
+	faults, err := New(Config{
+		Seed: 42,
+		Rules: []Rule{
+			{Command: "b", Probability: 1, Fault: Status(http.StatusInternalServerError)},
+			{Command: "v", Probability: 0.5, Fault: Refuse()},
+		},
+	})
+	assert.Nil(t, err)
+	client := newClient(t, server, faults)
+
+	_, err = client.Send(ctx, requests.CloseDay{})
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+
+	refused := 0
+	for i := 0; i < 200; i++ {
+		_, err = client.Send(ctx, registerRequest)
+		if errors.Is(err, ErrRefused) {
+			refused++
+		} else {
+			assert.Nil(t, err)
+		}
+	}
+	assert.InDelta(t, 100, refused, 30)
+	assert.Len(t, server.Transactions(), 200-refused)
+
+	// The same seed gives the same faults.
+	again, err := New(Config{
+		Seed: 42,
+		Rules: []Rule{
+			{Command: "b", Probability: 1, Fault: Status(http.StatusInternalServerError)},
+			{Command: "v", Probability: 0.5, Fault: Refuse()},
+		},
+		Base: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return respond(req, http.StatusOK, ""), nil
+		}),
+	})
+	assert.Nil(t, err)
+	for _, injection := range faults.History() {
+		req, _ := http.NewRequest(http.MethodPost, "https://ecomm.example/?command="+injection.Command, nil)
+		_, _ = again.RoundTrip(req)
+	}
+	assert.Equal(t, faults.History(), again.History())
+}
+
+func TestTransport_Latency(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	faults, err := New(Config{
+		Clock: fake,
+		Rules: []Rule{
+			{Probability: 1, Fault: Latency(time.Second)},
+			{Probability: 1, Fault: Latency(2 * time.Second)},
+			{Probability: 1, Fault: ErrorBody("late")},
+		},
+		Base: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			t.Error("request must not be sent")
+			return nil, nil
+		}),
+	})
+	assert.Nil(t, err)
+
+	done := make(chan string)
+	go func() {
+		req, _ := http.NewRequest(http.MethodPost, "https://ecomm.example/?command=c", nil)
+		res, err := faults.RoundTrip(req)
+		assert.Nil(t, err)
+		body, _ := io.ReadAll(res.Body)
+		done <- string(body)
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	fake.BlockUntil(1)
+	fake.Advance(2 * time.Second)
+	assert.Equal(t, "error: late", <-done)
+
+	// The latency is cut short by the context.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(timeoutCtx, http.MethodPost, "https://ecomm.example/?command=c", nil)
+	_, err = faults.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestTruncatedBody(t *testing.T) {
+	body := &truncatedBody{body: io.NopCloser(strings.NewReader("RESULT: OK\n")), remaining: 4}
+	read, err := io.ReadAll(body)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	assert.Equal(t, "RESU", string(read))
+
+	// Bodies shorter than the limit are not cut.
+	body = &truncatedBody{body: io.NopCloser(strings.NewReader("OK")), remaining: 4}
+	read, err = io.ReadAll(body)
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", string(read))
+}
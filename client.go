@@ -1,12 +1,15 @@
 package maib
 
 import (
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
+	"time"
 
 	"software.sslmate.com/src/go-pkcs12"
 )
@@ -22,6 +25,19 @@ import (
 type Client struct {
 	httpClient              *http.Client
 	merchantHandlerEndpoint string
+	secondaryEndpoint       string
+	failoverCooldown        time.Duration
+	primaryHealth           health
+	certificate             *x509.Certificate
+
+	idempotencyStore IdempotencyStore
+	idempotencyTTL   time.Duration
+	idempotencyLocks keyedMutex
+	transactionStore TransactionStore
+	logger           *slog.Logger
+	recorder         Recorder
+	onStoreError     func(context.Context, error)
+	now              func() time.Time
 }
 
 // Config is the configuration required to set up a [Client].
@@ -30,22 +46,89 @@ type Config struct {
 	PFXPath string
 	// Passphrase to the certificate.
 	Passphrase string
-	// API communication URL issued by MAIB.
+	// API communication URL issued by MAIB. Default is the endpoint of the
+	// Environment.
 	MerchantHandlerEndpoint string
+
+	// Preset of the environment, [EnvironmentTest] or [EnvironmentProduction].
+	// Optional. Sets the defaults of MerchantHandlerEndpoint,
+	// SecondaryMerchantHandlerEndpoint and RootCAs, and rejects endpoints of
+	// another environment.
+	Environment Environment
+
+	// Endpoint used for read-only commands, i.e. TransactionStatus, while
+	// MerchantHandlerEndpoint is failing. Other commands are never sent to it,
+	// see [FailoverRefusedError]. Optional.
+	SecondaryMerchantHandlerEndpoint string
+
+	// How long MerchantHandlerEndpoint is considered failing after a network
+	// error or a 5xx response. Default is 30 seconds.
+	FailoverCooldown time.Duration
+
+	// Pool of CAs used to verify the server certificate. Optional. Default is the
+	// system pool.
+	RootCAs *x509.CertPool
+
+	// Wraps the mutual TLS transport, e.g. to inject faults or record traffic in
+	// tests. Optional.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
+
+	// Stores the results of requests sent with an idempotency key, see
+	// [WithIdempotencyKey]. Default is a new [MemoryIdempotencyStore].
+	IdempotencyStore IdempotencyStore
+
+	// How long the result of a request sent with an idempotency key is kept.
+	// Pending records of requests with an unknown outcome expire as well.
+	// Default is 24 hours.
+	IdempotencyTTL time.Duration
+
+	// Stores the transactions created by requests sent with a merchant
+	// reference, see [WithMerchantReference]. Default is a new
+	// [MemoryTransactionStore].
+	TransactionStore TransactionStore
+
+	// Logs every sent request with its command, transaction ID and merchant
+	// reference: successful ones at debug level, failed ones at warning level.
+	// Optional.
+	Logger *slog.Logger
+
+	// Records every request before it is sent, and again with its outcome, e.g.
+	// a journal.Journal. Optional.
+	Recorder Recorder
+
+	// Called when a request was sent, but saving its idempotency record,
+	// transaction record or exchange failed. [Client.Send] doesn't return the
+	// error, because the request may have been executed. Optional. The error is
+	// also logged at error level.
+	OnStoreError func(ctx context.Context, err error)
 }
 
 // NewClient reads and parses the PFX certificate file and returns a *[Client]
 // that uses the certificate for mutual TLS.
 func NewClient(config Config) (*Client, error) {
+	config, err := config.withEnvironment()
+	if err != nil {
+		return nil, err
+	}
+	httpClient, certificate, err := newHTTPClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(config, httpClient, certificate)
+}
+
+// newHTTPClient reads the PFX certificate file and returns an HTTP client that
+// uses it for mutual TLS, along with the parsed certificate.
+func newHTTPClient(config Config) (*http.Client, *x509.Certificate, error) {
 	// Read pfx certificate
 	pfxBytes, err := os.ReadFile(config.PFXPath)
 	if err != nil {
-		return nil, fmt.Errorf("read certificate: %w", err)
+		return nil, nil, fmt.Errorf("read certificate: %w", err)
 	}
 	// Decode certificate
 	privateKey, certificate, caArray, err := pkcs12.DecodeChain(pfxBytes, config.Passphrase)
 	if err != nil {
-		return nil, fmt.Errorf("load certificate: %w", err)
+		return nil, nil, fmt.Errorf("load certificate: %w", err)
 	}
 	// Parse CAs
 	caPool := x509.NewCertPool()
@@ -61,23 +144,68 @@ func NewClient(config Config) (*Client, error) {
 	}
 	tlsConfig := &tls.Config{
 		ClientCAs:    caPool,
+		RootCAs:      config.RootCAs,
 		Certificates: []tls.Certificate{tlsCertificate},
 		MinVersion:   tls.VersionTLS12,
 	}
+	var transport http.RoundTripper = &http.Transport{
+		TLSClientConfig: tlsConfig,
+	}
+	if config.WrapTransport != nil {
+		transport = config.WrapTransport(transport)
+	}
 	httpClient := &http.Client{
-		Transport: &http.Transport{
-			TLSClientConfig: tlsConfig,
-		},
+		Transport: transport,
 	}
+	return httpClient, certificate, nil
+}
 
+// newClient returns a *[Client] that sends requests with the HTTP client.
+func newClient(config Config, httpClient *http.Client, certificate *x509.Certificate) (*Client, error) {
 	// Parse merchantHandlerEndpoint to check for malformed URL before any actual requests
-	_, err = url.Parse(config.MerchantHandlerEndpoint)
+	_, err := url.Parse(config.MerchantHandlerEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("parse merchant handler endpoint: %w", err)
 	}
+	_, err = url.Parse(config.SecondaryMerchantHandlerEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse secondary merchant handler endpoint: %w", err)
+	}
 
-	return &Client{
+	c := &Client{
 		httpClient:              httpClient,
 		merchantHandlerEndpoint: config.MerchantHandlerEndpoint,
-	}, nil
+		secondaryEndpoint:       config.SecondaryMerchantHandlerEndpoint,
+		failoverCooldown:        config.FailoverCooldown,
+		certificate:             certificate,
+		idempotencyStore:        config.IdempotencyStore,
+		idempotencyTTL:          config.IdempotencyTTL,
+		transactionStore:        config.TransactionStore,
+		logger:                  config.Logger,
+		recorder:                config.Recorder,
+		onStoreError:            config.OnStoreError,
+		now:                     time.Now,
+	}
+	if c.idempotencyStore == nil {
+		c.idempotencyStore = NewMemoryIdempotencyStore()
+	}
+	if c.idempotencyTTL <= 0 {
+		c.idempotencyTTL = defaultIdempotencyTTL
+	}
+	if c.failoverCooldown <= 0 {
+		c.failoverCooldown = defaultFailoverCooldown
+	}
+	if c.transactionStore == nil {
+		c.transactionStore = NewMemoryTransactionStore()
+	}
+	return c, nil
+}
+
+// CertificateExpiry returns the time after which the client certificate is no
+// longer valid. The certificate must be renewed with MAIB before that.
+func (c *Client) CertificateExpiry() time.Time {
+	if c.certificate == nil {
+		return time.Time{}
+	}
+	return c.certificate.NotAfter
 }
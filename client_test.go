@@ -1,22 +1,31 @@
 package maib
 
 import (
+	"io"
+	"net/http"
 	"net/url"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"software.sslmate.com/src/go-pkcs12"
-)
 
-const (
-	clientCertPath = "testdata/certs/client.pfx"
-	clientCertPass = "password"
+	"github.com/NikSays/go-maib-ecomm/v2/testpki"
 )
 
+const clientCertPass = "password"
+
+// clientCertPath generates a client certificate and returns the path to it.
+func clientCertPath(t *testing.T) string {
+	ca, err := testpki.NewCA(testpki.Options{})
+	assert.Nil(t, err)
+	return ca.ClientPFXFile(t, clientCertPass, testpki.Options{})
+}
+
 func TestNewClient_OK(t *testing.T) {
 	_, err := NewClient(Config{
-		PFXPath:                 clientCertPath,
+		PFXPath:                 clientCertPath(t),
 		Passphrase:              clientCertPass,
 		MerchantHandlerEndpoint: "",
 	})
@@ -26,7 +35,7 @@ func TestNewClient_OK(t *testing.T) {
 
 func TestNewClient_InvalidPath(t *testing.T) {
 	_, err := NewClient(Config{
-		PFXPath:                 clientCertPath + "wrong",
+		PFXPath:                 clientCertPath(t) + "wrong",
 		Passphrase:              clientCertPass,
 		MerchantHandlerEndpoint: "",
 	})
@@ -36,7 +45,7 @@ func TestNewClient_InvalidPath(t *testing.T) {
 
 func TestNewClient_InvalidPass(t *testing.T) {
 	_, err := NewClient(Config{
-		PFXPath:                 clientCertPath,
+		PFXPath:                 clientCertPath(t),
 		Passphrase:              clientCertPass + "wrong",
 		MerchantHandlerEndpoint: "",
 	})
@@ -45,7 +54,7 @@ func TestNewClient_InvalidPass(t *testing.T) {
 }
 func TestNewClient_InvalidEndpoint(t *testing.T) {
 	_, err := NewClient(Config{
-		PFXPath:                 clientCertPath,
+		PFXPath:                 clientCertPath(t),
 		Passphrase:              clientCertPass,
 		MerchantHandlerEndpoint: ":",
 	})
@@ -53,3 +62,33 @@ func TestNewClient_InvalidEndpoint(t *testing.T) {
 	var urlErr *url.Error
 	assert.ErrorAs(t, err, &urlErr)
 }
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestNewClient_WrapTransport(t *testing.T) {
+	var wrapped http.RoundTripper
+	client, err := NewClient(Config{
+		PFXPath:                 clientCertPath(t),
+		Passphrase:              clientCertPass,
+		MerchantHandlerEndpoint: "https://ecomm.example/MerchantHandler",
+		WrapTransport: func(base http.RoundTripper) http.RoundTripper {
+			wrapped = base
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(strings.NewReader("RESULT: OK\n")),
+				}, nil
+			})
+		},
+	})
+	assert.Nil(t, err)
+	assert.IsType(t, &http.Transport{}, wrapped)
+
+	res, err := client.Send(ctx, testRequest{true})
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"RESULT": "OK"}, res)
+}
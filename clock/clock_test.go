@@ -0,0 +1,47 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+	assert.Equal(t, start, fake.Now())
+
+	short := fake.After(time.Second)
+	long := fake.After(time.Minute)
+	assert.Equal(t, 2, fake.Waiters())
+
+	fake.Advance(time.Second)
+	assert.Equal(t, start.Add(time.Second), <-short)
+	assert.Equal(t, 1, fake.Waiters())
+	select {
+	case <-long:
+		t.Error("fired too early")
+	default:
+	}
+
+	fake.Set(start.Add(time.Hour))
+	assert.Equal(t, start.Add(time.Hour), <-long)
+	assert.Equal(t, 0, fake.Waiters())
+
+	// Non-positive durations fire immediately.
+	assert.Equal(t, start.Add(time.Hour), <-fake.After(0))
+}
+
+func TestFake_BlockUntil(t *testing.T) {
+	fake := NewFake(time.Time{})
+	fired := make(chan struct{})
+	go func() {
+		<-fake.After(time.Second)
+		close(fired)
+	}()
+
+	fake.BlockUntil(1)
+	fake.Advance(time.Second)
+	<-fired
+}
@@ -0,0 +1,299 @@
+/*
+Package closeday runs the [requests.CloseDay] command once a day at a
+configured local time.
+
+The [Scheduler] persists the last successful run in a [Store]. When it starts
+after downtime, it immediately closes the business day that was missed. A
+single CloseDay closes every transaction that is still open, so one request is
+enough regardless of how many days were missed; the count is reported in
+[Run.Missed].
+
+Transient errors, such as network failures and 5xx responses, are retried
+with exponential backoff. All waiting is done through a [clock.Clock], so the
+scheduler can be tested with [clock.Fake].
+*/
+package closeday
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+const (
+	defaultAt         = "23:59"
+	defaultLocation   = "Europe/Chisinau"
+	defaultRetries    = 3
+	defaultRetryDelay = time.Minute
+)
+
+// Config is the configuration required to set up a [Scheduler].
+type Config struct {
+	// Sender used to send CloseDay. Required.
+	Sender maib.Sender
+
+	// Local time of day in "HH:MM" format. Default is "23:59".
+	At string
+
+	// Time zone in which At is interpreted. Default is Europe/Chisinau.
+	Location *time.Location
+
+	// Storage for the last successful run. Default is a new [MemoryStore], which
+	// disables catch-up across restarts.
+	Store Store
+
+	// Source of time. Default is [clock.Real].
+	Clock clock.Clock
+
+	// How many times a transient error is retried before giving up until the next
+	// day. Default is 3. Use a negative value to disable retries.
+	Retries int
+
+	// Delay before the first retry. It is doubled after each attempt. Default is
+	// one minute.
+	RetryDelay time.Duration
+
+	// Called after each run, successful or not. Optional.
+	OnRun func(Run)
+}
+
+// Run describes one scheduled execution of CloseDay.
+type Run struct {
+	// Scheduled time of the business day that was closed.
+	Scheduled time.Time
+
+	// Number of earlier scheduled runs that were missed and are covered by this
+	// run.
+	Missed int
+
+	// Number of requests sent.
+	Attempts int
+
+	// Decoded response. Valid only if Err is nil.
+	Result requests.CloseDayResult
+
+	// Error of the last attempt, if all attempts have failed.
+	Err error
+}
+
+// ResultError is returned when the ECommerce system responds to CloseDay with
+// a RESULT other than OK.
+type ResultError struct {
+	// Transaction result status.
+	Result maib.ResultEnum
+
+	// Transaction result code returned from Card Suite FO (3 digits).
+	ResultCode int
+}
+
+func (e *ResultError) Error() string {
+	return fmt.Sprintf("close day returned %s (%d)", e.Result, e.ResultCode)
+}
+
+// Scheduler sends CloseDay once a day. Must be initiated with [New].
+type Scheduler struct {
+	sender     maib.Sender
+	hour       int
+	minute     int
+	location   *time.Location
+	store      Store
+	clock      clock.Clock
+	retries    int
+	retryDelay time.Duration
+	onRun      func(Run)
+}
+
+// New validates the configuration and returns a *[Scheduler]. It fails if the
+// default time zone is needed but cannot be loaded.
+func New(config Config) (*Scheduler, error) {
+	if config.Sender == nil {
+		return nil, errors.New("sender is required")
+	}
+
+	at := config.At
+	if at == "" {
+		at = defaultAt
+	}
+	parsedAt, err := time.Parse("15:04", at)
+	if err != nil {
+		return nil, fmt.Errorf("parse time of day: %w", err)
+	}
+
+	location := config.Location
+	if location == nil {
+		location, err = time.LoadLocation(defaultLocation)
+		if err != nil {
+			return nil, fmt.Errorf("load default location: %w", err)
+		}
+	}
+
+	s := &Scheduler{
+		sender:     config.Sender,
+		hour:       parsedAt.Hour(),
+		minute:     parsedAt.Minute(),
+		location:   location,
+		store:      config.Store,
+		clock:      config.Clock,
+		retries:    config.Retries,
+		retryDelay: config.RetryDelay,
+		onRun:      config.OnRun,
+	}
+	if s.store == nil {
+		s.store = NewMemoryStore()
+	}
+	if s.clock == nil {
+		s.clock = clock.Real
+	}
+	if s.retries == 0 {
+		s.retries = defaultRetries
+	} else if s.retries < 0 {
+		s.retries = 0
+	}
+	if s.retryDelay <= 0 {
+		s.retryDelay = defaultRetryDelay
+	}
+	return s, nil
+}
+
+// Run blocks until the context is done, sending CloseDay at every scheduled
+// time. If a scheduled time has passed since the last successful run, CloseDay
+// is sent immediately. If the store has no last run, the first CloseDay is sent
+// at the next scheduled time.
+//
+// Failed runs are reported through Config.OnRun and retried the next day. Run
+// returns early only if the store fails, since continuing could close the same
+// day twice after a restart.
+func (s *Scheduler) Run(ctx context.Context) error {
+	var attempted time.Time
+	for {
+		now := s.clock.Now()
+		lastRun, err := s.store.LastRun(ctx)
+		if err != nil {
+			return fmt.Errorf("get last run: %w", err)
+		}
+
+		due := s.Previous(now)
+		if !lastRun.IsZero() && lastRun.Before(due) && !due.Equal(attempted) {
+			attempted = due
+			run := s.execute(ctx, due)
+			run.Missed = s.count(lastRun, due) - 1
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if run.Err == nil {
+				err = s.store.SaveLastRun(ctx, due)
+			}
+			if s.onRun != nil {
+				s.onRun(run)
+			}
+			if err != nil {
+				return fmt.Errorf("save last run: %w", err)
+			}
+		} else if lastRun.IsZero() {
+			// Remember the most recent schedule, so that the next one is not mistaken
+			// for a missed day if the process restarts right after it.
+			err = s.store.SaveLastRun(ctx, due)
+			if err != nil {
+				return fmt.Errorf("save last run: %w", err)
+			}
+		}
+
+		next := s.Next(s.clock.Now())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock.After(next.Sub(s.clock.Now())):
+		}
+	}
+}
+
+// Next returns the first scheduled time strictly after t.
+func (s *Scheduler) Next(t time.Time) time.Time {
+	t = t.In(s.location)
+	scheduled := s.on(t.Year(), t.Month(), t.Day())
+	if !scheduled.After(t) {
+		scheduled = s.on(t.Year(), t.Month(), t.Day()+1)
+	}
+	return scheduled
+}
+
+// Previous returns the last scheduled time not after t.
+func (s *Scheduler) Previous(t time.Time) time.Time {
+	t = t.In(s.location)
+	scheduled := s.on(t.Year(), t.Month(), t.Day())
+	if scheduled.After(t) {
+		scheduled = s.on(t.Year(), t.Month(), t.Day()-1)
+	}
+	return scheduled
+}
+
+// on returns the scheduled time on the given date.
+func (s *Scheduler) on(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, s.hour, s.minute, 0, 0, s.location)
+}
+
+// count returns the number of scheduled times in (from, to].
+func (s *Scheduler) count(from, to time.Time) int {
+	n := 0
+	for t := s.Next(from); !t.After(to); t = s.Next(t) {
+		n++
+	}
+	return n
+}
+
+// execute sends CloseDay, retrying transient errors.
+func (s *Scheduler) execute(ctx context.Context, scheduled time.Time) Run {
+	run := Run{Scheduled: scheduled}
+	delay := s.retryDelay
+	for {
+		run.Attempts++
+		run.Result, run.Err = s.closeDay(ctx)
+		if run.Err == nil || run.Attempts > s.retries || !isTransient(run.Err) {
+			return run
+		}
+
+		select {
+		case <-ctx.Done():
+			return run
+		case <-s.clock.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (s *Scheduler) closeDay(ctx context.Context) (requests.CloseDayResult, error) {
+	res, err := s.sender.Send(ctx, requests.CloseDay{})
+	if err != nil {
+		return requests.CloseDayResult{}, err
+	}
+	result, err := requests.DecodeResponse[requests.CloseDayResult](res)
+	if err != nil {
+		return requests.CloseDayResult{}, fmt.Errorf("decode response: %w", err)
+	}
+	if result.Result != maib.ResultOk {
+		return result, &ResultError{
+			Result:     result.Result,
+			ResultCode: result.ResultCode,
+		}
+	}
+	return result, nil
+}
+
+// isTransient reports whether the error may go away if the request is retried.
+func isTransient(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if eCommErr := (&maib.ECommError{}); errors.As(err, &eCommErr) {
+		return eCommErr.Code >= 500
+	}
+	// Network failures are wrapped in *url.Error by the HTTP client.
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,251 @@
+package closeday
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+)
+
+// fakeSender returns the scripted errors in order, then succeeds.
+type fakeSender struct {
+	mu     sync.Mutex
+	errors []error
+	calls  int
+}
+
+func (f *fakeSender) Send(_ context.Context, req maib.Request) (map[string]any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+
+	if len(f.errors) > 0 {
+		err := f.errors[0]
+		f.errors = f.errors[1:]
+		return nil, err
+	}
+	return map[string]any{"RESULT": "OK", "RESULT_CODE": 500}, nil
+}
+
+func (f *fakeSender) Calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+func mustLocation(t *testing.T) *time.Location {
+	location, err := time.LoadLocation("Europe/Chisinau")
+	assert.Nil(t, err)
+	return location
+}
+
+// start runs the scheduler in the background and returns a channel with each
+// reported run, and a function that stops the scheduler.
+func start(t *testing.T, config Config) (<-chan Run, func() error) {
+	runs := make(chan Run, 10)
+	config.OnRun = func(run Run) {
+		runs <- run
+	}
+	scheduler, err := New(config)
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- scheduler.Run(ctx)
+	}()
+	return runs, func() error {
+		cancel()
+		return <-done
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.Error(t, err)
+
+	_, err = New(Config{Sender: &fakeSender{}, At: "25:00"})
+	assert.Error(t, err)
+
+	s, err := New(Config{Sender: &fakeSender{}})
+	assert.Nil(t, err)
+	assert.Equal(t, "Europe/Chisinau", s.location.String())
+	assert.Equal(t, 23, s.hour)
+	assert.Equal(t, 59, s.minute)
+}
+
+func TestScheduler_NextPrevious(t *testing.T) {
+	location := mustLocation(t)
+	s, err := New(Config{Sender: &fakeSender{}, Location: location})
+	assert.Nil(t, err)
+
+	morning := time.Date(2025, 3, 10, 9, 0, 0, 0, location)
+	assert.Equal(t, time.Date(2025, 3, 10, 23, 59, 0, 0, location), s.Next(morning))
+	assert.Equal(t, time.Date(2025, 3, 9, 23, 59, 0, 0, location), s.Previous(morning))
+
+	scheduled := time.Date(2025, 3, 10, 23, 59, 0, 0, location)
+	assert.Equal(t, time.Date(2025, 3, 11, 23, 59, 0, 0, location), s.Next(scheduled))
+	assert.Equal(t, scheduled, s.Previous(scheduled))
+
+	// Daylight saving time starts on 2025-03-30 in Moldova.
+	beforeDST := time.Date(2025, 3, 29, 23, 59, 0, 0, location)
+	assert.Equal(t, 23*time.Hour, s.Next(beforeDST).Sub(beforeDST))
+}
+
+func TestScheduler_Run(t *testing.T) {
+	location := mustLocation(t)
+	fakeClock := clock.NewFake(time.Date(2025, 3, 10, 12, 0, 0, 0, location))
+	sender := &fakeSender{}
+	store := NewMemoryStore()
+
+	runs, stop := start(t, Config{
+		Sender: sender,
+		Store:  store,
+		Clock:  fakeClock,
+	})
+
+	// Nothing runs before the scheduled time.
+	fakeClock.BlockUntil(1)
+	assert.Equal(t, 0, sender.Calls())
+
+	fakeClock.Set(time.Date(2025, 3, 10, 23, 59, 0, 0, location))
+	run := <-runs
+	assert.Nil(t, run.Err)
+	assert.Equal(t, 0, run.Missed)
+	assert.Equal(t, 1, run.Attempts)
+	assert.Equal(t, maib.ResultOk, run.Result.Result)
+	assert.Equal(t, 1, sender.Calls())
+
+	fakeClock.BlockUntil(1)
+	lastRun, err := store.LastRun(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, lastRun.Equal(run.Scheduled))
+
+	assert.ErrorIs(t, stop(), context.Canceled)
+}
+
+func TestScheduler_Run_CatchUp(t *testing.T) {
+	location := mustLocation(t)
+	fakeClock := clock.NewFake(time.Date(2025, 3, 13, 8, 0, 0, 0, location))
+	sender := &fakeSender{}
+	store := NewMemoryStore()
+	err := store.SaveLastRun(context.Background(), time.Date(2025, 3, 9, 23, 59, 0, 0, location))
+	assert.Nil(t, err)
+
+	runs, stop := start(t, Config{
+		Sender: sender,
+		Store:  store,
+		Clock:  fakeClock,
+	})
+
+	run := <-runs
+	assert.Nil(t, run.Err)
+	assert.Equal(t, 2, run.Missed)
+	assert.True(t, run.Scheduled.Equal(time.Date(2025, 3, 12, 23, 59, 0, 0, location)))
+	assert.Equal(t, 1, sender.Calls())
+
+	assert.ErrorIs(t, stop(), context.Canceled)
+}
+
+func TestScheduler_Run_Retry(t *testing.T) {
+	location := mustLocation(t)
+	fakeClock := clock.NewFake(time.Date(2025, 3, 11, 8, 0, 0, 0, location))
+	sender := &fakeSender{
+		errors: []error{
+			&url.Error{Op: "Post", URL: "https://example.org", Err: context.DeadlineExceeded},
+			&maib.ECommError{Code: 503},
+		},
+	}
+	store := NewMemoryStore()
+	err := store.SaveLastRun(context.Background(), time.Date(2025, 3, 9, 23, 59, 0, 0, location))
+	assert.Nil(t, err)
+
+	runs, stop := start(t, Config{
+		Sender:     sender,
+		Store:      store,
+		Clock:      fakeClock,
+		RetryDelay: time.Second,
+	})
+
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(time.Second)
+	fakeClock.BlockUntil(1)
+	fakeClock.Advance(2 * time.Second)
+
+	run := <-runs
+	assert.Nil(t, run.Err)
+	assert.Equal(t, 3, run.Attempts)
+	assert.Equal(t, 3, sender.Calls())
+
+	assert.ErrorIs(t, stop(), context.Canceled)
+}
+
+func TestScheduler_Run_PermanentError(t *testing.T) {
+	location := mustLocation(t)
+	fakeClock := clock.NewFake(time.Date(2025, 3, 11, 8, 0, 0, 0, location))
+	sender := &fakeSender{
+		errors: []error{&maib.ECommError{Code: 200, Body: "error: wrong merchant"}},
+	}
+	store := NewMemoryStore()
+	lastRun := time.Date(2025, 3, 9, 23, 59, 0, 0, location)
+	err := store.SaveLastRun(context.Background(), lastRun)
+	assert.Nil(t, err)
+
+	runs, stop := start(t, Config{
+		Sender: sender,
+		Store:  store,
+		Clock:  fakeClock,
+	})
+
+	run := <-runs
+	assert.ErrorAs(t, run.Err, new(*maib.ECommError))
+	assert.Equal(t, 1, run.Attempts)
+
+	// The failed day is not stored, and is not retried until the next schedule.
+	fakeClock.BlockUntil(1)
+	stored, err := store.LastRun(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, stored.Equal(lastRun))
+	assert.Equal(t, 1, sender.Calls())
+
+	fakeClock.Set(time.Date(2025, 3, 11, 23, 59, 0, 0, location))
+	run = <-runs
+	assert.Nil(t, run.Err)
+	assert.Equal(t, 1, run.Missed)
+
+	assert.ErrorIs(t, stop(), context.Canceled)
+}
+
+func TestScheduler_Run_ResultError(t *testing.T) {
+	location := mustLocation(t)
+	fakeClock := clock.NewFake(time.Date(2025, 3, 11, 8, 0, 0, 0, location))
+	store := NewMemoryStore()
+	err := store.SaveLastRun(context.Background(), time.Date(2025, 3, 9, 23, 59, 0, 0, location))
+	assert.Nil(t, err)
+
+	runs, stop := start(t, Config{
+		Sender: failingSender{},
+		Store:  store,
+		Clock:  fakeClock,
+	})
+
+	run := <-runs
+	resultErr := &ResultError{}
+	assert.ErrorAs(t, run.Err, &resultErr)
+	assert.Equal(t, maib.ResultFailed, resultErr.Result)
+	assert.Equal(t, 1, run.Attempts)
+
+	assert.ErrorIs(t, stop(), context.Canceled)
+}
+
+type failingSender struct{}
+
+func (failingSender) Send(context.Context, maib.Request) (map[string]any, error) {
+	return map[string]any{"RESULT": "FAILED", "RESULT_CODE": 914}, nil
+}
@@ -0,0 +1,93 @@
+package closeday
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Store persists the scheduled time of the last successful CloseDay, so that
+// missed days can be caught up after a restart.
+type Store interface {
+	// LastRun returns the scheduled time of the last successful CloseDay, or the
+	// zero time if it has never run.
+	LastRun(ctx context.Context) (time.Time, error)
+
+	// SaveLastRun stores the scheduled time of a successful CloseDay.
+	SaveLastRun(ctx context.Context, t time.Time) error
+}
+
+// MemoryStore is a [Store] that keeps the last run in memory. It is safe for
+// concurrent use.
+type MemoryStore struct {
+	mu      sync.Mutex
+	lastRun time.Time
+}
+
+// NewMemoryStore returns an empty *[MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) LastRun(context.Context) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.lastRun, nil
+}
+
+func (s *MemoryStore) SaveLastRun(_ context.Context, t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastRun = t
+	return nil
+}
+
+// FileStore is a [Store] that keeps the last run in a file as an RFC 3339
+// timestamp. The file is replaced atomically on each save.
+type FileStore struct {
+	// Path to the file. It is created on the first save.
+	Path string
+}
+
+func (s FileStore) LastRun(context.Context) (time.Time, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("read last run: %w", err)
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(string(data)))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse last run: %w", err)
+	}
+	return t, nil
+}
+
+func (s FileStore) SaveLastRun(_ context.Context, t time.Time) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.WriteString(t.Format(time.RFC3339) + "\n")
+	if err != nil {
+		tmp.Close()
+		return fmt.Errorf("write last run: %w", err)
+	}
+	err = tmp.Close()
+	if err != nil {
+		return fmt.Errorf("close temporary file: %w", err)
+	}
+	err = os.Rename(tmp.Name(), s.Path)
+	if err != nil {
+		return fmt.Errorf("replace last run: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package closeday
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	lastRun, err := store.LastRun(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, lastRun.IsZero())
+
+	now := time.Date(2025, 3, 10, 23, 59, 0, 0, time.UTC)
+	assert.Nil(t, store.SaveLastRun(context.Background(), now))
+	lastRun, err = store.LastRun(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, lastRun.Equal(now))
+}
+
+func TestFileStore(t *testing.T) {
+	store := FileStore{Path: filepath.Join(t.TempDir(), "closeday")}
+	lastRun, err := store.LastRun(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, lastRun.IsZero())
+
+	now := time.Date(2025, 3, 10, 23, 59, 0, 0, time.FixedZone("EET", 2*60*60))
+	assert.Nil(t, store.SaveLastRun(context.Background(), now))
+	lastRun, err = store.LastRun(context.Background())
+	assert.Nil(t, err)
+	assert.True(t, lastRun.Equal(now))
+}
+
+func TestFileStore_Malformed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "closeday")
+	assert.Nil(t, os.WriteFile(path, []byte("yesterday"), 0o600))
+
+	_, err := FileStore{Path: path}.LastRun(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/NikSays/go-maib-ecomm/v2/batch"
+)
+
+const batchDescription = "run status, reverse or delete-recurring rows from a CSV or JSONL file"
+
+// runBatch runs the batch command, and returns the exit code.
+func (a *app) runBatch(args []string) int {
+	fs := flag.NewFlagSet("maib batch", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	var conn connection
+	conn.register(fs, a.getenv)
+	input := fs.String("input", "", "CSV or JSONL file with the rows")
+	output := fs.String("output", "", "JSONL file for the results, appended to when resuming (default is the input with .out.jsonl)")
+	command := fs.String("command", "", "command of the rows without a command column: status, reverse or delete-recurring")
+	workers := fs.Int("workers", 4, "number of concurrent requests")
+	rate := fs.Float64("rate", 0, "maximum requests per second, 0 for no limit")
+	retryErrors := fs.Bool("retry-errors", false, "send again the rows that failed in the previous run without being executed")
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		return exitUsage
+	}
+	if *input == "" || fs.NArg() > 0 {
+		fmt.Fprintln(a.stderr, "maib: batch requires -input and no arguments")
+		return exitUsage
+	}
+	if conn.format != "table" && conn.format != "json" {
+		fmt.Fprintf(a.stderr, "maib: unknown format %q\n", conn.format)
+		return exitUsage
+	}
+	if *output == "" {
+		*output = *input + ".out.jsonl"
+	}
+
+	summary, err := a.executeBatch(conn, *input, *output, batch.Command(*command), batch.Config{
+		Workers:     *workers,
+		Rate:        *rate,
+		RetryErrors: *retryErrors,
+	})
+	if err != nil {
+		fmt.Fprintf(a.stderr, "maib: %s\n", err)
+		return exitError
+	}
+	if summary.Failed > 0 {
+		fmt.Fprintf(a.stderr, "maib: %d rows failed, see %s\n", summary.Failed, *output)
+		return exitError
+	}
+	return exitOK
+}
+
+// executeBatch confirms the rows that move money, runs the batch and prints
+// the summary.
+func (a *app) executeBatch(conn connection, input, output string, command batch.Command, config batch.Config) (batch.Summary, error) {
+	rows, err := batch.ReadFile(input, command)
+	if err != nil {
+		return batch.Summary{}, err
+	}
+	reversals := 0
+	for _, row := range rows {
+		if row.Command() == batch.CommandReverse {
+			reversals++
+		}
+	}
+	if reversals > 0 && !conn.yes {
+		ok, err := a.ask(fmt.Sprintf("Reverse %d transactions from %s?", reversals, input))
+		if err != nil {
+			return batch.Summary{}, err
+		}
+		if !ok {
+			return batch.Summary{}, errors.New("aborted")
+		}
+	}
+
+	config.Timeout = conn.timeout
+	config.Sender, err = a.newSender(conn)
+	if err != nil {
+		return batch.Summary{}, err
+	}
+	executor, err := batch.New(config)
+	if err != nil {
+		return batch.Summary{}, err
+	}
+
+	// Stop on interrupt, leaving the remaining rows to be resumed.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	summary, err := executor.RunFile(ctx, input, output, command)
+	if err != nil {
+		return summary, err
+	}
+	return summary, printResult(a.stdout, conn.format, summary)
+}
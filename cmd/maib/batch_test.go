@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/batch"
+	"github.com/NikSays/go-maib-ecomm/v2/maibfake"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+func TestRun_Batch(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "duplicates.csv")
+	require.NoError(t, os.WriteFile(input, []byte(
+		"trans_id,amount\naaaaaaaaaaaaaaaaaaaaaaaaaaa=,100\nbbbbbbbbbbbbbbbbbbbbbbbbbbb=,200\n",
+	), 0o600))
+	fake := maibfake.New()
+	fake.Respond(requests.ReverseTransaction{}, maibfake.Result(requests.ReverseTransactionResult{Result: maib.ResultOk}))
+	args := []string{"batch", "-input", input, "-command", "reverse", "-rate", "100"}
+
+	t.Run("declined", func(t *testing.T) {
+		a, _, stderr := testApp(fake, "n\n")
+		assert.Equal(t, exitError, a.run(args))
+		assert.Contains(t, stderr.String(), "Reverse 2 transactions from "+input+"? [y/N]")
+		assert.Empty(t, fake.Calls())
+		assert.NoFileExists(t, input+".out.jsonl")
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		a, stdout, stderr := testApp(fake, "y\n")
+		require.Equal(t, exitOK, a.run(args), stderr.String())
+		assert.Regexp(t, `(?m)^Succeeded\s+2$`, stdout.String())
+		fake.AssertSentOnce(t, requests.ReverseTransaction{Amount: 200})
+
+		results, err := batch.LoadResults(input + ".out.jsonl")
+		require.NoError(t, err)
+		assert.Len(t, results, 2)
+	})
+
+	t.Run("resumed", func(t *testing.T) {
+		fake.Reset()
+		a, stdout, stderr := testApp(fake, "")
+		require.Equal(t, exitOK, a.run(append(args, "-yes", "-format", "json")), stderr.String())
+		assert.JSONEq(t, `{"total": 2, "skipped": 2, "succeeded": 0, "failed": 0}`, stdout.String())
+		assert.Empty(t, fake.Calls())
+	})
+
+	t.Run("failed rows", func(t *testing.T) {
+		output := filepath.Join(t.TempDir(), "status.jsonl")
+		// TransactionStatus without client_ip_addr fails validation.
+		a, _, stderr := testApp(maibfake.New(), "")
+		assert.Equal(t, exitError, a.run([]string{"batch", "-input", input, "-output", output, "-command", "status"}))
+		assert.Contains(t, stderr.String(), "2 rows failed, see "+output)
+	})
+
+	t.Run("usage", func(t *testing.T) {
+		a, _, _ := testApp(fake, "")
+		assert.Equal(t, exitUsage, a.run([]string{"batch"}))
+	})
+}
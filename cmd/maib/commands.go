@@ -0,0 +1,219 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+// command is a subcommand that sends one request.
+type command struct {
+	name        string
+	description string
+
+	// Registers the flags of the command, and returns a function that builds
+	// the request once the flags are parsed.
+	flags func(fs *flag.FlagSet) func() (maib.Request, error)
+
+	// Decodes the response into the result struct.
+	decode func(res map[string]any) (any, error)
+
+	// Returns the confirmation question for commands that move money. Nil for
+	// the others.
+	confirm func(req maib.Request) string
+}
+
+var commands = []command{
+	{
+		name:        "status",
+		description: "check a transaction with TransactionStatus",
+		flags: func(fs *flag.FlagSet) func() (maib.Request, error) {
+			var req requests.TransactionStatus
+			fs.StringVar(&req.TransactionID, "trans-id", "", "ID of the transaction")
+			fs.StringVar(&req.ClientIPAddress, "client-ip", "", "client's IP address")
+			return func() (maib.Request, error) {
+				return req, nil
+			}
+		},
+		decode: func(res map[string]any) (any, error) {
+			return requests.DecodeResponse[requests.TransactionStatusResult](res)
+		},
+	},
+	{
+		name:        "register",
+		description: "register an SMS or DMS transaction",
+		flags: func(fs *flag.FlagSet) func() (maib.Request, error) {
+			var req requests.RegisterTransaction
+			currency := currencyFlag(maib.CurrencyMDL)
+			transactionType := fs.String("type", "sms", "transaction type: sms or dms")
+			fs.IntVar(&req.Amount, "amount", 0, "amount in minor units, e.g. 1999 for 19.99")
+			fs.Var(&currency, "currency", "currency: MDL, EUR, USD or an ISO4217 numeric code")
+			fs.StringVar(&req.ClientIPAddress, "client-ip", "", "client's IP address")
+			fs.StringVar(&req.Description, "description", "", "transaction details")
+			language := fs.String("language", string(maib.LanguageRomanian), "language of the payment page")
+			return func() (maib.Request, error) {
+				switch *transactionType {
+				case "sms":
+					req.TransactionType = requests.RegisterTransactionSMS
+				case "dms":
+					req.TransactionType = requests.RegisterTransactionDMS
+				default:
+					return nil, fmt.Errorf("unknown transaction type %q", *transactionType)
+				}
+				req.Currency = maib.Currency(currency)
+				req.Language = maib.Language(*language)
+				return req, nil
+			}
+		},
+		decode: func(res map[string]any) (any, error) {
+			return requests.DecodeResponse[requests.RegisterTransactionResult](res)
+		},
+	},
+	{
+		name:        "execute-dms",
+		description: "execute a DMS authorization",
+		flags: func(fs *flag.FlagSet) func() (maib.Request, error) {
+			var req requests.ExecuteDMS
+			currency := currencyFlag(maib.CurrencyMDL)
+			fs.StringVar(&req.TransactionID, "trans-id", "", "ID of the authorized transaction")
+			fs.IntVar(&req.Amount, "amount", 0, "amount in minor units, e.g. 1999 for 19.99")
+			fs.Var(&currency, "currency", "currency: MDL, EUR, USD or an ISO4217 numeric code")
+			fs.StringVar(&req.ClientIPAddress, "client-ip", "", "client's IP address")
+			fs.StringVar(&req.Description, "description", "", "transaction details")
+			return func() (maib.Request, error) {
+				req.Currency = maib.Currency(currency)
+				return req, nil
+			}
+		},
+		decode: func(res map[string]any) (any, error) {
+			return requests.DecodeResponse[requests.ExecuteDMSResult](res)
+		},
+		confirm: func(r maib.Request) string {
+			req := r.(requests.ExecuteDMS)
+			return fmt.Sprintf("Charge %s %s for transaction %s?",
+				formatAmount(req.Amount), currencyFlag(req.Currency), req.TransactionID)
+		},
+	},
+	{
+		name:        "reverse",
+		description: "reverse a transaction, fully or partially",
+		flags: func(fs *flag.FlagSet) func() (maib.Request, error) {
+			var req requests.ReverseTransaction
+			fs.StringVar(&req.TransactionID, "trans-id", "", "ID of the transaction")
+			fs.IntVar(&req.Amount, "amount", 0, "amount to return in minor units, e.g. 1999 for 19.99")
+			fs.BoolVar(&req.SuspectedFraud, "suspected-fraud", false, "reverse because of suspected fraud, full amount only")
+			return func() (maib.Request, error) {
+				return req, nil
+			}
+		},
+		decode: func(res map[string]any) (any, error) {
+			return requests.DecodeResponse[requests.ReverseTransactionResult](res)
+		},
+		confirm: func(r maib.Request) string {
+			req := r.(requests.ReverseTransaction)
+			return fmt.Sprintf("Return %s of transaction %s to the customer?",
+				formatAmount(req.Amount), req.TransactionID)
+		},
+	},
+	{
+		name:        "execute-recurring",
+		description: "charge a saved card with ExecuteRecurring",
+		flags: func(fs *flag.FlagSet) func() (maib.Request, error) {
+			var req requests.ExecuteRecurring
+			currency := currencyFlag(maib.CurrencyMDL)
+			fs.StringVar(&req.BillerClientID, "biller-client-id", "", "ID of the recurring payment")
+			fs.IntVar(&req.Amount, "amount", 0, "amount in minor units, e.g. 1999 for 19.99")
+			fs.Var(&currency, "currency", "currency: MDL, EUR, USD or an ISO4217 numeric code")
+			fs.StringVar(&req.ClientIPAddress, "client-ip", "", "client's IP address")
+			fs.StringVar(&req.Description, "description", "", "transaction details")
+			return func() (maib.Request, error) {
+				req.Currency = maib.Currency(currency)
+				return req, nil
+			}
+		},
+		decode: func(res map[string]any) (any, error) {
+			return requests.DecodeResponse[requests.ExecuteRecurringResult](res)
+		},
+		confirm: func(r maib.Request) string {
+			req := r.(requests.ExecuteRecurring)
+			return fmt.Sprintf("Charge %s %s to the card saved as %s?",
+				formatAmount(req.Amount), currencyFlag(req.Currency), req.BillerClientID)
+		},
+	},
+	{
+		name:        "delete-recurring",
+		description: "delete a recurring or oneClick payment",
+		flags: func(fs *flag.FlagSet) func() (maib.Request, error) {
+			var req requests.DeleteRecurring
+			fs.StringVar(&req.BillerClientID, "biller-client-id", "", "ID of the recurring or oneClick payment")
+			return func() (maib.Request, error) {
+				return req, nil
+			}
+		},
+		decode: func(res map[string]any) (any, error) {
+			return requests.DecodeResponse[requests.DeleteRecurringResult](res)
+		},
+	},
+	{
+		name:        "close-day",
+		description: "close the business day",
+		flags: func(fs *flag.FlagSet) func() (maib.Request, error) {
+			return func() (maib.Request, error) {
+				return requests.CloseDay{}, nil
+			}
+		},
+		decode: func(res map[string]any) (any, error) {
+			return requests.DecodeResponse[requests.CloseDayResult](res)
+		},
+	},
+}
+
+// findCommand returns the command with the name.
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+// currencyFlag is a [maib.Currency] that can be set by its code or number.
+type currencyFlag maib.Currency
+
+var currencyCodes = map[string]maib.Currency{
+	"MDL": maib.CurrencyMDL,
+	"EUR": maib.CurrencyEUR,
+	"USD": maib.CurrencyUSD,
+}
+
+func (c *currencyFlag) Set(s string) error {
+	if currency, ok := currencyCodes[strings.ToUpper(s)]; ok {
+		*c = currencyFlag(currency)
+		return nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("unknown currency %q", s)
+	}
+	*c = currencyFlag(n)
+	return nil
+}
+
+func (c currencyFlag) String() string {
+	for code, currency := range currencyCodes {
+		if currency == maib.Currency(c) {
+			return code
+		}
+	}
+	return strconv.Itoa(int(c))
+}
+
+// formatAmount formats minor units with 2 decimals.
+func formatAmount(amount int) string {
+	return fmt.Sprintf("%d.%02d", amount/100, amount%100)
+}
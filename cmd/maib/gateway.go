@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2/gateway"
+)
+
+const gatewayDescription = "serve the commands as a JSON HTTP API"
+
+// runGateway runs the gateway command until interrupted, and returns the exit
+// code.
+func (a *app) runGateway(args []string) int {
+	fs := flag.NewFlagSet("maib gateway", flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	var conn connection
+	conn.register(fs, a.getenv)
+	listen := fs.String("listen", ":8080", "address to listen on")
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		return exitUsage
+	}
+
+	apiKeys, err := parseAPIKeys(a.getenv("MAIB_GATEWAY_API_KEYS"))
+	if err != nil {
+		fmt.Fprintf(a.stderr, "maib: %s\n", err)
+		return exitUsage
+	}
+	sender, err := a.newSender(conn)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "maib: %s\n", err)
+		return exitError
+	}
+	handler, err := gateway.New(gateway.Config{
+		Sender:  sender,
+		APIKeys: apiKeys,
+		Timeout: conn.timeout,
+	})
+	if err != nil {
+		fmt.Fprintf(a.stderr, "maib: %s\n", err)
+		return exitError
+	}
+
+	server := &http.Server{
+		Addr:              *listen,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), conn.timeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	fmt.Fprintf(a.stderr, "maib: gateway listening on %s\n", *listen)
+	err = server.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(a.stderr, "maib: %s\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// parseAPIKeys parses "name=key" pairs separated by commas.
+func parseAPIKeys(s string) (map[string]string, error) {
+	keys := make(map[string]string)
+	for i, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, key, ok := strings.Cut(pair, "=")
+		if !ok || name == "" || key == "" {
+			// The pair is not quoted, so that the key doesn't end up in logs.
+			return nil, fmt.Errorf("malformed API key #%d, expected name=key", i+1)
+		}
+		keys[key] = name
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("API keys are required: set MAIB_GATEWAY_API_KEYS to name=key pairs separated by commas")
+	}
+	return keys, nil
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAPIKeys(t *testing.T) {
+	keys, err := parseAPIKeys("shop=key-1, billing=key-2,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"key-1": "shop", "key-2": "billing"}, keys)
+
+	_, err = parseAPIKeys("shop=key-1,key-2")
+	assert.EqualError(t, err, "malformed API key #2, expected name=key")
+
+	_, err = parseAPIKeys("")
+	assert.ErrorContains(t, err, "MAIB_GATEWAY_API_KEYS")
+}
+
+func TestRun_GatewayWithoutKeys(t *testing.T) {
+	a, _, stderr := testApp(nil, "")
+	assert.Equal(t, exitUsage, a.run([]string{"gateway"}))
+	assert.Contains(t, stderr.String(), "API keys are required")
+}
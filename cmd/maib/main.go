@@ -0,0 +1,265 @@
+/*
+Command maib operates a MAIB ECommerce merchant account from the command line.
+
+Usage:
+
+	maib <command> [flags]
+
+Commands:
+
+	status             check a transaction with TransactionStatus
+	register           register an SMS or DMS transaction
+	execute-dms        execute a DMS authorization
+	reverse            reverse a transaction, fully or partially
+	execute-recurring  charge a saved card with ExecuteRecurring
+	delete-recurring   delete a recurring or oneClick payment
+	close-day          close the business day
+	batch              run status, reverse or delete-recurring rows from a CSV
+	                   or JSONL file
+	gateway            serve the commands as a JSON HTTP API
+
+Every command accepts the connection flags, which default to the environment
+variables in brackets:
+
+	-pfx-path    path to the .pfx certificate issued by MAIB (MAIB_PFX_PATH)
+	-passphrase  passphrase to the certificate (MAIB_PASSPHRASE)
+	-env         environment preset, test or production, that sets the endpoint
+	             (MAIB_ENVIRONMENT)
+	-endpoint    merchant handler URL issued by MAIB (MAIB_MERCHANT_HANDLER_ENDPOINT)
+	-root-ca     PEM file with the CA of the server, if it is not in the system
+	             pool (MAIB_ROOT_CA)
+
+Results are printed as a table, or as JSON with -format json. Commands that
+move money (execute-dms, reverse, execute-recurring, and batch with reverse
+rows) ask for confirmation,
+unless -yes is set.
+
+The batch command appends the result of each row to an output file as soon as
+it completes. Running it again with the same output file resumes the run.
+
+The gateway command serves the commands as a JSON HTTP API, see package
+gateway. The API keys of the callers are read from MAIB_GATEWAY_API_KEYS, as
+name=key pairs separated by commas.
+
+Run "maib <command> -h" for the flags of a command.
+*/
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/x509"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// Exit codes.
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+func main() {
+	a := &app{
+		stdin:     os.Stdin,
+		stdout:    os.Stdout,
+		stderr:    os.Stderr,
+		getenv:    os.Getenv,
+		newSender: newClient,
+	}
+	os.Exit(a.run(os.Args[1:]))
+}
+
+// app holds the dependencies of the command, replaced in tests.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+
+	// Creates the sender from the connection flags.
+	newSender func(connection) (maib.Sender, error)
+}
+
+// connection contains the flags shared by every command.
+type connection struct {
+	pfxPath    string
+	passphrase string
+	env        string
+	endpoint   string
+	rootCA     string
+	format     string
+	yes        bool
+	timeout    time.Duration
+}
+
+// register adds the connection flags to the flag set, with defaults from the
+// environment.
+func (c *connection) register(fs *flag.FlagSet, getenv func(string) string) {
+	fs.StringVar(&c.pfxPath, "pfx-path", getenv("MAIB_PFX_PATH"), "path to the .pfx certificate issued by MAIB")
+	fs.StringVar(&c.passphrase, "passphrase", getenv("MAIB_PASSPHRASE"), "passphrase to the certificate")
+	fs.StringVar(&c.env, "env", getenv("MAIB_ENVIRONMENT"), "environment preset: test or production")
+	fs.StringVar(&c.endpoint, "endpoint", getenv("MAIB_MERCHANT_HANDLER_ENDPOINT"), "merchant handler URL issued by MAIB")
+	fs.StringVar(&c.rootCA, "root-ca", getenv("MAIB_ROOT_CA"), "PEM file with the CA of the server")
+	fs.StringVar(&c.format, "format", "table", "output format: table or json")
+	fs.BoolVar(&c.yes, "yes", false, "don't ask for confirmation")
+	fs.DurationVar(&c.timeout, "timeout", 30*time.Second, "request timeout")
+}
+
+// newClient creates a *maib.Client from the connection flags.
+func newClient(c connection) (maib.Sender, error) {
+	if c.pfxPath == "" {
+		return nil, errors.New("certificate is required: set -pfx-path or MAIB_PFX_PATH")
+	}
+	if c.endpoint == "" && c.env == "" {
+		return nil, errors.New("endpoint is required: set -endpoint or MAIB_MERCHANT_HANDLER_ENDPOINT, or -env or MAIB_ENVIRONMENT")
+	}
+
+	config := maib.Config{
+		PFXPath:                 c.pfxPath,
+		Passphrase:              c.passphrase,
+		MerchantHandlerEndpoint: c.endpoint,
+	}
+	if c.env != "" {
+		env, ok := maib.LookupEnvironment(c.env)
+		if !ok {
+			return nil, fmt.Errorf("unknown environment %q: use test or production", c.env)
+		}
+		config.Environment = env
+	}
+	if c.rootCA != "" {
+		pem, err := os.ReadFile(c.rootCA)
+		if err != nil {
+			return nil, fmt.Errorf("read root CA: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("root CA contains no certificates")
+		}
+	}
+	return maib.NewClient(config)
+}
+
+// run executes the command line, and returns the exit code.
+func (a *app) run(args []string) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		a.usage()
+		if len(args) == 0 {
+			return exitUsage
+		}
+		return exitOK
+	}
+
+	if args[0] == "batch" {
+		return a.runBatch(args[1:])
+	}
+	if args[0] == "gateway" {
+		return a.runGateway(args[1:])
+	}
+	cmd, ok := findCommand(args[0])
+	if !ok {
+		fmt.Fprintf(a.stderr, "maib: unknown command %q\n", args[0])
+		a.usage()
+		return exitUsage
+	}
+
+	fs := flag.NewFlagSet("maib "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	var conn connection
+	conn.register(fs, a.getenv)
+	build := cmd.flags(fs)
+	err := fs.Parse(args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return exitOK
+	}
+	if err != nil {
+		return exitUsage
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(a.stderr, "maib: unexpected arguments %q\n", fs.Args())
+		return exitUsage
+	}
+	if conn.format != "table" && conn.format != "json" {
+		fmt.Fprintf(a.stderr, "maib: unknown format %q\n", conn.format)
+		return exitUsage
+	}
+
+	err = a.execute(cmd, build, conn)
+	if err != nil {
+		fmt.Fprintf(a.stderr, "maib: %s\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// execute sends the request of the command and prints the result.
+func (a *app) execute(cmd command, build func() (maib.Request, error), conn connection) error {
+	req, err := build()
+	if err != nil {
+		return err
+	}
+	// Validate before asking for confirmation.
+	_, err = req.Values()
+	if err != nil {
+		return err
+	}
+
+	if cmd.confirm != nil && !conn.yes {
+		ok, err := a.ask(cmd.confirm(req))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("aborted")
+		}
+	}
+
+	sender, err := a.newSender(conn)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), conn.timeout)
+	defer cancel()
+
+	res, err := sender.Send(ctx, req)
+	if err != nil {
+		return err
+	}
+	result, err := cmd.decode(res)
+	if err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return printResult(a.stdout, conn.format, result)
+}
+
+// ask prints the question and reads a yes or no answer from stdin.
+func (a *app) ask(question string) (bool, error) {
+	fmt.Fprintf(a.stderr, "%s [y/N] ", question)
+	answer, err := bufio.NewReader(a.stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("read answer: %w", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+func (a *app) usage() {
+	fmt.Fprintln(a.stderr, "Usage: maib <command> [flags]")
+	fmt.Fprintln(a.stderr)
+	fmt.Fprintln(a.stderr, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(a.stderr, "  %-18s %s\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(a.stderr, "  %-18s %s\n", "batch", batchDescription)
+	fmt.Fprintf(a.stderr, "  %-18s %s\n", "gateway", gatewayDescription)
+	fmt.Fprintln(a.stderr)
+	fmt.Fprintln(a.stderr, `Run "maib <command> -h" for the flags of a command.`)
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/maibfake"
+	"github.com/NikSays/go-maib-ecomm/v2/maibtest"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+// testApp returns an app that sends through fake, and its output buffers.
+func testApp(fake maib.Sender, stdin string) (*app, *bytes.Buffer, *bytes.Buffer) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	a := &app{
+		stdin:  strings.NewReader(stdin),
+		stdout: stdout,
+		stderr: stderr,
+		getenv: func(string) string { return "" },
+		newSender: func(connection) (maib.Sender, error) {
+			return fake, nil
+		},
+	}
+	return a, stdout, stderr
+}
+
+func TestRun_Commands(t *testing.T) {
+	cases := []struct {
+		name     string
+		args     []string
+		request  maib.Request
+		response any
+		expected maib.Request
+	}{
+		{
+			name:     "status",
+			args:     []string{"status", "-trans-id", "abcdefghijklmnopqrstuvwxyz0=", "-client-ip", "127.0.0.1"},
+			request:  requests.TransactionStatus{},
+			response: requests.TransactionStatusResult{Result: maib.ResultOk},
+			expected: requests.TransactionStatus{TransactionID: "abcdefghijklmnopqrstuvwxyz0=", ClientIPAddress: "127.0.0.1"},
+		},
+		{
+			name:     "register",
+			args:     []string{"register", "-type", "dms", "-amount", "1999", "-currency", "eur", "-client-ip", "127.0.0.1", "-language", "en"},
+			request:  requests.RegisterTransaction{},
+			response: requests.RegisterTransactionResult{TransactionID: "abcdefghijklmnopqrstuvwxyz0="},
+			expected: requests.RegisterTransaction{
+				TransactionType: requests.RegisterTransactionDMS,
+				Amount:          1999,
+				Currency:        maib.CurrencyEUR,
+				ClientIPAddress: "127.0.0.1",
+				Language:        maib.LanguageEnglish,
+			},
+		},
+		{
+			name:     "execute-dms",
+			args:     []string{"execute-dms", "-yes", "-trans-id", "abcdefghijklmnopqrstuvwxyz0=", "-amount", "1999", "-client-ip", "127.0.0.1"},
+			request:  requests.ExecuteDMS{},
+			response: requests.ExecuteDMSResult{Result: maib.ResultOk},
+			expected: requests.ExecuteDMS{TransactionID: "abcdefghijklmnopqrstuvwxyz0=", Amount: 1999, Currency: maib.CurrencyMDL, ClientIPAddress: "127.0.0.1"},
+		},
+		{
+			name:     "reverse",
+			args:     []string{"reverse", "-yes", "-trans-id", "abcdefghijklmnopqrstuvwxyz0=", "-amount", "500"},
+			request:  requests.ReverseTransaction{},
+			response: requests.ReverseTransactionResult{Result: maib.ResultReversed},
+			expected: requests.ReverseTransaction{TransactionID: "abcdefghijklmnopqrstuvwxyz0=", Amount: 500},
+		},
+		{
+			name:     "execute-recurring",
+			args:     []string{"execute-recurring", "-yes", "-biller-client-id", "client-1", "-amount", "1999", "-currency", "840", "-client-ip", "127.0.0.1"},
+			request:  requests.ExecuteRecurring{},
+			response: requests.ExecuteRecurringResult{Result: maib.ResultOk},
+			expected: requests.ExecuteRecurring{BillerClientID: "client-1", Amount: 1999, Currency: maib.CurrencyUSD, ClientIPAddress: "127.0.0.1"},
+		},
+		{
+			name:     "delete-recurring",
+			args:     []string{"delete-recurring", "-biller-client-id", "client-1"},
+			request:  requests.DeleteRecurring{},
+			response: requests.DeleteRecurringResult{Result: maib.ResultOk},
+			expected: requests.DeleteRecurring{BillerClientID: "client-1"},
+		},
+		{
+			name:     "close-day",
+			args:     []string{"close-day"},
+			request:  requests.CloseDay{},
+			response: requests.CloseDayResult{Result: maib.ResultOk},
+			expected: requests.CloseDay{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := maibfake.New()
+			fake.Respond(c.request, maibfake.Result(c.response))
+			a, _, stderr := testApp(fake, "")
+
+			code := a.run(c.args)
+			require.Equal(t, exitOK, code, stderr.String())
+			calls := fake.Calls()
+			require.Len(t, calls, 1)
+			assert.Equal(t, c.expected, calls[0].Request)
+		})
+	}
+}
+
+func TestRun_Output(t *testing.T) {
+	fake := maibfake.New()
+	fake.Respond(requests.TransactionStatus{}, maibfake.Result(requests.TransactionStatusResult{
+		Result:     maib.ResultOk,
+		ResultCode: 0,
+		RRN:        123456789,
+		CardNumber: "4***********1111",
+	}))
+	args := []string{"status", "-trans-id", "abcdefghijklmnopqrstuvwxyz0=", "-client-ip", "127.0.0.1"}
+
+	t.Run("table", func(t *testing.T) {
+		a, stdout, _ := testApp(fake, "")
+		require.Equal(t, exitOK, a.run(args))
+		assert.Regexp(t, `(?m)^Result\s+OK$`, stdout.String())
+		assert.Regexp(t, `(?m)^RRN\s+123456789$`, stdout.String())
+		assert.NotContains(t, stdout.String(), "ApprovalCode")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		a, stdout, _ := testApp(fake, "")
+		require.Equal(t, exitOK, a.run(append(args, "-format", "json")))
+		var result requests.TransactionStatusResult
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+		assert.Equal(t, maib.ResultOk, result.Result)
+		assert.Equal(t, 123456789, result.RRN)
+	})
+}
+
+func TestRun_Confirmation(t *testing.T) {
+	args := []string{"reverse", "-trans-id", "abcdefghijklmnopqrstuvwxyz0=", "-amount", "1999"}
+
+	t.Run("declined", func(t *testing.T) {
+		fake := maibfake.New()
+		fake.Respond(requests.ReverseTransaction{}, maibfake.Result(requests.ReverseTransactionResult{Result: maib.ResultReversed}))
+		a, _, stderr := testApp(fake, "n\n")
+
+		assert.Equal(t, exitError, a.run(args))
+		assert.Contains(t, stderr.String(), "Return 19.99 of transaction abcdefghijklmnopqrstuvwxyz0= to the customer? [y/N]")
+		assert.Contains(t, stderr.String(), "aborted")
+		assert.Empty(t, fake.Calls())
+	})
+
+	t.Run("no answer", func(t *testing.T) {
+		fake := maibfake.New()
+		a, _, _ := testApp(fake, "")
+
+		assert.Equal(t, exitError, a.run(args))
+		assert.Empty(t, fake.Calls())
+	})
+
+	t.Run("confirmed", func(t *testing.T) {
+		fake := maibfake.New()
+		fake.Respond(requests.ReverseTransaction{}, maibfake.Result(requests.ReverseTransactionResult{Result: maib.ResultReversed}))
+		a, _, _ := testApp(fake, "yes\n")
+
+		assert.Equal(t, exitOK, a.run(args))
+		fake.AssertSentOnce(t, requests.ReverseTransaction{Amount: 1999})
+	})
+
+	t.Run("invalid request is not confirmed", func(t *testing.T) {
+		fake := maibfake.New()
+		a, _, stderr := testApp(fake, "y\n")
+
+		assert.Equal(t, exitError, a.run([]string{"reverse", "-amount", "1999"}))
+		assert.NotContains(t, stderr.String(), "[y/N]")
+	})
+}
+
+func TestRun_Usage(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		code int
+	}{
+		{name: "no command", args: nil, code: exitUsage},
+		{name: "help", args: []string{"help"}, code: exitOK},
+		{name: "unknown command", args: []string{"refund"}, code: exitUsage},
+		{name: "unknown flag", args: []string{"status", "-amount", "1"}, code: exitUsage},
+		{name: "command help", args: []string{"status", "-h"}, code: exitOK},
+		{name: "extra arguments", args: []string{"close-day", "now"}, code: exitUsage},
+		{name: "unknown format", args: []string{"close-day", "-format", "xml"}, code: exitUsage},
+		{name: "unknown currency", args: []string{"register", "-currency", "XYZ"}, code: exitUsage},
+		{name: "unknown type", args: []string{"register", "-type", "oneclick", "-amount", "1"}, code: exitError},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a, _, _ := testApp(maibfake.New(), "")
+			assert.Equal(t, c.code, a.run(c.args))
+		})
+	}
+}
+
+func TestRun_Client(t *testing.T) {
+	server := maibtest.NewServer(t, maibtest.Config{})
+	config := server.Config()
+
+	caPath := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caPath, server.CA().PEM(), 0o600))
+	env := map[string]string{
+		"MAIB_PFX_PATH":                  config.PFXPath,
+		"MAIB_PASSPHRASE":                config.Passphrase,
+		"MAIB_MERCHANT_HANDLER_ENDPOINT": config.MerchantHandlerEndpoint,
+		"MAIB_ROOT_CA":                   caPath,
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	a := &app{
+		stdin:     strings.NewReader(""),
+		stdout:    stdout,
+		stderr:    stderr,
+		getenv:    func(key string) string { return env[key] },
+		newSender: newClient,
+	}
+
+	code := a.run([]string{"register", "-amount", "1999", "-client-ip", "127.0.0.1", "-format", "json"})
+	require.Equal(t, exitOK, code, stderr.String())
+	var registered requests.RegisterTransactionResult
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &registered))
+	require.NotEmpty(t, registered.TransactionID)
+
+	stdout.Reset()
+	code = a.run([]string{"status", "-trans-id", registered.TransactionID, "-client-ip", "127.0.0.1"})
+	require.Equal(t, exitOK, code, stderr.String())
+	assert.Regexp(t, `(?m)^Result\s+CREATED$`, stdout.String())
+
+	t.Run("flags override environment", func(t *testing.T) {
+		code := a.run([]string{"close-day", "-endpoint", "https://127.0.0.1:1/"})
+		assert.Equal(t, exitError, code)
+	})
+
+	t.Run("missing certificate", func(t *testing.T) {
+		_, err := newClient(connection{endpoint: config.MerchantHandlerEndpoint})
+		assert.ErrorContains(t, err, "MAIB_PFX_PATH")
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		_, err := newClient(connection{pfxPath: config.PFXPath, passphrase: config.Passphrase, env: "staging"})
+		assert.EqualError(t, err, `unknown environment "staging": use test or production`)
+		_, err = newClient(connection{pfxPath: config.PFXPath, passphrase: config.Passphrase, env: "production", endpoint: config.MerchantHandlerEndpoint})
+		assert.ErrorContains(t, err, "does not belong to the production environment")
+		_, err = newClient(connection{pfxPath: config.PFXPath, passphrase: config.Passphrase, env: "test"})
+		assert.NoError(t, err)
+	})
+}
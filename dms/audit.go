@@ -0,0 +1,76 @@
+package dms
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// AuditEntry records a decision made about a stale authorization.
+type AuditEntry struct {
+	// When the decision was made.
+	At time.Time
+
+	// ID of the transaction. 28 symbols in base64.
+	TransactionID string
+
+	// Merchant's reference of the authorization, if it has one.
+	MerchantReference string
+
+	// Action chosen by the policy.
+	Action Action
+
+	// Amount that was captured or released.
+	Amount int
+
+	// Currency of the authorization.
+	Currency maib.Currency
+
+	// State of the authorization after the action.
+	State State
+
+	// Result returned by the ECommerce system, if a request was sent.
+	Result maib.ResultEnum
+
+	// Result code returned by the ECommerce system, if a request was sent.
+	ResultCode int
+
+	// Error message, if the action has failed.
+	Error string
+}
+
+// AuditLog stores [AuditEntry] records.
+type AuditLog interface {
+	// Record appends an entry to the log.
+	Record(ctx context.Context, entry AuditEntry) error
+}
+
+// MemoryAuditLog is an [AuditLog] that keeps entries in memory. It is safe for
+// concurrent use.
+type MemoryAuditLog struct {
+	mu      sync.Mutex
+	entries []AuditEntry
+}
+
+// NewMemoryAuditLog returns an empty *[MemoryAuditLog].
+func NewMemoryAuditLog() *MemoryAuditLog {
+	return &MemoryAuditLog{}
+}
+
+func (l *MemoryAuditLog) Record(_ context.Context, entry AuditEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+// Entries returns a copy of all recorded entries in order.
+func (l *MemoryAuditLog) Entries() []AuditEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entries := make([]AuditEntry, len(l.entries))
+	copy(entries, l.entries)
+	return entries
+}
@@ -0,0 +1,25 @@
+package dms
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryAuditLog(t *testing.T) {
+	auditLog := NewMemoryAuditLog()
+	assert.Empty(t, auditLog.Entries())
+
+	first := AuditEntry{TransactionID: "first", Action: ActionCapture}
+	second := AuditEntry{TransactionID: "second", Action: ActionRelease}
+	assert.Nil(t, auditLog.Record(context.Background(), first))
+	assert.Nil(t, auditLog.Record(context.Background(), second))
+
+	entries := auditLog.Entries()
+	assert.Equal(t, []AuditEntry{first, second}, entries)
+
+	// The returned slice is a copy.
+	entries[0].Action = ActionKeep
+	assert.Equal(t, ActionCapture, auditLog.Entries()[0].Action)
+}
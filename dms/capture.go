@@ -0,0 +1,380 @@
+package dms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+)
+
+var (
+	// ErrUnknownAuthorization is returned by the [Capturer] when the
+	// authorization is not in the store.
+	ErrUnknownAuthorization = errors.New("dms: authorization is not in the store")
+
+	// ErrAlreadyCaptured is returned by [Capturer.Capture] when the
+	// authorization is already captured. ExecuteDMS can only be sent once per
+	// authorization.
+	ErrAlreadyCaptured = errors.New("dms: authorization is already captured")
+
+	// ErrInProgress is returned by the [Capturer] when another capture or
+	// release of the authorization has not finished yet.
+	ErrInProgress = errors.New("dms: authorization is being captured or released")
+)
+
+// CaptureError is returned by [Capturer.Capture] when the capture doesn't
+// match the authorization. Nothing is sent.
+type CaptureError struct {
+	// ID of the transaction.
+	TransactionID string
+
+	// The mismatching field.
+	Field maib.PayloadField
+
+	// Description of the mismatch.
+	Description string
+}
+
+func (e *CaptureError) Error() string {
+	return fmt.Sprintf("capture of %s: %s %s", e.TransactionID, e.Field, e.Description)
+}
+
+// StateError is returned by the [Capturer] when the authorization is in a
+// state that doesn't allow the action.
+type StateError struct {
+	// ID of the transaction.
+	TransactionID string
+
+	// Local state of the authorization.
+	State State
+
+	// Result returned by TransactionStatus, if the state was just checked.
+	Result maib.ResultEnum
+}
+
+func (e *StateError) Error() string {
+	if e.Result != "" {
+		return fmt.Sprintf("authorization %s has result %s, not OK", e.TransactionID, e.Result)
+	}
+	return fmt.Sprintf("authorization %s is %s", e.TransactionID, e.State)
+}
+
+// CapturerConfig is the configuration required to set up a [Capturer].
+type CapturerConfig struct {
+	// Sender used to send the requests. Required.
+	Sender maib.Sender
+
+	// Storage for the authorizations. Required.
+	Store Store
+
+	// Storage for the captures and releases. Default is a new
+	// [MemoryAuditLog].
+	AuditLog AuditLog
+
+	// Source of time. Default is [clock.Real].
+	Clock clock.Clock
+}
+
+// Capturer captures DMS authorizations on the merchant's request, validating
+// each capture against the stored [Authorization]. It is safe for concurrent
+// use, but a [Store] shared by several processes needs a single Capturer in
+// front of it, as double captures are detected within a process. A [Sweeper]
+// of the same authorizations must be set up with the Capturer, see
+// [SweeperConfig].
+//
+// Must be initiated with [NewCapturer].
+type Capturer struct {
+	sender   maib.Sender
+	store    Store
+	auditLog AuditLog
+	clock    clock.Clock
+
+	locks *locks
+}
+
+// NewCapturer validates the configuration and returns a *[Capturer].
+func NewCapturer(config CapturerConfig) (*Capturer, error) {
+	if config.Sender == nil {
+		return nil, errors.New("sender is required")
+	}
+	if config.Store == nil {
+		return nil, errors.New("store is required")
+	}
+
+	c := &Capturer{
+		sender:   config.Sender,
+		store:    config.Store,
+		auditLog: config.AuditLog,
+		clock:    config.Clock,
+		locks:    newLocks(),
+	}
+	if c.auditLog == nil {
+		c.auditLog = NewMemoryAuditLog()
+	}
+	if c.clock == nil {
+		c.clock = clock.Real
+	}
+	return c, nil
+}
+
+// Capture executes the authorization with ExecuteDMS for the amount, which may
+// be less than the authorized one.
+//
+// Before sending, the currency must match the authorization, the amount must
+// not exceed the authorized one, and TransactionStatus must return OK. An
+// authorization that has reached another final result is marked [StateVoid].
+// If the ECommerce system rejects the capture, the authorization is marked
+// [StateFailed]. If the capture fails with an unknown outcome, e.g. a timeout
+// or a 5xx status, see maib.IsRejected, it may have been executed, so the
+// authorization is marked [StateUnknown] and the error is returned. It can't
+// be captured or released until it is settled with [Capturer.Resolve]. It
+// returns the recorded audit entry.
+func (c *Capturer) Capture(ctx context.Context, transactionID string, amount int, currency maib.Currency) (AuditEntry, error) {
+	authorization, err := c.acquire(ctx, transactionID)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	defer c.done(transactionID)
+	ctx = withReference(ctx, authorization)
+
+	switch {
+	case authorization.State == StateCaptured:
+		return AuditEntry{}, ErrAlreadyCaptured
+	case authorization.State != StateAuthorized:
+		return AuditEntry{}, &StateError{TransactionID: transactionID, State: authorization.State}
+	case currency != authorization.Currency:
+		return AuditEntry{}, &CaptureError{
+			TransactionID: transactionID,
+			Field:         maib.FieldCurrency,
+			Description:   fmt.Sprintf("%d doesn't match the authorized %d", currency, authorization.Currency),
+		}
+	case amount <= 0 || amount > authorization.Amount:
+		return AuditEntry{}, &CaptureError{
+			TransactionID: transactionID,
+			Field:         maib.FieldAmount,
+			Description:   fmt.Sprintf("%d is not within the authorized %d", amount, authorization.Amount),
+		}
+	}
+
+	status, err := checkStatus(ctx, c.sender, authorization)
+	if err != nil {
+		return AuditEntry{}, fmt.Errorf("check status: %w", err)
+	}
+	entry := AuditEntry{
+		At:                c.clock.Now(),
+		TransactionID:     transactionID,
+		MerchantReference: authorization.MerchantReference,
+		Action:            ActionCapture,
+		Currency:          authorization.Currency,
+		State:             authorization.State,
+		Result:            status.Result,
+		ResultCode:        status.ResultCode,
+	}
+	if status.Result != maib.ResultOk {
+		stateErr := &StateError{TransactionID: transactionID, State: authorization.State, Result: status.Result}
+		if !status.Result.IsFinal() {
+			return AuditEntry{}, stateErr
+		}
+		entry.Action = ActionNone
+		entry.State = StateVoid
+		entry.Error = stateErr.Error()
+		return entry, errors.Join(stateErr, c.finish(ctx, authorization, entry))
+	}
+
+	result, resultCode, err := execute(ctx, c.sender, authorization, amount)
+	entry.At = c.clock.Now()
+	entry.Amount = amount
+	entry.Result = result
+	entry.ResultCode = resultCode
+	switch {
+	case err != nil && maib.IsRejected(err):
+		entry.State = StateFailed
+		entry.Error = err.Error()
+	case err != nil:
+		entry.State = StateUnknown
+		entry.Error = err.Error()
+		authorization.PendingAction = ActionCapture
+		authorization.PendingAmount = amount
+	case result != maib.ResultOk:
+		entry.State = StateFailed
+	default:
+		entry.State = StateCaptured
+		authorization.CapturedAmount = amount
+	}
+	return entry, errors.Join(err, c.finish(ctx, authorization, entry))
+}
+
+// Release frees the amount blocked by the authorization that won't be
+// captured. The Amount of the returned audit entry is the released amount.
+//
+// An authorization that is not captured is reversed in full with
+// ReverseTransaction. After a partial capture, the ECommerce system completes
+// the authorization with the captured amount, and there is no request to
+// release the rest, so the remainder is only recorded as released, for
+// reconciliation.
+//
+// Like a capture, a release that fails with an unknown outcome marks the
+// authorization [StateUnknown] until it is settled with [Capturer.Resolve].
+func (c *Capturer) Release(ctx context.Context, transactionID string) (AuditEntry, error) {
+	authorization, err := c.acquire(ctx, transactionID)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	defer c.done(transactionID)
+	ctx = withReference(ctx, authorization)
+
+	entry := AuditEntry{
+		At:                c.clock.Now(),
+		TransactionID:     transactionID,
+		MerchantReference: authorization.MerchantReference,
+		Action:            ActionRelease,
+		Currency:          authorization.Currency,
+		State:             authorization.State,
+	}
+	switch {
+	case authorization.State == StateCaptured && authorization.ReleasedAmount == 0 && authorization.CapturedAmount < authorization.Amount:
+		entry.Amount = authorization.Amount - authorization.CapturedAmount
+		authorization.ReleasedAmount = entry.Amount
+		return entry, c.finish(ctx, authorization, entry)
+	case authorization.State != StateAuthorized:
+		return AuditEntry{}, &StateError{TransactionID: transactionID, State: authorization.State}
+	}
+
+	result, resultCode, err := reverse(ctx, c.sender, authorization)
+	entry.Amount = authorization.Amount
+	entry.Result = result
+	entry.ResultCode = resultCode
+	switch {
+	case err != nil && maib.IsRejected(err):
+		entry.State = StateFailed
+		entry.Error = err.Error()
+	case err != nil:
+		entry.State = StateUnknown
+		entry.Error = err.Error()
+		authorization.PendingAction = ActionRelease
+		authorization.PendingAmount = authorization.Amount
+	case result != maib.ResultOk:
+		entry.State = StateFailed
+	default:
+		entry.State = StateReleased
+		authorization.ReleasedAmount = authorization.Amount
+	}
+	return entry, errors.Join(err, c.finish(ctx, authorization, entry))
+}
+
+// Resolve settles an authorization in [StateUnknown], after the transaction
+// was checked, e.g. with TransactionStatus or with MAIB support. If executed is
+// true, the pending capture or release is recorded as done. Otherwise the
+// authorization returns to [StateAuthorized], and may be captured or released
+// again. It returns the recorded audit entry.
+func (c *Capturer) Resolve(ctx context.Context, transactionID string, executed bool) (AuditEntry, error) {
+	authorization, err := c.acquire(ctx, transactionID)
+	if err != nil {
+		return AuditEntry{}, err
+	}
+	defer c.done(transactionID)
+	if authorization.State != StateUnknown {
+		return AuditEntry{}, &StateError{TransactionID: transactionID, State: authorization.State}
+	}
+
+	entry := AuditEntry{
+		At:                c.clock.Now(),
+		TransactionID:     transactionID,
+		MerchantReference: authorization.MerchantReference,
+		Action:            authorization.PendingAction,
+		Amount:            authorization.PendingAmount,
+		Currency:          authorization.Currency,
+		State:             StateAuthorized,
+	}
+	switch {
+	case !executed:
+	case authorization.PendingAction == ActionCapture:
+		entry.State = StateCaptured
+		authorization.CapturedAmount = authorization.PendingAmount
+	default:
+		entry.State = StateReleased
+		authorization.ReleasedAmount = authorization.PendingAmount
+	}
+	authorization.PendingAction = ""
+	authorization.PendingAmount = 0
+	return entry, c.finish(ctx, authorization, entry)
+}
+
+// acquire locks the authorization until done is called, and loads it.
+func (c *Capturer) acquire(ctx context.Context, transactionID string) (Authorization, error) {
+	if !c.locks.lock(transactionID) {
+		return Authorization{}, ErrInProgress
+	}
+
+	authorization, ok, err := c.store.Load(ctx, transactionID)
+	if err == nil && !ok {
+		err = fmt.Errorf("%w: %s", ErrUnknownAuthorization, transactionID)
+	} else if err != nil {
+		err = fmt.Errorf("load authorization: %w", err)
+	}
+	if err != nil {
+		c.done(transactionID)
+		return Authorization{}, err
+	}
+	return authorization, nil
+}
+
+// withReference returns the context with the merchant reference of the
+// authorization, if it has one.
+func withReference(ctx context.Context, authorization Authorization) context.Context {
+	if authorization.MerchantReference == "" {
+		return ctx
+	}
+	return maib.WithMerchantReference(ctx, authorization.MerchantReference)
+}
+
+func (c *Capturer) done(transactionID string) {
+	c.locks.unlock(transactionID)
+}
+
+// locks marks the authorizations that are being captured or released, by a
+// [Capturer] and the [Sweeper] sharing it.
+type locks struct {
+	mu     sync.Mutex
+	locked map[string]bool
+}
+
+func newLocks() *locks {
+	return &locks{locked: make(map[string]bool)}
+}
+
+// lock marks the authorization, and reports false if it is already marked.
+func (l *locks) lock(transactionID string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.locked[transactionID] {
+		return false
+	}
+	l.locked[transactionID] = true
+	return true
+}
+
+func (l *locks) unlock(transactionID string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.locked, transactionID)
+}
+
+// finish saves the authorization in the state of the entry, and records the
+// entry. The outcome is saved even if the context is done.
+func (c *Capturer) finish(ctx context.Context, authorization Authorization, entry AuditEntry) error {
+	ctx = context.WithoutCancel(ctx)
+	authorization.State = entry.State
+	err := c.store.Save(ctx, authorization)
+	if err != nil {
+		return fmt.Errorf("save authorization: %w", err)
+	}
+	err = c.auditLog.Record(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("record audit entry: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,246 @@
+package dms
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+const authorized = "authorizedaaaaaaaaaaaaaaaaa="
+
+type senderFunc func(ctx context.Context, req maib.Request) (map[string]any, error)
+
+func (f senderFunc) Send(ctx context.Context, req maib.Request) (map[string]any, error) {
+	return f(ctx, req)
+}
+
+func newCapturer(t *testing.T, sender maib.Sender) (*Capturer, *MemoryStore, *MemoryAuditLog) {
+	store := NewMemoryStore()
+	require.NoError(t, store.Save(context.Background(), authorization(authorized, time.Hour)))
+	auditLog := NewMemoryAuditLog()
+	capturer, err := NewCapturer(CapturerConfig{
+		Sender:   sender,
+		Store:    store,
+		AuditLog: auditLog,
+		Clock:    clock.NewFake(now),
+	})
+	require.NoError(t, err)
+	return capturer, store, auditLog
+}
+
+func TestNewCapturer(t *testing.T) {
+	_, err := NewCapturer(CapturerConfig{})
+	assert.EqualError(t, err, "sender is required")
+	_, err = NewCapturer(CapturerConfig{Sender: &fakeSender{}})
+	assert.EqualError(t, err, "store is required")
+}
+
+func TestCapturer_PartialCapture(t *testing.T) {
+	ctx := context.Background()
+	sender := &fakeSender{}
+	capturer, store, auditLog := newCapturer(t, sender)
+
+	entry, err := capturer.Capture(ctx, authorized, 1500, maib.CurrencyMDL)
+	require.NoError(t, err)
+	assert.Equal(t, ActionCapture, entry.Action)
+	assert.Equal(t, StateCaptured, entry.State)
+	assert.Equal(t, 1500, entry.Amount)
+	require.Len(t, sender.sent, 2)
+	assert.Equal(t, requests.TransactionStatus{TransactionID: authorized, ClientIPAddress: "127.0.0.1"}, sender.sent[0])
+	assert.Equal(t, requests.ExecuteDMS{
+		TransactionID:   authorized,
+		Amount:          1500,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+	}, sender.sent[1])
+
+	_, err = capturer.Capture(ctx, authorized, 499, maib.CurrencyMDL)
+	assert.ErrorIs(t, err, ErrAlreadyCaptured)
+
+	// The remainder is recorded as released without a request.
+	entry, err = capturer.Release(ctx, authorized)
+	require.NoError(t, err)
+	assert.Equal(t, ActionRelease, entry.Action)
+	assert.Equal(t, 499, entry.Amount)
+	assert.Len(t, sender.sent, 2)
+
+	saved, _ := store.Get(authorized)
+	assert.Equal(t, StateCaptured, saved.State)
+	assert.Equal(t, 1500, saved.CapturedAmount)
+	assert.Equal(t, 499, saved.ReleasedAmount)
+	assert.Len(t, auditLog.Entries(), 2)
+
+	_, err = capturer.Release(ctx, authorized)
+	assert.ErrorAs(t, err, new(*StateError))
+}
+
+func TestCapturer_Release(t *testing.T) {
+	ctx := context.Background()
+	sender := &fakeSender{}
+	capturer, store, _ := newCapturer(t, sender)
+
+	entry, err := capturer.Release(ctx, authorized)
+	require.NoError(t, err)
+	assert.Equal(t, StateReleased, entry.State)
+	assert.Equal(t, 1999, entry.Amount)
+	assert.Equal(t, []maib.Request{requests.ReverseTransaction{TransactionID: authorized, Amount: 1999}}, sender.sent)
+	saved, _ := store.Get(authorized)
+	assert.Equal(t, 1999, saved.ReleasedAmount)
+
+	_, err = capturer.Capture(ctx, authorized, 1999, maib.CurrencyMDL)
+	var stateErr *StateError
+	require.ErrorAs(t, err, &stateErr)
+	assert.Equal(t, StateReleased, stateErr.State)
+}
+
+func TestCapturer_Capture_Mismatch(t *testing.T) {
+	ctx := context.Background()
+	sender := &fakeSender{}
+	capturer, _, _ := newCapturer(t, sender)
+
+	_, err := capturer.Capture(ctx, authorized, 1999, maib.CurrencyEUR)
+	var captureErr *CaptureError
+	require.ErrorAs(t, err, &captureErr)
+	assert.Equal(t, maib.FieldCurrency, captureErr.Field)
+
+	_, err = capturer.Capture(ctx, authorized, 2000, maib.CurrencyMDL)
+	require.ErrorAs(t, err, &captureErr)
+	assert.Equal(t, maib.FieldAmount, captureErr.Field)
+
+	_, err = capturer.Capture(ctx, "unknown", 1999, maib.CurrencyMDL)
+	assert.ErrorIs(t, err, ErrUnknownAuthorization)
+	assert.Empty(t, sender.sent)
+}
+
+func TestCapturer_Capture_Outcomes(t *testing.T) {
+	cases := []struct {
+		name          string
+		sender        *fakeSender
+		expectedState State
+		expectedSent  int
+	}{
+		{
+			name: "declined",
+			sender: &fakeSender{responses: map[string]map[string]any{
+				"c": {"RESULT": "DECLINED", "RESULT_CODE": 116},
+			}},
+			expectedState: StateVoid,
+			expectedSent:  1,
+		},
+		{
+			name: "pending",
+			sender: &fakeSender{responses: map[string]map[string]any{
+				"c": {"RESULT": "PENDING"},
+			}},
+			expectedState: StateAuthorized,
+			expectedSent:  1,
+		},
+		{
+			name:          "rejected",
+			sender:        &fakeSender{errors: map[string]error{"t": &maib.ECommError{Code: 200, Body: "error: wrong state"}}},
+			expectedState: StateFailed,
+			expectedSent:  2,
+		},
+		{
+			name:          "bad gateway",
+			sender:        &fakeSender{errors: map[string]error{"t": &maib.ECommError{Code: 502, Body: "Bad Gateway"}}},
+			expectedState: StateUnknown,
+			expectedSent:  2,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			capturer, store, _ := newCapturer(t, c.sender)
+			_, err := capturer.Capture(context.Background(), authorized, 1999, maib.CurrencyMDL)
+			assert.Error(t, err)
+			saved, _ := store.Get(authorized)
+			assert.Equal(t, c.expectedState, saved.State)
+			assert.Len(t, c.sender.sent, c.expectedSent)
+		})
+	}
+}
+
+func TestCapturer_Capture_Unknown(t *testing.T) {
+	ctx := context.Background()
+	sender := &fakeSender{errors: map[string]error{"t": &url.Error{Op: "Post", Err: context.DeadlineExceeded}}}
+	capturer, store, auditLog := newCapturer(t, sender)
+
+	entry, err := capturer.Capture(ctx, authorized, 1500, maib.CurrencyMDL)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, StateUnknown, entry.State)
+	saved, _ := store.Get(authorized)
+	assert.Equal(t, StateUnknown, saved.State)
+	assert.Equal(t, ActionCapture, saved.PendingAction)
+	assert.Equal(t, 1500, saved.PendingAmount)
+
+	// The capture may have been executed, so nothing is sent until it is
+	// resolved.
+	delete(sender.errors, "t")
+	var stateErr *StateError
+	_, err = capturer.Capture(ctx, authorized, 1500, maib.CurrencyMDL)
+	require.ErrorAs(t, err, &stateErr)
+	assert.Equal(t, StateUnknown, stateErr.State)
+	_, err = capturer.Release(ctx, authorized)
+	require.ErrorAs(t, err, &stateErr)
+	assert.Len(t, sender.sent, 2)
+
+	entry, err = capturer.Resolve(ctx, authorized, true)
+	require.NoError(t, err)
+	assert.Equal(t, StateCaptured, entry.State)
+	assert.Equal(t, 1500, entry.Amount)
+	saved, _ = store.Get(authorized)
+	assert.Equal(t, StateCaptured, saved.State)
+	assert.Equal(t, 1500, saved.CapturedAmount)
+	assert.Empty(t, saved.PendingAction)
+	assert.Len(t, auditLog.Entries(), 2)
+
+	_, err = capturer.Resolve(ctx, authorized, true)
+	assert.ErrorAs(t, err, &stateErr)
+}
+
+func TestCapturer_Release_Unknown(t *testing.T) {
+	ctx := context.Background()
+	sender := &fakeSender{errors: map[string]error{"r": &maib.ECommError{Code: 504, Body: "Gateway Timeout"}}}
+	capturer, store, _ := newCapturer(t, sender)
+
+	entry, err := capturer.Release(ctx, authorized)
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+	assert.Equal(t, StateUnknown, entry.State)
+
+	// The release was not executed, so it may be sent again.
+	entry, err = capturer.Resolve(ctx, authorized, false)
+	require.NoError(t, err)
+	assert.Equal(t, StateAuthorized, entry.State)
+	delete(sender.errors, "r")
+	_, err = capturer.Release(ctx, authorized)
+	require.NoError(t, err)
+	saved, _ := store.Get(authorized)
+	assert.Equal(t, StateReleased, saved.State)
+	assert.Len(t, sender.sent, 2)
+}
+
+func TestCapturer_Capture_InProgress(t *testing.T) {
+	ctx := context.Background()
+	var capturer *Capturer
+	var secondErr error
+	fake := &fakeSender{}
+	capturer, _, _ = newCapturer(t, senderFunc(func(ctx context.Context, req maib.Request) (map[string]any, error) {
+		if _, ok := req.(requests.ExecuteDMS); ok && secondErr == nil {
+			_, secondErr = capturer.Capture(ctx, authorized, 1999, maib.CurrencyMDL)
+		}
+		return fake.Send(ctx, req)
+	}))
+
+	_, err := capturer.Capture(ctx, authorized, 1999, maib.CurrencyMDL)
+	require.NoError(t, err)
+	assert.ErrorIs(t, secondErr, ErrInProgress)
+	assert.Len(t, fake.sent, 2)
+}
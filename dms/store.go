@@ -0,0 +1,148 @@
+package dms
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// State is the local state of a DMS authorization.
+type State string
+
+const (
+	// StateAuthorized - the authorization was registered and is neither captured
+	// nor released yet.
+	StateAuthorized State = "AUTHORIZED"
+
+	// StateCaptured - the authorization was executed with ExecuteDMS.
+	StateCaptured State = "CAPTURED"
+
+	// StateReleased - the authorization was reversed with ReverseTransaction.
+	StateReleased State = "RELEASED"
+
+	// StateVoid - the authorization never reached OK, so there is nothing to
+	// capture or release.
+	StateVoid State = "VOID"
+
+	// StateFailed - the ECommerce system has rejected the capture or the release.
+	// Requires manual handling.
+	StateFailed State = "FAILED"
+
+	// StateUnknown - the capture or the release failed after it may have
+	// reached the ECommerce system, so it may have been executed. It is never
+	// repeated. Check the transaction and settle it with [Capturer.Resolve].
+	StateUnknown State = "UNKNOWN"
+)
+
+// Authorization is the local record of a transaction registered with
+// RegisterTransactionDMS.
+type Authorization struct {
+	// ID of the transaction. 28 symbols in base64.
+	TransactionID string
+
+	// Authorized amount. Positive integer with last 2 digits being the cents.
+	Amount int
+
+	// Authorization currency in ISO4217 3 digit format.
+	Currency maib.Currency
+
+	// Client's IP address in quad-dotted notation, like "127.0.0.1".
+	ClientIPAddress string
+
+	// Transaction details. Optional. Sent with ExecuteDMS.
+	Description string
+
+	// Merchant's reference of the transaction, like the order number. Optional.
+	// Sent with every request by maib.WithMerchantReference, and copied to the
+	// audit entries.
+	MerchantReference string
+
+	// When the authorization was registered.
+	AuthorizedAt time.Time
+
+	// Local state of the authorization.
+	State State
+
+	// Amount executed with ExecuteDMS, if the authorization is captured. May be
+	// less than Amount.
+	CapturedAmount int
+
+	// Amount that is no longer blocked: the full Amount if the authorization is
+	// released, or the remainder after a partial capture released with
+	// [Capturer.Release].
+	ReleasedAmount int
+
+	// The capture or release with an unknown outcome, in [StateUnknown].
+	PendingAction Action
+
+	// Amount of the PendingAction.
+	PendingAmount int
+}
+
+// Store persists DMS authorizations.
+type Store interface {
+	// Stale returns authorizations in [StateAuthorized] that were registered
+	// before the cutoff, ordered by AuthorizedAt.
+	Stale(ctx context.Context, cutoff time.Time) ([]Authorization, error)
+
+	// Load returns the authorization with the transaction ID, or false if there
+	// is none.
+	Load(ctx context.Context, transactionID string) (Authorization, bool, error)
+
+	// Save creates or replaces the authorization with the same TransactionID.
+	Save(ctx context.Context, authorization Authorization) error
+}
+
+// MemoryStore is a [Store] that keeps authorizations in memory. It is safe for
+// concurrent use.
+type MemoryStore struct {
+	mu             sync.Mutex
+	authorizations map[string]Authorization
+}
+
+// NewMemoryStore returns an empty *[MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		authorizations: make(map[string]Authorization),
+	}
+}
+
+func (s *MemoryStore) Stale(_ context.Context, cutoff time.Time) ([]Authorization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var stale []Authorization
+	for _, a := range s.authorizations {
+		if a.State == StateAuthorized && a.AuthorizedAt.Before(cutoff) {
+			stale = append(stale, a)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].AuthorizedAt.Before(stale[j].AuthorizedAt)
+	})
+	return stale, nil
+}
+
+func (s *MemoryStore) Load(_ context.Context, transactionID string) (Authorization, bool, error) {
+	a, ok := s.Get(transactionID)
+	return a, ok, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, authorization Authorization) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.authorizations[authorization.TransactionID] = authorization
+	return nil
+}
+
+// Get returns the authorization with the given transaction ID, and whether it
+// was found.
+func (s *MemoryStore) Get(transactionID string) (Authorization, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.authorizations[transactionID]
+	return a, ok
+}
@@ -0,0 +1,42 @@
+package dms
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_Stale(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	older := authorization("older", 10*time.Hour)
+	old := authorization("old", 5*time.Hour)
+	fresh := authorization("fresh", time.Hour)
+	captured := authorization("captured", 10*time.Hour)
+	captured.State = StateCaptured
+	for _, a := range []Authorization{old, fresh, captured, older} {
+		assert.Nil(t, store.Save(ctx, a))
+	}
+
+	stale, err := store.Stale(ctx, now.Add(-2*time.Hour))
+	assert.Nil(t, err)
+	assert.Equal(t, []Authorization{older, old}, stale)
+}
+
+func TestMemoryStore_Load(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	a := authorization("a", time.Hour)
+	assert.Nil(t, store.Save(ctx, a))
+
+	loaded, ok, err := store.Load(ctx, "a")
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, a, loaded)
+	_, ok, err = store.Load(ctx, "b")
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
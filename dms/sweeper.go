@@ -0,0 +1,363 @@
+/*
+Package dms manages DMS authorizations made with
+[requests.RegisterTransactionDMS].
+
+An authorization that is never executed with [requests.ExecuteDMS] keeps the
+funds blocked on the customer's card until the bank releases them. The
+[Sweeper] finds authorizations older than a threshold and, depending on the
+merchant's [Policy], captures them with ExecuteDMS or releases them with a full
+[requests.ReverseTransaction]. Every decision is recorded in an [AuditLog].
+
+The [Capturer] captures and releases authorizations on the merchant's request,
+checking every capture against the stored authorization: the currency must
+match, the amount must not exceed the authorized one, and the authorization
+must have reached OK. An authorization is captured at most once.
+*/
+package dms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+const defaultInterval = time.Hour
+
+// Action is the decision made about a stale authorization.
+type Action string
+
+const (
+	// ActionCapture - execute the authorization with ExecuteDMS.
+	ActionCapture Action = "CAPTURE"
+
+	// ActionRelease - reverse the full authorized amount with
+	// ReverseTransaction.
+	ActionRelease Action = "RELEASE"
+
+	// ActionKeep - leave the authorization as is until the next sweep.
+	ActionKeep Action = "KEEP"
+
+	// ActionNone - nothing to do, because the authorization has not reached OK.
+	// Set by the [Sweeper], never returned by a [Policy].
+	ActionNone Action = "NONE"
+)
+
+// Policy decides what to do with a stale authorization.
+type Policy func(Authorization) Action
+
+// CaptureAll is a [Policy] that captures every stale authorization.
+func CaptureAll(Authorization) Action {
+	return ActionCapture
+}
+
+// ReleaseAll is a [Policy] that releases every stale authorization.
+func ReleaseAll(Authorization) Action {
+	return ActionRelease
+}
+
+// SweeperConfig is the configuration required to set up a [Sweeper].
+type SweeperConfig struct {
+	// Sender used to send the requests. Required.
+	Sender maib.Sender
+
+	// Storage for the authorizations. Required.
+	Store Store
+
+	// Decides what to do with each stale authorization. Required.
+	Policy Policy
+
+	// Authorizations registered earlier than MaxAge ago are stale. Required.
+	MaxAge time.Duration
+
+	// Storage for the decisions. Default is a new [MemoryAuditLog].
+	AuditLog AuditLog
+
+	// Source of time. Default is [clock.Real].
+	Clock clock.Clock
+
+	// How often [Sweeper.Run] looks for stale authorizations. Default is one hour.
+	Interval time.Duration
+
+	// Capturer of the same authorizations. Optional. The sweeper skips the
+	// authorizations that the Capturer is capturing or releasing, and the
+	// Capturer returns [ErrInProgress] for the ones being swept.
+	Capturer *Capturer
+}
+
+// Sweeper captures or releases stale DMS authorizations. Must be initiated with
+// [NewSweeper].
+type Sweeper struct {
+	sender   maib.Sender
+	store    Store
+	policy   Policy
+	maxAge   time.Duration
+	auditLog AuditLog
+	clock    clock.Clock
+	interval time.Duration
+	locks    *locks
+}
+
+// NewSweeper validates the configuration and returns a *[Sweeper].
+func NewSweeper(config SweeperConfig) (*Sweeper, error) {
+	if config.Sender == nil {
+		return nil, errors.New("sender is required")
+	}
+	if config.Store == nil {
+		return nil, errors.New("store is required")
+	}
+	if config.Policy == nil {
+		return nil, errors.New("policy is required")
+	}
+	if config.MaxAge <= 0 {
+		return nil, errors.New("max age must be positive")
+	}
+
+	s := &Sweeper{
+		sender:   config.Sender,
+		store:    config.Store,
+		policy:   config.Policy,
+		maxAge:   config.MaxAge,
+		auditLog: config.AuditLog,
+		clock:    config.Clock,
+		interval: config.Interval,
+	}
+	if s.auditLog == nil {
+		s.auditLog = NewMemoryAuditLog()
+	}
+	if s.clock == nil {
+		s.clock = clock.Real
+	}
+	if s.interval <= 0 {
+		s.interval = defaultInterval
+	}
+	s.locks = newLocks()
+	if config.Capturer != nil {
+		s.locks = config.Capturer.locks
+	}
+	return s, nil
+}
+
+// Run blocks until the context is done, calling [Sweeper.Sweep] on every
+// interval. It returns early if the store or the audit log fails.
+func (s *Sweeper) Run(ctx context.Context) error {
+	for {
+		_, err := s.Sweep(ctx)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.clock.After(s.interval):
+		}
+	}
+}
+
+// Sweep handles every stale authorization once, and returns the recorded audit
+// entries. Authorizations that are being captured or released by the
+// Capturer, or are no longer [StateAuthorized] when loaded again, are skipped.
+//
+// Before acting, the authorization is checked with TransactionStatus. If it
+// has not reached OK, it is marked [StateVoid]. If the check fails, the
+// authorization is left as is and retried on the next sweep. If the ECommerce
+// system rejects the action, the authorization is marked [StateFailed]. If the
+// action fails with an unknown outcome, see maib.IsRejected, it is marked
+// [StateUnknown] and not retried, until it is settled with [Capturer.Resolve].
+// Only store and audit log errors are returned.
+func (s *Sweeper) Sweep(ctx context.Context) ([]AuditEntry, error) {
+	stale, err := s.store.Stale(ctx, s.clock.Now().Add(-s.maxAge))
+	if err != nil {
+		return nil, fmt.Errorf("get stale authorizations: %w", err)
+	}
+
+	var entries []AuditEntry
+	for _, authorization := range stale {
+		if ctx.Err() != nil {
+			return entries, ctx.Err()
+		}
+
+		entry, ok, err := s.sweep(ctx, authorization.TransactionID)
+		if err != nil {
+			return entries, err
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// sweep handles the authorization and records the audit entry, unless it is
+// locked or no longer [StateAuthorized]. It returns false if it was skipped.
+func (s *Sweeper) sweep(ctx context.Context, transactionID string) (AuditEntry, bool, error) {
+	if !s.locks.lock(transactionID) {
+		return AuditEntry{}, false, nil
+	}
+	defer s.locks.unlock(transactionID)
+
+	// The stale list may be outdated, so the authorization is loaded again.
+	authorization, ok, err := s.store.Load(ctx, transactionID)
+	if err != nil {
+		return AuditEntry{}, false, fmt.Errorf("load authorization: %w", err)
+	}
+	if !ok || authorization.State != StateAuthorized {
+		return AuditEntry{}, false, nil
+	}
+
+	entry := s.handle(ctx, authorization)
+	// The outcome is saved even if the context is done.
+	saveCtx := context.WithoutCancel(ctx)
+	if entry.State != authorization.State {
+		// Another process may have changed the authorization meanwhile, so
+		// the outcome is saved only over an unchanged state.
+		authorization, ok, err = s.store.Load(saveCtx, transactionID)
+		if err != nil {
+			return AuditEntry{}, false, fmt.Errorf("load authorization: %w", err)
+		}
+		if !ok || authorization.State != StateAuthorized {
+			if entry.Error != "" {
+				entry.Error += "; "
+			}
+			entry.Error += "authorization changed during the sweep, not saved"
+		} else {
+			authorization.State = entry.State
+			switch entry.State {
+			case StateCaptured:
+				authorization.CapturedAmount = authorization.Amount
+			case StateReleased:
+				authorization.ReleasedAmount = authorization.Amount
+			case StateUnknown:
+				authorization.PendingAction = entry.Action
+				authorization.PendingAmount = entry.Amount
+			}
+			err = s.store.Save(saveCtx, authorization)
+			if err != nil {
+				return AuditEntry{}, false, fmt.Errorf("save authorization: %w", err)
+			}
+		}
+	}
+	err = s.auditLog.Record(saveCtx, entry)
+	if err != nil {
+		return AuditEntry{}, false, fmt.Errorf("record audit entry: %w", err)
+	}
+	return entry, true, nil
+}
+
+// handle checks the authorization, applies the policy, and returns the audit
+// entry describing the outcome.
+func (s *Sweeper) handle(ctx context.Context, authorization Authorization) AuditEntry {
+	entry := AuditEntry{
+		At:                s.clock.Now(),
+		TransactionID:     authorization.TransactionID,
+		MerchantReference: authorization.MerchantReference,
+		Currency:          authorization.Currency,
+		State:             authorization.State,
+	}
+	ctx = withReference(ctx, authorization)
+
+	status, err := checkStatus(ctx, s.sender, authorization)
+	if err != nil {
+		entry.Action = ActionNone
+		entry.Error = fmt.Sprintf("check status: %s", err)
+		return entry
+	}
+	if status.Result != maib.ResultOk {
+		entry.Action = ActionNone
+		entry.State = StateVoid
+		entry.Result = status.Result
+		entry.ResultCode = status.ResultCode
+		return entry
+	}
+
+	entry.Action = s.policy(authorization)
+	var result maib.ResultEnum
+	var resultCode int
+	switch entry.Action {
+	case ActionCapture:
+		result, resultCode, err = execute(ctx, s.sender, authorization, authorization.Amount)
+	case ActionRelease:
+		result, resultCode, err = reverse(ctx, s.sender, authorization)
+	case ActionKeep:
+		return entry
+	default:
+		entry.Error = fmt.Sprintf("unknown action %q", entry.Action)
+		return entry
+	}
+
+	entry.Amount = authorization.Amount
+	entry.Result = result
+	entry.ResultCode = resultCode
+	switch {
+	case err != nil && maib.IsRejected(err):
+		entry.State = StateFailed
+		entry.Error = err.Error()
+	case err != nil:
+		entry.State = StateUnknown
+		entry.Error = err.Error()
+	case result != maib.ResultOk:
+		entry.State = StateFailed
+	case entry.Action == ActionCapture:
+		entry.State = StateCaptured
+	default:
+		entry.State = StateReleased
+	}
+	return entry
+}
+
+// checkStatus sends TransactionStatus for the authorization.
+func checkStatus(ctx context.Context, sender maib.Sender, authorization Authorization) (requests.TransactionStatusResult, error) {
+	res, err := sender.Send(ctx, requests.TransactionStatus{
+		TransactionID:   authorization.TransactionID,
+		ClientIPAddress: authorization.ClientIPAddress,
+	})
+	if err != nil {
+		return requests.TransactionStatusResult{}, err
+	}
+	status, err := requests.DecodeResponse[requests.TransactionStatusResult](res)
+	if err != nil {
+		return requests.TransactionStatusResult{}, fmt.Errorf("decode response: %w", err)
+	}
+	return status, nil
+}
+
+// execute captures the amount of the authorization with ExecuteDMS.
+func execute(ctx context.Context, sender maib.Sender, authorization Authorization, amount int) (maib.ResultEnum, int, error) {
+	res, err := sender.Send(ctx, requests.ExecuteDMS{
+		TransactionID:   authorization.TransactionID,
+		Amount:          amount,
+		Currency:        authorization.Currency,
+		ClientIPAddress: authorization.ClientIPAddress,
+		Description:     authorization.Description,
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	result, err := requests.DecodeResponse[requests.ExecuteDMSResult](res)
+	if err != nil {
+		return "", 0, fmt.Errorf("decode response: %w", err)
+	}
+	return result.Result, result.ResultCode, nil
+}
+
+// reverse releases the full amount of the authorization with
+// ReverseTransaction.
+func reverse(ctx context.Context, sender maib.Sender, authorization Authorization) (maib.ResultEnum, int, error) {
+	res, err := sender.Send(ctx, requests.ReverseTransaction{
+		TransactionID: authorization.TransactionID,
+		Amount:        authorization.Amount,
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	result, err := requests.DecodeResponse[requests.ReverseTransactionResult](res)
+	if err != nil {
+		return "", 0, fmt.Errorf("decode response: %w", err)
+	}
+	return result.Result, result.ResultCode, nil
+}
@@ -0,0 +1,405 @@
+package dms
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+var now = time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+// fakeSender answers each command with the scripted response or error.
+type fakeSender struct {
+	mu        sync.Mutex
+	responses map[string]map[string]any
+	errors    map[string]error
+	sent      []maib.Request
+	refs      []string
+}
+
+func (f *fakeSender) Send(ctx context.Context, req maib.Request) (map[string]any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, req)
+	ref, _ := maib.MerchantReferenceFrom(ctx)
+	f.refs = append(f.refs, ref)
+
+	values, err := req.Values()
+	if err != nil {
+		return nil, err
+	}
+	command := values.Get("command")
+	if err := f.errors[command]; err != nil {
+		return nil, err
+	}
+	if res, ok := f.responses[command]; ok {
+		return res, nil
+	}
+	return map[string]any{"RESULT": "OK"}, nil
+}
+
+func authorization(id string, age time.Duration) Authorization {
+	return Authorization{
+		TransactionID:   id,
+		Amount:          1999,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		AuthorizedAt:    now.Add(-age),
+		State:           StateAuthorized,
+	}
+}
+
+func newSweeper(t *testing.T, sender maib.Sender, store Store, policy Policy) (*Sweeper, *MemoryAuditLog) {
+	auditLog := NewMemoryAuditLog()
+	sweeper, err := NewSweeper(SweeperConfig{
+		Sender:   sender,
+		Store:    store,
+		Policy:   policy,
+		MaxAge:   72 * time.Hour,
+		AuditLog: auditLog,
+		Clock:    clock.NewFake(now),
+	})
+	assert.Nil(t, err)
+	return sweeper, auditLog
+}
+
+func TestNewSweeper(t *testing.T) {
+	_, err := NewSweeper(SweeperConfig{})
+	assert.Error(t, err)
+	_, err = NewSweeper(SweeperConfig{Sender: &fakeSender{}, Store: NewMemoryStore(), Policy: CaptureAll})
+	assert.Error(t, err)
+	_, err = NewSweeper(SweeperConfig{Sender: &fakeSender{}, Store: NewMemoryStore(), Policy: CaptureAll, MaxAge: time.Hour})
+	assert.Nil(t, err)
+}
+
+func TestSweeper_Sweep_Capture(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	assert.Nil(t, store.Save(ctx, authorization("staleaaaaaaaaaaaaaaaaaaaaaa=", 96*time.Hour)))
+	assert.Nil(t, store.Save(ctx, authorization("freshaaaaaaaaaaaaaaaaaaaaaa=", time.Hour)))
+	sender := &fakeSender{}
+	sweeper, auditLog := newSweeper(t, sender, store, CaptureAll)
+
+	entries, err := sweeper.Sweep(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, entries, auditLog.Entries())
+
+	entry := entries[0]
+	assert.Equal(t, "staleaaaaaaaaaaaaaaaaaaaaaa=", entry.TransactionID)
+	assert.Equal(t, ActionCapture, entry.Action)
+	assert.Equal(t, StateCaptured, entry.State)
+	assert.Equal(t, 1999, entry.Amount)
+	assert.Equal(t, now, entry.At)
+
+	assert.Len(t, sender.sent, 2)
+	assert.Equal(t, requests.ExecuteDMS{
+		TransactionID:   "staleaaaaaaaaaaaaaaaaaaaaaa=",
+		Amount:          1999,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+	}, sender.sent[1])
+
+	saved, _ := store.Get("staleaaaaaaaaaaaaaaaaaaaaaa=")
+	assert.Equal(t, StateCaptured, saved.State)
+	fresh, _ := store.Get("freshaaaaaaaaaaaaaaaaaaaaaa=")
+	assert.Equal(t, StateAuthorized, fresh.State)
+
+	// Handled authorizations are not swept again.
+	entries, err = sweeper.Sweep(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSweeper_Sweep_Release(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	assert.Nil(t, store.Save(ctx, authorization("staleaaaaaaaaaaaaaaaaaaaaaa=", 96*time.Hour)))
+	sender := &fakeSender{}
+	sweeper, _ := newSweeper(t, sender, store, ReleaseAll)
+
+	entries, err := sweeper.Sweep(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, StateReleased, entries[0].State)
+	assert.Equal(t, requests.ReverseTransaction{
+		TransactionID: "staleaaaaaaaaaaaaaaaaaaaaaa=",
+		Amount:        1999,
+	}, sender.sent[1])
+}
+
+func TestSweeper_Sweep_MerchantReference(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	stale := authorization("staleaaaaaaaaaaaaaaaaaaaaaa=", 96*time.Hour)
+	stale.MerchantReference = "order-1"
+	assert.Nil(t, store.Save(ctx, stale))
+	sender := &fakeSender{}
+	sweeper, _ := newSweeper(t, sender, store, CaptureAll)
+
+	entries, err := sweeper.Sweep(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "order-1", entries[0].MerchantReference)
+	assert.Equal(t, []string{"order-1", "order-1"}, sender.refs)
+}
+
+// outdatedStore returns the stale authorizations as they were before they
+// changed.
+type outdatedStore struct {
+	*MemoryStore
+	stale []Authorization
+}
+
+func (s outdatedStore) Stale(context.Context, time.Time) ([]Authorization, error) {
+	return s.stale, nil
+}
+
+func TestSweeper_Sweep_Outdated(t *testing.T) {
+	ctx := context.Background()
+	stale := authorization("staleaaaaaaaaaaaaaaaaaaaaaa=", 96*time.Hour)
+	store := outdatedStore{MemoryStore: NewMemoryStore(), stale: []Authorization{stale}}
+	captured := stale
+	captured.State = StateCaptured
+	captured.CapturedAmount = 1000
+	assert.Nil(t, store.Save(ctx, captured))
+	sender := &fakeSender{}
+	sweeper, _ := newSweeper(t, sender, store, ReleaseAll)
+
+	entries, err := sweeper.Sweep(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, entries)
+	assert.Empty(t, sender.sent)
+	saved, _ := store.Get(stale.TransactionID)
+	assert.Equal(t, captured, saved)
+}
+
+func TestSweeper_Sweep_ChangedMeanwhile(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	stale := authorization("staleaaaaaaaaaaaaaaaaaaaaaa=", 96*time.Hour)
+	assert.Nil(t, store.Save(ctx, stale))
+	released := stale
+	released.State = StateReleased
+	released.ReleasedAmount = stale.Amount
+
+	// Another process releases the authorization while it is being captured.
+	fake := &fakeSender{}
+	sender := senderFunc(func(ctx context.Context, req maib.Request) (map[string]any, error) {
+		if _, ok := req.(requests.ExecuteDMS); ok {
+			assert.Nil(t, store.Save(ctx, released))
+		}
+		return fake.Send(ctx, req)
+	})
+	sweeper, auditLog := newSweeper(t, sender, store, CaptureAll)
+
+	entries, err := sweeper.Sweep(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, StateCaptured, entries[0].State)
+	assert.Equal(t, "authorization changed during the sweep, not saved", entries[0].Error)
+	assert.Equal(t, entries, auditLog.Entries())
+	saved, _ := store.Get(stale.TransactionID)
+	assert.Equal(t, released, saved)
+}
+
+func TestSweeper_Sweep_Capturer(t *testing.T) {
+	ctx := context.Background()
+	var sweeper *Sweeper
+	var swept []AuditEntry
+	fake := &fakeSender{}
+	capturer, store, _ := newCapturer(t, senderFunc(func(ctx context.Context, req maib.Request) (map[string]any, error) {
+		if _, ok := req.(requests.ExecuteDMS); ok {
+			var err error
+			swept, err = sweeper.Sweep(ctx)
+			assert.Nil(t, err)
+		}
+		return fake.Send(ctx, req)
+	}))
+	sweeper, err := NewSweeper(SweeperConfig{
+		Sender:   fake,
+		Store:    store,
+		Policy:   ReleaseAll,
+		MaxAge:   time.Minute,
+		Clock:    clock.NewFake(now),
+		Capturer: capturer,
+	})
+	assert.Nil(t, err)
+
+	// The authorization being captured is not released by the sweeper.
+	_, err = capturer.Capture(ctx, authorized, 1000, maib.CurrencyMDL)
+	assert.Nil(t, err)
+	assert.Empty(t, swept)
+	assert.Len(t, fake.sent, 2)
+	saved, _ := store.Get(authorized)
+	assert.Equal(t, StateCaptured, saved.State)
+	assert.Equal(t, 1000, saved.CapturedAmount)
+	assert.Zero(t, saved.ReleasedAmount)
+}
+
+func TestSweeper_Sweep_CapturerInProgress(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	stale := authorization("staleaaaaaaaaaaaaaaaaaaaaaa=", 96*time.Hour)
+	assert.Nil(t, store.Save(ctx, stale))
+	capturer, err := NewCapturer(CapturerConfig{Sender: &fakeSender{}, Store: store})
+	assert.Nil(t, err)
+
+	// The authorization being swept can't be released by the capturer.
+	var releaseErr error
+	fake := &fakeSender{}
+	sender := senderFunc(func(ctx context.Context, req maib.Request) (map[string]any, error) {
+		if _, ok := req.(requests.ExecuteDMS); ok {
+			_, releaseErr = capturer.Release(ctx, stale.TransactionID)
+		}
+		return fake.Send(ctx, req)
+	})
+	sweeper, err := NewSweeper(SweeperConfig{
+		Sender:   sender,
+		Store:    store,
+		Policy:   CaptureAll,
+		MaxAge:   time.Hour,
+		Clock:    clock.NewFake(now),
+		Capturer: capturer,
+	})
+	assert.Nil(t, err)
+
+	entries, err := sweeper.Sweep(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, entries, 1)
+	assert.ErrorIs(t, releaseErr, ErrInProgress)
+	saved, _ := store.Get(stale.TransactionID)
+	assert.Equal(t, StateCaptured, saved.State)
+	assert.Zero(t, saved.ReleasedAmount)
+}
+
+func TestSweeper_Sweep_Outcomes(t *testing.T) {
+	cases := []struct {
+		name          string
+		policy        Policy
+		sender        *fakeSender
+		expectedState State
+		expectedError bool
+		expectedSent  int
+	}{
+		{
+			name:   "Not OK",
+			policy: CaptureAll,
+			sender: &fakeSender{responses: map[string]map[string]any{
+				"c": {"RESULT": "DECLINED", "RESULT_CODE": 116},
+			}},
+			expectedState: StateVoid,
+			expectedSent:  1,
+		},
+		{
+			name:          "Status unreachable",
+			policy:        CaptureAll,
+			sender:        &fakeSender{errors: map[string]error{"c": &url.Error{Err: context.DeadlineExceeded}}},
+			expectedState: StateAuthorized,
+			expectedError: true,
+			expectedSent:  1,
+		},
+		{
+			name:          "Capture unreachable",
+			policy:        CaptureAll,
+			sender:        &fakeSender{errors: map[string]error{"t": &url.Error{Err: context.DeadlineExceeded}}},
+			expectedState: StateUnknown,
+			expectedError: true,
+			expectedSent:  2,
+		},
+		{
+			name:          "Release bad gateway",
+			policy:        ReleaseAll,
+			sender:        &fakeSender{errors: map[string]error{"r": &maib.ECommError{Code: 502, Body: "Bad Gateway"}}},
+			expectedState: StateUnknown,
+			expectedError: true,
+			expectedSent:  2,
+		},
+		{
+			name:          "Capture rejected",
+			policy:        CaptureAll,
+			sender:        &fakeSender{errors: map[string]error{"t": &maib.ECommError{Code: 200, Body: "error: wrong state"}}},
+			expectedState: StateFailed,
+			expectedError: true,
+			expectedSent:  2,
+		},
+		{
+			name:   "Release declined",
+			policy: ReleaseAll,
+			sender: &fakeSender{responses: map[string]map[string]any{
+				"r": {"RESULT": "FAILED", "RESULT_CODE": 914},
+			}},
+			expectedState: StateFailed,
+			expectedSent:  2,
+		},
+		{
+			name: "Keep",
+			policy: func(Authorization) Action {
+				return ActionKeep
+			},
+			sender:        &fakeSender{},
+			expectedState: StateAuthorized,
+			expectedSent:  1,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			ctx := context.Background()
+			assert.Nil(t, store.Save(ctx, authorization("staleaaaaaaaaaaaaaaaaaaaaaa=", 96*time.Hour)))
+			sweeper, _ := newSweeper(t, c.sender, store, c.policy)
+
+			entries, err := sweeper.Sweep(ctx)
+			assert.Nil(t, err)
+			assert.Len(t, entries, 1)
+			assert.Equal(t, c.expectedState, entries[0].State)
+			assert.Equal(t, c.expectedError, entries[0].Error != "")
+			assert.Len(t, c.sender.sent, c.expectedSent)
+
+			saved, _ := store.Get("staleaaaaaaaaaaaaaaaaaaaaaa=")
+			assert.Equal(t, c.expectedState, saved.State)
+
+			// Only authorizations left as is are swept again.
+			entries, err = sweeper.Sweep(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedState == StateAuthorized, len(entries) == 1)
+		})
+	}
+}
+
+func TestSweeper_Run(t *testing.T) {
+	store := NewMemoryStore()
+	fakeClock := clock.NewFake(now)
+	auditLog := NewMemoryAuditLog()
+	sweeper, err := NewSweeper(SweeperConfig{
+		Sender:   &fakeSender{},
+		Store:    store,
+		Policy:   CaptureAll,
+		MaxAge:   time.Hour,
+		AuditLog: auditLog,
+		Clock:    fakeClock,
+		Interval: time.Minute,
+	})
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- sweeper.Run(ctx)
+	}()
+
+	fakeClock.BlockUntil(1)
+	assert.Nil(t, store.Save(ctx, authorization("staleaaaaaaaaaaaaaaaaaaaaaa=", 2*time.Hour)))
+	fakeClock.Advance(time.Minute)
+	fakeClock.BlockUntil(1)
+	assert.Len(t, auditLog.Entries(), 1)
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -12,11 +12,22 @@ To use this module you should:
 # Usage
 
  1. Use [NewClient] to set up a [Client] that communicates with the MAIB
-    ECommerce system.
+    ECommerce system. Set [Config.Environment] to [EnvironmentTest] or
+    [EnvironmentProduction] to use the addresses of that environment.
  2. Send a [Request] with [Client.Send] (The requests described in the
     ECommerce documentation are implemented in the `requests` package).
  3. Decode the returned map into a result struct with requests.DecodeResult.
 
+Merchants with several certificates or merchant handler endpoints, e.g. one
+per legal entity, may use a [MultiClient], that routes each request to the
+[Client] of the merchant named with [WithMerchant] or [MerchantRequest].
+
+Many requests, e.g. TransactionStatus of thousands of transactions, may be
+sent concurrently with [Client.SendBatch].
+
+Set [Config.Recorder] to keep an audit trail of every sent request, e.g. the
+tamper-evident journal of the `journal` package.
+
 # Error Handling
 
 Use [errors.As] to check the type and the contents of the errors returned by
@@ -27,6 +38,16 @@ the [Client]:
     body starts with "error:".
   - [ParseError] is returned if the response has an invalid structure, or
     a response field has an unexpected datatype.
+  - [IdempotencyConflictError] is returned if an idempotency key set with
+    [WithIdempotencyKey] was already used for a different payload.
+  - [IdempotencyPendingError] is returned if the request of an idempotency
+    key has failed with an unknown outcome, until the key is resolved.
+  - [RecordError] is returned if the [Recorder] fails to record the request
+    before it is sent.
+  - [FailoverRefusedError] is returned if a command that moves money fails on
+    the primary endpoint while a secondary endpoint is configured.
+  - [ReferenceError] wraps the errors of requests linked to a merchant
+    reference with [WithMerchantReference].
 
 See the example to get an understanding of the full flow.
 */
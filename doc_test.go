@@ -44,7 +44,8 @@ func Example() {
 	// Send a Transaction Status request with a timeout.
 	// Equivalent to this POST request:
 	// command=c&trans_id=<TransactionID>&client_ip_addr=127.0.0.1
-	ctx, _ := context.WithTimeout(context.Background(), time.Minute)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
 	res, _ = client.Send(ctx, requests.TransactionStatus{
 		TransactionID:   newTransaction.TransactionID,
 		ClientIPAddress: "127.0.0.1",
@@ -0,0 +1,111 @@
+package maib
+
+import (
+	"crypto/x509"
+	"fmt"
+	"net/url"
+)
+
+// Environment bundles the addresses of one MAIB ECommerce environment. Set
+// [Config.Environment] to one of the presets, [EnvironmentTest] or
+// [EnvironmentProduction], instead of copying the URLs by hand.
+type Environment struct {
+	// Name of the environment, like "test".
+	Name string
+
+	// API communication URL.
+	MerchantHandlerEndpoint string
+
+	// API communication URL for read-only commands while
+	// MerchantHandlerEndpoint is failing, see
+	// Config.SecondaryMerchantHandlerEndpoint. Optional. MAIB doesn't publish
+	// one, so the presets leave it empty.
+	SecondaryMerchantHandlerEndpoint string
+
+	// URL of the payment page, where the payer is redirected with the
+	// transaction ID.
+	ClientHandlerURL string
+
+	// Pool of CAs expected to sign the server certificate. Optional. The presets
+	// don't pin a CA yet, so the system pool is used, as the ECommerce servers
+	// use certificates of public CAs.
+	RootCAs *x509.CertPool
+}
+
+// EnvironmentTest returns the test environment, used with the test certificate
+// issued by MAIB.
+func EnvironmentTest() Environment {
+	return Environment{
+		Name:                    "test",
+		MerchantHandlerEndpoint: "https://maib.ecommerce.md:21440/ecomm/MerchantHandler",
+		ClientHandlerURL:        "https://maib.ecommerce.md:21443/ecomm/ClientHandler",
+	}
+}
+
+// EnvironmentProduction returns the production environment, used with the
+// production certificate issued by MAIB.
+func EnvironmentProduction() Environment {
+	return Environment{
+		Name:                    "production",
+		MerchantHandlerEndpoint: "https://maib.ecommerce.md:11440/ecomm01/MerchantHandler",
+		ClientHandlerURL:        "https://maib.ecommerce.md:443/ecomm01/ClientHandler",
+	}
+}
+
+// LookupEnvironment returns the preset with the name, "test" or "production",
+// and whether it was found.
+func LookupEnvironment(name string) (Environment, bool) {
+	for _, env := range []Environment{EnvironmentTest(), EnvironmentProduction()} {
+		if env.Name == name {
+			return env, true
+		}
+	}
+	return Environment{}, false
+}
+
+// PaymentURL returns the URL of the payment page of the transaction, where the
+// payer should be redirected after it is registered.
+func (e Environment) PaymentURL(transactionID string) string {
+	return e.ClientHandlerURL + "?" + url.Values{"trans_id": {transactionID}}.Encode()
+}
+
+// withEnvironment fills the configuration from its environment. Explicit
+// merchant handler endpoints must match the environment, to catch a client of
+// one environment pointed at the other.
+func (config Config) withEnvironment() (Config, error) {
+	env := config.Environment
+	if env.MerchantHandlerEndpoint != "" {
+		switch config.MerchantHandlerEndpoint {
+		case "":
+			config.MerchantHandlerEndpoint = env.MerchantHandlerEndpoint
+		case env.MerchantHandlerEndpoint:
+		default:
+			return Config{}, fmt.Errorf("merchant handler endpoint %q does not belong to the %s environment", config.MerchantHandlerEndpoint, env.Name)
+		}
+	}
+	switch {
+	case config.SecondaryMerchantHandlerEndpoint == "":
+		config.SecondaryMerchantHandlerEndpoint = env.SecondaryMerchantHandlerEndpoint
+	case config.SecondaryMerchantHandlerEndpoint == env.SecondaryMerchantHandlerEndpoint:
+	case env.SecondaryMerchantHandlerEndpoint != "", env.Name != "" && otherEnvironment(env.Name, config.SecondaryMerchantHandlerEndpoint):
+		return Config{}, fmt.Errorf("secondary merchant handler endpoint %q does not belong to the %s environment", config.SecondaryMerchantHandlerEndpoint, env.Name)
+	}
+	if config.RootCAs == nil {
+		config.RootCAs = env.RootCAs
+	}
+	return config, nil
+}
+
+// otherEnvironment reports whether the endpoint belongs to a preset other than
+// the one with the name.
+func otherEnvironment(name, endpoint string) bool {
+	for _, env := range []Environment{EnvironmentTest(), EnvironmentProduction()} {
+		if env.Name == name {
+			continue
+		}
+		if endpoint == env.MerchantHandlerEndpoint || endpoint == env.SecondaryMerchantHandlerEndpoint {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package maib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupEnvironment(t *testing.T) {
+	env, ok := LookupEnvironment("production")
+	assert.True(t, ok)
+	assert.Equal(t, EnvironmentProduction(), env)
+	_, ok = LookupEnvironment("staging")
+	assert.False(t, ok)
+
+	// The presets can't be changed by their users.
+	env.MerchantHandlerEndpoint = "https://localhost"
+	env, _ = LookupEnvironment("production")
+	assert.Equal(t, EnvironmentProduction().MerchantHandlerEndpoint, env.MerchantHandlerEndpoint)
+}
+
+func TestEnvironment_PaymentURL(t *testing.T) {
+	assert.Equal(t,
+		"https://maib.ecommerce.md:21443/ecomm/ClientHandler?trans_id=abc%2B%2F%3D",
+		EnvironmentTest().PaymentURL("abc+/="))
+}
+
+func TestConfig_withEnvironment(t *testing.T) {
+	config, err := Config{Environment: EnvironmentTest()}.withEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, EnvironmentTest().MerchantHandlerEndpoint, config.MerchantHandlerEndpoint)
+
+	_, err = Config{
+		Environment:             EnvironmentTest(),
+		MerchantHandlerEndpoint: EnvironmentTest().MerchantHandlerEndpoint,
+	}.withEnvironment()
+	assert.NoError(t, err)
+
+	config, err = Config{MerchantHandlerEndpoint: "https://localhost"}.withEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, "https://localhost", config.MerchantHandlerEndpoint)
+}
+
+func TestConfig_withEnvironment_Secondary(t *testing.T) {
+	env := EnvironmentTest()
+	env.SecondaryMerchantHandlerEndpoint = "https://backup.maib.md/ecomm/MerchantHandler"
+	config, err := Config{Environment: env}.withEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, env.SecondaryMerchantHandlerEndpoint, config.SecondaryMerchantHandlerEndpoint)
+
+	_, err = Config{Environment: env, SecondaryMerchantHandlerEndpoint: "https://localhost"}.withEnvironment()
+	assert.EqualError(t, err, `secondary merchant handler endpoint "https://localhost" does not belong to the test environment`)
+
+	// Without a secondary endpoint in the preset, only the endpoints of the
+	// other presets are rejected.
+	config, err = Config{Environment: EnvironmentTest(), SecondaryMerchantHandlerEndpoint: "https://localhost"}.withEnvironment()
+	require.NoError(t, err)
+	assert.Equal(t, "https://localhost", config.SecondaryMerchantHandlerEndpoint)
+
+	_, err = Config{
+		Environment:                      EnvironmentTest(),
+		SecondaryMerchantHandlerEndpoint: EnvironmentProduction().MerchantHandlerEndpoint,
+	}.withEnvironment()
+	assert.EqualError(t, err, `secondary merchant handler endpoint "https://maib.ecommerce.md:11440/ecomm01/MerchantHandler" does not belong to the test environment`)
+}
+
+func TestNewClient_WrongEnvironment(t *testing.T) {
+	_, err := NewClient(Config{
+		PFXPath:                 clientCertPath(t),
+		Passphrase:              clientCertPass,
+		MerchantHandlerEndpoint: EnvironmentTest().MerchantHandlerEndpoint,
+		Environment:             EnvironmentProduction(),
+	})
+	assert.EqualError(t, err, `merchant handler endpoint "https://maib.ecommerce.md:21440/ecomm/MerchantHandler" does not belong to the production environment`)
+}
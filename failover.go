@@ -0,0 +1,85 @@
+package maib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const defaultFailoverCooldown = 30 * time.Second
+
+// readOnlyCommands are the commands that may be sent to the secondary
+// endpoint. Repeating them can't move money.
+var readOnlyCommands = map[string]bool{
+	"c": true, // TransactionStatus
+}
+
+// FailoverRefusedError is returned by [Client.Send] when a command that is not
+// read-only fails on the primary endpoint. The command is not repeated on the
+// secondary endpoint, because it may have been executed by the primary one.
+// Check the outcome with TransactionStatus before retrying.
+type FailoverRefusedError struct {
+	// Command field of the request.
+	Command string
+
+	// Error returned by the primary endpoint.
+	Err error
+}
+
+func (e *FailoverRefusedError) Error() string {
+	return fmt.Sprintf("command %q is not read-only, refusing to fail over: %s", e.Command, e.Err)
+}
+
+func (e *FailoverRefusedError) Unwrap() error {
+	return e.Err
+}
+
+// health tracks the failures of the primary endpoint.
+type health struct {
+	mu             sync.Mutex
+	unhealthyUntil time.Time
+}
+
+// sendFailover sends the payload to the primary endpoint, or for read-only
+// commands, to the secondary endpoint if the primary one is failing.
+func (c *Client) sendFailover(ctx context.Context, values url.Values) (map[string]any, error) {
+	command := values.Get(string(FieldCommand))
+	readOnly := readOnlyCommands[command]
+
+	c.primaryHealth.mu.Lock()
+	healthy := !c.now().Before(c.primaryHealth.unhealthyUntil)
+	c.primaryHealth.mu.Unlock()
+	if readOnly && !healthy {
+		return c.post(ctx, c.secondaryEndpoint, values)
+	}
+
+	res, err := c.post(ctx, c.merchantHandlerEndpoint, values)
+	if err == nil || !isEndpointFailure(ctx, err) {
+		return res, err
+	}
+
+	c.primaryHealth.mu.Lock()
+	c.primaryHealth.unhealthyUntil = c.now().Add(c.failoverCooldown)
+	c.primaryHealth.mu.Unlock()
+	if !readOnly {
+		return nil, &FailoverRefusedError{Command: command, Err: err}
+	}
+	return c.post(ctx, c.secondaryEndpoint, values)
+}
+
+// isEndpointFailure reports whether the error is caused by the endpoint
+// rather than the request, so another endpoint may succeed.
+func isEndpointFailure(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var ecommErr *ECommError
+	if errors.As(err, &ecommErr) {
+		return ecommErr.Code >= http.StatusInternalServerError
+	}
+	return !errors.As(err, new(*ParseError))
+}
@@ -0,0 +1,115 @@
+package maib
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2/testpki"
+)
+
+type endpoint struct {
+	url    string
+	hits   atomic.Int32
+	status atomic.Int32
+}
+
+// createFailoverClient returns a client with a primary and a secondary
+// endpoint. Both respond with their name and the status set on them.
+func createFailoverClient(t *testing.T) (client *Client, primary *endpoint, secondary *endpoint) {
+	ca, serverCert := loadCerts(t)
+	start := func(name string) *endpoint {
+		e := &endpoint{}
+		e.status.Store(http.StatusOK)
+		server := createServer(ca, serverCert, func(writer http.ResponseWriter, request *http.Request) {
+			e.hits.Add(1)
+			writer.WriteHeader(int(e.status.Load()))
+			_, _ = writer.Write([]byte("ENDPOINT: " + name))
+		})
+		server.StartTLS()
+		t.Cleanup(server.Close)
+		e.url = server.URL
+		return e
+	}
+	primary = start("primary")
+	secondary = start("secondary")
+
+	client, err := NewClient(Config{
+		PFXPath:                          ca.ClientPFXFile(t, clientCertPass, testpki.Options{}),
+		Passphrase:                       clientCertPass,
+		MerchantHandlerEndpoint:          primary.url,
+		SecondaryMerchantHandlerEndpoint: secondary.url,
+		RootCAs:                          ca.Pool(),
+	})
+	require.NoError(t, err)
+	return client, primary, secondary
+}
+
+func TestClient_Send_FailoverReadOnly(t *testing.T) {
+	client, primary, secondary := createFailoverClient(t)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+	status := transactionRequest{referenceTransactionID}
+
+	res, err := client.Send(ctx, status)
+	require.NoError(t, err)
+	assert.Equal(t, "primary", res["ENDPOINT"])
+
+	primary.status.Store(http.StatusServiceUnavailable)
+	res, err = client.Send(ctx, status)
+	require.NoError(t, err)
+	assert.Equal(t, "secondary", res["ENDPOINT"])
+
+	// The failing primary is skipped until the cooldown ends.
+	res, err = client.Send(ctx, status)
+	require.NoError(t, err)
+	assert.Equal(t, "secondary", res["ENDPOINT"])
+	assert.Equal(t, int32(2), primary.hits.Load())
+
+	primary.status.Store(http.StatusOK)
+	now = now.Add(defaultFailoverCooldown)
+	res, err = client.Send(ctx, status)
+	require.NoError(t, err)
+	assert.Equal(t, "primary", res["ENDPOINT"])
+	assert.Equal(t, int32(2), secondary.hits.Load())
+}
+
+func TestClient_Send_FailoverRefused(t *testing.T) {
+	client, primary, secondary := createFailoverClient(t)
+	primary.status.Store(http.StatusBadGateway)
+
+	_, err := client.Send(ctx, testRequest{true})
+	var refusedErr *FailoverRefusedError
+	require.ErrorAs(t, err, &refusedErr)
+	assert.Equal(t, testCommand, refusedErr.Command)
+	assert.ErrorAs(t, err, new(*ECommError))
+
+	// Not even while the primary is known to be failing.
+	_, err = client.Send(ctx, testRequest{true})
+	assert.ErrorAs(t, err, &refusedErr)
+	assert.Equal(t, int32(2), primary.hits.Load())
+	assert.Equal(t, int32(0), secondary.hits.Load())
+}
+
+func TestClient_Send_NoFailover(t *testing.T) {
+	client, primary, secondary := createFailoverClient(t)
+	status := transactionRequest{referenceTransactionID}
+
+	// A rejected request fails on any endpoint.
+	primary.status.Store(http.StatusBadRequest)
+	_, err := client.Send(ctx, status)
+	assert.ErrorAs(t, err, new(*ECommError))
+
+	// A cancelled request is not repeated.
+	primary.status.Store(http.StatusOK)
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = client.Send(cancelled, status)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(0), secondary.hits.Load())
+}
@@ -0,0 +1,64 @@
+package gateway
+
+import (
+	"reflect"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+// endpoint exposes a request type at POST /v1/{path}.
+type endpoint struct {
+	path    string
+	summary string
+	request reflect.Type
+	result  reflect.Type
+	decode  func(res map[string]any) (any, error)
+}
+
+// newEndpoint creates the endpoint of the request type Req, which is decoded
+// with decode.
+func newEndpoint[Req maib.Request, Res any](path, summary string, decode func(map[string]any) (Res, error)) endpoint {
+	return endpoint{
+		path:    path,
+		summary: summary,
+		request: reflect.TypeFor[Req](),
+		result:  reflect.TypeFor[Res](),
+		decode: func(res map[string]any) (any, error) {
+			return decode(res)
+		},
+	}
+}
+
+var endpoints = []endpoint{
+	newEndpoint[requests.RegisterTransaction]("register-transaction",
+		"Register an SMS or DMS transaction",
+		requests.DecodeResponse[requests.RegisterTransactionResult]),
+	newEndpoint[requests.RegisterRecurring]("register-recurring",
+		"Register a recurring payment",
+		requests.DecodeResponse[requests.RegisterRecurringResult]),
+	newEndpoint[requests.RegisterOneClick]("register-one-click",
+		"Register a oneClick payment",
+		requests.DecodeResponse[requests.RegisterOneClickResult]),
+	newEndpoint[requests.TransactionStatus]("transaction-status",
+		"Get the status of a transaction",
+		requests.DecodeResponse[requests.TransactionStatusResult]),
+	newEndpoint[requests.ExecuteDMS]("execute-dms",
+		"Execute a DMS authorization",
+		requests.DecodeResponse[requests.ExecuteDMSResult]),
+	newEndpoint[requests.ExecuteRecurring]("execute-recurring",
+		"Charge a card saved for recurring payments",
+		requests.DecodeResponse[requests.ExecuteRecurringResult]),
+	newEndpoint[requests.ExecuteOneClick]("execute-one-click",
+		"Charge a card saved for oneClick payments",
+		requests.DecodeResponse[requests.ExecuteOneClickResult]),
+	newEndpoint[requests.ReverseTransaction]("reverse-transaction",
+		"Reverse a transaction, fully or partially",
+		requests.DecodeResponse[requests.ReverseTransactionResult]),
+	newEndpoint[requests.DeleteRecurring]("delete-recurring",
+		"Delete a recurring or oneClick payment",
+		requests.DecodeResponse[requests.DeleteRecurringResult]),
+	newEndpoint[requests.CloseDay]("close-day",
+		"Close the business day",
+		requests.DecodeResponse[requests.CloseDayResult]),
+}
@@ -0,0 +1,159 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// Types of [Error].
+const (
+	// The API key is missing or unknown. Status 401.
+	ErrorUnauthorized = "unauthorized"
+
+	// The body is not a JSON object of the request. Status 400.
+	ErrorBadRequest = "bad_request"
+
+	// The request failed validation, see [maib.ValidationError]. Status 422.
+	ErrorValidation = "validation"
+
+	// The ECommerce system rejected the request with an "error:" body or a 4xx
+	// status, so it was not executed, see [maib.ECommError]. Status 422.
+	ErrorECommerce = "ecomm"
+
+	// The request was refused before it was sent, e.g. its merchant is
+	// unknown, see [maib.IsRejected]. Status 422.
+	ErrorRejected = "rejected"
+
+	// The idempotency key was used with another payload, or its request has an
+	// unknown outcome, see [maib.IdempotencyConflictError] and
+	// [maib.IdempotencyPendingError]. Nothing was sent. Status 409.
+	ErrorConflict = "conflict"
+
+	// The request could not be recorded before it was sent, see
+	// [maib.RecordError]. Nothing was sent, so it can be retried. Status 503.
+	ErrorNotSent = "not_sent"
+
+	// The outcome of the request is unknown: the ECommerce system returned a
+	// 5xx status, or could not be reached. A command may have been executed,
+	// so check it with transaction-status before retrying. Status 502.
+	ErrorUnknown = "unknown"
+
+	// The response of the ECommerce system could not be parsed, see
+	// [maib.ParseError]. The outcome is unknown, as for [ErrorUnknown].
+	// Status 502.
+	ErrorParse = "parse"
+
+	// The ECommerce system didn't respond in time. The outcome is unknown, as
+	// for [ErrorUnknown]. Status 504.
+	ErrorTimeout = "timeout"
+)
+
+// errorTypes are the Error* constants, documented in the OpenAPI schema.
+var errorTypes = []string{
+	ErrorUnauthorized, ErrorBadRequest, ErrorValidation, ErrorECommerce, ErrorRejected,
+	ErrorConflict, ErrorNotSent, ErrorUnknown, ErrorParse, ErrorTimeout,
+}
+
+// Error is the body of failed responses, wrapped as {"error": {...}}.
+type Error struct {
+	// One of the Error* constants.
+	Type string `json:"type"`
+
+	// Human-readable explanation.
+	Message string `json:"message"`
+
+	// JSON name of the malformed field, for validation errors.
+	Field string `json:"field,omitempty"`
+
+	// ECommerce payload name of the malformed field, for validation errors.
+	PayloadField maib.PayloadField `json:"payload_field,omitempty"`
+
+	// HTTP status code returned by the ECommerce system, for ecomm and unknown
+	// errors.
+	Code int `json:"code,omitempty"`
+
+	// Body returned by the ECommerce system, for ecomm, unknown and parse
+	// errors.
+	Body string `json:"body,omitempty"`
+}
+
+// errorBody wraps an [Error] in the response body.
+type errorBody struct {
+	Error Error `json:"error"`
+}
+
+// sendError converts the error of Client.Send into an [Error], and returns it
+// with its status code. Rejected requests, see [maib.IsRejected], are 4xx, and
+// requests with an unknown outcome are 5xx.
+func sendError(err error, request reflect.Type) (int, Error) {
+	var validationErr *maib.ValidationError
+	var ecommErr *maib.ECommError
+	var parseErr *maib.ParseError
+	switch {
+	case errors.As(err, &validationErr):
+		return http.StatusUnprocessableEntity, Error{
+			Type:         ErrorValidation,
+			Message:      validationErr.Error(),
+			Field:        jsonNameOfPayloadField(request, validationErr.Field),
+			PayloadField: validationErr.Field,
+		}
+	case errors.As(err, new(*maib.IdempotencyConflictError)), errors.As(err, new(*maib.IdempotencyPendingError)):
+		return http.StatusConflict, Error{
+			Type:    ErrorConflict,
+			Message: err.Error(),
+		}
+	case errors.As(err, new(*maib.RecordError)):
+		return http.StatusServiceUnavailable, Error{
+			Type:    ErrorNotSent,
+			Message: err.Error(),
+		}
+	case errors.As(err, &ecommErr):
+		status, errorType := http.StatusBadGateway, ErrorUnknown
+		if maib.IsRejected(ecommErr) {
+			status, errorType = http.StatusUnprocessableEntity, ErrorECommerce
+		}
+		return status, Error{
+			Type:    errorType,
+			Message: ecommErr.Error(),
+			Code:    ecommErr.Code,
+			Body:    ecommErr.Body,
+		}
+	case maib.IsRejected(err):
+		return http.StatusUnprocessableEntity, Error{
+			Type:    ErrorRejected,
+			Message: err.Error(),
+		}
+	case errors.As(err, &parseErr):
+		return http.StatusBadGateway, Error{
+			Type:    ErrorParse,
+			Message: parseErr.Error(),
+			Body:    parseErr.Body,
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout, Error{
+			Type:    ErrorTimeout,
+			Message: err.Error(),
+		}
+	}
+	return http.StatusBadGateway, Error{
+		Type:    ErrorUnknown,
+		Message: err.Error(),
+	}
+}
+
+// writeError writes the error as the response.
+func writeError(w http.ResponseWriter, status int, e Error) {
+	writeJSON(w, status, errorBody{Error: e})
+}
+
+// writeJSON writes the value as the JSON response.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,177 @@
+/*
+Package gateway exposes the ECommerce commands as a JSON HTTP API, so that
+services written in other languages can take payments without handling the
+MAIB certificate themselves.
+
+Each request type of the requests package is served at POST /v1/{command},
+e.g. POST /v1/transaction-status. The body is a JSON object with the fields of
+the request in snake case, and the response is the result struct in the same
+form:
+
+	POST /v1/reverse-transaction
+	Authorization: Bearer <API key>
+
+	{"transaction_id": "abcdefghijklmnopqrstuvwxyz0=", "amount": 500}
+
+	200 OK
+	{"result": "OK", "result_code": 400}
+
+Requests are validated by the same validators as [maib.Client.Send]. Failures
+are returned as an [Error] body. The OpenAPI document is served at GET
+/openapi.json without authentication.
+*/
+package gateway
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/journal"
+)
+
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultMaxBodySize = 64 << 10
+)
+
+// Config is the configuration required to set up a [Server].
+type Config struct {
+	// Sender used to send the requests, usually a *maib.Client. Required.
+	Sender maib.Sender
+
+	// API keys of the callers, mapped to the names of the callers. Callers send
+	// the key as "Authorization: Bearer <key>". Required.
+	APIKeys map[string]string
+
+	// Timeout of a request to the ECommerce system. Default is 30 seconds.
+	Timeout time.Duration
+}
+
+// Server is an [http.Handler] serving the gateway API. It is safe for
+// concurrent use.
+//
+// Must be initiated with [New].
+type Server struct {
+	sender  maib.Sender
+	apiKeys map[string]string
+	timeout time.Duration
+	mux     *http.ServeMux
+	openAPI []byte
+}
+
+// New validates the configuration and returns a *[Server].
+func New(config Config) (*Server, error) {
+	if config.Sender == nil {
+		return nil, errors.New("sender is required")
+	}
+	if len(config.APIKeys) == 0 {
+		return nil, errors.New("API keys are required")
+	}
+	for key := range config.APIKeys {
+		if key == "" {
+			return nil, errors.New("API key must not be empty")
+		}
+	}
+
+	s := &Server{
+		sender:  config.Sender,
+		apiKeys: config.APIKeys,
+		timeout: config.Timeout,
+		mux:     http.NewServeMux(),
+		openAPI: OpenAPI(),
+	}
+	if s.timeout <= 0 {
+		s.timeout = defaultTimeout
+	}
+
+	for _, e := range endpoints {
+		s.mux.Handle("POST /v1/"+e.path, s.authenticate(s.handle(e)))
+	}
+	s.mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(s.openAPI)
+	})
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, Error{Type: ErrorBadRequest, Message: "unknown endpoint " + r.Method + " " + r.URL.Path})
+	})
+	return s, nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+type callerKey struct{}
+
+// Caller returns the name of the caller authenticated by its API key, from
+// the context of a request handled by the [Server].
+func Caller(ctx context.Context) (string, bool) {
+	name, ok := ctx.Value(callerKey{}).(string)
+	return name, ok
+}
+
+// authenticate rejects requests without a known API key, and stores the name
+// of the caller in the context, also as the actor of the audit journal.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		name, found := s.caller(key)
+		if !ok || !found {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, Error{Type: ErrorUnauthorized, Message: "missing or unknown API key"})
+			return
+		}
+		ctx := journal.WithActor(context.WithValue(r.Context(), callerKey{}, name), name)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// caller finds the caller of the key. Every key is compared in constant time,
+// so that the timing doesn't reveal the keys.
+func (s *Server) caller(key string) (string, bool) {
+	var name string
+	found := false
+	for k, n := range s.apiKeys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			name, found = n, true
+		}
+	}
+	return name, found
+}
+
+// handle decodes the request of the endpoint, sends it and writes the result.
+func (s *Server) handle(e endpoint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, defaultMaxBodySize))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, Error{Type: ErrorBadRequest, Message: "read body: " + err.Error()})
+			return
+		}
+		req, err := decodeRequest(body, e.request)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, Error{Type: ErrorBadRequest, Message: err.Error()})
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+		defer cancel()
+		res, err := s.sender.Send(ctx, req)
+		if err != nil {
+			status, body := sendError(err, e.request)
+			writeError(w, status, body)
+			return
+		}
+		result, err := e.decode(res)
+		if err != nil {
+			writeError(w, http.StatusBadGateway, Error{Type: ErrorParse, Message: "decode response: " + err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, encodeResult(result))
+	})
+}
@@ -0,0 +1,333 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/journal"
+	"github.com/NikSays/go-maib-ecomm/v2/maibfake"
+	"github.com/NikSays/go-maib-ecomm/v2/maibtest"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+const (
+	apiKey        = "secret-key"
+	transactionID = "abcdefghijklmnopqrstuvwxyz0="
+)
+
+func newServer(t *testing.T, sender maib.Sender) *httptest.Server {
+	s, err := New(Config{Sender: sender, APIKeys: map[string]string{apiKey: "shop"}})
+	require.NoError(t, err)
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// post sends the body to the endpoint, and decodes the JSON response.
+func post(t *testing.T, server *httptest.Server, path, key, body string) (int, map[string]any) {
+	req, err := http.NewRequest(http.MethodPost, server.URL+path, strings.NewReader(body))
+	require.NoError(t, err)
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	res, err := server.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+	var decoded map[string]any
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&decoded))
+	return res.StatusCode, decoded
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{APIKeys: map[string]string{apiKey: "shop"}})
+	assert.EqualError(t, err, "sender is required")
+
+	_, err = New(Config{Sender: maibfake.New()})
+	assert.EqualError(t, err, "API keys are required")
+
+	_, err = New(Config{Sender: maibfake.New(), APIKeys: map[string]string{"": "shop"}})
+	assert.EqualError(t, err, "API key must not be empty")
+}
+
+func TestServer_Endpoints(t *testing.T) {
+	fake := maibfake.New()
+	fake.Respond(requests.RegisterRecurring{}, maibfake.Result(requests.RegisterRecurringResult{TransactionID: transactionID}))
+	fake.Respond(requests.ReverseTransaction{}, maibfake.Result(requests.ReverseTransactionResult{Result: maib.ResultOk, ResultCode: 400}))
+	fake.Respond(requests.CloseDay{}, maibfake.Result(requests.CloseDayResult{Result: maib.ResultOk, CreditTransactionNumber: 3}))
+	server := newServer(t, fake)
+
+	status, body := post(t, server, "/v1/register-recurring", apiKey, `{
+		"transaction_type": "without_payment",
+		"currency": 498,
+		"client_ip_address": "127.0.0.1",
+		"language": "ro",
+		"biller_client_id": "client-1",
+		"perspayee_expiry": "1230",
+		"overwrite_existing": true
+	}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]any{"transaction_id": transactionID}, body)
+	fake.AssertSentOnce(t, requests.RegisterRecurring{
+		TransactionType:   requests.RegisterRecurringWithoutPayment,
+		Currency:          maib.CurrencyMDL,
+		ClientIPAddress:   "127.0.0.1",
+		Language:          maib.LanguageRomanian,
+		BillerClientID:    "client-1",
+		PerspayeeExpiry:   "1230",
+		OverwriteExisting: true,
+	})
+
+	status, body = post(t, server, "/v1/reverse-transaction", apiKey, `{"transaction_id": "`+transactionID+`", "amount": 500}`)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]any{"result": "OK", "result_code": 400.0}, body)
+	fake.AssertSentOnce(t, requests.ReverseTransaction{TransactionID: transactionID, Amount: 500})
+
+	status, body = post(t, server, "/v1/close-day", apiKey, "")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "OK", body["result"])
+	assert.Equal(t, 3.0, body["credit_transaction_number"])
+	assert.Equal(t, 0.0, body["debit_transaction_number"])
+}
+
+func TestServer_Authentication(t *testing.T) {
+	fake := maibfake.New()
+	fake.Respond(requests.CloseDay{}, maibfake.Result(requests.CloseDayResult{Result: maib.ResultOk}))
+	var caller, actor string
+	s, err := New(Config{
+		Sender: maib.Sender(senderFunc(func(ctx context.Context, req maib.Request) (map[string]any, error) {
+			caller, _ = Caller(ctx)
+			actor, _ = journal.ActorFrom(ctx)
+			return fake.Send(ctx, req)
+		})),
+		APIKeys: map[string]string{apiKey: "shop", "other-key": "billing"},
+	})
+	require.NoError(t, err)
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	for _, key := range []string{"", "wrong-key", apiKey + "x"} {
+		status, body := post(t, server, "/v1/close-day", key, "{}")
+		assert.Equal(t, http.StatusUnauthorized, status)
+		assert.Equal(t, ErrorUnauthorized, body["error"].(map[string]any)["type"])
+	}
+	assert.Empty(t, fake.Calls())
+
+	status, _ := post(t, server, "/v1/close-day", "other-key", "{}")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "billing", caller)
+	assert.Equal(t, "billing", actor)
+}
+
+type senderFunc func(ctx context.Context, req maib.Request) (map[string]any, error)
+
+func (f senderFunc) Send(ctx context.Context, req maib.Request) (map[string]any, error) {
+	return f(ctx, req)
+}
+
+func TestServer_Errors(t *testing.T) {
+	cases := []struct {
+		name     string
+		path     string
+		body     string
+		response maibfake.Response
+		status   int
+		expected Error
+	}{
+		{
+			name:     "malformed JSON",
+			path:     "/v1/transaction-status",
+			body:     `{"transaction_id": `,
+			status:   http.StatusBadRequest,
+			expected: Error{Type: ErrorBadRequest, Message: "malformed JSON: unexpected end of JSON input"},
+		},
+		{
+			name:     "unknown field",
+			path:     "/v1/transaction-status",
+			body:     `{"trans_id": "x"}`,
+			status:   http.StatusBadRequest,
+			expected: Error{Type: ErrorBadRequest, Message: `unknown field "trans_id"`},
+		},
+		{
+			name:     "unknown enum",
+			path:     "/v1/register-transaction",
+			body:     `{"transaction_type": "recurring"}`,
+			status:   http.StatusBadRequest,
+			expected: Error{Type: ErrorBadRequest, Message: `malformed field "transaction_type": expected one of sms, dms`},
+		},
+		{
+			name:   "validation",
+			path:   "/v1/transaction-status",
+			body:   `{"transaction_id": "` + transactionID + `", "client_ip_address": "localhost"}`,
+			status: http.StatusUnprocessableEntity,
+			expected: Error{
+				Type:         ErrorValidation,
+				Field:        "client_ip_address",
+				PayloadField: maib.FieldClientIPAddress,
+			},
+		},
+		{
+			name:     "ECommerce error",
+			path:     "/v1/transaction-status",
+			body:     `{"transaction_id": "` + transactionID + `", "client_ip_address": "127.0.0.1"}`,
+			response: maibfake.Error(&maib.ECommError{Code: 200, Body: "error: wrong trans_id"}),
+			status:   http.StatusUnprocessableEntity,
+			expected: Error{
+				Type:    ErrorECommerce,
+				Message: "maib ecomm returned 200: error: wrong trans_id",
+				Code:    200,
+				Body:    "error: wrong trans_id",
+			},
+		},
+		{
+			name:     "ECommerce unavailable",
+			path:     "/v1/transaction-status",
+			body:     `{"transaction_id": "` + transactionID + `", "client_ip_address": "127.0.0.1"}`,
+			response: maibfake.Error(&maib.ECommError{Code: 503, Body: "maintenance"}),
+			status:   http.StatusBadGateway,
+			expected: Error{
+				Type:    ErrorUnknown,
+				Message: "maib ecomm returned 503: maintenance",
+				Code:    503,
+				Body:    "maintenance",
+			},
+		},
+		{
+			name:     "unknown merchant",
+			path:     "/v1/transaction-status",
+			body:     `{"transaction_id": "` + transactionID + `", "client_ip_address": "127.0.0.1"}`,
+			response: maibfake.Error(maib.ErrNoMerchant),
+			status:   http.StatusUnprocessableEntity,
+			expected: Error{Type: ErrorRejected, Message: maib.ErrNoMerchant.Error()},
+		},
+		{
+			name:     "idempotency conflict",
+			path:     "/v1/transaction-status",
+			body:     `{"transaction_id": "` + transactionID + `", "client_ip_address": "127.0.0.1"}`,
+			response: maibfake.Error(&maib.IdempotencyConflictError{Key: "k"}),
+			status:   http.StatusConflict,
+			expected: Error{Type: ErrorConflict, Message: `idempotency key "k" was used with a different payload`},
+		},
+		{
+			name:     "not recorded",
+			path:     "/v1/transaction-status",
+			body:     `{"transaction_id": "` + transactionID + `", "client_ip_address": "127.0.0.1"}`,
+			response: maibfake.Error(&maib.RecordError{Err: errors.New("disk full")}),
+			status:   http.StatusServiceUnavailable,
+			expected: Error{Type: ErrorNotSent, Message: "record exchange: disk full"},
+		},
+		{
+			name:     "parse error",
+			path:     "/v1/transaction-status",
+			body:     `{"transaction_id": "` + transactionID + `", "client_ip_address": "127.0.0.1"}`,
+			response: maibfake.Error(&maib.ParseError{Err: errors.New("bad line"), Body: "RESULT"}),
+			status:   http.StatusBadGateway,
+			expected: Error{Type: ErrorParse, Message: "parse response: bad line", Body: "RESULT"},
+		},
+		{
+			name:     "timeout",
+			path:     "/v1/transaction-status",
+			body:     `{"transaction_id": "` + transactionID + `", "client_ip_address": "127.0.0.1"}`,
+			response: maibfake.Error(context.DeadlineExceeded),
+			status:   http.StatusGatewayTimeout,
+			expected: Error{Type: ErrorTimeout, Message: "context deadline exceeded"},
+		},
+		{
+			name:     "network",
+			path:     "/v1/transaction-status",
+			body:     `{"transaction_id": "` + transactionID + `", "client_ip_address": "127.0.0.1"}`,
+			response: maibfake.Error(errors.New("connection refused")),
+			status:   http.StatusBadGateway,
+			expected: Error{Type: ErrorUnknown, Message: "connection refused"},
+		},
+		{
+			name:     "unknown endpoint",
+			path:     "/v1/refund",
+			body:     `{}`,
+			status:   http.StatusNotFound,
+			expected: Error{Type: ErrorBadRequest, Message: "unknown endpoint POST /v1/refund"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := maibfake.New()
+			fake.Respond(requests.TransactionStatus{}, c.response)
+			server := newServer(t, fake)
+
+			req, err := http.NewRequest(http.MethodPost, server.URL+c.path, strings.NewReader(c.body))
+			require.NoError(t, err)
+			req.Header.Set("Authorization", "Bearer "+apiKey)
+			res, err := server.Client().Do(req)
+			require.NoError(t, err)
+			defer res.Body.Close()
+			assert.Equal(t, c.status, res.StatusCode)
+
+			var body errorBody
+			require.NoError(t, json.NewDecoder(res.Body).Decode(&body))
+			if c.expected.Type == ErrorValidation {
+				assert.NotEmpty(t, body.Error.Message)
+				body.Error.Message = ""
+			}
+			assert.Equal(t, c.expected, body.Error)
+		})
+	}
+}
+
+func TestServer_Timeout(t *testing.T) {
+	s, err := New(Config{
+		Sender: senderFunc(func(ctx context.Context, req maib.Request) (map[string]any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}),
+		APIKeys: map[string]string{apiKey: "shop"},
+		Timeout: time.Millisecond,
+	})
+	require.NoError(t, err)
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	status, _ := post(t, server, "/v1/close-day", apiKey, "{}")
+	assert.Equal(t, http.StatusGatewayTimeout, status)
+}
+
+func TestServer_OpenAPI(t *testing.T) {
+	server := newServer(t, maibfake.New())
+
+	res, err := server.Client().Get(server.URL + "/openapi.json")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(OpenAPI()), string(body))
+}
+
+func TestServer_Client(t *testing.T) {
+	mock := maibtest.NewServer(t, maibtest.Config{})
+	server := newServer(t, mock.Client(t))
+
+	status, body := post(t, server, "/v1/register-transaction", apiKey,
+		`{"transaction_type": "dms", "amount": 1999, "currency": 498, "client_ip_address": "127.0.0.1", "language": "en"}`)
+	require.Equal(t, http.StatusOK, status, body)
+	id := body["transaction_id"].(string)
+	require.NoError(t, mock.Approve(id))
+
+	status, body = post(t, server, "/v1/transaction-status", apiKey,
+		`{"transaction_id": "`+id+`", "client_ip_address": "127.0.0.1"}`)
+	require.Equal(t, http.StatusOK, status, body)
+	assert.Equal(t, "OK", body["result"])
+	assert.NotZero(t, body["rrn"])
+}
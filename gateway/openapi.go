@@ -0,0 +1,99 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// OpenAPI returns the OpenAPI 3.0 document of the gateway API, generated from
+// the request and result structs.
+func OpenAPI() []byte {
+	errorSchema := schemaOf(reflect.TypeFor[Error]())
+	errorSchema["properties"].(map[string]any)["type"] = map[string]any{"type": "string", "enum": errorTypes}
+	schemas := map[string]any{
+		"Error": map[string]any{
+			"type":     "object",
+			"required": []string{"error"},
+			"properties": map[string]any{
+				"error": errorSchema,
+			},
+		},
+	}
+	paths := make(map[string]any, len(endpoints))
+	for _, e := range endpoints {
+		schemas[e.request.Name()] = schemaOf(e.request)
+		schemas[e.result.Name()] = schemaOf(e.result)
+		paths["/v1/"+e.path] = map[string]any{
+			"post": operation(e),
+		}
+	}
+
+	document := map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":   "MAIB ECommerce gateway",
+			"version": "1",
+		},
+		"paths":    paths,
+		"security": []any{map[string]any{"apiKey": []string{}}},
+		"components": map[string]any{
+			"schemas": schemas,
+			"securitySchemes": map[string]any{
+				"apiKey": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+	}
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		// The document only contains maps, slices and strings.
+		panic(err)
+	}
+	return data
+}
+
+// errorResponses describe the status codes of the failed responses.
+var errorResponses = map[int]string{
+	http.StatusBadRequest:          "The body is not a JSON object of the request.",
+	http.StatusUnauthorized:        "The API key is missing or unknown.",
+	http.StatusConflict:            "The idempotency key was used with another payload, or its request has an unknown outcome. Nothing was sent.",
+	http.StatusUnprocessableEntity: "The request failed validation, or was rejected without being executed.",
+	http.StatusBadGateway:          "The outcome of the request is unknown. A command may have been executed, so check it with transaction-status before retrying.",
+	http.StatusServiceUnavailable:  "The request could not be recorded, so nothing was sent. It can be retried.",
+	http.StatusGatewayTimeout:      "The ECommerce system didn't respond in time. The outcome is unknown, as for 502.",
+}
+
+// operation returns the OpenAPI operation of the endpoint.
+func operation(e endpoint) map[string]any {
+	responses := map[string]any{
+		"200": map[string]any{
+			"description": "Response of the ECommerce system.",
+			"content":     jsonContent(e.result.Name()),
+		},
+	}
+	for status, description := range errorResponses {
+		responses[strconv.Itoa(status)] = map[string]any{
+			"description": description,
+			"content":     jsonContent("Error"),
+		}
+	}
+	return map[string]any{
+		"operationId": e.request.Name(),
+		"summary":     e.summary,
+		"requestBody": map[string]any{
+			"required": true,
+			"content":  jsonContent(e.request.Name()),
+		},
+		"responses": responses,
+	}
+}
+
+// jsonContent references the schema as the JSON content of a body.
+func jsonContent(schema string) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{
+			"schema": map[string]any{"$ref": "#/components/schemas/" + schema},
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package gateway
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI(t *testing.T) {
+	var document struct {
+		OpenAPI string `json:"openapi"`
+		Paths   map[string]struct {
+			Post struct {
+				OperationID string `json:"operationId"`
+				RequestBody struct {
+					Content map[string]struct {
+						Schema struct {
+							Ref string `json:"$ref"`
+						} `json:"schema"`
+					} `json:"content"`
+				} `json:"requestBody"`
+				Responses map[string]any `json:"responses"`
+			} `json:"post"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]any `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.Unmarshal(OpenAPI(), &document))
+
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+	assert.Len(t, document.Paths, len(endpoints))
+	for _, e := range endpoints {
+		post := document.Paths["/v1/"+e.path].Post
+		assert.Equal(t, e.request.Name(), post.OperationID)
+		assert.Equal(t, "#/components/schemas/"+e.request.Name(), post.RequestBody.Content["application/json"].Schema.Ref)
+		assert.Contains(t, post.Responses, "200")
+		assert.Contains(t, post.Responses, "409")
+		assert.Contains(t, post.Responses, "422")
+		assert.Contains(t, post.Responses, "502")
+		assert.Contains(t, document.Components.Schemas, e.request.Name())
+		assert.Contains(t, document.Components.Schemas, e.result.Name())
+	}
+	assert.Contains(t, document.Components.Schemas, "Error")
+}
@@ -0,0 +1,153 @@
+package gateway
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/internal/snakecase"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+// enumNames are the JSON names of the integer enums in the requests package.
+var enumNames = map[reflect.Type][]string{
+	reflect.TypeOf(requests.RegisterTransactionType(0)): {"sms", "dms"},
+	reflect.TypeOf(requests.RegisterRecurringType(0)):   {"sms", "dms", "without_payment"},
+	reflect.TypeOf(requests.RegisterOneClickType(0)):    {"sms", "without_payment"},
+}
+
+// stringEnums are the values of the string enums in the results.
+var stringEnums = map[reflect.Type][]string{
+	reflect.TypeOf(maib.ResultEnum("")): {
+		string(maib.ResultOk), string(maib.ResultFailed), string(maib.ResultCreated),
+		string(maib.ResultPending), string(maib.ResultDeclined), string(maib.ResultReversed),
+		string(maib.ResultAutoReversed), string(maib.ResultTimeout),
+	},
+	reflect.TypeOf(maib.ResultPSEnum("")): {
+		string(maib.ResultPSActive), string(maib.ResultPSFinished),
+		string(maib.ResultPSCancelled), string(maib.ResultPSReturned),
+	},
+}
+
+// decodeRequest decodes a JSON object into a request struct of type t. Keys
+// are the snake case field names, and unknown keys are rejected. An empty
+// body is an empty object.
+func decodeRequest(body []byte, t reflect.Type) (maib.Request, error) {
+	v := reflect.New(t).Elem()
+	if len(bytes.TrimSpace(body)) > 0 {
+		var raw map[string]json.RawMessage
+		err := json.Unmarshal(body, &raw)
+		if err != nil {
+			return nil, fmt.Errorf("malformed JSON: %w", err)
+		}
+		for key, value := range raw {
+			field, ok := fieldByJSONName(v, key)
+			if !ok {
+				return nil, fmt.Errorf("unknown field %q", key)
+			}
+			err = decodeField(field, value)
+			if err != nil {
+				return nil, fmt.Errorf("malformed field %q: %w", key, err)
+			}
+		}
+	}
+	return v.Interface().(maib.Request), nil
+}
+
+// fieldByJSONName returns the field of the struct with the JSON name.
+func fieldByJSONName(v reflect.Value, name string) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).IsExported() && snakecase.FromField(v.Type().Field(i).Name) == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// decodeField decodes a JSON value into the field, converting enum names.
+func decodeField(field reflect.Value, value json.RawMessage) error {
+	names, ok := enumNames[field.Type()]
+	if !ok {
+		return json.Unmarshal(value, field.Addr().Interface())
+	}
+	var name string
+	err := json.Unmarshal(value, &name)
+	if err != nil {
+		return err
+	}
+	for i, n := range names {
+		if n == name {
+			field.SetInt(int64(i))
+			return nil
+		}
+	}
+	return fmt.Errorf("expected one of %s", strings.Join(names, ", "))
+}
+
+// encodeResult converts a result struct into a JSON object with snake case
+// keys. Zero fields are kept, because a RESULT_CODE of 0 is meaningful.
+func encodeResult(result any) map[string]any {
+	v := reflect.ValueOf(result)
+	object := make(map[string]any, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).IsExported() {
+			object[snakecase.FromField(v.Type().Field(i).Name)] = v.Field(i).Interface()
+		}
+	}
+	return object
+}
+
+// jsonNameOfPayloadField returns the JSON name of the request field that is
+// sent as the payload field, or the payload field itself if there is none.
+func jsonNameOfPayloadField(t reflect.Type, field maib.PayloadField) string {
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("url"), ",")
+		if tag == string(field) {
+			return snakecase.FromField(t.Field(i).Name)
+		}
+	}
+	return string(field)
+}
+
+// schemaOf returns the OpenAPI schema of a request or result struct.
+func schemaOf(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		properties[snakecase.FromField(field.Name)] = fieldSchema(field.Type)
+	}
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+// fieldSchema returns the OpenAPI schema of a field type.
+func fieldSchema(t reflect.Type) map[string]any {
+	if names, ok := enumNames[t]; ok {
+		return map[string]any{"type": "string", "enum": names, "default": names[0]}
+	}
+	if values, ok := stringEnums[t]; ok {
+		return map[string]any{"type": "string", "enum": values}
+	}
+	switch t {
+	case reflect.TypeOf(maib.Currency(0)):
+		return map[string]any{"type": "integer", "description": "ISO4217 numeric code, e.g. 498 for MDL."}
+	case reflect.TypeOf(maib.Language("")):
+		return map[string]any{"type": "string", "example": string(maib.LanguageRomanian)}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	}
+	return map[string]any{"type": "string"}
+}
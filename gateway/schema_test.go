@@ -0,0 +1,54 @@
+package gateway
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	req, err := decodeRequest([]byte(`{"transaction_type": "dms", "amount": 100, "currency": 978}`),
+		reflect.TypeFor[requests.RegisterTransaction]())
+	require.NoError(t, err)
+	assert.Equal(t, requests.RegisterTransaction{
+		TransactionType: requests.RegisterTransactionDMS,
+		Amount:          100,
+		Currency:        maib.CurrencyEUR,
+	}, req)
+
+	req, err = decodeRequest(nil, reflect.TypeFor[requests.CloseDay]())
+	require.NoError(t, err)
+	assert.Equal(t, requests.CloseDay{}, req)
+
+	_, err = decodeRequest([]byte(`{"amount": "100"}`), reflect.TypeFor[requests.ExecuteDMS]())
+	assert.ErrorContains(t, err, `malformed field "amount"`)
+
+	_, err = decodeRequest([]byte(`[]`), reflect.TypeFor[requests.ExecuteDMS]())
+	assert.ErrorContains(t, err, "malformed JSON")
+}
+
+func TestJSONNameOfPayloadField(t *testing.T) {
+	typ := reflect.TypeFor[requests.ExecuteDMS]()
+	assert.Equal(t, "transaction_id", jsonNameOfPayloadField(typ, maib.FieldTransactionID))
+	assert.Equal(t, "client_ip_address", jsonNameOfPayloadField(typ, maib.FieldClientIPAddress))
+	assert.Equal(t, "language", jsonNameOfPayloadField(typ, maib.FieldLanguage))
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema := schemaOf(reflect.TypeFor[requests.RegisterOneClick]())
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "enum": []string{"sms", "without_payment"}, "default": "sms"},
+		properties["transaction_type"])
+	assert.Equal(t, "integer", properties["amount"].(map[string]any)["type"])
+	assert.Equal(t, "boolean", properties["ask_save_card_data"].(map[string]any)["type"])
+
+	schema = schemaOf(reflect.TypeFor[requests.TransactionStatusResult]())
+	properties = schema["properties"].(map[string]any)
+	assert.Contains(t, properties["result"].(map[string]any)["enum"], string(maib.ResultAutoReversed))
+	assert.Contains(t, properties["result_ps"].(map[string]any)["enum"], string(maib.ResultPSReturned))
+}
@@ -0,0 +1,269 @@
+package maib
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const defaultIdempotencyTTL = 24 * time.Hour
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a context that makes [Client.Send] idempotent
+// under the key, e.g. the ID of the order being paid.
+//
+// A pending record of the key is saved in the [IdempotencyStore] of the
+// client before the request is sent, and the result of a successful Send
+// replaces it. A later Send with the same key and the same payload returns
+// that result without contacting the ECommerce system, and a Send with the
+// same key but a different payload fails with [IdempotencyConflictError].
+//
+// A request rejected without being executed, see [IsRejected], releases the
+// key, so it can be retried with the same key. Any other failure, like a
+// timeout, leaves the record pending, because the request may have been
+// executed: a later Send with the key fails with [IdempotencyPendingError]
+// until the outcome is checked and settled with
+// [Client.ResolveIdempotencyKey].
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFrom returns the idempotency key of the context, set by
+// [WithIdempotencyKey].
+func IdempotencyKeyFrom(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKey{}).(string)
+	return key, ok && key != ""
+}
+
+// IdempotencyRecord is the result of a request sent with an idempotency key.
+type IdempotencyRecord struct {
+	// Idempotency key of the request.
+	Key string
+
+	// Hash of the request payload, to detect a key reused for another request.
+	PayloadHash string
+
+	// The request was sent, and its outcome is not known yet: it is in flight,
+	// or it failed after it may have reached the ECommerce system.
+	Pending bool
+
+	// Response returned by [Client.Send]. Empty while the record is pending.
+	Result map[string]any
+
+	// When the request was sent.
+	CreatedAt time.Time
+
+	// After this time the record is ignored, and the key may be reused.
+	ExpiresAt time.Time
+}
+
+// IdempotencyStore persists [IdempotencyRecord]s. A store shared by several
+// processes makes the keys idempotent across them, but concurrent requests
+// with the same key are only serialized within a process.
+type IdempotencyStore interface {
+	// Load returns the record of the key, or false if there is none.
+	Load(ctx context.Context, key string) (IdempotencyRecord, bool, error)
+
+	// Save creates or replaces the record with the same Key.
+	Save(ctx context.Context, record IdempotencyRecord) error
+}
+
+// IdempotencyConflictError is returned by [Client.Send] when the idempotency
+// key was already used with a different payload.
+type IdempotencyConflictError struct {
+	// The reused idempotency key.
+	Key string
+}
+
+func (e *IdempotencyConflictError) Error() string {
+	return fmt.Sprintf("idempotency key %q was used with a different payload", e.Key)
+}
+
+// IdempotencyPendingError is returned by [Client.Send] when the request of the
+// idempotency key is in flight in another process, or has failed with an
+// unknown outcome. Nothing is sent. Check the transaction, e.g. with
+// TransactionStatus, and settle the key with [Client.ResolveIdempotencyKey].
+type IdempotencyPendingError struct {
+	// The pending idempotency key.
+	Key string
+}
+
+func (e *IdempotencyPendingError) Error() string {
+	return fmt.Sprintf("idempotency key %q has a request with an unknown outcome", e.Key)
+}
+
+// MemoryIdempotencyStore is an [IdempotencyStore] that keeps records in
+// memory. Expired records are dropped as new ones are saved. It is safe for
+// concurrent use.
+type MemoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]IdempotencyRecord
+}
+
+// NewMemoryIdempotencyStore returns an empty *[MemoryIdempotencyStore].
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]IdempotencyRecord)}
+}
+
+func (s *MemoryIdempotencyStore) Load(_ context.Context, key string) (IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[key]
+	return record, ok, nil
+}
+
+func (s *MemoryIdempotencyStore) Save(_ context.Context, record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, r := range s.records {
+		if !r.ExpiresAt.After(record.CreatedAt) {
+			delete(s.records, key)
+		}
+	}
+	s.records[record.Key] = record
+	return nil
+}
+
+// sendIdempotent sends the request once per idempotency key, see
+// [WithIdempotencyKey].
+func (c *Client) sendIdempotent(ctx context.Context, key string, req Request, values url.Values) (map[string]any, error) {
+	hash := payloadHash(values)
+
+	unlock := c.idempotencyLocks.lock(key)
+	defer unlock()
+
+	now := c.now()
+	record, ok, err := c.idempotencyStore.Load(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("load idempotency record: %w", err)
+	}
+	if ok && now.Before(record.ExpiresAt) {
+		switch {
+		case record.PayloadHash != hash:
+			return nil, &IdempotencyConflictError{Key: key}
+		case record.Pending:
+			return nil, &IdempotencyPendingError{Key: key}
+		}
+		return maps.Clone(record.Result), nil
+	}
+
+	record = IdempotencyRecord{
+		Key:         key,
+		PayloadHash: hash,
+		Pending:     true,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(c.idempotencyTTL),
+	}
+	err = c.idempotencyStore.Save(ctx, record)
+	if err != nil {
+		return nil, fmt.Errorf("save idempotency record: %w", err)
+	}
+
+	res, err := c.send(ctx, req, values)
+	// The outcome is saved even if the context is done.
+	saveCtx := context.WithoutCancel(ctx)
+	switch {
+	case err != nil && IsRejected(err):
+		// Nothing was executed, so the key is released by expiring the record.
+		record.ExpiresAt = c.now()
+		saveErr := c.idempotencyStore.Save(saveCtx, record)
+		if saveErr != nil {
+			c.storeFailed(ctx, fmt.Errorf("release idempotency key: %w", saveErr))
+		}
+		return nil, err
+	case err != nil:
+		// The record stays pending, until it is resolved.
+		return nil, err
+	}
+
+	record.Pending = false
+	record.Result = maps.Clone(res)
+	err = c.idempotencyStore.Save(saveCtx, record)
+	if err != nil {
+		// The request was executed, and the record stays pending.
+		c.storeFailed(ctx, fmt.Errorf("save idempotency record: %w", err))
+	}
+	return res, nil
+}
+
+// ResolveIdempotencyKey settles a key whose request failed with an unknown
+// outcome, see [IdempotencyPendingError], after the transaction was checked,
+// e.g. with TransactionStatus. If the request was executed, result is the
+// response to return for the key, like the TRANSACTION_ID. If result is nil,
+// the request was not executed, and the key is released, so the request can be
+// sent again.
+func (c *Client) ResolveIdempotencyKey(ctx context.Context, key string, result map[string]any) error {
+	unlock := c.idempotencyLocks.lock(key)
+	defer unlock()
+
+	now := c.now()
+	record, ok, err := c.idempotencyStore.Load(ctx, key)
+	if err != nil {
+		return fmt.Errorf("load idempotency record: %w", err)
+	}
+	if !ok || !now.Before(record.ExpiresAt) || !record.Pending {
+		return fmt.Errorf("idempotency key %q is not pending", key)
+	}
+
+	record.Pending = false
+	if result != nil {
+		record.Result = maps.Clone(result)
+	} else {
+		record.ExpiresAt = now
+	}
+	err = c.idempotencyStore.Save(ctx, record)
+	if err != nil {
+		return fmt.Errorf("save idempotency record: %w", err)
+	}
+	return nil
+}
+
+// payloadHash returns the hash of the encoded payload. Encode sorts the keys,
+// so equal payloads have equal hashes.
+func payloadHash(values url.Values) string {
+	sum := sha256.Sum256([]byte(values.Encode()))
+	return hex.EncodeToString(sum[:])
+}
+
+// keyedMutex serializes the holders of the same key.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyLock
+}
+
+type keyLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// lock locks the key and returns the function that unlocks it.
+func (m *keyedMutex) lock(key string) func() {
+	m.mu.Lock()
+	if m.locks == nil {
+		m.locks = make(map[string]*keyLock)
+	}
+	l, ok := m.locks[key]
+	if !ok {
+		l = &keyLock{}
+		m.locks[key] = l
+	}
+	l.refs++
+	m.mu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		m.mu.Lock()
+		l.refs--
+		if l.refs == 0 {
+			delete(m.locks, key)
+		}
+		m.mu.Unlock()
+	}
+}
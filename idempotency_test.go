@@ -0,0 +1,234 @@
+package maib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type amountRequest struct {
+	amount int
+}
+
+func (r amountRequest) Values() (url.Values, error) {
+	return url.Values{"command": {testCommand}, "amount": {fmt.Sprint(r.amount)}}, nil
+}
+
+// createCountingClient returns a client whose server responds with the number
+// of requests it received, or with status if it is not 200.
+func createCountingClient(t *testing.T, status int) (*Client, *atomic.Int32) {
+	ca, serverCert := loadCerts(t)
+	var hits atomic.Int32
+	server := createServer(ca, serverCert, func(writer http.ResponseWriter, request *http.Request) {
+		n := hits.Add(1)
+		writer.WriteHeader(status)
+		_, _ = fmt.Fprintf(writer, "TRANSACTION_ID: %d", n)
+	})
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	client, err := createTrustingClient(t, server.URL, ca, ca.Pool())
+	require.NoError(t, err)
+	return client, &hits
+}
+
+func TestClient_Send_Idempotent(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusOK)
+	keyed := WithIdempotencyKey(ctx, "order-1")
+
+	first, err := client.Send(keyed, amountRequest{100})
+	require.NoError(t, err)
+	second, err := client.Send(keyed, amountRequest{100})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, int32(1), hits.Load())
+
+	_, err = client.Send(keyed, amountRequest{200})
+	var conflictErr *IdempotencyConflictError
+	require.ErrorAs(t, err, &conflictErr)
+	assert.Equal(t, "order-1", conflictErr.Key)
+	assert.Equal(t, int32(1), hits.Load())
+
+	// Another key and no key are sent.
+	_, err = client.Send(WithIdempotencyKey(ctx, "order-2"), amountRequest{200})
+	require.NoError(t, err)
+	_, err = client.Send(ctx, amountRequest{100})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), hits.Load())
+}
+
+func TestClient_Send_IdempotentExpired(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusOK)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+	keyed := WithIdempotencyKey(ctx, "order-1")
+
+	_, err := client.Send(keyed, amountRequest{100})
+	require.NoError(t, err)
+	now = now.Add(defaultIdempotencyTTL)
+	res, err := client.Send(keyed, amountRequest{200})
+	require.NoError(t, err)
+	assert.Equal(t, "2", res["TRANSACTION_ID"])
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestClient_Send_IdempotentRejected(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusBadRequest)
+	keyed := WithIdempotencyKey(ctx, "order-1")
+
+	// Rejected requests release the key.
+	for range 2 {
+		_, err := client.Send(keyed, amountRequest{100})
+		assert.ErrorAs(t, err, new(*ECommError))
+	}
+	assert.Equal(t, int32(2), hits.Load())
+
+	_, err := client.Send(keyed, testRequest{false})
+	assert.ErrorAs(t, err, new(*ValidationError))
+	assert.Equal(t, int32(2), hits.Load())
+}
+
+func TestClient_Send_IdempotentUnknown(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusBadGateway)
+	keyed := WithIdempotencyKey(ctx, "order-1")
+
+	_, err := client.Send(keyed, amountRequest{100})
+	assert.ErrorAs(t, err, new(*ECommError))
+
+	// The request may have been executed, so it is not sent again.
+	_, err = client.Send(keyed, amountRequest{100})
+	var pendingErr *IdempotencyPendingError
+	require.ErrorAs(t, err, &pendingErr)
+	assert.Equal(t, "order-1", pendingErr.Key)
+	assert.False(t, IsRejected(err))
+	assert.Equal(t, int32(1), hits.Load())
+
+	// The transaction was executed.
+	require.NoError(t, client.ResolveIdempotencyKey(ctx, "order-1", map[string]any{"TRANSACTION_ID": "1"}))
+	res, err := client.Send(keyed, amountRequest{100})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"TRANSACTION_ID": "1"}, res)
+	assert.Error(t, client.ResolveIdempotencyKey(ctx, "order-1", nil))
+
+	// The transaction was not executed, so the request is sent again.
+	keyed = WithIdempotencyKey(ctx, "order-2")
+	_, err = client.Send(keyed, amountRequest{100})
+	assert.ErrorAs(t, err, new(*ECommError))
+	require.NoError(t, client.ResolveIdempotencyKey(ctx, "order-2", nil))
+	_, err = client.Send(keyed, amountRequest{100})
+	assert.ErrorAs(t, err, new(*ECommError))
+	assert.Equal(t, int32(3), hits.Load())
+}
+
+func TestClient_Send_IdempotentConcurrent(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusOK)
+	keyed := WithIdempotencyKey(ctx, "order-1")
+
+	var wg sync.WaitGroup
+	results := make([]map[string]any, 8)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := client.Send(keyed, amountRequest{100})
+			assert.NoError(t, err)
+			results[i] = res
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), hits.Load())
+	for _, res := range results {
+		assert.Equal(t, results[0], res)
+	}
+}
+
+type failingIdempotencyStore struct{}
+
+func (failingIdempotencyStore) Load(_ context.Context, _ string) (IdempotencyRecord, bool, error) {
+	return IdempotencyRecord{}, false, nil
+}
+
+func (failingIdempotencyStore) Save(_ context.Context, _ IdempotencyRecord) error {
+	return errors.New("disk full")
+}
+
+func TestClient_Send_IdempotentSaveError(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusOK)
+	client.idempotencyStore = failingIdempotencyStore{}
+
+	// Without the pending record, the request is not sent.
+	res, err := client.Send(WithIdempotencyKey(ctx, "order-1"), amountRequest{100})
+	assert.EqualError(t, err, "save idempotency record: disk full")
+	assert.Nil(t, res)
+	assert.Zero(t, hits.Load())
+}
+
+// resultFailingIdempotencyStore fails to save the results, but not the pending
+// records.
+type resultFailingIdempotencyStore struct {
+	*MemoryIdempotencyStore
+}
+
+func (s resultFailingIdempotencyStore) Save(ctx context.Context, record IdempotencyRecord) error {
+	if !record.Pending {
+		return errors.New("disk full")
+	}
+	return s.MemoryIdempotencyStore.Save(ctx, record)
+}
+
+func TestClient_Send_IdempotentResultSaveError(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusOK)
+	client.idempotencyStore = resultFailingIdempotencyStore{NewMemoryIdempotencyStore()}
+	var storeErr error
+	client.onStoreError = func(_ context.Context, err error) {
+		storeErr = err
+	}
+	keyed := WithIdempotencyKey(ctx, "order-1")
+
+	// The request was executed, so Send doesn't fail, and the key stays pending.
+	res, err := client.Send(keyed, amountRequest{100})
+	require.NoError(t, err)
+	assert.Equal(t, "1", res["TRANSACTION_ID"])
+	assert.EqualError(t, storeErr, "save idempotency record: disk full")
+	_, err = client.Send(keyed, amountRequest{100})
+	assert.ErrorAs(t, err, new(*IdempotencyPendingError))
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, ok, err := store.Load(ctx, "a")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, store.Save(ctx, IdempotencyRecord{Key: "a", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}))
+	record, ok, err := store.Load(ctx, "a")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "a", record.Key)
+
+	// Saving after "a" expired drops it.
+	require.NoError(t, store.Save(ctx, IdempotencyRecord{Key: "b", CreatedAt: now.Add(time.Hour), ExpiresAt: now.Add(2 * time.Hour)}))
+	_, ok, _ = store.Load(ctx, "a")
+	assert.False(t, ok)
+}
+
+func TestIdempotencyKeyFrom(t *testing.T) {
+	_, ok := IdempotencyKeyFrom(ctx)
+	assert.False(t, ok)
+	_, ok = IdempotencyKeyFrom(WithIdempotencyKey(ctx, ""))
+	assert.False(t, ok)
+	key, ok := IdempotencyKeyFrom(WithIdempotencyKey(ctx, "order-1"))
+	assert.True(t, ok)
+	assert.Equal(t, "order-1", key)
+}
@@ -0,0 +1,26 @@
+// Package snakecase converts Go field names to the snake case names used by the
+// gateway.
+package snakecase
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FromField converts a Go field name to snake case, keeping acronyms together:
+// ClientIPAddress is client_ip_address.
+func FromField(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
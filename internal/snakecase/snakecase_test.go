@@ -0,0 +1,23 @@
+package snakecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromField(t *testing.T) {
+	cases := map[string]string{
+		"Amount":                  "amount",
+		"TransactionID":           "transaction_id",
+		"ClientIPAddress":         "client_ip_address",
+		"ThreeDSecure":            "three_d_secure",
+		"RRN":                     "rrn",
+		"ResultPS":                "result_ps",
+		"CreditTransactionNumber": "credit_transaction_number",
+		"Field2Name":              "field2_name",
+	}
+	for name, expected := range cases {
+		assert.Equal(t, expected, FromField(name), name)
+	}
+}
@@ -0,0 +1,170 @@
+// Package sqlfake provides a database/sql driver that passes every statement to
+// a Go [Handler], to test SQL stores without a database.
+package sqlfake
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+)
+
+// Handler executes the statements sent to the fake database. It is called
+// with the arguments converted to [driver.Value].
+type Handler interface {
+	// Exec executes a statement that returns no rows.
+	Exec(query string, args []driver.Value) (rowsAffected int64, err error)
+
+	// Query executes a statement that returns rows.
+	Query(query string, args []driver.Value) (columns []string, rows [][]driver.Value, err error)
+}
+
+// TxHandler is implemented by handlers that support transactions. Without
+// it, transactions are accepted and ignored.
+type TxHandler interface {
+	Handler
+
+	Begin() error
+	Commit() error
+	Rollback() error
+}
+
+// Open returns a database that sends every statement to the handler.
+func Open(h Handler) *sql.DB {
+	return sql.OpenDB(connector{h})
+}
+
+type connector struct {
+	h Handler
+}
+
+func (c connector) Connect(context.Context) (driver.Conn, error) {
+	return &conn{h: c.h}, nil
+}
+
+func (c connector) Driver() driver.Driver {
+	return fakeDriver{c}
+}
+
+type fakeDriver struct {
+	c connector
+}
+
+func (d fakeDriver) Open(string) (driver.Conn, error) {
+	return d.c.Connect(context.Background())
+}
+
+type conn struct {
+	h Handler
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return &stmt{c: c, query: query}, nil
+}
+
+func (c *conn) Close() error {
+	return nil
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	if tx, ok := c.h.(TxHandler); ok {
+		err := tx.Begin()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *conn) Commit() error {
+	if tx, ok := c.h.(TxHandler); ok {
+		return tx.Commit()
+	}
+	return nil
+}
+
+func (c *conn) Rollback() error {
+	if tx, ok := c.h.(TxHandler); ok {
+		return tx.Rollback()
+	}
+	return nil
+}
+
+func (c *conn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	n, err := c.h.Exec(query, values(args))
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (c *conn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	columns, data, err := c.h.Query(query, values(args))
+	if err != nil {
+		return nil, err
+	}
+	return &rows{columns: columns, data: data}, nil
+}
+
+type stmt struct {
+	c     *conn
+	query string
+}
+
+func (s *stmt) Close() error {
+	return nil
+}
+
+func (s *stmt) NumInput() int {
+	return -1
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	n, err := s.c.h.Exec(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	columns, data, err := s.c.h.Query(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &rows{columns: columns, data: data}, nil
+}
+
+type rows struct {
+	columns []string
+	data    [][]driver.Value
+}
+
+func (r *rows) Columns() []string {
+	return r.columns
+}
+
+func (r *rows) Close() error {
+	return nil
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if len(r.data) == 0 {
+		return io.EOF
+	}
+	if len(dest) != len(r.data[0]) {
+		return errors.New("sqlfake: row has a different number of columns")
+	}
+	copy(dest, r.data[0])
+	r.data = r.data[1:]
+	return nil
+}
+
+func values(args []driver.NamedValue) []driver.Value {
+	v := make([]driver.Value, len(args))
+	for i, arg := range args {
+		v[i] = arg.Value
+	}
+	return v
+}
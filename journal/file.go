@@ -0,0 +1,139 @@
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// FileStore is a [Store] that keeps entries in a file as JSON Lines. Every
+// entry is synced to disk before Append returns. It is safe for concurrent
+// use within a process.
+//
+// Must be initiated with [OpenFile].
+type FileStore struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+	last []byte
+}
+
+// OpenFile opens the journal file, creating it if needed, and returns a
+// *[FileStore]. A partial line left by an interrupted append is dropped.
+func OpenFile(path string) (*FileStore, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	s := &FileStore{path: path, file: file}
+
+	var length int64
+	err = readLines(file, func(line []byte) error {
+		length += int64(len(line))
+		if !isBlank(line) {
+			s.last = line
+		}
+		return nil
+	})
+	if err == nil {
+		err = file.Truncate(length)
+	}
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("read journal: %w", err)
+	}
+	return s, nil
+}
+
+// Close closes the file.
+func (s *FileStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+func (s *FileStore) Append(_ context.Context, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	line := append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info, err := s.file.Stat()
+	if err != nil {
+		return err
+	}
+	_, err = s.file.Write(line)
+	if err == nil {
+		err = s.file.Sync()
+	}
+	if err != nil {
+		// Drop the part of the entry that may have been written, so the next
+		// entry doesn't continue its line.
+		return errors.Join(err, s.file.Truncate(info.Size()))
+	}
+	s.last = line
+	return nil
+}
+
+func (s *FileStore) Last(_ context.Context) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.last == nil {
+		return Entry{}, false, nil
+	}
+	entry, err := decodeEntry(s.last)
+	return entry, err == nil, err
+}
+
+// Each reads the file from the start, so it also sees entries appended by
+// other processes.
+func (s *FileStore) Each(ctx context.Context, f func(Entry) error) error {
+	file, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return readLines(file, func(line []byte) error {
+		err := ctx.Err()
+		if err != nil || isBlank(line) {
+			return err
+		}
+		entry, err := decodeEntry(line)
+		if err != nil {
+			return err
+		}
+		return f(entry)
+	})
+}
+
+// readLines calls f for every complete line of r, including the newline.
+func readLines(r io.Reader, f func(line []byte) error) error {
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		err = f(line)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func isBlank(line []byte) bool {
+	return len(bytes.TrimSpace(line)) == 0
+}
@@ -0,0 +1,66 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+
+	store, err := OpenFile(path)
+	require.NoError(t, err)
+	_, ok, err := store.Last(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	appendEntries(t, newJournal(t, store), 2)
+	require.NoError(t, store.Close())
+
+	// An append interrupted halfway.
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	require.NoError(t, err)
+	_, err = file.WriteString(`{"seq":3,"ti`)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	store, err = OpenFile(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	last, ok, err := store.Last(ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), last.Sequence)
+
+	j := newJournal(t, store)
+	appendEntries(t, j, 1)
+	n, err := j.Verify(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
+
+func TestFileStore_Tampered(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	store, err := OpenFile(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	j := newJournal(t, store)
+	appendEntries(t, j, 2)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data = bytes.Replace(data, []byte(`"merchant_reference":"a"`), []byte(`"merchant_reference":"z"`), 1)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+
+	_, err = j.Verify(ctx)
+	var tamperErr *TamperError
+	require.ErrorAs(t, err, &tamperErr)
+	assert.Equal(t, int64(1), tamperErr.Sequence)
+}
@@ -0,0 +1,372 @@
+/*
+Package journal keeps a tamper-evident audit journal of the requests sent to
+the ECommerce system.
+
+Set the [Journal] as [maib.Config.Recorder], and every request sent by the
+client is appended to the journal with its type, payload, actor and time
+before it is sent, and again with its response or error. Sensitive payload and
+response fields are redacted. Each [Entry] contains the hash of the previous
+one, so changing, removing or reordering entries breaks the chain, which is
+detected by [Journal.Verify].
+
+Removing the last entries leaves a valid chain, so it can't be detected from
+the journal alone. Keep the [Anchor] of the journal outside of its store, e.g.
+by publishing it periodically, and check it with [Journal.VerifyAnchor].
+
+The journal is kept in a [Store]: [MemoryStore], [FileStore] or [SQLStore].
+*/
+package journal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+)
+
+// Redacted replaces the values of redacted fields.
+const Redacted = "REDACTED"
+
+// DefaultRedact are the payload and response fields redacted by default. Card
+// data never passes through the merchant, and card numbers are masked by the
+// ECommerce system.
+var DefaultRedact = []string{string(maib.FieldClientIPAddress)}
+
+type actorKey struct{}
+
+// WithActor returns a context that attributes the requests sent with it to the
+// actor, e.g. the operator of the back office or the calling service.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFrom returns the actor of the context, set by [WithActor].
+func ActorFrom(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok && actor != ""
+}
+
+// Entry is a request recorded in the journal.
+type Entry struct {
+	// Position in the journal, starting from 1.
+	Sequence int64 `json:"seq"`
+
+	// When the request was sent.
+	Time time.Time `json:"time"`
+
+	// Whether the request is about to be sent, or has completed.
+	Stage maib.ExchangeStage `json:"stage"`
+
+	// Who sent the request, set by [WithActor].
+	Actor string `json:"actor,omitempty"`
+
+	// Merchant's reference, set by maib.WithMerchantReference.
+	MerchantReference string `json:"merchant_reference,omitempty"`
+
+	// Go type of the request, like "requests.ReverseTransaction".
+	RequestType string `json:"request_type"`
+
+	// Redacted payload of the request.
+	Values url.Values `json:"values"`
+
+	// Redacted response, if the request succeeded. Empty at maib.StageSent.
+	Response map[string]any `json:"response,omitempty"`
+
+	// Go type of the error, like "*maib.ECommError", if the request failed.
+	ErrorType string `json:"error_type,omitempty"`
+
+	// Error message, if the request failed.
+	Error string `json:"error,omitempty"`
+
+	// Hash of the previous entry, empty for the first one.
+	PrevHash string `json:"prev_hash"`
+
+	// Hash of this entry, computed with Hash empty.
+	Hash string `json:"hash"`
+}
+
+// computeHash returns the SHA-256 of the JSON encoding of the entry without
+// its hash. The encoding sorts the keys of the maps, so it is stable.
+func (e Entry) computeHash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// TamperError is returned by [Journal.Verify] when the chain of entries is
+// broken, and by [Journal.VerifyAnchor] when the anchored entry is missing.
+type TamperError struct {
+	// Sequence of the first entry that doesn't match.
+	Sequence int64
+
+	// What doesn't match.
+	Reason string
+}
+
+func (e *TamperError) Error() string {
+	return fmt.Sprintf("journal entry %d: %s", e.Sequence, e.Reason)
+}
+
+// Anchor identifies the last entry of a journal at some point. Entries are
+// only appended, so a journal that is not tampered with always contains the
+// entry of an earlier anchor.
+type Anchor struct {
+	// Sequence of the entry, 0 if the journal was empty.
+	Sequence int64 `json:"seq"`
+
+	// Hash of the entry.
+	Hash string `json:"hash"`
+}
+
+// Config is the configuration required to set up a [Journal].
+type Config struct {
+	// Storage for the entries. Required.
+	Store Store
+
+	// Source of time, used when the exchange has no time. Default is
+	// [clock.Real].
+	Clock clock.Clock
+
+	// Payload and response fields whose values are replaced with [Redacted].
+	// Default is [DefaultRedact].
+	Redact []string
+}
+
+// Journal appends the exchanges of a maib.Client to a [Store]. It implements
+// maib.Recorder. It is safe for concurrent use, but only one Journal may
+// append to a store at a time.
+//
+// Must be initiated with [New].
+type Journal struct {
+	store  Store
+	clock  clock.Clock
+	redact []string
+
+	mu     sync.Mutex
+	loaded bool
+	last   Entry
+}
+
+var _ maib.Recorder = (*Journal)(nil)
+
+// New validates the configuration and returns a *[Journal].
+func New(config Config) (*Journal, error) {
+	if config.Store == nil {
+		return nil, errors.New("store is required")
+	}
+
+	j := &Journal{
+		store:  config.Store,
+		clock:  config.Clock,
+		redact: config.Redact,
+	}
+	if j.clock == nil {
+		j.clock = clock.Real
+	}
+	if j.redact == nil {
+		j.redact = DefaultRedact
+	}
+	return j, nil
+}
+
+// RecordExchange appends the exchange to the journal.
+func (j *Journal) RecordExchange(ctx context.Context, exchange maib.Exchange) error {
+	entry := Entry{
+		Time:        exchange.SentAt.UTC(),
+		Stage:       exchange.Stage,
+		RequestType: typeName(exchange.Request),
+		Values:      j.redactValues(exchange.Values),
+		Response:    j.redactResponse(exchange.Response),
+	}
+	if entry.Time.IsZero() {
+		entry.Time = j.clock.Now().UTC()
+	}
+	entry.Actor, _ = ActorFrom(ctx)
+	entry.MerchantReference, _ = maib.MerchantReferenceFrom(ctx)
+	if exchange.Err != nil {
+		entry.ErrorType = typeName(exchange.Err)
+		entry.Error = exchange.Err.Error()
+	}
+	return j.Append(ctx, entry)
+}
+
+// Append chains the entry to the last one and stores it. Its Sequence,
+// PrevHash and Hash are set by the journal.
+func (j *Journal) Append(ctx context.Context, entry Entry) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	err := j.load(ctx)
+	if err != nil {
+		return err
+	}
+	entry.Sequence = j.last.Sequence + 1
+	entry.PrevHash = j.last.Hash
+	hash, err := entry.computeHash()
+	if err != nil {
+		return fmt.Errorf("hash entry: %w", err)
+	}
+	entry.Hash = hash
+
+	err = j.store.Append(ctx, entry)
+	if err != nil {
+		// The store may have been appended to by another journal, or
+		// partially, so the next entry is chained to its last entry again.
+		j.loaded = false
+		return fmt.Errorf("append entry: %w", err)
+	}
+	j.last = entry
+	return nil
+}
+
+// Anchor returns the anchor of the last entry, to be kept outside of the
+// store.
+func (j *Journal) Anchor(ctx context.Context) (Anchor, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	err := j.load(ctx)
+	if err != nil {
+		return Anchor{}, err
+	}
+	return Anchor{Sequence: j.last.Sequence, Hash: j.last.Hash}, nil
+}
+
+// load loads the last entry of the store, once. Must be called with mu held.
+func (j *Journal) load(ctx context.Context) error {
+	if j.loaded {
+		return nil
+	}
+	last, _, err := j.store.Last(ctx)
+	if err != nil {
+		return fmt.Errorf("load last entry: %w", err)
+	}
+	j.last = last
+	j.loaded = true
+	return nil
+}
+
+// Verify checks the chain of all entries, and returns their number. If the
+// chain is broken, a *[TamperError] is returned.
+func (j *Journal) Verify(ctx context.Context) (int, error) {
+	var n int
+	err := j.verified(ctx, func(Entry) error {
+		n++
+		return nil
+	})
+	return n, err
+}
+
+// VerifyAnchor checks the chain like [Journal.Verify], and that the entry of
+// the anchor is still in the journal, so the entries after it were not
+// removed. If it is missing or changed, a *[TamperError] is returned.
+func (j *Journal) VerifyAnchor(ctx context.Context, anchor Anchor) (int, error) {
+	var n int
+	err := j.verified(ctx, func(entry Entry) error {
+		n++
+		if entry.Sequence == anchor.Sequence && entry.Hash != anchor.Hash {
+			return &TamperError{Sequence: entry.Sequence, Reason: "hash doesn't match the anchor"}
+		}
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+	if int64(n) < anchor.Sequence {
+		return n, &TamperError{Sequence: anchor.Sequence, Reason: "anchored entry is missing"}
+	}
+	return n, nil
+}
+
+// Replay verifies the journal and saves a record of every transaction created
+// by a recorded request, like RegisterTransaction, in the store, e.g. to
+// rebuild the lookups by merchant reference. It stops at the first broken
+// entry.
+func (j *Journal) Replay(ctx context.Context, store maib.TransactionStore) error {
+	return j.verified(ctx, func(entry Entry) error {
+		id, ok := entry.Response["TRANSACTION_ID"].(string)
+		if !ok || entry.ErrorType != "" {
+			return nil
+		}
+		err := store.Save(ctx, maib.TransactionRecord{
+			TransactionID:     id,
+			MerchantReference: entry.MerchantReference,
+			Command:           entry.Values.Get(string(maib.FieldCommand)),
+			CreatedAt:         entry.Time,
+		})
+		if err != nil {
+			return fmt.Errorf("save transaction record: %w", err)
+		}
+		return nil
+	})
+}
+
+// verified calls f for each entry in order, after checking it against the
+// previous one.
+func (j *Journal) verified(ctx context.Context, f func(Entry) error) error {
+	var prev Entry
+	return j.store.Each(ctx, func(entry Entry) error {
+		if entry.Sequence != prev.Sequence+1 {
+			return &TamperError{Sequence: entry.Sequence, Reason: fmt.Sprintf("follows entry %d", prev.Sequence)}
+		}
+		if entry.PrevHash != prev.Hash {
+			return &TamperError{Sequence: entry.Sequence, Reason: "previous hash doesn't match"}
+		}
+		hash, err := entry.computeHash()
+		if err != nil {
+			return fmt.Errorf("hash entry %d: %w", entry.Sequence, err)
+		}
+		if hash != entry.Hash {
+			return &TamperError{Sequence: entry.Sequence, Reason: "hash doesn't match the contents"}
+		}
+		prev = entry
+		return f(entry)
+	})
+}
+
+func (j *Journal) redactValues(values url.Values) url.Values {
+	redacted := make(url.Values, len(values))
+	for key, v := range values {
+		if slices.Contains(j.redact, key) {
+			v = []string{Redacted}
+		}
+		redacted[key] = slices.Clone(v)
+	}
+	return redacted
+}
+
+func (j *Journal) redactResponse(response map[string]any) map[string]any {
+	if response == nil {
+		return nil
+	}
+	redacted := make(map[string]any, len(response))
+	for key, v := range response {
+		if slices.Contains(j.redact, key) {
+			v = Redacted
+		}
+		redacted[key] = v
+	}
+	return redacted
+}
+
+// typeName returns the Go type of v, like "*maib.ECommError".
+func typeName(v any) string {
+	if v == nil {
+		return ""
+	}
+	return reflect.TypeOf(v).String()
+}
@@ -0,0 +1,250 @@
+package journal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/maibtest"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newJournal(t *testing.T, store Store) *Journal {
+	j, err := New(Config{Store: store, Clock: clock.NewFake(now)})
+	require.NoError(t, err)
+	return j
+}
+
+// appendEntries appends n entries with different merchant references.
+func appendEntries(t *testing.T, j *Journal, n int) {
+	ctx := context.Background()
+	for i := range n {
+		err := j.RecordExchange(maib.WithMerchantReference(ctx, string(rune('a'+i))), maib.Exchange{
+			Request: requests.CloseDay{},
+			Values:  map[string][]string{"command": {"b"}},
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.EqualError(t, err, "store is required")
+}
+
+func TestJournal_Client(t *testing.T) {
+	server := maibtest.NewServer(t, maibtest.Config{})
+	j := newJournal(t, NewMemoryStore())
+	config := server.Config()
+	config.Recorder = j
+	client, err := maib.NewClient(config)
+	require.NoError(t, err)
+	ctx := WithActor(maib.WithMerchantReference(context.Background(), "order-1"), "alice")
+
+	res, err := client.Send(ctx, requests.RegisterTransaction{
+		TransactionType: requests.RegisterTransactionSMS,
+		Amount:          1000,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "10.0.0.1",
+		Language:        maib.LanguageEnglish,
+	})
+	require.NoError(t, err)
+	registered, err := requests.DecodeResponse[requests.RegisterTransactionResult](res)
+	require.NoError(t, err)
+	_, err = client.Send(context.Background(), requests.ReverseTransaction{TransactionID: "AAAAAAAAAAAAAAAAAAAAAAAAAAA=", Amount: 100})
+	require.Error(t, err)
+
+	var entries []Entry
+	require.NoError(t, j.store.Each(context.Background(), func(entry Entry) error {
+		entries = append(entries, entry)
+		return nil
+	}))
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, int64(1), entries[0].Sequence)
+	assert.Equal(t, maib.StageSent, entries[0].Stage)
+	assert.Equal(t, "alice", entries[0].Actor)
+	assert.Equal(t, "order-1", entries[0].MerchantReference)
+	assert.Equal(t, "requests.RegisterTransaction", entries[0].RequestType)
+	assert.Equal(t, Redacted, entries[0].Values.Get("client_ip_addr"))
+	assert.Equal(t, "1000", entries[0].Values.Get("amount"))
+	assert.Nil(t, entries[0].Response)
+	assert.Empty(t, entries[0].PrevHash)
+
+	assert.Equal(t, int64(2), entries[1].Sequence)
+	assert.Equal(t, maib.StageCompleted, entries[1].Stage)
+	assert.Equal(t, entries[0].Time, entries[1].Time)
+	assert.Equal(t, "requests.RegisterTransaction", entries[1].RequestType)
+	assert.Equal(t, registered.TransactionID, entries[1].Response["TRANSACTION_ID"])
+	assert.Empty(t, entries[1].ErrorType)
+	assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+
+	assert.Equal(t, maib.StageSent, entries[2].Stage)
+	assert.Empty(t, entries[2].Actor)
+	assert.Equal(t, "requests.ReverseTransaction", entries[2].RequestType)
+	assert.Empty(t, entries[2].ErrorType)
+
+	assert.Equal(t, int64(4), entries[3].Sequence)
+	assert.Equal(t, maib.StageCompleted, entries[3].Stage)
+	assert.Equal(t, "*maib.ECommError", entries[3].ErrorType)
+	assert.Equal(t, err.Error(), entries[3].Error)
+	assert.Nil(t, entries[3].Response)
+	assert.Equal(t, entries[2].Hash, entries[3].PrevHash)
+
+	n, err := j.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	store := maib.NewMemoryTransactionStore()
+	require.NoError(t, j.Replay(context.Background(), store))
+	records, err := store.FindByMerchantReference(context.Background(), "order-1")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, registered.TransactionID, records[0].TransactionID)
+	assert.Equal(t, "v", records[0].Command)
+}
+
+func TestJournal_Verify_Tampered(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		tamper   func(store *MemoryStore)
+		sequence int64
+		reason   string
+	}{
+		{
+			name: "changed",
+			tamper: func(store *MemoryStore) {
+				entry, err := decodeEntry(store.entries[1])
+				require.NoError(t, err)
+				entry.MerchantReference = "z"
+				store.entries[1] = encode(t, entry)
+			},
+			sequence: 2,
+			reason:   "hash doesn't match the contents",
+		},
+		{
+			name: "rehashed",
+			tamper: func(store *MemoryStore) {
+				entry, err := decodeEntry(store.entries[1])
+				require.NoError(t, err)
+				entry.MerchantReference = "z"
+				entry.Hash, err = entry.computeHash()
+				require.NoError(t, err)
+				store.entries[1] = encode(t, entry)
+			},
+			sequence: 3,
+			reason:   "previous hash doesn't match",
+		},
+		{
+			name: "removed",
+			tamper: func(store *MemoryStore) {
+				store.entries = append(store.entries[:1], store.entries[2:]...)
+			},
+			sequence: 3,
+			reason:   "follows entry 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			j := newJournal(t, store)
+			appendEntries(t, j, 3)
+			tt.tamper(store)
+
+			_, err := j.Verify(ctx)
+			var tamperErr *TamperError
+			require.ErrorAs(t, err, &tamperErr)
+			assert.Equal(t, tt.sequence, tamperErr.Sequence)
+			assert.Equal(t, tt.reason, tamperErr.Reason)
+
+			err = j.Replay(ctx, maib.NewMemoryTransactionStore())
+			assert.ErrorAs(t, err, &tamperErr)
+		})
+	}
+}
+
+func TestJournal_VerifyAnchor(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	j := newJournal(t, store)
+
+	anchor, err := j.Anchor(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, Anchor{}, anchor)
+
+	appendEntries(t, j, 3)
+	anchor, err = j.Anchor(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), anchor.Sequence)
+	appendEntries(t, j, 1)
+
+	// Later entries don't affect the anchor.
+	n, err := j.VerifyAnchor(ctx, anchor)
+	assert.NoError(t, err)
+	assert.Equal(t, 4, n)
+
+	// Removing the tail leaves a valid chain, but not the anchored entry.
+	store.entries = store.entries[:2]
+	n, err = j.Verify(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, n)
+	_, err = j.VerifyAnchor(ctx, anchor)
+	var tamperErr *TamperError
+	require.ErrorAs(t, err, &tamperErr)
+	assert.Equal(t, int64(3), tamperErr.Sequence)
+	assert.Equal(t, "anchored entry is missing", tamperErr.Reason)
+
+	// Replacing the tail with a new chain doesn't match the anchor.
+	appendEntries(t, newJournal(t, store), 2)
+	_, err = j.VerifyAnchor(ctx, anchor)
+	require.ErrorAs(t, err, &tamperErr)
+	assert.Equal(t, int64(3), tamperErr.Sequence)
+	assert.Equal(t, "hash doesn't match the anchor", tamperErr.Reason)
+}
+
+func TestJournal_Append_Resume(t *testing.T) {
+	store := NewMemoryStore()
+	appendEntries(t, newJournal(t, store), 2)
+	appendEntries(t, newJournal(t, store), 1)
+
+	last, ok, err := store.Last(context.Background())
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), last.Sequence)
+	assert.Equal(t, now, last.Time)
+	n, err := newJournal(t, store).Verify(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
+
+func TestJournal_RecordExchange_Redact(t *testing.T) {
+	j, err := New(Config{Store: NewMemoryStore(), Redact: []string{"description", "RRN"}})
+	require.NoError(t, err)
+	err = j.RecordExchange(context.Background(), maib.Exchange{
+		Values:   map[string][]string{"description": {"secret"}, "client_ip_addr": {"10.0.0.1"}},
+		Response: map[string]any{"RRN": "123", "RESULT": "OK"},
+	})
+	require.NoError(t, err)
+
+	entry, _, err := j.store.Last(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, Redacted, entry.Values.Get("description"))
+	assert.Equal(t, "10.0.0.1", entry.Values.Get("client_ip_addr"))
+	assert.Equal(t, map[string]any{"RRN": Redacted, "RESULT": "OK"}, entry.Response)
+	assert.Empty(t, entry.RequestType)
+}
+
+func encode(t *testing.T, entry Entry) []byte {
+	store := NewMemoryStore()
+	require.NoError(t, store.Append(context.Background(), entry))
+	return store.entries[0]
+}
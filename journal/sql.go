@@ -0,0 +1,125 @@
+package journal
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// DefaultTable is the default table of the [SQLStore].
+const DefaultTable = "maib_journal"
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// SQLConfig is the configuration required to set up a [SQLStore].
+type SQLConfig struct {
+	// Database with the journal table. The driver is chosen by the caller.
+	// Required.
+	DB *sql.DB
+
+	// Name of the journal table, optionally with the schema. Default is
+	// [DefaultTable].
+	Table string
+
+	// Use numbered placeholders, like $1, as in PostgreSQL, instead of ?.
+	NumberedPlaceholders bool
+}
+
+// SQLStore is a [Store] that keeps entries in a database/sql table with the
+// columns:
+//
+//	seq   BIGINT PRIMARY KEY -- Entry.Sequence
+//	hash  TEXT NOT NULL      -- Entry.Hash
+//	entry TEXT NOT NULL      -- JSON encoding of the Entry
+//
+// The primary key makes a second Journal appending to the same table fail
+// instead of forking the chain. Create the table with [SQLStore.CreateTable],
+// or with a migration. It is safe for concurrent use.
+//
+// Must be initiated with [NewSQLStore].
+type SQLStore struct {
+	db     *sql.DB
+	table  string
+	insert string
+}
+
+// NewSQLStore validates the configuration and returns a *[SQLStore].
+func NewSQLStore(config SQLConfig) (*SQLStore, error) {
+	if config.DB == nil {
+		return nil, errors.New("db is required")
+	}
+	table := config.Table
+	if table == "" {
+		table = DefaultTable
+	}
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	s := &SQLStore{
+		db:     config.DB,
+		table:  table,
+		insert: fmt.Sprintf("INSERT INTO %s (seq, hash, entry) VALUES (?, ?, ?)", table),
+	}
+	if config.NumberedPlaceholders {
+		s.insert = fmt.Sprintf("INSERT INTO %s (seq, hash, entry) VALUES ($1, $2, $3)", table)
+	}
+	return s, nil
+}
+
+// CreateTable creates the journal table if it doesn't exist.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (seq BIGINT PRIMARY KEY, hash TEXT NOT NULL, entry TEXT NOT NULL)", s.table))
+	return err
+}
+
+func (s *SQLStore) Append(ctx context.Context, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, s.insert, entry.Sequence, entry.Hash, string(data))
+	return err
+}
+
+func (s *SQLStore) Last(ctx context.Context) (Entry, bool, error) {
+	var data string
+	err := s.db.QueryRowContext(ctx, fmt.Sprintf("SELECT entry FROM %s ORDER BY seq DESC LIMIT 1", s.table)).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, err
+	}
+	entry, err := decodeEntry([]byte(data))
+	return entry, err == nil, err
+}
+
+func (s *SQLStore) Each(ctx context.Context, f func(Entry) error) error {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf("SELECT entry FROM %s ORDER BY seq", s.table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data string
+		err = rows.Scan(&data)
+		if err != nil {
+			return err
+		}
+		entry, err := decodeEntry([]byte(data))
+		if err != nil {
+			return err
+		}
+		err = f(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,108 @@
+package journal
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2/internal/sqlfake"
+)
+
+// journalTable emulates the journal table, ordered by seq.
+type journalTable struct {
+	mu      sync.Mutex
+	queries []string
+	rows    [][]driver.Value
+}
+
+func (tb *journalTable) Exec(query string, args []driver.Value) (int64, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.queries = append(tb.queries, query)
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE"):
+		return 0, nil
+	case strings.HasPrefix(query, "INSERT"):
+		for _, row := range tb.rows {
+			if row[0] == args[0] {
+				return 0, errors.New("duplicate key")
+			}
+		}
+		tb.rows = append(tb.rows, args)
+		return 1, nil
+	}
+	return 0, fmt.Errorf("unexpected exec %q", query)
+}
+
+func (tb *journalTable) Query(query string, _ []driver.Value) ([]string, [][]driver.Value, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.queries = append(tb.queries, query)
+	var entries [][]driver.Value
+	for _, row := range tb.rows {
+		entries = append(entries, []driver.Value{row[2]})
+	}
+	if strings.Contains(query, "DESC LIMIT 1") {
+		slices.Reverse(entries)
+		entries = entries[:min(len(entries), 1)]
+	}
+	return []string{"entry"}, entries, nil
+}
+
+func TestNewSQLStore(t *testing.T) {
+	_, err := NewSQLStore(SQLConfig{})
+	assert.EqualError(t, err, "db is required")
+
+	db := sqlfake.Open(&journalTable{})
+	_, err = NewSQLStore(SQLConfig{DB: db, Table: "journal; DROP TABLE orders"})
+	assert.EqualError(t, err, `invalid table name "journal; DROP TABLE orders"`)
+	_, err = NewSQLStore(SQLConfig{DB: db, Table: "audit.maib_journal"})
+	assert.NoError(t, err)
+}
+
+func TestSQLStore(t *testing.T) {
+	ctx := context.Background()
+	table := &journalTable{}
+	store, err := NewSQLStore(SQLConfig{DB: sqlfake.Open(table), NumberedPlaceholders: true})
+	require.NoError(t, err)
+	require.NoError(t, store.CreateTable(ctx))
+
+	_, ok, err := store.Last(ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	appendEntries(t, newJournal(t, store), 2)
+	appendEntries(t, newJournal(t, store), 1)
+
+	n, err := newJournal(t, store).Verify(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Contains(t, table.queries, "INSERT INTO maib_journal (seq, hash, entry) VALUES ($1, $2, $3)")
+	assert.Equal(t, int64(3), table.rows[2][0])
+}
+
+func TestSQLStore_ForkedChain(t *testing.T) {
+	store, err := NewSQLStore(SQLConfig{DB: sqlfake.Open(&journalTable{})})
+	require.NoError(t, err)
+	first := newJournal(t, store)
+	second := newJournal(t, store)
+	appendEntries(t, first, 1)
+	appendEntries(t, second, 1)
+
+	// The first journal still chains to its own last entry.
+	err = first.Append(context.Background(), Entry{})
+	assert.ErrorContains(t, err, "duplicate key")
+
+	// After the failure, it chains to the last entry of the store.
+	appendEntries(t, first, 1)
+	n, err := first.Verify(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
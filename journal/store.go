@@ -0,0 +1,82 @@
+package journal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+)
+
+// Store persists the entries of the journal. Entries are never changed or
+// removed by the [Journal].
+type Store interface {
+	// Append adds the entry after the last one.
+	Append(ctx context.Context, entry Entry) error
+
+	// Last returns the last entry, or false if the store is empty.
+	Last(ctx context.Context) (Entry, bool, error)
+
+	// Each calls f for every entry in the order of Sequence, until f returns
+	// an error, which is then returned.
+	Each(ctx context.Context, f func(Entry) error) error
+}
+
+// MemoryStore is a [Store] that keeps entries in memory. It is safe for
+// concurrent use.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries [][]byte
+}
+
+// NewMemoryStore returns an empty *[MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{}
+}
+
+func (s *MemoryStore) Append(_ context.Context, entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, data)
+	return nil
+}
+
+func (s *MemoryStore) Last(_ context.Context) (Entry, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.entries) == 0 {
+		return Entry{}, false, nil
+	}
+	entry, err := decodeEntry(s.entries[len(s.entries)-1])
+	return entry, err == nil, err
+}
+
+func (s *MemoryStore) Each(_ context.Context, f func(Entry) error) error {
+	s.mu.Lock()
+	entries := s.entries[:len(s.entries):len(s.entries)]
+	s.mu.Unlock()
+	for _, data := range entries {
+		entry, err := decodeEntry(data)
+		if err != nil {
+			return err
+		}
+		err = f(entry)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeEntry parses the JSON encoding of the entry. Numbers in the response
+// are kept as [json.Number], so the entry encodes and hashes as before.
+func decodeEntry(data []byte) (Entry, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var entry Entry
+	err := dec.Decode(&entry)
+	return entry, err
+}
@@ -0,0 +1,261 @@
+/*
+Package maibfake provides a fake [maib.Sender] for unit tests of code that
+depends on the ECommerce system.
+
+[Client] validates and records every request, and answers with responses
+programmed per request type:
+
+	fake := maibfake.New()
+	fake.Respond(requests.ExecuteDMS{}, maibfake.Result(requests.ExecuteDMSResult{
+		Result: maib.ResultOk,
+	}))
+
+	checkout := NewCheckout(fake) // accepts maib.Sender
+	// ...
+
+	fake.AssertSentOnce(t, requests.ExecuteDMS{Amount: 1999})
+*/
+package maibfake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sync"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// TestingT is the subset of [testing.TB] used by the assertion helpers.
+type TestingT interface {
+	Errorf(format string, args ...any)
+}
+
+// Call is a request sent through the [Client].
+type Call struct {
+	Request maib.Request
+
+	// Payload of the request.
+	Values url.Values
+}
+
+// UnexpectedRequestError is returned for requests of a type that has no
+// programmed response.
+type UnexpectedRequestError struct {
+	Request maib.Request
+}
+
+func (e *UnexpectedRequestError) Error() string {
+	return fmt.Sprintf("maibfake: no response programmed for %T", e.Request)
+}
+
+// Client is a fake [maib.Sender]. It is safe for concurrent use.
+//
+// Must be initiated with [New].
+type Client struct {
+	mu        sync.Mutex
+	calls     []Call
+	responses map[reflect.Type][]Response
+	funcs     map[reflect.Type]ResponseFunc
+}
+
+var _ maib.Sender = (*Client)(nil)
+
+// New returns a *[Client] with no programmed responses.
+func New() *Client {
+	return &Client{
+		responses: make(map[reflect.Type][]Response),
+		funcs:     make(map[reflect.Type]ResponseFunc),
+	}
+}
+
+// Respond programs the responses to requests of the same type as req. The
+// responses are used in order, and the last one is repeated. Any previous
+// programming of the type is replaced.
+func (c *Client) Respond(req maib.Request, responses ...Response) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := reflect.TypeOf(req)
+	c.responses[t] = responses
+	delete(c.funcs, t)
+}
+
+// RespondFunc programs the responses to requests of the same type as req to be
+// computed by f. Any previous programming of the type is replaced.
+func (c *Client) RespondFunc(req maib.Request, f ResponseFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := reflect.TypeOf(req)
+	c.funcs[t] = f
+	delete(c.responses, t)
+}
+
+// Send validates the request like maib.Client.Send, records it, and returns
+// the programmed response. Invalid requests, and requests with a nil or done
+// context, are not recorded. Requests of a type without a programmed response
+// fail with *[UnexpectedRequestError].
+func (c *Client) Send(ctx context.Context, req maib.Request) (map[string]any, error) {
+	values, err := req.Values()
+	if err != nil {
+		return nil, fmt.Errorf("get request values: %w", err)
+	}
+	if ctx == nil {
+		// maib.Client.Send fails to create the HTTP request.
+		return nil, errors.New("create request: nil context")
+	}
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("send request to MAIB EComm: %w", ctx.Err())
+	}
+
+	res, ok := c.record(req, values)
+	if !ok {
+		return nil, &UnexpectedRequestError{Request: req}
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+	fields := make(map[string]any, len(res.fields))
+	for key, value := range res.fields {
+		fields[key] = value
+	}
+	return fields, nil
+}
+
+// record stores the call and picks the response.
+func (c *Client) record(req maib.Request, values url.Values) (Response, bool) {
+	c.mu.Lock()
+	c.calls = append(c.calls, Call{Request: req, Values: values})
+	t := reflect.TypeOf(req)
+	f, ok := c.funcs[t]
+	c.mu.Unlock()
+	if ok {
+		// Called without the lock, so that f may use the client.
+		return f(req), true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	responses := c.responses[t]
+	if len(responses) == 0 {
+		return Response{}, false
+	}
+	res := responses[0]
+	if len(responses) > 1 {
+		c.responses[t] = responses[1:]
+	}
+	return res, true
+}
+
+// Calls returns every recorded request, in order.
+func (c *Client) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// Sent returns the recorded requests of the same type as match, whose fields
+// equal the non-zero fields of match. For example, requests.ExecuteDMS{Amount:
+// 1999} matches every ExecuteDMS with that amount.
+func (c *Client) Sent(match maib.Request) []maib.Request {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var sent []maib.Request
+	for _, call := range c.calls {
+		if matches(match, call.Request) {
+			sent = append(sent, call.Request)
+		}
+	}
+	return sent
+}
+
+// Reset forgets the recorded requests. Programmed responses are kept.
+func (c *Client) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = nil
+}
+
+// AssertSent checks that exactly n requests were recorded that match, as
+// described in [Client.Sent].
+func (c *Client) AssertSent(t TestingT, match maib.Request, n int) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	sent := len(c.Sent(match))
+	if sent != n {
+		t.Errorf("maibfake: %s sent %d times, expected %d", describe(match), sent, n)
+		return false
+	}
+	return true
+}
+
+// AssertSentOnce checks that exactly one request was recorded that matches.
+func (c *Client) AssertSentOnce(t TestingT, match maib.Request) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	return c.AssertSent(t, match, 1)
+}
+
+// AssertNotSent checks that no request was recorded that matches.
+func (c *Client) AssertNotSent(t TestingT, match maib.Request) bool {
+	if h, ok := t.(interface{ Helper() }); ok {
+		h.Helper()
+	}
+	return c.AssertSent(t, match, 0)
+}
+
+// matches reports whether actual has the type of match, and the non-zero
+// fields of match.
+func matches(match, actual maib.Request) bool {
+	mv, av := reflect.ValueOf(match), reflect.ValueOf(actual)
+	if mv.Type() != av.Type() {
+		return false
+	}
+	if mv.Kind() == reflect.Pointer {
+		if mv.IsNil() || av.IsNil() {
+			return mv.IsNil()
+		}
+		mv, av = mv.Elem(), av.Elem()
+	}
+	if mv.Kind() != reflect.Struct {
+		return reflect.DeepEqual(match, actual)
+	}
+	for i := 0; i < mv.NumField(); i++ {
+		field := mv.Field(i)
+		if !field.CanInterface() || field.IsZero() {
+			continue
+		}
+		if !reflect.DeepEqual(field.Interface(), av.Field(i).Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+// describe returns the type and the non-zero fields of the request.
+func describe(req maib.Request) string {
+	v := reflect.ValueOf(req)
+	if v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fmt.Sprintf("%T", req)
+	}
+	s := fmt.Sprintf("%T{", req)
+	first := true
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Field(i)
+		if !field.CanInterface() || field.IsZero() {
+			continue
+		}
+		if !first {
+			s += ", "
+		}
+		first = false
+		s += fmt.Sprintf("%s: %v", v.Type().Field(i).Name, field.Interface())
+	}
+	return s + "}"
+}
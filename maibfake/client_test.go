@@ -0,0 +1,139 @@
+package maibfake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+var ctx = context.Background()
+
+func executeDMS(amount int) requests.ExecuteDMS {
+	return requests.ExecuteDMS{
+		TransactionID:   "abcdefghijklmnopqrstuvwxyz1=",
+		Amount:          amount,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+	}
+}
+
+func TestClient_Respond(t *testing.T) {
+	fake := New()
+	fake.Respond(requests.ExecuteDMS{},
+		Result(requests.ExecuteDMSResult{Result: maib.ResultOk, RRN: 123456789012}),
+		Error(&maib.ECommError{Code: 500}),
+	)
+
+	res, err := fake.Send(ctx, executeDMS(1999))
+	assert.Nil(t, err)
+	result, err := requests.DecodeResponse[requests.ExecuteDMSResult](res)
+	assert.Nil(t, err)
+	assert.Equal(t, maib.ResultOk, result.Result)
+	assert.Equal(t, 123456789012, result.RRN)
+
+	// The last response is repeated.
+	for i := 0; i < 2; i++ {
+		_, err = fake.Send(ctx, executeDMS(1999))
+		assert.ErrorAs(t, err, new(*maib.ECommError))
+	}
+
+	// No response for this type.
+	_, err = fake.Send(ctx, requests.CloseDay{})
+	unexpected := &UnexpectedRequestError{}
+	assert.ErrorAs(t, err, &unexpected)
+	assert.Equal(t, requests.CloseDay{}, unexpected.Request)
+
+	assert.Len(t, fake.Calls(), 4)
+	assert.Equal(t, "1999", fake.Calls()[0].Values.Get("amount"))
+}
+
+func TestClient_RespondFunc(t *testing.T) {
+	fake := New()
+	fake.Respond(requests.TransactionStatus{}, Fields(map[string]any{"RESULT": "PENDING"}))
+	fake.RespondFunc(requests.TransactionStatus{}, func(req maib.Request) Response {
+		// The client can be used from the function.
+		calls := len(fake.Calls())
+		return Fields(map[string]any{
+			"RESULT":      "OK",
+			"RESULT_CODE": calls,
+		})
+	})
+
+	status := requests.TransactionStatus{
+		TransactionID:   "abcdefghijklmnopqrstuvwxyz1=",
+		ClientIPAddress: "127.0.0.1",
+	}
+	res, err := fake.Send(ctx, status)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]any{"RESULT": "OK", "RESULT_CODE": 1}, res)
+
+	// Modifying the result doesn't affect the next response.
+	res["RESULT"] = "FAILED"
+	res, err = fake.Send(ctx, status)
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", res["RESULT"])
+}
+
+func TestClient_Send_Invalid(t *testing.T) {
+	fake := New()
+	fake.Respond(requests.ExecuteDMS{}, Result(requests.ExecuteDMSResult{Result: maib.ResultOk}))
+
+	_, err := fake.Send(ctx, executeDMS(0))
+	assert.ErrorAs(t, err, new(*maib.ValidationError))
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = fake.Send(cancelled, executeDMS(100))
+	assert.ErrorIs(t, err, context.Canceled)
+
+	_, err = fake.Send(nil, executeDMS(100))
+	assert.EqualError(t, err, "create request: nil context")
+
+	assert.Empty(t, fake.Calls())
+}
+
+// recordingT records assertion failures.
+type recordingT struct {
+	errors []string
+}
+
+func (t *recordingT) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestClient_Assertions(t *testing.T) {
+	fake := New()
+	fake.Respond(requests.ExecuteDMS{}, Result(requests.ExecuteDMSResult{Result: maib.ResultOk}))
+	_, _ = fake.Send(ctx, executeDMS(1999))
+	_, _ = fake.Send(ctx, executeDMS(500))
+	_, _ = fake.Send(ctx, executeDMS(500))
+
+	assert.True(t, fake.AssertSentOnce(t, requests.ExecuteDMS{Amount: 1999}))
+	assert.True(t, fake.AssertSent(t, requests.ExecuteDMS{Amount: 500}, 2))
+	assert.True(t, fake.AssertSent(t, requests.ExecuteDMS{Currency: maib.CurrencyMDL}, 3))
+	assert.True(t, fake.AssertNotSent(t, requests.ReverseTransaction{}))
+	assert.Len(t, fake.Sent(requests.ExecuteDMS{}), 3)
+
+	rt := &recordingT{}
+	assert.False(t, fake.AssertSentOnce(rt, requests.ExecuteDMS{Amount: 500}))
+	assert.False(t, fake.AssertNotSent(rt, requests.ExecuteDMS{}))
+	assert.Equal(t, []string{
+		"maibfake: requests.ExecuteDMS{Amount: 500} sent 2 times, expected 1",
+		"maibfake: requests.ExecuteDMS{} sent 3 times, expected 0",
+	}, rt.errors)
+
+	fake.Reset()
+	assert.True(t, fake.AssertNotSent(t, requests.ExecuteDMS{}))
+}
+
+func TestClient_ImplementsSender(t *testing.T) {
+	var sender maib.Sender = New()
+	_, err := sender.Send(ctx, requests.CloseDay{})
+	assert.True(t, errors.As(err, new(*UnexpectedRequestError)))
+}
@@ -0,0 +1,65 @@
+package maibfake
+
+import (
+	"reflect"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// Response is a programmed response of the [Client]. Create it with [Result],
+// [Fields] or [Error].
+type Response struct {
+	fields map[string]any
+	err    error
+}
+
+// Result responds with the fields of a result struct from the requests
+// package, like requests.ExecuteDMSResult. Zero fields are left out, as if
+// the ECommerce system didn't return them.
+func Result(result any) Response {
+	return Response{fields: resultFields(result)}
+}
+
+// Fields responds with the raw fields, as parsed by maib.Client.Send from the
+// "KEY: value" lines.
+func Fields(fields map[string]any) Response {
+	return Response{fields: fields}
+}
+
+// Error fails the request with err, e.g. *maib.ECommError.
+func Error(err error) Response {
+	return Response{err: err}
+}
+
+// ResponseFunc computes the response to a request.
+type ResponseFunc func(req maib.Request) Response
+
+// resultFields converts a result struct into the fields parsed by
+// maib.Client.Send, using the mapstructure tags.
+func resultFields(result any) map[string]any {
+	fields := make(map[string]any)
+	v := reflect.ValueOf(result)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return fields
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		key := v.Type().Field(i).Tag.Get("mapstructure")
+		value := v.Field(i)
+		if key == "" || value.IsZero() {
+			continue
+		}
+		switch value.Kind() {
+		case reflect.String:
+			fields[key] = value.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			fields[key] = int(value.Int())
+		default:
+			fields[key] = value.Interface()
+		}
+	}
+	return fields
+}
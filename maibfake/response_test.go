@@ -0,0 +1,35 @@
+package maibfake
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+func TestResult(t *testing.T) {
+	status := requests.TransactionStatusResult{
+		Result:                 maib.ResultOk,
+		ResultPS:               maib.ResultPSFinished,
+		ResultCode:             0,
+		RRN:                    123456789012,
+		RecurringPaymentExpiry: "1230",
+	}
+	res := Result(status)
+	assert.Equal(t, map[string]any{
+		"RESULT":           "OK",
+		"RESULT_PS":        "FINISHED",
+		"RRN":              123456789012,
+		"RECC_PMNT_EXPIRY": "1230",
+	}, res.fields)
+
+	decoded, err := requests.DecodeResponse[requests.TransactionStatusResult](res.fields)
+	assert.Nil(t, err)
+	assert.Equal(t, status, decoded)
+
+	// Pointers are dereferenced.
+	assert.Equal(t, map[string]any{"RESULT": "OK"}, Result(&requests.DeleteRecurringResult{Result: maib.ResultOk}).fields)
+	assert.Empty(t, Result("not a struct").fields)
+}
@@ -0,0 +1,133 @@
+package maibgrpc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+var results = map[maib.ResultEnum]Result{
+	maib.ResultOk:           Result_RESULT_OK,
+	maib.ResultFailed:       Result_RESULT_FAILED,
+	maib.ResultCreated:      Result_RESULT_CREATED,
+	maib.ResultPending:      Result_RESULT_PENDING,
+	maib.ResultDeclined:     Result_RESULT_DECLINED,
+	maib.ResultReversed:     Result_RESULT_REVERSED,
+	maib.ResultAutoReversed: Result_RESULT_AUTOREVERSED,
+	maib.ResultTimeout:      Result_RESULT_TIMEOUT,
+}
+
+var resultsPS = map[maib.ResultPSEnum]ResultPS{
+	maib.ResultPSActive:    ResultPS_RESULT_PS_ACTIVE,
+	maib.ResultPSFinished:  ResultPS_RESULT_PS_FINISHED,
+	maib.ResultPSCancelled: ResultPS_RESULT_PS_CANCELLED,
+	maib.ResultPSReturned:  ResultPS_RESULT_PS_RETURNED,
+}
+
+// resultOf converts the result, or returns RESULT_UNSPECIFIED if it is missing
+// or unknown.
+func resultOf(r maib.ResultEnum) Result {
+	return results[r]
+}
+
+// resultPSOf converts the result, or returns RESULT_PS_UNSPECIFIED if it is
+// missing or unknown.
+func resultPSOf(r maib.ResultPSEnum) ResultPS {
+	return resultsPS[r]
+}
+
+// statusError converts an error of maib.Client.Send into a gRPC status with
+// error details, as documented in maib.proto. Errors that leave the outcome of
+// a command unknown are UNAVAILABLE only for read-only commands, because gRPC
+// retry policies repeat UNAVAILABLE calls automatically.
+func statusError(err error, req maib.Request) error {
+	var validationErr *maib.ValidationError
+	var ecommErr *maib.ECommError
+	var parseErr *maib.ParseError
+	ambiguous := codes.Unknown
+	if _, ok := req.(requests.TransactionStatus); ok {
+		ambiguous = codes.Unavailable
+	}
+	switch {
+	case errors.As(err, &validationErr):
+		return withDetails(codes.InvalidArgument, err, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{{
+				Field:       protoFieldOf(req, validationErr.Field),
+				Description: validationErr.Description,
+			}},
+		})
+	case errors.As(err, &ecommErr):
+		code := ambiguous
+		if maib.IsRejected(ecommErr) {
+			// The ECommerce system refused the request without executing it.
+			code = codes.FailedPrecondition
+		}
+		return withDetails(code, err, &ECommError{
+			Code: int32(ecommErr.Code),
+			Body: ecommErr.Body,
+		})
+	case errors.As(err, new(*maib.RecordError)):
+		// Nothing was sent, so the call can be retried.
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.As(err, &parseErr):
+		return withDetails(codes.Internal, err, &ParseError{Body: parseErr.Body})
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	return status.Error(ambiguous, err.Error())
+}
+
+// withDetails creates a status error with the detail.
+func withDetails(code codes.Code, err error, detail protoadapt.MessageV1) error {
+	st, detailErr := status.New(code, err.Error()).WithDetails(detail)
+	if detailErr != nil {
+		return status.Error(code, err.Error())
+	}
+	return st.Err()
+}
+
+// protoFieldOf returns the name of the message field that is sent as the
+// payload field: the request struct field with the same url tag, in snake
+// case. It returns the payload field itself if there is none.
+func protoFieldOf(req maib.Request, field maib.PayloadField) string {
+	t := reflect.TypeOf(req)
+	for i := 0; i < t.NumField(); i++ {
+		tag, _, _ := strings.Cut(t.Field(i).Tag.Get("url"), ",")
+		if tag == string(field) {
+			return snakeCase(t.Field(i).Name)
+		}
+	}
+	return string(field)
+}
+
+// snakeCase converts a Go field name to the snake case name of the message
+// field, keeping acronyms together: ClientIPAddress is client_ip_address. It
+// matches protoc, and is not shared with the gateway, because internal packages
+// of the SDK can't be imported across modules.
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
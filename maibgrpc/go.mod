@@ -0,0 +1,31 @@
+module github.com/NikSays/go-maib-ecomm/v2/maibgrpc
+
+go 1.25.0
+
+require (
+	github.com/NikSays/go-maib-ecomm/v2 v2.0.0-20261019010056-d3506c757fce
+	github.com/stretchr/testify v1.10.0
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20260414002931-afd174a4e478
+	google.golang.org/grpc v1.82.1
+	google.golang.org/protobuf v1.36.11
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/google/go-querystring v1.1.0 // indirect
+	github.com/kr/text v0.2.0 // indirect
+	github.com/mitchellh/mapstructure v1.5.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.50.0 // indirect
+	golang.org/x/net v0.53.0 // indirect
+	golang.org/x/sys v0.43.0 // indirect
+	golang.org/x/text v0.36.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+	software.sslmate.com/src/go-pkcs12 v0.5.0 // indirect
+)
+
+// The required SDK is the commit with the API used here, like maib.Sender and
+// maib.IsRejected. Raise it to the release tag that includes that API. The
+// replace only builds against the SDK in the same repository during
+// development, and is ignored by the users of the module.
+replace github.com/NikSays/go-maib-ecomm/v2 => ../
@@ -0,0 +1,2139 @@
+// Protobuf API of the MAIB ECommerce commands. The messages mirror the structs
+// of the Go package github.com/NikSays/go-maib-ecomm/v2/requests: e.g.
+// ExecuteDMSRequest mirrors requests.ExecuteDMS, and ExecuteDMSResult mirrors
+// requests.ExecuteDMSResult. Each RPC sends the request through
+// maib.Client.Send.
+//
+// Failed RPCs carry a google.rpc.Status with details:
+//   - INVALID_ARGUMENT with google.rpc.BadRequest, if the request failed
+//     validation. The field violation names the field of the message.
+//   - FAILED_PRECONDITION with ECommError, if the ECommerce system rejected
+//     the request with an "error:" body or a 4xx status.
+//   - UNAVAILABLE for TransactionStatus, with ECommError if the ECommerce
+//     system returned another error, or without details if it could not be
+//     reached. TransactionStatus is read-only, so it is safe to retry.
+//   - UNKNOWN for the other RPCs in the same cases. The command may have been
+//     executed, so check it with TransactionStatus before retrying.
+//   - UNAVAILABLE for any RPC, if the request could not be recorded before
+//     sending, see maib.RecordError. Nothing was sent, so it is safe to retry.
+//   - INTERNAL with ParseError, if the response could not be parsed.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: maib.proto
+
+package maibgrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Mirrors maib.ResultEnum.
+type Result int32
+
+const (
+	// The ECommerce system didn't return a RESULT.
+	Result_RESULT_UNSPECIFIED  Result = 0
+	Result_RESULT_OK           Result = 1
+	Result_RESULT_FAILED       Result = 2
+	Result_RESULT_CREATED      Result = 3
+	Result_RESULT_PENDING      Result = 4
+	Result_RESULT_DECLINED     Result = 5
+	Result_RESULT_REVERSED     Result = 6
+	Result_RESULT_AUTOREVERSED Result = 7
+	Result_RESULT_TIMEOUT      Result = 8
+)
+
+// Enum value maps for Result.
+var (
+	Result_name = map[int32]string{
+		0: "RESULT_UNSPECIFIED",
+		1: "RESULT_OK",
+		2: "RESULT_FAILED",
+		3: "RESULT_CREATED",
+		4: "RESULT_PENDING",
+		5: "RESULT_DECLINED",
+		6: "RESULT_REVERSED",
+		7: "RESULT_AUTOREVERSED",
+		8: "RESULT_TIMEOUT",
+	}
+	Result_value = map[string]int32{
+		"RESULT_UNSPECIFIED":  0,
+		"RESULT_OK":           1,
+		"RESULT_FAILED":       2,
+		"RESULT_CREATED":      3,
+		"RESULT_PENDING":      4,
+		"RESULT_DECLINED":     5,
+		"RESULT_REVERSED":     6,
+		"RESULT_AUTOREVERSED": 7,
+		"RESULT_TIMEOUT":      8,
+	}
+)
+
+func (x Result) Enum() *Result {
+	p := new(Result)
+	*p = x
+	return p
+}
+
+func (x Result) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Result) Descriptor() protoreflect.EnumDescriptor {
+	return file_maib_proto_enumTypes[0].Descriptor()
+}
+
+func (Result) Type() protoreflect.EnumType {
+	return &file_maib_proto_enumTypes[0]
+}
+
+func (x Result) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Result.Descriptor instead.
+func (Result) EnumDescriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{0}
+}
+
+// Mirrors maib.ResultPSEnum.
+type ResultPS int32
+
+const (
+	// The ECommerce system didn't return a RESULT_PS.
+	ResultPS_RESULT_PS_UNSPECIFIED ResultPS = 0
+	ResultPS_RESULT_PS_ACTIVE      ResultPS = 1
+	ResultPS_RESULT_PS_FINISHED    ResultPS = 2
+	ResultPS_RESULT_PS_CANCELLED   ResultPS = 3
+	ResultPS_RESULT_PS_RETURNED    ResultPS = 4
+)
+
+// Enum value maps for ResultPS.
+var (
+	ResultPS_name = map[int32]string{
+		0: "RESULT_PS_UNSPECIFIED",
+		1: "RESULT_PS_ACTIVE",
+		2: "RESULT_PS_FINISHED",
+		3: "RESULT_PS_CANCELLED",
+		4: "RESULT_PS_RETURNED",
+	}
+	ResultPS_value = map[string]int32{
+		"RESULT_PS_UNSPECIFIED": 0,
+		"RESULT_PS_ACTIVE":      1,
+		"RESULT_PS_FINISHED":    2,
+		"RESULT_PS_CANCELLED":   3,
+		"RESULT_PS_RETURNED":    4,
+	}
+)
+
+func (x ResultPS) Enum() *ResultPS {
+	p := new(ResultPS)
+	*p = x
+	return p
+}
+
+func (x ResultPS) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ResultPS) Descriptor() protoreflect.EnumDescriptor {
+	return file_maib_proto_enumTypes[1].Descriptor()
+}
+
+func (ResultPS) Type() protoreflect.EnumType {
+	return &file_maib_proto_enumTypes[1]
+}
+
+func (x ResultPS) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ResultPS.Descriptor instead.
+func (ResultPS) EnumDescriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{1}
+}
+
+// Mirrors requests.RegisterTransactionType.
+type RegisterTransactionType int32
+
+const (
+	RegisterTransactionType_REGISTER_TRANSACTION_TYPE_SMS RegisterTransactionType = 0
+	RegisterTransactionType_REGISTER_TRANSACTION_TYPE_DMS RegisterTransactionType = 1
+)
+
+// Enum value maps for RegisterTransactionType.
+var (
+	RegisterTransactionType_name = map[int32]string{
+		0: "REGISTER_TRANSACTION_TYPE_SMS",
+		1: "REGISTER_TRANSACTION_TYPE_DMS",
+	}
+	RegisterTransactionType_value = map[string]int32{
+		"REGISTER_TRANSACTION_TYPE_SMS": 0,
+		"REGISTER_TRANSACTION_TYPE_DMS": 1,
+	}
+)
+
+func (x RegisterTransactionType) Enum() *RegisterTransactionType {
+	p := new(RegisterTransactionType)
+	*p = x
+	return p
+}
+
+func (x RegisterTransactionType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RegisterTransactionType) Descriptor() protoreflect.EnumDescriptor {
+	return file_maib_proto_enumTypes[2].Descriptor()
+}
+
+func (RegisterTransactionType) Type() protoreflect.EnumType {
+	return &file_maib_proto_enumTypes[2]
+}
+
+func (x RegisterTransactionType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RegisterTransactionType.Descriptor instead.
+func (RegisterTransactionType) EnumDescriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{2}
+}
+
+// Mirrors requests.RegisterRecurringType.
+type RegisterRecurringType int32
+
+const (
+	RegisterRecurringType_REGISTER_RECURRING_TYPE_SMS             RegisterRecurringType = 0
+	RegisterRecurringType_REGISTER_RECURRING_TYPE_DMS             RegisterRecurringType = 1
+	RegisterRecurringType_REGISTER_RECURRING_TYPE_WITHOUT_PAYMENT RegisterRecurringType = 2
+)
+
+// Enum value maps for RegisterRecurringType.
+var (
+	RegisterRecurringType_name = map[int32]string{
+		0: "REGISTER_RECURRING_TYPE_SMS",
+		1: "REGISTER_RECURRING_TYPE_DMS",
+		2: "REGISTER_RECURRING_TYPE_WITHOUT_PAYMENT",
+	}
+	RegisterRecurringType_value = map[string]int32{
+		"REGISTER_RECURRING_TYPE_SMS":             0,
+		"REGISTER_RECURRING_TYPE_DMS":             1,
+		"REGISTER_RECURRING_TYPE_WITHOUT_PAYMENT": 2,
+	}
+)
+
+func (x RegisterRecurringType) Enum() *RegisterRecurringType {
+	p := new(RegisterRecurringType)
+	*p = x
+	return p
+}
+
+func (x RegisterRecurringType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RegisterRecurringType) Descriptor() protoreflect.EnumDescriptor {
+	return file_maib_proto_enumTypes[3].Descriptor()
+}
+
+func (RegisterRecurringType) Type() protoreflect.EnumType {
+	return &file_maib_proto_enumTypes[3]
+}
+
+func (x RegisterRecurringType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RegisterRecurringType.Descriptor instead.
+func (RegisterRecurringType) EnumDescriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{3}
+}
+
+// Mirrors requests.RegisterOneClickType.
+type RegisterOneClickType int32
+
+const (
+	RegisterOneClickType_REGISTER_ONE_CLICK_TYPE_SMS             RegisterOneClickType = 0
+	RegisterOneClickType_REGISTER_ONE_CLICK_TYPE_WITHOUT_PAYMENT RegisterOneClickType = 1
+)
+
+// Enum value maps for RegisterOneClickType.
+var (
+	RegisterOneClickType_name = map[int32]string{
+		0: "REGISTER_ONE_CLICK_TYPE_SMS",
+		1: "REGISTER_ONE_CLICK_TYPE_WITHOUT_PAYMENT",
+	}
+	RegisterOneClickType_value = map[string]int32{
+		"REGISTER_ONE_CLICK_TYPE_SMS":             0,
+		"REGISTER_ONE_CLICK_TYPE_WITHOUT_PAYMENT": 1,
+	}
+)
+
+func (x RegisterOneClickType) Enum() *RegisterOneClickType {
+	p := new(RegisterOneClickType)
+	*p = x
+	return p
+}
+
+func (x RegisterOneClickType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RegisterOneClickType) Descriptor() protoreflect.EnumDescriptor {
+	return file_maib_proto_enumTypes[4].Descriptor()
+}
+
+func (RegisterOneClickType) Type() protoreflect.EnumType {
+	return &file_maib_proto_enumTypes[4]
+}
+
+func (x RegisterOneClickType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RegisterOneClickType.Descriptor instead.
+func (RegisterOneClickType) EnumDescriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{4}
+}
+
+type RegisterTransactionRequest struct {
+	state           protoimpl.MessageState  `protogen:"open.v1"`
+	TransactionType RegisterTransactionType `protobuf:"varint,1,opt,name=transaction_type,json=transactionType,proto3,enum=maib.ecomm.v1.RegisterTransactionType" json:"transaction_type,omitempty"`
+	// Positive integer with last 2 digits being the cents.
+	Amount int64 `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	// ISO4217 numeric code, e.g. 498 for MDL.
+	Currency        int32  `protobuf:"varint,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	ClientIpAddress string `protobuf:"bytes,4,opt,name=client_ip_address,json=clientIpAddress,proto3" json:"client_ip_address,omitempty"`
+	Description     string `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	Language        string `protobuf:"bytes,6,opt,name=language,proto3" json:"language,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RegisterTransactionRequest) Reset() {
+	*x = RegisterTransactionRequest{}
+	mi := &file_maib_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterTransactionRequest) ProtoMessage() {}
+
+func (x *RegisterTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterTransactionRequest.ProtoReflect.Descriptor instead.
+func (*RegisterTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RegisterTransactionRequest) GetTransactionType() RegisterTransactionType {
+	if x != nil {
+		return x.TransactionType
+	}
+	return RegisterTransactionType_REGISTER_TRANSACTION_TYPE_SMS
+}
+
+func (x *RegisterTransactionRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RegisterTransactionRequest) GetCurrency() int32 {
+	if x != nil {
+		return x.Currency
+	}
+	return 0
+}
+
+func (x *RegisterTransactionRequest) GetClientIpAddress() string {
+	if x != nil {
+		return x.ClientIpAddress
+	}
+	return ""
+}
+
+func (x *RegisterTransactionRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *RegisterTransactionRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+type RegisterTransactionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterTransactionResult) Reset() {
+	*x = RegisterTransactionResult{}
+	mi := &file_maib_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterTransactionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterTransactionResult) ProtoMessage() {}
+
+func (x *RegisterTransactionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterTransactionResult.ProtoReflect.Descriptor instead.
+func (*RegisterTransactionResult) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegisterTransactionResult) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+type RegisterRecurringRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TransactionType RegisterRecurringType  `protobuf:"varint,1,opt,name=transaction_type,json=transactionType,proto3,enum=maib.ecomm.v1.RegisterRecurringType" json:"transaction_type,omitempty"`
+	Amount          int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency        int32                  `protobuf:"varint,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	ClientIpAddress string                 `protobuf:"bytes,4,opt,name=client_ip_address,json=clientIpAddress,proto3" json:"client_ip_address,omitempty"`
+	Description     string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	Language        string                 `protobuf:"bytes,6,opt,name=language,proto3" json:"language,omitempty"`
+	BillerClientId  string                 `protobuf:"bytes,7,opt,name=biller_client_id,json=billerClientId,proto3" json:"biller_client_id,omitempty"`
+	// Format "MMYY".
+	PerspayeeExpiry   string `protobuf:"bytes,8,opt,name=perspayee_expiry,json=perspayeeExpiry,proto3" json:"perspayee_expiry,omitempty"`
+	OverwriteExisting bool   `protobuf:"varint,9,opt,name=overwrite_existing,json=overwriteExisting,proto3" json:"overwrite_existing,omitempty"`
+	AskSaveCardData   bool   `protobuf:"varint,10,opt,name=ask_save_card_data,json=askSaveCardData,proto3" json:"ask_save_card_data,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *RegisterRecurringRequest) Reset() {
+	*x = RegisterRecurringRequest{}
+	mi := &file_maib_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRecurringRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRecurringRequest) ProtoMessage() {}
+
+func (x *RegisterRecurringRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRecurringRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRecurringRequest) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *RegisterRecurringRequest) GetTransactionType() RegisterRecurringType {
+	if x != nil {
+		return x.TransactionType
+	}
+	return RegisterRecurringType_REGISTER_RECURRING_TYPE_SMS
+}
+
+func (x *RegisterRecurringRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RegisterRecurringRequest) GetCurrency() int32 {
+	if x != nil {
+		return x.Currency
+	}
+	return 0
+}
+
+func (x *RegisterRecurringRequest) GetClientIpAddress() string {
+	if x != nil {
+		return x.ClientIpAddress
+	}
+	return ""
+}
+
+func (x *RegisterRecurringRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *RegisterRecurringRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *RegisterRecurringRequest) GetBillerClientId() string {
+	if x != nil {
+		return x.BillerClientId
+	}
+	return ""
+}
+
+func (x *RegisterRecurringRequest) GetPerspayeeExpiry() string {
+	if x != nil {
+		return x.PerspayeeExpiry
+	}
+	return ""
+}
+
+func (x *RegisterRecurringRequest) GetOverwriteExisting() bool {
+	if x != nil {
+		return x.OverwriteExisting
+	}
+	return false
+}
+
+func (x *RegisterRecurringRequest) GetAskSaveCardData() bool {
+	if x != nil {
+		return x.AskSaveCardData
+	}
+	return false
+}
+
+type RegisterRecurringResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRecurringResult) Reset() {
+	*x = RegisterRecurringResult{}
+	mi := &file_maib_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRecurringResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRecurringResult) ProtoMessage() {}
+
+func (x *RegisterRecurringResult) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRecurringResult.ProtoReflect.Descriptor instead.
+func (*RegisterRecurringResult) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *RegisterRecurringResult) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+type RegisterOneClickRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TransactionType RegisterOneClickType   `protobuf:"varint,1,opt,name=transaction_type,json=transactionType,proto3,enum=maib.ecomm.v1.RegisterOneClickType" json:"transaction_type,omitempty"`
+	Amount          int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency        int32                  `protobuf:"varint,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	ClientIpAddress string                 `protobuf:"bytes,4,opt,name=client_ip_address,json=clientIpAddress,proto3" json:"client_ip_address,omitempty"`
+	Description     string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	Language        string                 `protobuf:"bytes,6,opt,name=language,proto3" json:"language,omitempty"`
+	BillerClientId  string                 `protobuf:"bytes,7,opt,name=biller_client_id,json=billerClientId,proto3" json:"biller_client_id,omitempty"`
+	// Format "MMYY".
+	PerspayeeExpiry   string `protobuf:"bytes,8,opt,name=perspayee_expiry,json=perspayeeExpiry,proto3" json:"perspayee_expiry,omitempty"`
+	OverwriteExisting bool   `protobuf:"varint,9,opt,name=overwrite_existing,json=overwriteExisting,proto3" json:"overwrite_existing,omitempty"`
+	AskSaveCardData   bool   `protobuf:"varint,10,opt,name=ask_save_card_data,json=askSaveCardData,proto3" json:"ask_save_card_data,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *RegisterOneClickRequest) Reset() {
+	*x = RegisterOneClickRequest{}
+	mi := &file_maib_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterOneClickRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterOneClickRequest) ProtoMessage() {}
+
+func (x *RegisterOneClickRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterOneClickRequest.ProtoReflect.Descriptor instead.
+func (*RegisterOneClickRequest) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RegisterOneClickRequest) GetTransactionType() RegisterOneClickType {
+	if x != nil {
+		return x.TransactionType
+	}
+	return RegisterOneClickType_REGISTER_ONE_CLICK_TYPE_SMS
+}
+
+func (x *RegisterOneClickRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *RegisterOneClickRequest) GetCurrency() int32 {
+	if x != nil {
+		return x.Currency
+	}
+	return 0
+}
+
+func (x *RegisterOneClickRequest) GetClientIpAddress() string {
+	if x != nil {
+		return x.ClientIpAddress
+	}
+	return ""
+}
+
+func (x *RegisterOneClickRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *RegisterOneClickRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *RegisterOneClickRequest) GetBillerClientId() string {
+	if x != nil {
+		return x.BillerClientId
+	}
+	return ""
+}
+
+func (x *RegisterOneClickRequest) GetPerspayeeExpiry() string {
+	if x != nil {
+		return x.PerspayeeExpiry
+	}
+	return ""
+}
+
+func (x *RegisterOneClickRequest) GetOverwriteExisting() bool {
+	if x != nil {
+		return x.OverwriteExisting
+	}
+	return false
+}
+
+func (x *RegisterOneClickRequest) GetAskSaveCardData() bool {
+	if x != nil {
+		return x.AskSaveCardData
+	}
+	return false
+}
+
+type RegisterOneClickResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterOneClickResult) Reset() {
+	*x = RegisterOneClickResult{}
+	mi := &file_maib_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterOneClickResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterOneClickResult) ProtoMessage() {}
+
+func (x *RegisterOneClickResult) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterOneClickResult.ProtoReflect.Descriptor instead.
+func (*RegisterOneClickResult) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *RegisterOneClickResult) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+type TransactionStatusRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId   string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	ClientIpAddress string                 `protobuf:"bytes,2,opt,name=client_ip_address,json=clientIpAddress,proto3" json:"client_ip_address,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *TransactionStatusRequest) Reset() {
+	*x = TransactionStatusRequest{}
+	mi := &file_maib_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionStatusRequest) ProtoMessage() {}
+
+func (x *TransactionStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionStatusRequest.ProtoReflect.Descriptor instead.
+func (*TransactionStatusRequest) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TransactionStatusRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *TransactionStatusRequest) GetClientIpAddress() string {
+	if x != nil {
+		return x.ClientIpAddress
+	}
+	return ""
+}
+
+type TransactionStatusResult struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	Result                  Result                 `protobuf:"varint,1,opt,name=result,proto3,enum=maib.ecomm.v1.Result" json:"result,omitempty"`
+	ResultPs                ResultPS               `protobuf:"varint,2,opt,name=result_ps,json=resultPs,proto3,enum=maib.ecomm.v1.ResultPS" json:"result_ps,omitempty"`
+	ResultCode              int32                  `protobuf:"varint,3,opt,name=result_code,json=resultCode,proto3" json:"result_code,omitempty"`
+	ThreeDSecure            string                 `protobuf:"bytes,4,opt,name=three_d_secure,json=threeDSecure,proto3" json:"three_d_secure,omitempty"`
+	ThreeDSecureReason      string                 `protobuf:"bytes,5,opt,name=three_d_secure_reason,json=threeDSecureReason,proto3" json:"three_d_secure_reason,omitempty"`
+	Rrn                     int64                  `protobuf:"varint,6,opt,name=rrn,proto3" json:"rrn,omitempty"`
+	ApprovalCode            string                 `protobuf:"bytes,7,opt,name=approval_code,json=approvalCode,proto3" json:"approval_code,omitempty"`
+	CardNumber              string                 `protobuf:"bytes,8,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	Aav                     string                 `protobuf:"bytes,9,opt,name=aav,proto3" json:"aav,omitempty"`
+	PaymentAccountReference string                 `protobuf:"bytes,10,opt,name=payment_account_reference,json=paymentAccountReference,proto3" json:"payment_account_reference,omitempty"`
+	RecurringPaymentId      string                 `protobuf:"bytes,11,opt,name=recurring_payment_id,json=recurringPaymentId,proto3" json:"recurring_payment_id,omitempty"`
+	RecurringPaymentExpiry  string                 `protobuf:"bytes,12,opt,name=recurring_payment_expiry,json=recurringPaymentExpiry,proto3" json:"recurring_payment_expiry,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *TransactionStatusResult) Reset() {
+	*x = TransactionStatusResult{}
+	mi := &file_maib_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TransactionStatusResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TransactionStatusResult) ProtoMessage() {}
+
+func (x *TransactionStatusResult) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TransactionStatusResult.ProtoReflect.Descriptor instead.
+func (*TransactionStatusResult) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TransactionStatusResult) GetResult() Result {
+	if x != nil {
+		return x.Result
+	}
+	return Result_RESULT_UNSPECIFIED
+}
+
+func (x *TransactionStatusResult) GetResultPs() ResultPS {
+	if x != nil {
+		return x.ResultPs
+	}
+	return ResultPS_RESULT_PS_UNSPECIFIED
+}
+
+func (x *TransactionStatusResult) GetResultCode() int32 {
+	if x != nil {
+		return x.ResultCode
+	}
+	return 0
+}
+
+func (x *TransactionStatusResult) GetThreeDSecure() string {
+	if x != nil {
+		return x.ThreeDSecure
+	}
+	return ""
+}
+
+func (x *TransactionStatusResult) GetThreeDSecureReason() string {
+	if x != nil {
+		return x.ThreeDSecureReason
+	}
+	return ""
+}
+
+func (x *TransactionStatusResult) GetRrn() int64 {
+	if x != nil {
+		return x.Rrn
+	}
+	return 0
+}
+
+func (x *TransactionStatusResult) GetApprovalCode() string {
+	if x != nil {
+		return x.ApprovalCode
+	}
+	return ""
+}
+
+func (x *TransactionStatusResult) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+func (x *TransactionStatusResult) GetAav() string {
+	if x != nil {
+		return x.Aav
+	}
+	return ""
+}
+
+func (x *TransactionStatusResult) GetPaymentAccountReference() string {
+	if x != nil {
+		return x.PaymentAccountReference
+	}
+	return ""
+}
+
+func (x *TransactionStatusResult) GetRecurringPaymentId() string {
+	if x != nil {
+		return x.RecurringPaymentId
+	}
+	return ""
+}
+
+func (x *TransactionStatusResult) GetRecurringPaymentExpiry() string {
+	if x != nil {
+		return x.RecurringPaymentExpiry
+	}
+	return ""
+}
+
+type ExecuteDMSRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId   string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Amount          int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency        int32                  `protobuf:"varint,3,opt,name=currency,proto3" json:"currency,omitempty"`
+	ClientIpAddress string                 `protobuf:"bytes,4,opt,name=client_ip_address,json=clientIpAddress,proto3" json:"client_ip_address,omitempty"`
+	Description     string                 `protobuf:"bytes,5,opt,name=description,proto3" json:"description,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ExecuteDMSRequest) Reset() {
+	*x = ExecuteDMSRequest{}
+	mi := &file_maib_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteDMSRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteDMSRequest) ProtoMessage() {}
+
+func (x *ExecuteDMSRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteDMSRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteDMSRequest) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExecuteDMSRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ExecuteDMSRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ExecuteDMSRequest) GetCurrency() int32 {
+	if x != nil {
+		return x.Currency
+	}
+	return 0
+}
+
+func (x *ExecuteDMSRequest) GetClientIpAddress() string {
+	if x != nil {
+		return x.ClientIpAddress
+	}
+	return ""
+}
+
+func (x *ExecuteDMSRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+type ExecuteDMSResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        Result                 `protobuf:"varint,1,opt,name=result,proto3,enum=maib.ecomm.v1.Result" json:"result,omitempty"`
+	ResultCode    int32                  `protobuf:"varint,2,opt,name=result_code,json=resultCode,proto3" json:"result_code,omitempty"`
+	Rrn           int64                  `protobuf:"varint,3,opt,name=rrn,proto3" json:"rrn,omitempty"`
+	ApprovalCode  string                 `protobuf:"bytes,4,opt,name=approval_code,json=approvalCode,proto3" json:"approval_code,omitempty"`
+	CardNumber    string                 `protobuf:"bytes,5,opt,name=card_number,json=cardNumber,proto3" json:"card_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteDMSResult) Reset() {
+	*x = ExecuteDMSResult{}
+	mi := &file_maib_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteDMSResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteDMSResult) ProtoMessage() {}
+
+func (x *ExecuteDMSResult) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteDMSResult.ProtoReflect.Descriptor instead.
+func (*ExecuteDMSResult) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ExecuteDMSResult) GetResult() Result {
+	if x != nil {
+		return x.Result
+	}
+	return Result_RESULT_UNSPECIFIED
+}
+
+func (x *ExecuteDMSResult) GetResultCode() int32 {
+	if x != nil {
+		return x.ResultCode
+	}
+	return 0
+}
+
+func (x *ExecuteDMSResult) GetRrn() int64 {
+	if x != nil {
+		return x.Rrn
+	}
+	return 0
+}
+
+func (x *ExecuteDMSResult) GetApprovalCode() string {
+	if x != nil {
+		return x.ApprovalCode
+	}
+	return ""
+}
+
+func (x *ExecuteDMSResult) GetCardNumber() string {
+	if x != nil {
+		return x.CardNumber
+	}
+	return ""
+}
+
+type ExecuteRecurringRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Amount          int64                  `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency        int32                  `protobuf:"varint,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	ClientIpAddress string                 `protobuf:"bytes,3,opt,name=client_ip_address,json=clientIpAddress,proto3" json:"client_ip_address,omitempty"`
+	Description     string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	BillerClientId  string                 `protobuf:"bytes,5,opt,name=biller_client_id,json=billerClientId,proto3" json:"biller_client_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ExecuteRecurringRequest) Reset() {
+	*x = ExecuteRecurringRequest{}
+	mi := &file_maib_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteRecurringRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteRecurringRequest) ProtoMessage() {}
+
+func (x *ExecuteRecurringRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteRecurringRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteRecurringRequest) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ExecuteRecurringRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ExecuteRecurringRequest) GetCurrency() int32 {
+	if x != nil {
+		return x.Currency
+	}
+	return 0
+}
+
+func (x *ExecuteRecurringRequest) GetClientIpAddress() string {
+	if x != nil {
+		return x.ClientIpAddress
+	}
+	return ""
+}
+
+func (x *ExecuteRecurringRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ExecuteRecurringRequest) GetBillerClientId() string {
+	if x != nil {
+		return x.BillerClientId
+	}
+	return ""
+}
+
+type ExecuteRecurringResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Result        Result                 `protobuf:"varint,2,opt,name=result,proto3,enum=maib.ecomm.v1.Result" json:"result,omitempty"`
+	ResultCode    int32                  `protobuf:"varint,3,opt,name=result_code,json=resultCode,proto3" json:"result_code,omitempty"`
+	Rrn           int64                  `protobuf:"varint,4,opt,name=rrn,proto3" json:"rrn,omitempty"`
+	ApprovalCode  string                 `protobuf:"bytes,5,opt,name=approval_code,json=approvalCode,proto3" json:"approval_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteRecurringResult) Reset() {
+	*x = ExecuteRecurringResult{}
+	mi := &file_maib_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteRecurringResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteRecurringResult) ProtoMessage() {}
+
+func (x *ExecuteRecurringResult) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteRecurringResult.ProtoReflect.Descriptor instead.
+func (*ExecuteRecurringResult) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ExecuteRecurringResult) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ExecuteRecurringResult) GetResult() Result {
+	if x != nil {
+		return x.Result
+	}
+	return Result_RESULT_UNSPECIFIED
+}
+
+func (x *ExecuteRecurringResult) GetResultCode() int32 {
+	if x != nil {
+		return x.ResultCode
+	}
+	return 0
+}
+
+func (x *ExecuteRecurringResult) GetRrn() int64 {
+	if x != nil {
+		return x.Rrn
+	}
+	return 0
+}
+
+func (x *ExecuteRecurringResult) GetApprovalCode() string {
+	if x != nil {
+		return x.ApprovalCode
+	}
+	return ""
+}
+
+type ExecuteOneClickRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Amount          int64                  `protobuf:"varint,1,opt,name=amount,proto3" json:"amount,omitempty"`
+	Currency        int32                  `protobuf:"varint,2,opt,name=currency,proto3" json:"currency,omitempty"`
+	ClientIpAddress string                 `protobuf:"bytes,3,opt,name=client_ip_address,json=clientIpAddress,proto3" json:"client_ip_address,omitempty"`
+	Description     string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	BillerClientId  string                 `protobuf:"bytes,5,opt,name=biller_client_id,json=billerClientId,proto3" json:"biller_client_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ExecuteOneClickRequest) Reset() {
+	*x = ExecuteOneClickRequest{}
+	mi := &file_maib_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteOneClickRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteOneClickRequest) ProtoMessage() {}
+
+func (x *ExecuteOneClickRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteOneClickRequest.ProtoReflect.Descriptor instead.
+func (*ExecuteOneClickRequest) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ExecuteOneClickRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ExecuteOneClickRequest) GetCurrency() int32 {
+	if x != nil {
+		return x.Currency
+	}
+	return 0
+}
+
+func (x *ExecuteOneClickRequest) GetClientIpAddress() string {
+	if x != nil {
+		return x.ClientIpAddress
+	}
+	return ""
+}
+
+func (x *ExecuteOneClickRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *ExecuteOneClickRequest) GetBillerClientId() string {
+	if x != nil {
+		return x.BillerClientId
+	}
+	return ""
+}
+
+type ExecuteOneClickResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Result        Result                 `protobuf:"varint,2,opt,name=result,proto3,enum=maib.ecomm.v1.Result" json:"result,omitempty"`
+	ResultCode    int32                  `protobuf:"varint,3,opt,name=result_code,json=resultCode,proto3" json:"result_code,omitempty"`
+	Rrn           int64                  `protobuf:"varint,4,opt,name=rrn,proto3" json:"rrn,omitempty"`
+	ApprovalCode  string                 `protobuf:"bytes,5,opt,name=approval_code,json=approvalCode,proto3" json:"approval_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExecuteOneClickResult) Reset() {
+	*x = ExecuteOneClickResult{}
+	mi := &file_maib_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExecuteOneClickResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExecuteOneClickResult) ProtoMessage() {}
+
+func (x *ExecuteOneClickResult) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExecuteOneClickResult.ProtoReflect.Descriptor instead.
+func (*ExecuteOneClickResult) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ExecuteOneClickResult) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ExecuteOneClickResult) GetResult() Result {
+	if x != nil {
+		return x.Result
+	}
+	return Result_RESULT_UNSPECIFIED
+}
+
+func (x *ExecuteOneClickResult) GetResultCode() int32 {
+	if x != nil {
+		return x.ResultCode
+	}
+	return 0
+}
+
+func (x *ExecuteOneClickResult) GetRrn() int64 {
+	if x != nil {
+		return x.Rrn
+	}
+	return 0
+}
+
+func (x *ExecuteOneClickResult) GetApprovalCode() string {
+	if x != nil {
+		return x.ApprovalCode
+	}
+	return ""
+}
+
+type ReverseTransactionRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TransactionId  string                 `protobuf:"bytes,1,opt,name=transaction_id,json=transactionId,proto3" json:"transaction_id,omitempty"`
+	Amount         int64                  `protobuf:"varint,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	SuspectedFraud bool                   `protobuf:"varint,3,opt,name=suspected_fraud,json=suspectedFraud,proto3" json:"suspected_fraud,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ReverseTransactionRequest) Reset() {
+	*x = ReverseTransactionRequest{}
+	mi := &file_maib_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseTransactionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseTransactionRequest) ProtoMessage() {}
+
+func (x *ReverseTransactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseTransactionRequest.ProtoReflect.Descriptor instead.
+func (*ReverseTransactionRequest) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ReverseTransactionRequest) GetTransactionId() string {
+	if x != nil {
+		return x.TransactionId
+	}
+	return ""
+}
+
+func (x *ReverseTransactionRequest) GetAmount() int64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+func (x *ReverseTransactionRequest) GetSuspectedFraud() bool {
+	if x != nil {
+		return x.SuspectedFraud
+	}
+	return false
+}
+
+type ReverseTransactionResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        Result                 `protobuf:"varint,1,opt,name=result,proto3,enum=maib.ecomm.v1.Result" json:"result,omitempty"`
+	ResultCode    int32                  `protobuf:"varint,2,opt,name=result_code,json=resultCode,proto3" json:"result_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReverseTransactionResult) Reset() {
+	*x = ReverseTransactionResult{}
+	mi := &file_maib_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReverseTransactionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReverseTransactionResult) ProtoMessage() {}
+
+func (x *ReverseTransactionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReverseTransactionResult.ProtoReflect.Descriptor instead.
+func (*ReverseTransactionResult) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReverseTransactionResult) GetResult() Result {
+	if x != nil {
+		return x.Result
+	}
+	return Result_RESULT_UNSPECIFIED
+}
+
+func (x *ReverseTransactionResult) GetResultCode() int32 {
+	if x != nil {
+		return x.ResultCode
+	}
+	return 0
+}
+
+type DeleteRecurringRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	BillerClientId string                 `protobuf:"bytes,1,opt,name=biller_client_id,json=billerClientId,proto3" json:"biller_client_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeleteRecurringRequest) Reset() {
+	*x = DeleteRecurringRequest{}
+	mi := &file_maib_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRecurringRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRecurringRequest) ProtoMessage() {}
+
+func (x *DeleteRecurringRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRecurringRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRecurringRequest) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DeleteRecurringRequest) GetBillerClientId() string {
+	if x != nil {
+		return x.BillerClientId
+	}
+	return ""
+}
+
+type DeleteRecurringResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        Result                 `protobuf:"varint,1,opt,name=result,proto3,enum=maib.ecomm.v1.Result" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRecurringResult) Reset() {
+	*x = DeleteRecurringResult{}
+	mi := &file_maib_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRecurringResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRecurringResult) ProtoMessage() {}
+
+func (x *DeleteRecurringResult) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRecurringResult.ProtoReflect.Descriptor instead.
+func (*DeleteRecurringResult) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DeleteRecurringResult) GetResult() Result {
+	if x != nil {
+		return x.Result
+	}
+	return Result_RESULT_UNSPECIFIED
+}
+
+type CloseDayRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CloseDayRequest) Reset() {
+	*x = CloseDayRequest{}
+	mi := &file_maib_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseDayRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseDayRequest) ProtoMessage() {}
+
+func (x *CloseDayRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseDayRequest.ProtoReflect.Descriptor instead.
+func (*CloseDayRequest) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{18}
+}
+
+type CloseDayResult struct {
+	state                   protoimpl.MessageState `protogen:"open.v1"`
+	Result                  Result                 `protobuf:"varint,1,opt,name=result,proto3,enum=maib.ecomm.v1.Result" json:"result,omitempty"`
+	ResultCode              int32                  `protobuf:"varint,2,opt,name=result_code,json=resultCode,proto3" json:"result_code,omitempty"`
+	CreditTransactionNumber int64                  `protobuf:"varint,3,opt,name=credit_transaction_number,json=creditTransactionNumber,proto3" json:"credit_transaction_number,omitempty"`
+	CreditReversalNumber    int64                  `protobuf:"varint,4,opt,name=credit_reversal_number,json=creditReversalNumber,proto3" json:"credit_reversal_number,omitempty"`
+	DebitTransactionNumber  int64                  `protobuf:"varint,5,opt,name=debit_transaction_number,json=debitTransactionNumber,proto3" json:"debit_transaction_number,omitempty"`
+	DebitReversalNumber     int64                  `protobuf:"varint,6,opt,name=debit_reversal_number,json=debitReversalNumber,proto3" json:"debit_reversal_number,omitempty"`
+	CreditTransactionAmount int64                  `protobuf:"varint,7,opt,name=credit_transaction_amount,json=creditTransactionAmount,proto3" json:"credit_transaction_amount,omitempty"`
+	CreditReversalAmount    int64                  `protobuf:"varint,8,opt,name=credit_reversal_amount,json=creditReversalAmount,proto3" json:"credit_reversal_amount,omitempty"`
+	DebitTransactionAmount  int64                  `protobuf:"varint,9,opt,name=debit_transaction_amount,json=debitTransactionAmount,proto3" json:"debit_transaction_amount,omitempty"`
+	DebitReversalAmount     int64                  `protobuf:"varint,10,opt,name=debit_reversal_amount,json=debitReversalAmount,proto3" json:"debit_reversal_amount,omitempty"`
+	unknownFields           protoimpl.UnknownFields
+	sizeCache               protoimpl.SizeCache
+}
+
+func (x *CloseDayResult) Reset() {
+	*x = CloseDayResult{}
+	mi := &file_maib_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CloseDayResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CloseDayResult) ProtoMessage() {}
+
+func (x *CloseDayResult) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CloseDayResult.ProtoReflect.Descriptor instead.
+func (*CloseDayResult) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CloseDayResult) GetResult() Result {
+	if x != nil {
+		return x.Result
+	}
+	return Result_RESULT_UNSPECIFIED
+}
+
+func (x *CloseDayResult) GetResultCode() int32 {
+	if x != nil {
+		return x.ResultCode
+	}
+	return 0
+}
+
+func (x *CloseDayResult) GetCreditTransactionNumber() int64 {
+	if x != nil {
+		return x.CreditTransactionNumber
+	}
+	return 0
+}
+
+func (x *CloseDayResult) GetCreditReversalNumber() int64 {
+	if x != nil {
+		return x.CreditReversalNumber
+	}
+	return 0
+}
+
+func (x *CloseDayResult) GetDebitTransactionNumber() int64 {
+	if x != nil {
+		return x.DebitTransactionNumber
+	}
+	return 0
+}
+
+func (x *CloseDayResult) GetDebitReversalNumber() int64 {
+	if x != nil {
+		return x.DebitReversalNumber
+	}
+	return 0
+}
+
+func (x *CloseDayResult) GetCreditTransactionAmount() int64 {
+	if x != nil {
+		return x.CreditTransactionAmount
+	}
+	return 0
+}
+
+func (x *CloseDayResult) GetCreditReversalAmount() int64 {
+	if x != nil {
+		return x.CreditReversalAmount
+	}
+	return 0
+}
+
+func (x *CloseDayResult) GetDebitTransactionAmount() int64 {
+	if x != nil {
+		return x.DebitTransactionAmount
+	}
+	return 0
+}
+
+func (x *CloseDayResult) GetDebitReversalAmount() int64 {
+	if x != nil {
+		return x.DebitReversalAmount
+	}
+	return 0
+}
+
+// Error detail of FAILED_PRECONDITION, UNAVAILABLE and UNKNOWN statuses,
+// mirrors maib.ECommError.
+type ECommError struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// HTTP status code returned by the ECommerce system.
+	Code int32 `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	// Response body.
+	Body          string `protobuf:"bytes,2,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ECommError) Reset() {
+	*x = ECommError{}
+	mi := &file_maib_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ECommError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ECommError) ProtoMessage() {}
+
+func (x *ECommError) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ECommError.ProtoReflect.Descriptor instead.
+func (*ECommError) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ECommError) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ECommError) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+// Error detail of INTERNAL statuses caused by a response that could not be
+// parsed, mirrors maib.ParseError.
+type ParseError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Body          string                 `protobuf:"bytes,1,opt,name=body,proto3" json:"body,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParseError) Reset() {
+	*x = ParseError{}
+	mi := &file_maib_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParseError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParseError) ProtoMessage() {}
+
+func (x *ParseError) ProtoReflect() protoreflect.Message {
+	mi := &file_maib_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParseError.ProtoReflect.Descriptor instead.
+func (*ParseError) Descriptor() ([]byte, []int) {
+	return file_maib_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *ParseError) GetBody() string {
+	if x != nil {
+		return x.Body
+	}
+	return ""
+}
+
+var File_maib_proto protoreflect.FileDescriptor
+
+const file_maib_proto_rawDesc = "" +
+	"\n" +
+	"\n" +
+	"maib.proto\x12\rmaib.ecomm.v1\"\x8d\x02\n" +
+	"\x1aRegisterTransactionRequest\x12Q\n" +
+	"\x10transaction_type\x18\x01 \x01(\x0e2&.maib.ecomm.v1.RegisterTransactionTypeR\x0ftransactionType\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\x05R\bcurrency\x12*\n" +
+	"\x11client_ip_address\x18\x04 \x01(\tR\x0fclientIpAddress\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1a\n" +
+	"\blanguage\x18\x06 \x01(\tR\blanguage\"B\n" +
+	"\x19RegisterTransactionResult\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\"\xba\x03\n" +
+	"\x18RegisterRecurringRequest\x12O\n" +
+	"\x10transaction_type\x18\x01 \x01(\x0e2$.maib.ecomm.v1.RegisterRecurringTypeR\x0ftransactionType\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\x05R\bcurrency\x12*\n" +
+	"\x11client_ip_address\x18\x04 \x01(\tR\x0fclientIpAddress\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1a\n" +
+	"\blanguage\x18\x06 \x01(\tR\blanguage\x12(\n" +
+	"\x10biller_client_id\x18\a \x01(\tR\x0ebillerClientId\x12)\n" +
+	"\x10perspayee_expiry\x18\b \x01(\tR\x0fperspayeeExpiry\x12-\n" +
+	"\x12overwrite_existing\x18\t \x01(\bR\x11overwriteExisting\x12+\n" +
+	"\x12ask_save_card_data\x18\n" +
+	" \x01(\bR\x0faskSaveCardData\"@\n" +
+	"\x17RegisterRecurringResult\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\"\xb8\x03\n" +
+	"\x17RegisterOneClickRequest\x12N\n" +
+	"\x10transaction_type\x18\x01 \x01(\x0e2#.maib.ecomm.v1.RegisterOneClickTypeR\x0ftransactionType\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\x05R\bcurrency\x12*\n" +
+	"\x11client_ip_address\x18\x04 \x01(\tR\x0fclientIpAddress\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\x12\x1a\n" +
+	"\blanguage\x18\x06 \x01(\tR\blanguage\x12(\n" +
+	"\x10biller_client_id\x18\a \x01(\tR\x0ebillerClientId\x12)\n" +
+	"\x10perspayee_expiry\x18\b \x01(\tR\x0fperspayeeExpiry\x12-\n" +
+	"\x12overwrite_existing\x18\t \x01(\bR\x11overwriteExisting\x12+\n" +
+	"\x12ask_save_card_data\x18\n" +
+	" \x01(\bR\x0faskSaveCardData\"?\n" +
+	"\x16RegisterOneClickResult\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\"m\n" +
+	"\x18TransactionStatusRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12*\n" +
+	"\x11client_ip_address\x18\x02 \x01(\tR\x0fclientIpAddress\"\x8a\x04\n" +
+	"\x17TransactionStatusResult\x12-\n" +
+	"\x06result\x18\x01 \x01(\x0e2\x15.maib.ecomm.v1.ResultR\x06result\x124\n" +
+	"\tresult_ps\x18\x02 \x01(\x0e2\x17.maib.ecomm.v1.ResultPSR\bresultPs\x12\x1f\n" +
+	"\vresult_code\x18\x03 \x01(\x05R\n" +
+	"resultCode\x12$\n" +
+	"\x0ethree_d_secure\x18\x04 \x01(\tR\fthreeDSecure\x121\n" +
+	"\x15three_d_secure_reason\x18\x05 \x01(\tR\x12threeDSecureReason\x12\x10\n" +
+	"\x03rrn\x18\x06 \x01(\x03R\x03rrn\x12#\n" +
+	"\rapproval_code\x18\a \x01(\tR\fapprovalCode\x12\x1f\n" +
+	"\vcard_number\x18\b \x01(\tR\n" +
+	"cardNumber\x12\x10\n" +
+	"\x03aav\x18\t \x01(\tR\x03aav\x12:\n" +
+	"\x19payment_account_reference\x18\n" +
+	" \x01(\tR\x17paymentAccountReference\x120\n" +
+	"\x14recurring_payment_id\x18\v \x01(\tR\x12recurringPaymentId\x128\n" +
+	"\x18recurring_payment_expiry\x18\f \x01(\tR\x16recurringPaymentExpiry\"\xbc\x01\n" +
+	"\x11ExecuteDMSRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x03 \x01(\x05R\bcurrency\x12*\n" +
+	"\x11client_ip_address\x18\x04 \x01(\tR\x0fclientIpAddress\x12 \n" +
+	"\vdescription\x18\x05 \x01(\tR\vdescription\"\xba\x01\n" +
+	"\x10ExecuteDMSResult\x12-\n" +
+	"\x06result\x18\x01 \x01(\x0e2\x15.maib.ecomm.v1.ResultR\x06result\x12\x1f\n" +
+	"\vresult_code\x18\x02 \x01(\x05R\n" +
+	"resultCode\x12\x10\n" +
+	"\x03rrn\x18\x03 \x01(\x03R\x03rrn\x12#\n" +
+	"\rapproval_code\x18\x04 \x01(\tR\fapprovalCode\x12\x1f\n" +
+	"\vcard_number\x18\x05 \x01(\tR\n" +
+	"cardNumber\"\xc5\x01\n" +
+	"\x17ExecuteRecurringRequest\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\x05R\bcurrency\x12*\n" +
+	"\x11client_ip_address\x18\x03 \x01(\tR\x0fclientIpAddress\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12(\n" +
+	"\x10biller_client_id\x18\x05 \x01(\tR\x0ebillerClientId\"\xc6\x01\n" +
+	"\x16ExecuteRecurringResult\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12-\n" +
+	"\x06result\x18\x02 \x01(\x0e2\x15.maib.ecomm.v1.ResultR\x06result\x12\x1f\n" +
+	"\vresult_code\x18\x03 \x01(\x05R\n" +
+	"resultCode\x12\x10\n" +
+	"\x03rrn\x18\x04 \x01(\x03R\x03rrn\x12#\n" +
+	"\rapproval_code\x18\x05 \x01(\tR\fapprovalCode\"\xc4\x01\n" +
+	"\x16ExecuteOneClickRequest\x12\x16\n" +
+	"\x06amount\x18\x01 \x01(\x03R\x06amount\x12\x1a\n" +
+	"\bcurrency\x18\x02 \x01(\x05R\bcurrency\x12*\n" +
+	"\x11client_ip_address\x18\x03 \x01(\tR\x0fclientIpAddress\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12(\n" +
+	"\x10biller_client_id\x18\x05 \x01(\tR\x0ebillerClientId\"\xc5\x01\n" +
+	"\x15ExecuteOneClickResult\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12-\n" +
+	"\x06result\x18\x02 \x01(\x0e2\x15.maib.ecomm.v1.ResultR\x06result\x12\x1f\n" +
+	"\vresult_code\x18\x03 \x01(\x05R\n" +
+	"resultCode\x12\x10\n" +
+	"\x03rrn\x18\x04 \x01(\x03R\x03rrn\x12#\n" +
+	"\rapproval_code\x18\x05 \x01(\tR\fapprovalCode\"\x83\x01\n" +
+	"\x19ReverseTransactionRequest\x12%\n" +
+	"\x0etransaction_id\x18\x01 \x01(\tR\rtransactionId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x03R\x06amount\x12'\n" +
+	"\x0fsuspected_fraud\x18\x03 \x01(\bR\x0esuspectedFraud\"j\n" +
+	"\x18ReverseTransactionResult\x12-\n" +
+	"\x06result\x18\x01 \x01(\x0e2\x15.maib.ecomm.v1.ResultR\x06result\x12\x1f\n" +
+	"\vresult_code\x18\x02 \x01(\x05R\n" +
+	"resultCode\"B\n" +
+	"\x16DeleteRecurringRequest\x12(\n" +
+	"\x10biller_client_id\x18\x01 \x01(\tR\x0ebillerClientId\"F\n" +
+	"\x15DeleteRecurringResult\x12-\n" +
+	"\x06result\x18\x01 \x01(\x0e2\x15.maib.ecomm.v1.ResultR\x06result\"\x11\n" +
+	"\x0fCloseDayRequest\"\xa0\x04\n" +
+	"\x0eCloseDayResult\x12-\n" +
+	"\x06result\x18\x01 \x01(\x0e2\x15.maib.ecomm.v1.ResultR\x06result\x12\x1f\n" +
+	"\vresult_code\x18\x02 \x01(\x05R\n" +
+	"resultCode\x12:\n" +
+	"\x19credit_transaction_number\x18\x03 \x01(\x03R\x17creditTransactionNumber\x124\n" +
+	"\x16credit_reversal_number\x18\x04 \x01(\x03R\x14creditReversalNumber\x128\n" +
+	"\x18debit_transaction_number\x18\x05 \x01(\x03R\x16debitTransactionNumber\x122\n" +
+	"\x15debit_reversal_number\x18\x06 \x01(\x03R\x13debitReversalNumber\x12:\n" +
+	"\x19credit_transaction_amount\x18\a \x01(\x03R\x17creditTransactionAmount\x124\n" +
+	"\x16credit_reversal_amount\x18\b \x01(\x03R\x14creditReversalAmount\x128\n" +
+	"\x18debit_transaction_amount\x18\t \x01(\x03R\x16debitTransactionAmount\x122\n" +
+	"\x15debit_reversal_amount\x18\n" +
+	" \x01(\x03R\x13debitReversalAmount\"4\n" +
+	"\n" +
+	"ECommError\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x12\n" +
+	"\x04body\x18\x02 \x01(\tR\x04body\" \n" +
+	"\n" +
+	"ParseError\x12\x12\n" +
+	"\x04body\x18\x01 \x01(\tR\x04body*\xc1\x01\n" +
+	"\x06Result\x12\x16\n" +
+	"\x12RESULT_UNSPECIFIED\x10\x00\x12\r\n" +
+	"\tRESULT_OK\x10\x01\x12\x11\n" +
+	"\rRESULT_FAILED\x10\x02\x12\x12\n" +
+	"\x0eRESULT_CREATED\x10\x03\x12\x12\n" +
+	"\x0eRESULT_PENDING\x10\x04\x12\x13\n" +
+	"\x0fRESULT_DECLINED\x10\x05\x12\x13\n" +
+	"\x0fRESULT_REVERSED\x10\x06\x12\x17\n" +
+	"\x13RESULT_AUTOREVERSED\x10\a\x12\x12\n" +
+	"\x0eRESULT_TIMEOUT\x10\b*\x84\x01\n" +
+	"\bResultPS\x12\x19\n" +
+	"\x15RESULT_PS_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10RESULT_PS_ACTIVE\x10\x01\x12\x16\n" +
+	"\x12RESULT_PS_FINISHED\x10\x02\x12\x17\n" +
+	"\x13RESULT_PS_CANCELLED\x10\x03\x12\x16\n" +
+	"\x12RESULT_PS_RETURNED\x10\x04*_\n" +
+	"\x17RegisterTransactionType\x12!\n" +
+	"\x1dREGISTER_TRANSACTION_TYPE_SMS\x10\x00\x12!\n" +
+	"\x1dREGISTER_TRANSACTION_TYPE_DMS\x10\x01*\x86\x01\n" +
+	"\x15RegisterRecurringType\x12\x1f\n" +
+	"\x1bREGISTER_RECURRING_TYPE_SMS\x10\x00\x12\x1f\n" +
+	"\x1bREGISTER_RECURRING_TYPE_DMS\x10\x01\x12+\n" +
+	"'REGISTER_RECURRING_TYPE_WITHOUT_PAYMENT\x10\x02*d\n" +
+	"\x14RegisterOneClickType\x12\x1f\n" +
+	"\x1bREGISTER_ONE_CLICK_TYPE_SMS\x10\x00\x12+\n" +
+	"'REGISTER_ONE_CLICK_TYPE_WITHOUT_PAYMENT\x10\x012\xce\a\n" +
+	"\tECommerce\x12j\n" +
+	"\x13RegisterTransaction\x12).maib.ecomm.v1.RegisterTransactionRequest\x1a(.maib.ecomm.v1.RegisterTransactionResult\x12d\n" +
+	"\x11RegisterRecurring\x12'.maib.ecomm.v1.RegisterRecurringRequest\x1a&.maib.ecomm.v1.RegisterRecurringResult\x12a\n" +
+	"\x10RegisterOneClick\x12&.maib.ecomm.v1.RegisterOneClickRequest\x1a%.maib.ecomm.v1.RegisterOneClickResult\x12d\n" +
+	"\x11TransactionStatus\x12'.maib.ecomm.v1.TransactionStatusRequest\x1a&.maib.ecomm.v1.TransactionStatusResult\x12O\n" +
+	"\n" +
+	"ExecuteDMS\x12 .maib.ecomm.v1.ExecuteDMSRequest\x1a\x1f.maib.ecomm.v1.ExecuteDMSResult\x12a\n" +
+	"\x10ExecuteRecurring\x12&.maib.ecomm.v1.ExecuteRecurringRequest\x1a%.maib.ecomm.v1.ExecuteRecurringResult\x12^\n" +
+	"\x0fExecuteOneClick\x12%.maib.ecomm.v1.ExecuteOneClickRequest\x1a$.maib.ecomm.v1.ExecuteOneClickResult\x12g\n" +
+	"\x12ReverseTransaction\x12(.maib.ecomm.v1.ReverseTransactionRequest\x1a'.maib.ecomm.v1.ReverseTransactionResult\x12^\n" +
+	"\x0fDeleteRecurring\x12%.maib.ecomm.v1.DeleteRecurringRequest\x1a$.maib.ecomm.v1.DeleteRecurringResult\x12I\n" +
+	"\bCloseDay\x12\x1e.maib.ecomm.v1.CloseDayRequest\x1a\x1d.maib.ecomm.v1.CloseDayResultB.Z,github.com/NikSays/go-maib-ecomm/v2/maibgrpcb\x06proto3"
+
+var (
+	file_maib_proto_rawDescOnce sync.Once
+	file_maib_proto_rawDescData []byte
+)
+
+func file_maib_proto_rawDescGZIP() []byte {
+	file_maib_proto_rawDescOnce.Do(func() {
+		file_maib_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_maib_proto_rawDesc), len(file_maib_proto_rawDesc)))
+	})
+	return file_maib_proto_rawDescData
+}
+
+var file_maib_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_maib_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_maib_proto_goTypes = []any{
+	(Result)(0),                        // 0: maib.ecomm.v1.Result
+	(ResultPS)(0),                      // 1: maib.ecomm.v1.ResultPS
+	(RegisterTransactionType)(0),       // 2: maib.ecomm.v1.RegisterTransactionType
+	(RegisterRecurringType)(0),         // 3: maib.ecomm.v1.RegisterRecurringType
+	(RegisterOneClickType)(0),          // 4: maib.ecomm.v1.RegisterOneClickType
+	(*RegisterTransactionRequest)(nil), // 5: maib.ecomm.v1.RegisterTransactionRequest
+	(*RegisterTransactionResult)(nil),  // 6: maib.ecomm.v1.RegisterTransactionResult
+	(*RegisterRecurringRequest)(nil),   // 7: maib.ecomm.v1.RegisterRecurringRequest
+	(*RegisterRecurringResult)(nil),    // 8: maib.ecomm.v1.RegisterRecurringResult
+	(*RegisterOneClickRequest)(nil),    // 9: maib.ecomm.v1.RegisterOneClickRequest
+	(*RegisterOneClickResult)(nil),     // 10: maib.ecomm.v1.RegisterOneClickResult
+	(*TransactionStatusRequest)(nil),   // 11: maib.ecomm.v1.TransactionStatusRequest
+	(*TransactionStatusResult)(nil),    // 12: maib.ecomm.v1.TransactionStatusResult
+	(*ExecuteDMSRequest)(nil),          // 13: maib.ecomm.v1.ExecuteDMSRequest
+	(*ExecuteDMSResult)(nil),           // 14: maib.ecomm.v1.ExecuteDMSResult
+	(*ExecuteRecurringRequest)(nil),    // 15: maib.ecomm.v1.ExecuteRecurringRequest
+	(*ExecuteRecurringResult)(nil),     // 16: maib.ecomm.v1.ExecuteRecurringResult
+	(*ExecuteOneClickRequest)(nil),     // 17: maib.ecomm.v1.ExecuteOneClickRequest
+	(*ExecuteOneClickResult)(nil),      // 18: maib.ecomm.v1.ExecuteOneClickResult
+	(*ReverseTransactionRequest)(nil),  // 19: maib.ecomm.v1.ReverseTransactionRequest
+	(*ReverseTransactionResult)(nil),   // 20: maib.ecomm.v1.ReverseTransactionResult
+	(*DeleteRecurringRequest)(nil),     // 21: maib.ecomm.v1.DeleteRecurringRequest
+	(*DeleteRecurringResult)(nil),      // 22: maib.ecomm.v1.DeleteRecurringResult
+	(*CloseDayRequest)(nil),            // 23: maib.ecomm.v1.CloseDayRequest
+	(*CloseDayResult)(nil),             // 24: maib.ecomm.v1.CloseDayResult
+	(*ECommError)(nil),                 // 25: maib.ecomm.v1.ECommError
+	(*ParseError)(nil),                 // 26: maib.ecomm.v1.ParseError
+}
+var file_maib_proto_depIdxs = []int32{
+	2,  // 0: maib.ecomm.v1.RegisterTransactionRequest.transaction_type:type_name -> maib.ecomm.v1.RegisterTransactionType
+	3,  // 1: maib.ecomm.v1.RegisterRecurringRequest.transaction_type:type_name -> maib.ecomm.v1.RegisterRecurringType
+	4,  // 2: maib.ecomm.v1.RegisterOneClickRequest.transaction_type:type_name -> maib.ecomm.v1.RegisterOneClickType
+	0,  // 3: maib.ecomm.v1.TransactionStatusResult.result:type_name -> maib.ecomm.v1.Result
+	1,  // 4: maib.ecomm.v1.TransactionStatusResult.result_ps:type_name -> maib.ecomm.v1.ResultPS
+	0,  // 5: maib.ecomm.v1.ExecuteDMSResult.result:type_name -> maib.ecomm.v1.Result
+	0,  // 6: maib.ecomm.v1.ExecuteRecurringResult.result:type_name -> maib.ecomm.v1.Result
+	0,  // 7: maib.ecomm.v1.ExecuteOneClickResult.result:type_name -> maib.ecomm.v1.Result
+	0,  // 8: maib.ecomm.v1.ReverseTransactionResult.result:type_name -> maib.ecomm.v1.Result
+	0,  // 9: maib.ecomm.v1.DeleteRecurringResult.result:type_name -> maib.ecomm.v1.Result
+	0,  // 10: maib.ecomm.v1.CloseDayResult.result:type_name -> maib.ecomm.v1.Result
+	5,  // 11: maib.ecomm.v1.ECommerce.RegisterTransaction:input_type -> maib.ecomm.v1.RegisterTransactionRequest
+	7,  // 12: maib.ecomm.v1.ECommerce.RegisterRecurring:input_type -> maib.ecomm.v1.RegisterRecurringRequest
+	9,  // 13: maib.ecomm.v1.ECommerce.RegisterOneClick:input_type -> maib.ecomm.v1.RegisterOneClickRequest
+	11, // 14: maib.ecomm.v1.ECommerce.TransactionStatus:input_type -> maib.ecomm.v1.TransactionStatusRequest
+	13, // 15: maib.ecomm.v1.ECommerce.ExecuteDMS:input_type -> maib.ecomm.v1.ExecuteDMSRequest
+	15, // 16: maib.ecomm.v1.ECommerce.ExecuteRecurring:input_type -> maib.ecomm.v1.ExecuteRecurringRequest
+	17, // 17: maib.ecomm.v1.ECommerce.ExecuteOneClick:input_type -> maib.ecomm.v1.ExecuteOneClickRequest
+	19, // 18: maib.ecomm.v1.ECommerce.ReverseTransaction:input_type -> maib.ecomm.v1.ReverseTransactionRequest
+	21, // 19: maib.ecomm.v1.ECommerce.DeleteRecurring:input_type -> maib.ecomm.v1.DeleteRecurringRequest
+	23, // 20: maib.ecomm.v1.ECommerce.CloseDay:input_type -> maib.ecomm.v1.CloseDayRequest
+	6,  // 21: maib.ecomm.v1.ECommerce.RegisterTransaction:output_type -> maib.ecomm.v1.RegisterTransactionResult
+	8,  // 22: maib.ecomm.v1.ECommerce.RegisterRecurring:output_type -> maib.ecomm.v1.RegisterRecurringResult
+	10, // 23: maib.ecomm.v1.ECommerce.RegisterOneClick:output_type -> maib.ecomm.v1.RegisterOneClickResult
+	12, // 24: maib.ecomm.v1.ECommerce.TransactionStatus:output_type -> maib.ecomm.v1.TransactionStatusResult
+	14, // 25: maib.ecomm.v1.ECommerce.ExecuteDMS:output_type -> maib.ecomm.v1.ExecuteDMSResult
+	16, // 26: maib.ecomm.v1.ECommerce.ExecuteRecurring:output_type -> maib.ecomm.v1.ExecuteRecurringResult
+	18, // 27: maib.ecomm.v1.ECommerce.ExecuteOneClick:output_type -> maib.ecomm.v1.ExecuteOneClickResult
+	20, // 28: maib.ecomm.v1.ECommerce.ReverseTransaction:output_type -> maib.ecomm.v1.ReverseTransactionResult
+	22, // 29: maib.ecomm.v1.ECommerce.DeleteRecurring:output_type -> maib.ecomm.v1.DeleteRecurringResult
+	24, // 30: maib.ecomm.v1.ECommerce.CloseDay:output_type -> maib.ecomm.v1.CloseDayResult
+	21, // [21:31] is the sub-list for method output_type
+	11, // [11:21] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_maib_proto_init() }
+func file_maib_proto_init() {
+	if File_maib_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_maib_proto_rawDesc), len(file_maib_proto_rawDesc)),
+			NumEnums:      5,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_maib_proto_goTypes,
+		DependencyIndexes: file_maib_proto_depIdxs,
+		EnumInfos:         file_maib_proto_enumTypes,
+		MessageInfos:      file_maib_proto_msgTypes,
+	}.Build()
+	File_maib_proto = out.File
+	file_maib_proto_goTypes = nil
+	file_maib_proto_depIdxs = nil
+}
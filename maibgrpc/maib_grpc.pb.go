@@ -0,0 +1,483 @@
+// Protobuf API of the MAIB ECommerce commands. The messages mirror the structs
+// of the Go package github.com/NikSays/go-maib-ecomm/v2/requests: e.g.
+// ExecuteDMSRequest mirrors requests.ExecuteDMS, and ExecuteDMSResult mirrors
+// requests.ExecuteDMSResult. Each RPC sends the request through
+// maib.Client.Send.
+//
+// Failed RPCs carry a google.rpc.Status with details:
+//   - INVALID_ARGUMENT with google.rpc.BadRequest, if the request failed
+//     validation. The field violation names the field of the message.
+//   - FAILED_PRECONDITION with ECommError, if the ECommerce system rejected
+//     the request with an "error:" body or a 4xx status.
+//   - UNAVAILABLE for TransactionStatus, with ECommError if the ECommerce
+//     system returned another error, or without details if it could not be
+//     reached. TransactionStatus is read-only, so it is safe to retry.
+//   - UNKNOWN for the other RPCs in the same cases. The command may have been
+//     executed, so check it with TransactionStatus before retrying.
+//   - UNAVAILABLE for any RPC, if the request could not be recorded before
+//     sending, see maib.RecordError. Nothing was sent, so it is safe to retry.
+//   - INTERNAL with ParseError, if the response could not be parsed.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: maib.proto
+
+package maibgrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ECommerce_RegisterTransaction_FullMethodName = "/maib.ecomm.v1.ECommerce/RegisterTransaction"
+	ECommerce_RegisterRecurring_FullMethodName   = "/maib.ecomm.v1.ECommerce/RegisterRecurring"
+	ECommerce_RegisterOneClick_FullMethodName    = "/maib.ecomm.v1.ECommerce/RegisterOneClick"
+	ECommerce_TransactionStatus_FullMethodName   = "/maib.ecomm.v1.ECommerce/TransactionStatus"
+	ECommerce_ExecuteDMS_FullMethodName          = "/maib.ecomm.v1.ECommerce/ExecuteDMS"
+	ECommerce_ExecuteRecurring_FullMethodName    = "/maib.ecomm.v1.ECommerce/ExecuteRecurring"
+	ECommerce_ExecuteOneClick_FullMethodName     = "/maib.ecomm.v1.ECommerce/ExecuteOneClick"
+	ECommerce_ReverseTransaction_FullMethodName  = "/maib.ecomm.v1.ECommerce/ReverseTransaction"
+	ECommerce_DeleteRecurring_FullMethodName     = "/maib.ecomm.v1.ECommerce/DeleteRecurring"
+	ECommerce_CloseDay_FullMethodName            = "/maib.ecomm.v1.ECommerce/CloseDay"
+)
+
+// ECommerceClient is the client API for ECommerce service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ECommerceClient interface {
+	RegisterTransaction(ctx context.Context, in *RegisterTransactionRequest, opts ...grpc.CallOption) (*RegisterTransactionResult, error)
+	RegisterRecurring(ctx context.Context, in *RegisterRecurringRequest, opts ...grpc.CallOption) (*RegisterRecurringResult, error)
+	RegisterOneClick(ctx context.Context, in *RegisterOneClickRequest, opts ...grpc.CallOption) (*RegisterOneClickResult, error)
+	TransactionStatus(ctx context.Context, in *TransactionStatusRequest, opts ...grpc.CallOption) (*TransactionStatusResult, error)
+	ExecuteDMS(ctx context.Context, in *ExecuteDMSRequest, opts ...grpc.CallOption) (*ExecuteDMSResult, error)
+	ExecuteRecurring(ctx context.Context, in *ExecuteRecurringRequest, opts ...grpc.CallOption) (*ExecuteRecurringResult, error)
+	ExecuteOneClick(ctx context.Context, in *ExecuteOneClickRequest, opts ...grpc.CallOption) (*ExecuteOneClickResult, error)
+	ReverseTransaction(ctx context.Context, in *ReverseTransactionRequest, opts ...grpc.CallOption) (*ReverseTransactionResult, error)
+	DeleteRecurring(ctx context.Context, in *DeleteRecurringRequest, opts ...grpc.CallOption) (*DeleteRecurringResult, error)
+	CloseDay(ctx context.Context, in *CloseDayRequest, opts ...grpc.CallOption) (*CloseDayResult, error)
+}
+
+type eCommerceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewECommerceClient(cc grpc.ClientConnInterface) ECommerceClient {
+	return &eCommerceClient{cc}
+}
+
+func (c *eCommerceClient) RegisterTransaction(ctx context.Context, in *RegisterTransactionRequest, opts ...grpc.CallOption) (*RegisterTransactionResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterTransactionResult)
+	err := c.cc.Invoke(ctx, ECommerce_RegisterTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eCommerceClient) RegisterRecurring(ctx context.Context, in *RegisterRecurringRequest, opts ...grpc.CallOption) (*RegisterRecurringResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterRecurringResult)
+	err := c.cc.Invoke(ctx, ECommerce_RegisterRecurring_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eCommerceClient) RegisterOneClick(ctx context.Context, in *RegisterOneClickRequest, opts ...grpc.CallOption) (*RegisterOneClickResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RegisterOneClickResult)
+	err := c.cc.Invoke(ctx, ECommerce_RegisterOneClick_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eCommerceClient) TransactionStatus(ctx context.Context, in *TransactionStatusRequest, opts ...grpc.CallOption) (*TransactionStatusResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransactionStatusResult)
+	err := c.cc.Invoke(ctx, ECommerce_TransactionStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eCommerceClient) ExecuteDMS(ctx context.Context, in *ExecuteDMSRequest, opts ...grpc.CallOption) (*ExecuteDMSResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecuteDMSResult)
+	err := c.cc.Invoke(ctx, ECommerce_ExecuteDMS_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eCommerceClient) ExecuteRecurring(ctx context.Context, in *ExecuteRecurringRequest, opts ...grpc.CallOption) (*ExecuteRecurringResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecuteRecurringResult)
+	err := c.cc.Invoke(ctx, ECommerce_ExecuteRecurring_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eCommerceClient) ExecuteOneClick(ctx context.Context, in *ExecuteOneClickRequest, opts ...grpc.CallOption) (*ExecuteOneClickResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ExecuteOneClickResult)
+	err := c.cc.Invoke(ctx, ECommerce_ExecuteOneClick_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eCommerceClient) ReverseTransaction(ctx context.Context, in *ReverseTransactionRequest, opts ...grpc.CallOption) (*ReverseTransactionResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ReverseTransactionResult)
+	err := c.cc.Invoke(ctx, ECommerce_ReverseTransaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eCommerceClient) DeleteRecurring(ctx context.Context, in *DeleteRecurringRequest, opts ...grpc.CallOption) (*DeleteRecurringResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteRecurringResult)
+	err := c.cc.Invoke(ctx, ECommerce_DeleteRecurring_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *eCommerceClient) CloseDay(ctx context.Context, in *CloseDayRequest, opts ...grpc.CallOption) (*CloseDayResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CloseDayResult)
+	err := c.cc.Invoke(ctx, ECommerce_CloseDay_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ECommerceServer is the server API for ECommerce service.
+// All implementations must embed UnimplementedECommerceServer
+// for forward compatibility.
+type ECommerceServer interface {
+	RegisterTransaction(context.Context, *RegisterTransactionRequest) (*RegisterTransactionResult, error)
+	RegisterRecurring(context.Context, *RegisterRecurringRequest) (*RegisterRecurringResult, error)
+	RegisterOneClick(context.Context, *RegisterOneClickRequest) (*RegisterOneClickResult, error)
+	TransactionStatus(context.Context, *TransactionStatusRequest) (*TransactionStatusResult, error)
+	ExecuteDMS(context.Context, *ExecuteDMSRequest) (*ExecuteDMSResult, error)
+	ExecuteRecurring(context.Context, *ExecuteRecurringRequest) (*ExecuteRecurringResult, error)
+	ExecuteOneClick(context.Context, *ExecuteOneClickRequest) (*ExecuteOneClickResult, error)
+	ReverseTransaction(context.Context, *ReverseTransactionRequest) (*ReverseTransactionResult, error)
+	DeleteRecurring(context.Context, *DeleteRecurringRequest) (*DeleteRecurringResult, error)
+	CloseDay(context.Context, *CloseDayRequest) (*CloseDayResult, error)
+	mustEmbedUnimplementedECommerceServer()
+}
+
+// UnimplementedECommerceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedECommerceServer struct{}
+
+func (UnimplementedECommerceServer) RegisterTransaction(context.Context, *RegisterTransactionRequest) (*RegisterTransactionResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterTransaction not implemented")
+}
+func (UnimplementedECommerceServer) RegisterRecurring(context.Context, *RegisterRecurringRequest) (*RegisterRecurringResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterRecurring not implemented")
+}
+func (UnimplementedECommerceServer) RegisterOneClick(context.Context, *RegisterOneClickRequest) (*RegisterOneClickResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RegisterOneClick not implemented")
+}
+func (UnimplementedECommerceServer) TransactionStatus(context.Context, *TransactionStatusRequest) (*TransactionStatusResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TransactionStatus not implemented")
+}
+func (UnimplementedECommerceServer) ExecuteDMS(context.Context, *ExecuteDMSRequest) (*ExecuteDMSResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteDMS not implemented")
+}
+func (UnimplementedECommerceServer) ExecuteRecurring(context.Context, *ExecuteRecurringRequest) (*ExecuteRecurringResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteRecurring not implemented")
+}
+func (UnimplementedECommerceServer) ExecuteOneClick(context.Context, *ExecuteOneClickRequest) (*ExecuteOneClickResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ExecuteOneClick not implemented")
+}
+func (UnimplementedECommerceServer) ReverseTransaction(context.Context, *ReverseTransactionRequest) (*ReverseTransactionResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReverseTransaction not implemented")
+}
+func (UnimplementedECommerceServer) DeleteRecurring(context.Context, *DeleteRecurringRequest) (*DeleteRecurringResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteRecurring not implemented")
+}
+func (UnimplementedECommerceServer) CloseDay(context.Context, *CloseDayRequest) (*CloseDayResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloseDay not implemented")
+}
+func (UnimplementedECommerceServer) mustEmbedUnimplementedECommerceServer() {}
+func (UnimplementedECommerceServer) testEmbeddedByValue()                   {}
+
+// UnsafeECommerceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ECommerceServer will
+// result in compilation errors.
+type UnsafeECommerceServer interface {
+	mustEmbedUnimplementedECommerceServer()
+}
+
+func RegisterECommerceServer(s grpc.ServiceRegistrar, srv ECommerceServer) {
+	// If the following call pancis, it indicates UnimplementedECommerceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ECommerce_ServiceDesc, srv)
+}
+
+func _ECommerce_RegisterTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ECommerceServer).RegisterTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ECommerce_RegisterTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ECommerceServer).RegisterTransaction(ctx, req.(*RegisterTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ECommerce_RegisterRecurring_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRecurringRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ECommerceServer).RegisterRecurring(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ECommerce_RegisterRecurring_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ECommerceServer).RegisterRecurring(ctx, req.(*RegisterRecurringRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ECommerce_RegisterOneClick_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterOneClickRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ECommerceServer).RegisterOneClick(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ECommerce_RegisterOneClick_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ECommerceServer).RegisterOneClick(ctx, req.(*RegisterOneClickRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ECommerce_TransactionStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ECommerceServer).TransactionStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ECommerce_TransactionStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ECommerceServer).TransactionStatus(ctx, req.(*TransactionStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ECommerce_ExecuteDMS_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteDMSRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ECommerceServer).ExecuteDMS(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ECommerce_ExecuteDMS_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ECommerceServer).ExecuteDMS(ctx, req.(*ExecuteDMSRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ECommerce_ExecuteRecurring_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteRecurringRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ECommerceServer).ExecuteRecurring(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ECommerce_ExecuteRecurring_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ECommerceServer).ExecuteRecurring(ctx, req.(*ExecuteRecurringRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ECommerce_ExecuteOneClick_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ExecuteOneClickRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ECommerceServer).ExecuteOneClick(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ECommerce_ExecuteOneClick_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ECommerceServer).ExecuteOneClick(ctx, req.(*ExecuteOneClickRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ECommerce_ReverseTransaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReverseTransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ECommerceServer).ReverseTransaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ECommerce_ReverseTransaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ECommerceServer).ReverseTransaction(ctx, req.(*ReverseTransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ECommerce_DeleteRecurring_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRecurringRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ECommerceServer).DeleteRecurring(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ECommerce_DeleteRecurring_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ECommerceServer).DeleteRecurring(ctx, req.(*DeleteRecurringRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ECommerce_CloseDay_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CloseDayRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ECommerceServer).CloseDay(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ECommerce_CloseDay_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ECommerceServer).CloseDay(ctx, req.(*CloseDayRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ECommerce_ServiceDesc is the grpc.ServiceDesc for ECommerce service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ECommerce_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "maib.ecomm.v1.ECommerce",
+	HandlerType: (*ECommerceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RegisterTransaction",
+			Handler:    _ECommerce_RegisterTransaction_Handler,
+		},
+		{
+			MethodName: "RegisterRecurring",
+			Handler:    _ECommerce_RegisterRecurring_Handler,
+		},
+		{
+			MethodName: "RegisterOneClick",
+			Handler:    _ECommerce_RegisterOneClick_Handler,
+		},
+		{
+			MethodName: "TransactionStatus",
+			Handler:    _ECommerce_TransactionStatus_Handler,
+		},
+		{
+			MethodName: "ExecuteDMS",
+			Handler:    _ECommerce_ExecuteDMS_Handler,
+		},
+		{
+			MethodName: "ExecuteRecurring",
+			Handler:    _ECommerce_ExecuteRecurring_Handler,
+		},
+		{
+			MethodName: "ExecuteOneClick",
+			Handler:    _ECommerce_ExecuteOneClick_Handler,
+		},
+		{
+			MethodName: "ReverseTransaction",
+			Handler:    _ECommerce_ReverseTransaction_Handler,
+		},
+		{
+			MethodName: "DeleteRecurring",
+			Handler:    _ECommerce_DeleteRecurring_Handler,
+		},
+		{
+			MethodName: "CloseDay",
+			Handler:    _ECommerce_CloseDay_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "maib.proto",
+}
@@ -0,0 +1,259 @@
+/*
+Package maibgrpc serves the ECommerce commands over gRPC, so that the MAIB
+certificate can live in a single hardened service. The API is defined in
+maib.proto, and its messages mirror the structs of the requests package.
+
+[Server] implements [ECommerceServer] by sending the requests through a
+[maib.Sender], usually a *maib.Client:
+
+	server, err := maibgrpc.New(maibgrpc.Config{Sender: client})
+	// ...
+	s := grpc.NewServer(grpc.Creds(credentials), grpc.UnaryInterceptor(authenticate))
+	maibgrpc.RegisterECommerceServer(s, server)
+
+Authentication of the callers is left to gRPC credentials and interceptors.
+
+Package maibgrpc is a separate module, so that applications using the SDK
+don't depend on gRPC.
+*/
+package maibgrpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative maib.proto
+
+import (
+	"context"
+	"errors"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+// Config is the configuration required to set up a [Server].
+type Config struct {
+	// Sender used to send the requests, usually a *maib.Client. Required.
+	Sender maib.Sender
+}
+
+// Server implements [ECommerceServer]. It is safe for concurrent use.
+//
+// Must be initiated with [New].
+type Server struct {
+	UnimplementedECommerceServer
+
+	sender maib.Sender
+}
+
+var _ ECommerceServer = (*Server)(nil)
+
+// New validates the configuration and returns a *[Server].
+func New(config Config) (*Server, error) {
+	if config.Sender == nil {
+		return nil, errors.New("sender is required")
+	}
+	return &Server{sender: config.Sender}, nil
+}
+
+// send sends the request and decodes the response. Errors are converted to
+// statuses with [statusError].
+func send[Res any](ctx context.Context, s *Server, req maib.Request, decode func(map[string]any) (Res, error)) (Res, error) {
+	var result Res
+	res, err := s.sender.Send(ctx, req)
+	if err != nil {
+		return result, statusError(err, req)
+	}
+	result, err = decode(res)
+	if err != nil {
+		return result, statusError(&maib.ParseError{Err: err}, req)
+	}
+	return result, nil
+}
+
+func (s *Server) RegisterTransaction(ctx context.Context, in *RegisterTransactionRequest) (*RegisterTransactionResult, error) {
+	res, err := send(ctx, s, requests.RegisterTransaction{
+		TransactionType: requests.RegisterTransactionType(in.GetTransactionType()),
+		Amount:          int(in.GetAmount()),
+		Currency:        maib.Currency(in.GetCurrency()),
+		ClientIPAddress: in.GetClientIpAddress(),
+		Description:     in.GetDescription(),
+		Language:        maib.Language(in.GetLanguage()),
+	}, requests.DecodeResponse[requests.RegisterTransactionResult])
+	if err != nil {
+		return nil, err
+	}
+	return &RegisterTransactionResult{
+		TransactionId: res.TransactionID,
+	}, nil
+}
+
+func (s *Server) RegisterRecurring(ctx context.Context, in *RegisterRecurringRequest) (*RegisterRecurringResult, error) {
+	res, err := send(ctx, s, requests.RegisterRecurring{
+		TransactionType:   requests.RegisterRecurringType(in.GetTransactionType()),
+		Amount:            int(in.GetAmount()),
+		Currency:          maib.Currency(in.GetCurrency()),
+		ClientIPAddress:   in.GetClientIpAddress(),
+		Description:       in.GetDescription(),
+		Language:          maib.Language(in.GetLanguage()),
+		BillerClientID:    in.GetBillerClientId(),
+		PerspayeeExpiry:   in.GetPerspayeeExpiry(),
+		OverwriteExisting: in.GetOverwriteExisting(),
+		AskSaveCardData:   in.GetAskSaveCardData(),
+	}, requests.DecodeResponse[requests.RegisterRecurringResult])
+	if err != nil {
+		return nil, err
+	}
+	return &RegisterRecurringResult{
+		TransactionId: res.TransactionID,
+	}, nil
+}
+
+func (s *Server) RegisterOneClick(ctx context.Context, in *RegisterOneClickRequest) (*RegisterOneClickResult, error) {
+	res, err := send(ctx, s, requests.RegisterOneClick{
+		TransactionType:   requests.RegisterOneClickType(in.GetTransactionType()),
+		Amount:            int(in.GetAmount()),
+		Currency:          maib.Currency(in.GetCurrency()),
+		ClientIPAddress:   in.GetClientIpAddress(),
+		Description:       in.GetDescription(),
+		Language:          maib.Language(in.GetLanguage()),
+		BillerClientID:    in.GetBillerClientId(),
+		PerspayeeExpiry:   in.GetPerspayeeExpiry(),
+		OverwriteExisting: in.GetOverwriteExisting(),
+		AskSaveCardData:   in.GetAskSaveCardData(),
+	}, requests.DecodeResponse[requests.RegisterOneClickResult])
+	if err != nil {
+		return nil, err
+	}
+	return &RegisterOneClickResult{
+		TransactionId: res.TransactionID,
+	}, nil
+}
+
+func (s *Server) TransactionStatus(ctx context.Context, in *TransactionStatusRequest) (*TransactionStatusResult, error) {
+	res, err := send(ctx, s, requests.TransactionStatus{
+		TransactionID:   in.GetTransactionId(),
+		ClientIPAddress: in.GetClientIpAddress(),
+	}, requests.DecodeResponse[requests.TransactionStatusResult])
+	if err != nil {
+		return nil, err
+	}
+	return &TransactionStatusResult{
+		Result:                  resultOf(res.Result),
+		ResultPs:                resultPSOf(res.ResultPS),
+		ResultCode:              int32(res.ResultCode),
+		ThreeDSecure:            res.ThreeDSecure,
+		ThreeDSecureReason:      res.ThreeDSecureReason,
+		Rrn:                     int64(res.RRN),
+		ApprovalCode:            res.ApprovalCode,
+		CardNumber:              res.CardNumber,
+		Aav:                     res.AAV,
+		PaymentAccountReference: res.PaymentAccountReference,
+		RecurringPaymentId:      res.RecurringPaymentID,
+		RecurringPaymentExpiry:  res.RecurringPaymentExpiry,
+	}, nil
+}
+
+func (s *Server) ExecuteDMS(ctx context.Context, in *ExecuteDMSRequest) (*ExecuteDMSResult, error) {
+	res, err := send(ctx, s, requests.ExecuteDMS{
+		TransactionID:   in.GetTransactionId(),
+		Amount:          int(in.GetAmount()),
+		Currency:        maib.Currency(in.GetCurrency()),
+		ClientIPAddress: in.GetClientIpAddress(),
+		Description:     in.GetDescription(),
+	}, requests.DecodeResponse[requests.ExecuteDMSResult])
+	if err != nil {
+		return nil, err
+	}
+	return &ExecuteDMSResult{
+		Result:       resultOf(res.Result),
+		ResultCode:   int32(res.ResultCode),
+		Rrn:          int64(res.RRN),
+		ApprovalCode: res.ApprovalCode,
+		CardNumber:   res.CardNumber,
+	}, nil
+}
+
+func (s *Server) ExecuteRecurring(ctx context.Context, in *ExecuteRecurringRequest) (*ExecuteRecurringResult, error) {
+	res, err := send(ctx, s, requests.ExecuteRecurring{
+		Amount:          int(in.GetAmount()),
+		Currency:        maib.Currency(in.GetCurrency()),
+		ClientIPAddress: in.GetClientIpAddress(),
+		Description:     in.GetDescription(),
+		BillerClientID:  in.GetBillerClientId(),
+	}, requests.DecodeResponse[requests.ExecuteRecurringResult])
+	if err != nil {
+		return nil, err
+	}
+	return &ExecuteRecurringResult{
+		TransactionId: res.TransactionID,
+		Result:        resultOf(res.Result),
+		ResultCode:    int32(res.ResultCode),
+		Rrn:           int64(res.RRN),
+		ApprovalCode:  res.ApprovalCode,
+	}, nil
+}
+
+func (s *Server) ExecuteOneClick(ctx context.Context, in *ExecuteOneClickRequest) (*ExecuteOneClickResult, error) {
+	res, err := send(ctx, s, requests.ExecuteOneClick{
+		Amount:          int(in.GetAmount()),
+		Currency:        maib.Currency(in.GetCurrency()),
+		ClientIPAddress: in.GetClientIpAddress(),
+		Description:     in.GetDescription(),
+		BillerClientID:  in.GetBillerClientId(),
+	}, requests.DecodeResponse[requests.ExecuteOneClickResult])
+	if err != nil {
+		return nil, err
+	}
+	return &ExecuteOneClickResult{
+		TransactionId: res.TransactionID,
+		Result:        resultOf(res.Result),
+		ResultCode:    int32(res.ResultCode),
+		Rrn:           int64(res.RRN),
+		ApprovalCode:  res.ApprovalCode,
+	}, nil
+}
+
+func (s *Server) ReverseTransaction(ctx context.Context, in *ReverseTransactionRequest) (*ReverseTransactionResult, error) {
+	res, err := send(ctx, s, requests.ReverseTransaction{
+		TransactionID:  in.GetTransactionId(),
+		Amount:         int(in.GetAmount()),
+		SuspectedFraud: in.GetSuspectedFraud(),
+	}, requests.DecodeResponse[requests.ReverseTransactionResult])
+	if err != nil {
+		return nil, err
+	}
+	return &ReverseTransactionResult{
+		Result:     resultOf(res.Result),
+		ResultCode: int32(res.ResultCode),
+	}, nil
+}
+
+func (s *Server) DeleteRecurring(ctx context.Context, in *DeleteRecurringRequest) (*DeleteRecurringResult, error) {
+	res, err := send(ctx, s, requests.DeleteRecurring{
+		BillerClientID: in.GetBillerClientId(),
+	}, requests.DecodeResponse[requests.DeleteRecurringResult])
+	if err != nil {
+		return nil, err
+	}
+	return &DeleteRecurringResult{
+		Result: resultOf(res.Result),
+	}, nil
+}
+
+func (s *Server) CloseDay(ctx context.Context, _ *CloseDayRequest) (*CloseDayResult, error) {
+	res, err := send(ctx, s, requests.CloseDay{},
+		requests.DecodeResponse[requests.CloseDayResult])
+	if err != nil {
+		return nil, err
+	}
+	return &CloseDayResult{
+		Result:                  resultOf(res.Result),
+		ResultCode:              int32(res.ResultCode),
+		CreditTransactionNumber: int64(res.CreditTransactionNumber),
+		CreditReversalNumber:    int64(res.CreditReversalNumber),
+		DebitTransactionNumber:  int64(res.DebitTransactionNumber),
+		DebitReversalNumber:     int64(res.DebitReversalNumber),
+		CreditTransactionAmount: int64(res.CreditTransactionAmount),
+		CreditReversalAmount:    int64(res.CreditReversalAmount),
+		DebitTransactionAmount:  int64(res.DebitTransactionAmount),
+		DebitReversalAmount:     int64(res.DebitReversalAmount),
+	}, nil
+}
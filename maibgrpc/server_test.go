@@ -0,0 +1,258 @@
+package maibgrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/maibfake"
+	"github.com/NikSays/go-maib-ecomm/v2/maibtest"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+const transactionID = "abcdefghijklmnopqrstuvwxyz0="
+
+// newClient serves a Server backed by the sender over bufconn, and returns a
+// client connected to it.
+func newClient(t *testing.T, sender maib.Sender) ECommerceClient {
+	server, err := New(Config{Sender: sender})
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	RegisterECommerceServer(s, server)
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewECommerceClient(conn)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.EqualError(t, err, "sender is required")
+}
+
+func TestServer_Requests(t *testing.T) {
+	fake := maibfake.New()
+	fake.Respond(requests.RegisterOneClick{}, maibfake.Result(requests.RegisterOneClickResult{TransactionID: transactionID}))
+	fake.Respond(requests.TransactionStatus{}, maibfake.Result(requests.TransactionStatusResult{
+		Result:     maib.ResultOk,
+		ResultPS:   maib.ResultPSFinished,
+		ResultCode: 0,
+		RRN:        123456789,
+		CardNumber: "4***********1111",
+	}))
+	fake.Respond(requests.ReverseTransaction{}, maibfake.Result(requests.ReverseTransactionResult{Result: maib.ResultOk, ResultCode: 400}))
+	fake.Respond(requests.CloseDay{}, maibfake.Result(requests.CloseDayResult{Result: maib.ResultOk, DebitTransactionAmount: 1999}))
+	client := newClient(t, fake)
+	ctx := context.Background()
+
+	registered, err := client.RegisterOneClick(ctx, &RegisterOneClickRequest{
+		TransactionType: RegisterOneClickType_REGISTER_ONE_CLICK_TYPE_WITHOUT_PAYMENT,
+		Currency:        int32(maib.CurrencyMDL),
+		ClientIpAddress: "127.0.0.1",
+		Language:        string(maib.LanguageEnglish),
+		BillerClientId:  "client-1",
+		PerspayeeExpiry: "1230",
+		AskSaveCardData: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, transactionID, registered.GetTransactionId())
+	fake.AssertSentOnce(t, requests.RegisterOneClick{
+		TransactionType: requests.RegisterOneClickWithoutPayment,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageEnglish,
+		BillerClientID:  "client-1",
+		PerspayeeExpiry: "1230",
+		AskSaveCardData: true,
+	})
+
+	checked, err := client.TransactionStatus(ctx, &TransactionStatusRequest{TransactionId: transactionID, ClientIpAddress: "127.0.0.1"})
+	require.NoError(t, err)
+	assert.Equal(t, Result_RESULT_OK, checked.GetResult())
+	assert.Equal(t, ResultPS_RESULT_PS_FINISHED, checked.GetResultPs())
+	assert.Equal(t, int64(123456789), checked.GetRrn())
+	assert.Equal(t, "4***********1111", checked.GetCardNumber())
+
+	reversed, err := client.ReverseTransaction(ctx, &ReverseTransactionRequest{TransactionId: transactionID, Amount: 500})
+	require.NoError(t, err)
+	assert.Equal(t, Result_RESULT_OK, reversed.GetResult())
+	assert.Equal(t, int32(400), reversed.GetResultCode())
+	fake.AssertSentOnce(t, requests.ReverseTransaction{TransactionID: transactionID, Amount: 500})
+
+	closed, err := client.CloseDay(ctx, &CloseDayRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1999), closed.GetDebitTransactionAmount())
+}
+
+func TestServer_Errors(t *testing.T) {
+	validStatus := &TransactionStatusRequest{TransactionId: transactionID, ClientIpAddress: "127.0.0.1"}
+	cases := []struct {
+		name     string
+		request  *TransactionStatusRequest
+		response maibfake.Response
+		code     codes.Code
+		detail   any
+	}{
+		{
+			name:    "validation",
+			request: &TransactionStatusRequest{TransactionId: transactionID, ClientIpAddress: "localhost"},
+			code:    codes.InvalidArgument,
+			detail:  "client_ip_address",
+		},
+		{
+			name:     "rejected by ECommerce",
+			request:  validStatus,
+			response: maibfake.Error(&maib.ECommError{Code: 200, Body: "error: wrong trans_id"}),
+			code:     codes.FailedPrecondition,
+			detail:   &ECommError{Code: 200, Body: "error: wrong trans_id"},
+		},
+		{
+			name:     "ECommerce unavailable",
+			request:  validStatus,
+			response: maibfake.Error(&maib.ECommError{Code: 503, Body: "maintenance"}),
+			code:     codes.Unavailable,
+			detail:   &ECommError{Code: 503, Body: "maintenance"},
+		},
+		{
+			name:     "parse error",
+			request:  validStatus,
+			response: maibfake.Error(&maib.ParseError{Err: errors.New("bad line"), Body: "RESULT"}),
+			code:     codes.Internal,
+			detail:   &ParseError{Body: "RESULT"},
+		},
+		{
+			name:     "timeout",
+			request:  validStatus,
+			response: maibfake.Error(context.DeadlineExceeded),
+			code:     codes.DeadlineExceeded,
+		},
+		{
+			name:     "network",
+			request:  validStatus,
+			response: maibfake.Error(errors.New("connection refused")),
+			code:     codes.Unavailable,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := maibfake.New()
+			fake.Respond(requests.TransactionStatus{}, c.response)
+			client := newClient(t, fake)
+
+			_, err := client.TransactionStatus(context.Background(), c.request)
+			st, ok := status.FromError(err)
+			require.True(t, ok)
+			assert.Equal(t, c.code, st.Code())
+
+			switch detail := c.detail.(type) {
+			case nil:
+				assert.Empty(t, st.Details())
+			case string:
+				require.Len(t, st.Details(), 1)
+				badRequest, ok := st.Details()[0].(*errdetails.BadRequest)
+				require.True(t, ok)
+				assert.Equal(t, detail, badRequest.GetFieldViolations()[0].GetField())
+				assert.NotEmpty(t, badRequest.GetFieldViolations()[0].GetDescription())
+			default:
+				require.Len(t, st.Details(), 1)
+				assert.EqualExportedValues(t, detail, st.Details()[0])
+			}
+		})
+	}
+}
+
+func TestServer_ErrorsNotReadOnly(t *testing.T) {
+	cases := []struct {
+		name     string
+		response maibfake.Response
+		code     codes.Code
+	}{
+		{
+			name:     "rejected by ECommerce",
+			response: maibfake.Error(&maib.ECommError{Code: 400, Body: "bad request"}),
+			code:     codes.FailedPrecondition,
+		},
+		{
+			name:     "ECommerce unavailable",
+			response: maibfake.Error(&maib.ECommError{Code: 502, Body: "Bad Gateway"}),
+			code:     codes.Unknown,
+		},
+		{
+			name:     "network",
+			response: maibfake.Error(errors.New("connection reset")),
+			code:     codes.Unknown,
+		},
+		{
+			name:     "not recorded",
+			response: maibfake.Error(&maib.RecordError{Err: errors.New("disk full")}),
+			code:     codes.Unavailable,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := maibfake.New()
+			fake.Respond(requests.ReverseTransaction{}, c.response)
+			client := newClient(t, fake)
+
+			_, err := client.ReverseTransaction(context.Background(), &ReverseTransactionRequest{TransactionId: transactionID, Amount: 500})
+			assert.Equal(t, c.code, status.Code(err))
+		})
+	}
+}
+
+func TestServer_Client(t *testing.T) {
+	mock := maibtest.NewServer(t, maibtest.Config{})
+	client := newClient(t, mock.Client(t))
+	ctx := context.Background()
+
+	registered, err := client.RegisterTransaction(ctx, &RegisterTransactionRequest{
+		TransactionType: RegisterTransactionType_REGISTER_TRANSACTION_TYPE_DMS,
+		Amount:          1999,
+		Currency:        int32(maib.CurrencyMDL),
+		ClientIpAddress: "127.0.0.1",
+		Language:        string(maib.LanguageRomanian),
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.Approve(registered.GetTransactionId()))
+
+	executed, err := client.ExecuteDMS(ctx, &ExecuteDMSRequest{
+		TransactionId:   registered.GetTransactionId(),
+		Amount:          1999,
+		Currency:        int32(maib.CurrencyMDL),
+		ClientIpAddress: "127.0.0.1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Result_RESULT_OK, executed.GetResult())
+	assert.NotZero(t, executed.GetRrn())
+}
+
+func TestSnakeCase(t *testing.T) {
+	assert.Equal(t, "client_ip_address", snakeCase("ClientIPAddress"))
+	assert.Equal(t, "three_d_secure", snakeCase("ThreeDSecure"))
+	assert.Equal(t, "rrn", snakeCase("RRN"))
+}
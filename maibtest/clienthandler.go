@@ -0,0 +1,226 @@
+package maibtest
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// Test cards accepted by the client handler. Any other card number is declined
+// with result code 111.
+const (
+	// CardApproved is approved. Same as [DefaultCardNumber].
+	CardApproved = DefaultCardNumber
+
+	// CardInsufficientFunds is declined with result code 116.
+	CardInsufficientFunds = "4000000000000002"
+
+	// CardExpired is declined with result code 101.
+	CardExpired = "4000000000000069"
+
+	// CardLost is declined with result code 208.
+	CardLost = "4000000000009987"
+
+	// CardStolen is declined with result code 209.
+	CardStolen = "4000000000009979"
+)
+
+// Outcomes of 3-D Secure authentication, reported in the 3DSECURE field.
+// Payments that are not AUTHENTICATED, ATTEMPTED or NOTPARTICIPATED are declined
+// with result code 100.
+const (
+	ThreeDSecureAuthenticated   = "AUTHENTICATED"
+	ThreeDSecureAttempted       = "ATTEMPTED"
+	ThreeDSecureNotParticipated = "NOTPARTICIPATED"
+	ThreeDSecureDeclined        = "DECLINED"
+	ThreeDSecureUnavailable     = "UNAVAILABLE"
+	ThreeDSecureFailed          = "FAILED"
+)
+
+const (
+	unknownCardResultCode  = 111
+	threeDSecureResultCode = 100
+)
+
+// testCardResultCodes maps the declined test cards to their result codes.
+var testCardResultCodes = map[string]int{
+	CardInsufficientFunds: 116,
+	CardExpired:           101,
+	CardLost:              208,
+	CardStolen:            209,
+}
+
+// Payment is what the payer enters on the client handler page.
+type Payment struct {
+	// Card number. Default is [DefaultCardNumber].
+	CardNumber string
+
+	// Card expiry in the format "MMYY". Default is [DefaultCardExpiry].
+	CardExpiry string
+
+	// Outcome of 3-D Secure authentication. Default is
+	// [ThreeDSecureAuthenticated].
+	ThreeDSecure string
+}
+
+// payment resolves the outcome of the payer's input.
+func (p Payment) payment() payment {
+	out := payment{
+		cardNumber:   p.CardNumber,
+		cardExpiry:   p.CardExpiry,
+		threeDSecure: p.ThreeDSecure,
+		result:       maib.ResultOk,
+	}
+	if out.cardNumber == "" {
+		out.cardNumber = DefaultCardNumber
+	}
+	if out.cardExpiry == "" {
+		out.cardExpiry = DefaultCardExpiry
+	}
+	if out.threeDSecure == "" {
+		out.threeDSecure = ThreeDSecureAuthenticated
+	}
+
+	switch out.threeDSecure {
+	case ThreeDSecureAuthenticated, ThreeDSecureAttempted, ThreeDSecureNotParticipated:
+	default:
+		out.result = maib.ResultDeclined
+		out.resultCode = threeDSecureResultCode
+		return out
+	}
+
+	if out.cardNumber == CardApproved {
+		return out
+	}
+	out.result = maib.ResultDeclined
+	code, ok := testCardResultCodes[out.cardNumber]
+	if !ok {
+		code = unknownCardResultCode
+	}
+	out.resultCode = code
+	return out
+}
+
+// Pay acts as the payer submitting the client handler page, and returns the
+// completed transaction. It is equivalent to submitting the page from a
+// browser, except for the redirect.
+func (s *Server) Pay(transactionID string, p Payment) (Transaction, error) {
+	err := s.complete(transactionID, p.payment())
+	if err != nil {
+		return Transaction{}, err
+	}
+	t, _ := s.Transaction(transactionID)
+	return t, nil
+}
+
+// ReturnURL returns the URL where the payer is redirected after completing the
+// transaction, and false if Config.ReturnURL is not set.
+func (s *Server) ReturnURL(transactionID string) (string, bool) {
+	if s.returnURL == nil {
+		return "", false
+	}
+	u := *s.returnURL
+	query := u.Query()
+	query.Set("trans_id", transactionID)
+	u.RawQuery = query.Encode()
+	return u.String(), true
+}
+
+// pageData is rendered by clientHandlerPage.
+type pageData struct {
+	Transaction   Transaction
+	CardNumbers   []string
+	ThreeDSecures []string
+	Error         string
+}
+
+var clientHandlerPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head><title>maibtest client handler</title></head>
+<body>
+<h1>maibtest client handler</h1>
+{{with .Transaction}}<p>Transaction {{.ID}}: {{.Amount}} {{.Currency}} {{.Description}}</p>
+<p>Result: {{.Result}} {{.ResultPS}}</p>{{end}}
+{{if .Error}}<p>Error: {{.Error}}</p>{{end}}
+{{if eq .Transaction.Result "CREATED"}}<form method="post">
+<input type="hidden" name="trans_id" value="{{.Transaction.ID}}">
+<label>Card number <input name="card_number" list="cards" value="` + DefaultCardNumber + `"></label>
+<datalist id="cards">{{range .CardNumbers}}<option>{{.}}</option>{{end}}</datalist>
+<label>Expiry <input name="card_expiry" value="` + DefaultCardExpiry + `"></label>
+<label>3-D Secure <select name="three_d_secure">{{range .ThreeDSecures}}<option>{{.}}</option>{{end}}</select></label>
+<button type="submit">Pay</button>
+</form>{{end}}
+</body>
+</html>
+`))
+
+// handleClient serves the client handler page. GET shows the transaction and
+// the payment form, POST completes the transaction and redirects the payer to
+// the return URL.
+func (s *Server) handleClient(w http.ResponseWriter, r *http.Request) {
+	err := r.ParseForm()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	id := r.Form.Get("trans_id")
+	t, ok := s.Transaction(id)
+	if !ok {
+		http.Error(w, "transaction not found", http.StatusNotFound)
+		return
+	}
+
+	data := pageData{
+		Transaction: t,
+		CardNumbers: []string{
+			CardApproved, CardInsufficientFunds, CardExpired, CardLost, CardStolen,
+		},
+		ThreeDSecures: []string{
+			ThreeDSecureAuthenticated, ThreeDSecureAttempted, ThreeDSecureNotParticipated,
+			ThreeDSecureDeclined, ThreeDSecureUnavailable, ThreeDSecureFailed,
+		},
+	}
+
+	status := http.StatusOK
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		t, err = s.Pay(id, Payment{
+			CardNumber:   r.Form.Get("card_number"),
+			CardExpiry:   r.Form.Get("card_expiry"),
+			ThreeDSecure: r.Form.Get("three_d_secure"),
+		})
+		if err != nil {
+			status = http.StatusConflict
+			data.Error = err.Error()
+			break
+		}
+		if returnURL, ok := s.ReturnURL(id); ok {
+			http.Redirect(w, r, returnURL, http.StatusSeeOther)
+			return
+		}
+		data.Transaction = t
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = clientHandlerPage.Execute(w, data)
+}
+
+// parseReturnURL parses Config.ReturnURL.
+func parseReturnURL(returnURL string) (*url.URL, error) {
+	if returnURL == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(returnURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse return URL: %w", err)
+	}
+	return u, nil
+}
@@ -0,0 +1,118 @@
+package maibtest
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+func register(t *testing.T, client *maib.Client) string {
+	t.Helper()
+	return send(t, client, requests.RegisterTransaction{
+		Amount:          1000,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageRomanian,
+		Description:     "Order 42",
+	}, requests.DecodeResponse[requests.RegisterTransactionResult]).TransactionID
+}
+
+func TestServer_Pay(t *testing.T) {
+	cases := []struct {
+		name       string
+		payment    Payment
+		result     maib.ResultEnum
+		resultCode int
+	}{
+		{"Default", Payment{}, maib.ResultOk, 0},
+		{"Attempted", Payment{CardNumber: CardApproved, ThreeDSecure: ThreeDSecureAttempted}, maib.ResultOk, 0},
+		{"InsufficientFunds", Payment{CardNumber: CardInsufficientFunds}, maib.ResultDeclined, 116},
+		{"Expired", Payment{CardNumber: CardExpired}, maib.ResultDeclined, 101},
+		{"Lost", Payment{CardNumber: CardLost}, maib.ResultDeclined, 208},
+		{"Stolen", Payment{CardNumber: CardStolen}, maib.ResultDeclined, 209},
+		{"UnknownCard", Payment{CardNumber: "5555555555554444"}, maib.ResultDeclined, 111},
+		{"ThreeDSecureFailed", Payment{ThreeDSecure: ThreeDSecureFailed}, maib.ResultDeclined, 100},
+	}
+
+	server := NewServer(t, Config{})
+	client := server.Client(t)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			id := register(t, client)
+			paid, err := server.Pay(id, c.payment)
+			assert.Nil(t, err)
+			assert.Equal(t, c.result, paid.Result)
+
+			res := status(t, client, id)
+			assert.Equal(t, c.result, res.Result)
+			assert.Equal(t, c.resultCode, res.ResultCode)
+			assert.Equal(t, paid.ThreeDSecure, res.ThreeDSecure)
+		})
+	}
+}
+
+func TestServer_ClientHandler(t *testing.T) {
+	server := NewServer(t, Config{ReturnURL: "https://shop.example/return?order=42"})
+	client := server.Client(t)
+	id := register(t, client)
+	pageURL := server.ClientHandlerURL + "?trans_id=" + url.QueryEscape(id)
+
+	res, err := http.Get(pageURL)
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, string(body), "1000 498 Order 42")
+	assert.Contains(t, string(body), "<form")
+
+	noRedirect := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err = noRedirect.PostForm(server.ClientHandlerURL, url.Values{
+		"trans_id":       {id},
+		"card_number":    {CardInsufficientFunds},
+		"three_d_secure": {ThreeDSecureAuthenticated},
+	})
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusSeeOther, res.StatusCode)
+	location, err := url.Parse(res.Header.Get("Location"))
+	assert.Nil(t, err)
+	assert.Equal(t, "shop.example", location.Host)
+	assert.Equal(t, "42", location.Query().Get("order"))
+	assert.Equal(t, id, location.Query().Get("trans_id"))
+	assert.Equal(t, 116, status(t, client, id).ResultCode)
+
+	// Completed transactions can't be paid again.
+	res, err = noRedirect.PostForm(server.ClientHandlerURL, url.Values{"trans_id": {id}})
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusConflict, res.StatusCode)
+
+	res, err = http.Get(server.ClientHandlerURL + "?trans_id=unknown")
+	assert.Nil(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestServer_ClientHandlerWithoutReturnURL(t *testing.T) {
+	server := NewServer(t, Config{})
+	id := register(t, server.Client(t))
+
+	res, err := http.PostForm(server.ClientHandlerURL, url.Values{"trans_id": {id}})
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Contains(t, string(body), "Result: OK FINISHED")
+	assert.False(t, strings.Contains(string(body), "<form"))
+}
@@ -0,0 +1,481 @@
+package maibtest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// Commands of the merchant handler.
+const (
+	registerSMSCommand            = "v"
+	registerDMSCommand            = "a"
+	transactionStatusCommand      = "c"
+	executeDMSCommand             = "t"
+	reverseTransactionCommand     = "r"
+	registerRecurringSMSCommand   = "z"
+	registerRecurringDMSCommand   = "d"
+	registerWithoutPaymentCommand = "p"
+	executeRecurringCommand       = "e"
+	executeOneClickCommand        = "f"
+	deleteRecurringCommand        = "x"
+	closeDayCommand               = "b"
+)
+
+const (
+	reverseTransactionResultCode = 400
+	closeDayResultCode           = 500
+	transactionIDLength          = 28
+	maxBillerClientIDLength      = 49
+)
+
+// response is an ordered list of "KEY: value" lines.
+type response [][2]string
+
+func (r response) add(key string, value any) response {
+	return append(r, [2]string{key, fmt.Sprint(value)})
+}
+
+func (r response) String() string {
+	var b strings.Builder
+	for _, field := range r {
+		b.WriteString(field[0])
+		b.WriteString(": ")
+		b.WriteString(field[1])
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// commandError is written as an "error:" body.
+type commandError string
+
+func (e commandError) Error() string {
+	return string(e)
+}
+
+func (s *Server) handleMerchant(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	err := r.ParseForm()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if outcome, ok := s.requestOutcome(r.Form); ok {
+		switch outcome.kind {
+		case outcomeHang:
+			select {
+			case <-r.Context().Done():
+				return
+			case <-s.clock.After(outcome.delay):
+			}
+		case outcomeMalformed:
+			_, _ = w.Write([]byte(malformedBody))
+			return
+		case outcomeStatus:
+			w.WriteHeader(outcome.status)
+			return
+		case outcomeErrorBody:
+			_, _ = fmt.Fprintf(w, "error: %s", outcome.message)
+			return
+		}
+	}
+
+	res, err := s.execute(r.Form)
+	if cmdErr := commandError(""); errors.As(err, &cmdErr) {
+		_, _ = fmt.Fprintf(w, "error: %s", cmdErr)
+		return
+	}
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	_, _ = w.Write([]byte(res.String()))
+}
+
+// execute runs the command from the form.
+func (s *Server) execute(form url.Values) (response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch command := form.Get("command"); command {
+	case registerSMSCommand, registerDMSCommand:
+		return s.register(command, form)
+	case registerRecurringSMSCommand, registerRecurringDMSCommand, registerWithoutPaymentCommand:
+		return s.registerRecurring(command, form)
+	case transactionStatusCommand:
+		return s.status(form)
+	case executeDMSCommand:
+		return s.executeDMS(form)
+	case reverseTransactionCommand:
+		return s.reverse(form)
+	case executeRecurringCommand:
+		return s.executeRecurring(form)
+	case executeOneClickCommand:
+		return s.executeOneClick(form)
+	case deleteRecurringCommand:
+		return s.deleteRecurring(form)
+	case closeDayCommand:
+		return s.closeDay()
+	default:
+		return nil, commandError("unknown command")
+	}
+}
+
+// newTransaction parses the common fields of a registration.
+func (s *Server) newTransaction(command string, form url.Values, amountRequired bool) (*Transaction, error) {
+	amount, err := parseAmount(form, amountRequired)
+	if err != nil {
+		return nil, err
+	}
+	currency, err := parseCurrency(form)
+	if err != nil {
+		return nil, err
+	}
+	ip, err := parseClientIPAddress(form)
+	if err != nil {
+		return nil, err
+	}
+	language := form.Get("language")
+	if command != executeRecurringCommand && command != executeOneClickCommand && language == "" {
+		return nil, commandError("wrong language")
+	}
+
+	t := &Transaction{
+		ID:              newTransactionID(),
+		Command:         command,
+		Amount:          amount,
+		Currency:        currency,
+		ClientIPAddress: ip,
+		Description:     form.Get("description"),
+		Language:        maib.Language(language),
+		Result:          maib.ResultCreated,
+		ResultPS:        maib.ResultPSActive,
+		CreatedAt:       s.clock.Now(),
+	}
+	return t, nil
+}
+
+// add stores a new transaction.
+func (s *Server) add(t *Transaction) {
+	s.transactions[t.ID] = t
+	s.order = append(s.order, t.ID)
+}
+
+func (s *Server) register(command string, form url.Values) (response, error) {
+	t, err := s.newTransaction(command, form, true)
+	if err != nil {
+		return nil, err
+	}
+	s.add(t)
+	return response{}.add("TRANSACTION_ID", t.ID), nil
+}
+
+func (s *Server) registerRecurring(command string, form url.Values) (response, error) {
+	t, err := s.newTransaction(command, form, command != registerWithoutPaymentCommand)
+	if err != nil {
+		return nil, err
+	}
+	oneClick := form.Get("oneclick") == "Y"
+	if oneClick && command == registerRecurringDMSCommand {
+		return nil, commandError("oneclick is not supported for DMS")
+	}
+	perspayeeExpiry := form.Get("perspayee_expiry")
+	if len(perspayeeExpiry) != 4 {
+		return nil, commandError("wrong perspayee_expiry")
+	}
+
+	billerClientID := form.Get("biller_client_id")
+	if len(billerClientID) > maxBillerClientIDLength {
+		return nil, commandError("wrong biller_client_id")
+	}
+	if billerClientID == "" {
+		billerClientID = t.ID
+	}
+	_, exists := s.registrations[billerClientID]
+	if exists && form.Get("perspayee_overwrite") != "1" {
+		return nil, commandError("biller_client_id already exists")
+	}
+
+	t.BillerClientID = billerClientID
+	s.add(t)
+	s.pending[t.ID] = &Registration{
+		BillerClientID:  billerClientID,
+		OneClick:        oneClick,
+		TransactionID:   t.ID,
+		PerspayeeExpiry: perspayeeExpiry,
+	}
+	return response{}.add("TRANSACTION_ID", t.ID), nil
+}
+
+func (s *Server) status(form url.Values) (response, error) {
+	t, err := s.transaction(form)
+	if err != nil {
+		return nil, err
+	}
+
+	res := response{}.
+		add("RESULT", t.Result).
+		add("RESULT_PS", t.ResultPS)
+	if t.Result == maib.ResultCreated || t.Result == maib.ResultPending {
+		return res, nil
+	}
+
+	res = res.
+		add("RESULT_CODE", fmt.Sprintf("%03d", t.ResultCode)).
+		add("3DSECURE", t.ThreeDSecure)
+	if t.Result != maib.ResultOk && t.Result != maib.ResultReversed {
+		return res, nil
+	}
+	res = res.
+		add("RRN", t.RRN).
+		add("APPROVAL_CODE", t.ApprovalCode).
+		add("CARD_NUMBER", t.CardNumber)
+	if registration, ok := s.registrations[t.BillerClientID]; ok && registration.TransactionID == t.ID {
+		res = res.
+			add("RECC_PMNT_ID", registration.BillerClientID).
+			add("RECC_PMNT_EXPIRY", registration.CardExpiry)
+	}
+	return res, nil
+}
+
+func (s *Server) executeDMS(form url.Values) (response, error) {
+	t, err := s.transaction(form)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(form, true)
+	if err != nil {
+		return nil, err
+	}
+	currency, err := parseCurrency(form)
+	if err != nil {
+		return nil, err
+	}
+	_, err = parseClientIPAddress(form)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !isDMS(t):
+		return nil, commandError("not a DMS transaction")
+	case t.Result != maib.ResultOk:
+		return nil, commandError("transaction is not authorized")
+	case t.ExecutedAmount > 0:
+		return nil, commandError("transaction is already executed")
+	case currency != t.Currency:
+		return nil, commandError("wrong currency")
+	case amount > t.Amount:
+		return nil, commandError("amount exceeds authorization")
+	}
+
+	t.ExecutedAmount = amount
+	s.day.debitTransactions++
+	s.day.debitAmount += amount
+	return response{}.
+		add("RESULT", maib.ResultOk).
+		add("RESULT_CODE", "000").
+		add("RRN", t.RRN).
+		add("APPROVAL_CODE", t.ApprovalCode).
+		add("CARD_NUMBER", t.CardNumber), nil
+}
+
+func (s *Server) reverse(form url.Values) (response, error) {
+	t, err := s.transaction(form)
+	if err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(form, true)
+	if err != nil {
+		return nil, err
+	}
+	if t.Result != maib.ResultOk {
+		return nil, commandError("transaction can't be reversed")
+	}
+
+	// A DMS authorization that was not executed can only be reversed in full.
+	authorization := isDMS(t) && t.ExecutedAmount == 0
+	reversible := t.Amount
+	if isDMS(t) && !authorization {
+		reversible = t.ExecutedAmount
+	}
+	remaining := reversible - t.ReversedAmount
+
+	fullOnly := authorization || form.Get("suspected_fraud") == "yes"
+	switch {
+	case amount > remaining:
+		return nil, commandError("amount exceeds the reversible amount")
+	case fullOnly && amount != reversible:
+		return nil, commandError("only full reversal is allowed")
+	}
+
+	t.ReversedAmount += amount
+	if t.ReversedAmount == reversible {
+		t.Result = maib.ResultReversed
+		t.ResultPS = maib.ResultPSReturned
+	}
+	if !authorization {
+		s.day.debitReversals++
+		s.day.debitReversalTotal += amount
+	}
+	return response{}.
+		add("RESULT", maib.ResultOk).
+		add("RESULT_CODE", reverseTransactionResultCode), nil
+}
+
+func (s *Server) executeRecurring(form url.Values) (response, error) {
+	registration, err := s.registration(form, false)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.newTransaction(executeRecurringCommand, form, true)
+	if err != nil {
+		return nil, err
+	}
+
+	t.BillerClientID = registration.BillerClientID
+	p := s.paymentOutcome(t, payment{
+		cardNumber:   registration.CardNumber,
+		result:       maib.ResultOk,
+		threeDSecure: ThreeDSecureNotParticipated,
+	})
+	t.Result = p.result
+	t.ResultPS = resultPS(p.result)
+	t.ResultCode = p.resultCode
+	t.ThreeDSecure = p.threeDSecure
+	t.CardNumber = registration.CardNumber
+	s.add(t)
+
+	res := response{}.
+		add("TRANSACTION_ID", t.ID).
+		add("RESULT", t.Result).
+		add("RESULT_CODE", fmt.Sprintf("%03d", t.ResultCode))
+	if t.Result != maib.ResultOk {
+		return res, nil
+	}
+
+	t.RRN = randomRRN()
+	t.ApprovalCode = randomApprovalCode()
+	s.day.debitTransactions++
+	s.day.debitAmount += t.Amount
+	return res.
+		add("RRN", t.RRN).
+		add("APPROVAL_CODE", t.ApprovalCode), nil
+}
+
+func (s *Server) executeOneClick(form url.Values) (response, error) {
+	if form.Get("oneclick") != "Y" {
+		return nil, commandError("oneclick is required")
+	}
+	registration, err := s.registration(form, true)
+	if err != nil {
+		return nil, err
+	}
+	t, err := s.newTransaction(executeOneClickCommand, form, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// The payer confirms the transaction on the client handler.
+	t.BillerClientID = registration.BillerClientID
+	s.add(t)
+	return response{}.add("TRANSACTION_ID", t.ID), nil
+}
+
+func (s *Server) deleteRecurring(form url.Values) (response, error) {
+	billerClientID := form.Get("biller_client_id")
+	if billerClientID == "" {
+		return nil, commandError("wrong biller_client_id")
+	}
+	if _, ok := s.registrations[billerClientID]; !ok {
+		return response{}.add("RESULT", maib.ResultFailed), nil
+	}
+	delete(s.registrations, billerClientID)
+	return response{}.add("RESULT", maib.ResultOk), nil
+}
+
+func (s *Server) closeDay() (response, error) {
+	day := s.day
+	s.day = dayTotals{}
+	return response{}.
+		add("RESULT", maib.ResultOk).
+		add("RESULT_CODE", closeDayResultCode).
+		add("FLD_074", 0).
+		add("FLD_075", 0).
+		add("FLD_076", day.debitTransactions).
+		add("FLD_077", day.debitReversals).
+		add("FLD_086", 0).
+		add("FLD_087", 0).
+		add("FLD_088", day.debitAmount).
+		add("FLD_089", day.debitReversalTotal), nil
+}
+
+// transaction returns the transaction referenced by trans_id.
+func (s *Server) transaction(form url.Values) (*Transaction, error) {
+	id := form.Get("trans_id")
+	if len(id) != transactionIDLength {
+		return nil, commandError("wrong trans_id")
+	}
+	t, ok := s.transactions[id]
+	if !ok {
+		return nil, commandError("transaction not found")
+	}
+	return t, nil
+}
+
+// registration returns the active registration referenced by
+// biller_client_id.
+func (s *Server) registration(form url.Values, oneClick bool) (*Registration, error) {
+	registration, ok := s.registrations[form.Get("biller_client_id")]
+	if !ok || !registration.Active {
+		return nil, commandError("biller_client_id not found")
+	}
+	if registration.OneClick != oneClick {
+		return nil, commandError("wrong registration type")
+	}
+	return registration, nil
+}
+
+// isDMS reports whether the transaction is a DMS authorization.
+func isDMS(t *Transaction) bool {
+	return t.Command == registerDMSCommand || t.Command == registerRecurringDMSCommand
+}
+
+func parseAmount(form url.Values, required bool) (int, error) {
+	value := form.Get("amount")
+	if value == "" && !required {
+		return 0, nil
+	}
+	amount, err := strconv.Atoi(value)
+	if err != nil || amount < 0 || (required && amount == 0) {
+		return 0, commandError("wrong amount")
+	}
+	return amount, nil
+}
+
+func parseCurrency(form url.Values) (maib.Currency, error) {
+	currency, err := strconv.Atoi(form.Get("currency"))
+	if err != nil || currency < 0 || currency > 999 {
+		return 0, commandError("wrong currency")
+	}
+	return maib.Currency(currency), nil
+}
+
+func parseClientIPAddress(form url.Values) (string, error) {
+	ip := form.Get("client_ip_addr")
+	if net.ParseIP(ip) == nil {
+		return "", commandError("wrong client_ip_addr")
+	}
+	return ip, nil
+}
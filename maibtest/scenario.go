@@ -0,0 +1,362 @@
+package maibtest
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// outcomeKind tells where an [Outcome] is applied.
+type outcomeKind int
+
+const (
+	// Applied when the payer completes the transaction.
+	outcomeApprove outcomeKind = iota + 1
+	outcomeDecline
+
+	// Applied to merchant handler requests.
+	outcomeHang
+	outcomeMalformed
+	outcomeStatus
+	outcomeErrorBody
+)
+
+// malformedBody can't be parsed by maib.Client.Send, which returns
+// *maib.ParseError.
+const malformedBody = "RESULT: OK\nRESULT_CODE: ???\n"
+
+// Outcome is a scripted reaction of the emulator. Create it with [Approve],
+// [Decline], [Hang], [MalformedBody], [HTTPStatus] or [ErrorBody].
+type Outcome struct {
+	kind         outcomeKind
+	resultCode   int
+	threeDSecure string
+	delay        time.Duration
+	status       int
+	message      string
+}
+
+// Approve completes the transaction with RESULT OK, whatever card the payer
+// uses.
+func Approve() Outcome {
+	return Outcome{kind: outcomeApprove}
+}
+
+// Decline completes the transaction with RESULT DECLINED and the result code,
+// whatever card the payer uses.
+func Decline(resultCode int) Outcome {
+	return Outcome{kind: outcomeDecline, resultCode: resultCode}
+}
+
+// WithThreeDSecure sets the 3DSECURE value reported for the transaction
+// completed by [Approve] or [Decline].
+func (o Outcome) WithThreeDSecure(value string) Outcome {
+	o.threeDSecure = value
+	return o
+}
+
+// Hang delays the response of the merchant handler. The command is executed
+// after the delay, if the client is still waiting. The delay is measured by
+// Config.Clock.
+func Hang(d time.Duration) Outcome {
+	return Outcome{kind: outcomeHang, delay: d}
+}
+
+// MalformedBody responds with a body that doesn't follow the "KEY: value"
+// format, without executing the command. maib.Client.Send returns
+// *maib.ParseError.
+func MalformedBody() Outcome {
+	return Outcome{kind: outcomeMalformed}
+}
+
+// HTTPStatus responds with the HTTP status code, without executing the
+// command. maib.Client.Send returns *maib.ECommError.
+func HTTPStatus(code int) Outcome {
+	return Outcome{kind: outcomeStatus, status: code}
+}
+
+// ErrorBody responds with 200 and an "error: message" body, without executing
+// the command. maib.Client.Send returns *maib.ECommError.
+func ErrorBody(message string) Outcome {
+	return Outcome{kind: outcomeErrorBody, message: message}
+}
+
+// String returns the outcome in the format accepted by [ParseScenarios].
+func (o Outcome) String() string {
+	var s string
+	switch o.kind {
+	case outcomeApprove:
+		s = "approve"
+	case outcomeDecline:
+		s = fmt.Sprintf("decline %d", o.resultCode)
+	case outcomeHang:
+		s = fmt.Sprintf("hang %s", o.delay)
+	case outcomeMalformed:
+		s = "malformed"
+	case outcomeStatus:
+		s = fmt.Sprintf("http %d", o.status)
+	case outcomeErrorBody:
+		s = fmt.Sprintf("error %s", o.message)
+	}
+	if o.threeDSecure != "" {
+		s += " 3ds " + o.threeDSecure
+	}
+	return s
+}
+
+// isPayment reports whether the outcome is applied when the payer completes
+// the transaction, rather than to merchant handler requests.
+func (o Outcome) isPayment() bool {
+	return o.kind == outcomeApprove || o.kind == outcomeDecline
+}
+
+// Scenario applies the outcome to the requests and payments that match every
+// non-empty condition.
+//
+// [Approve] and [Decline] are applied when the payer completes a matching
+// transaction, on the client handler, with [Server.Pay], [Server.Approve] or
+// [Server.Decline], and to ExecuteRecurring, which is completed immediately.
+//
+// The other outcomes are applied to matching merchant handler requests. A
+// request matches on its own fields, and on the fields of the transaction it
+// references with trans_id, so a scenario matching a card number also applies
+// to TransactionStatus of the transactions paid with that card.
+type Scenario struct {
+	// Command, like "c" for TransactionStatus. For payments, the command that
+	// registered the transaction.
+	Command string
+
+	// Card number used by the payer.
+	CardNumber string
+
+	// Transaction amount in minor units.
+	Amount int
+
+	// ID of the recurring or oneClick payment.
+	BillerClientID string
+
+	Outcome Outcome
+}
+
+// subject is what scenarios are matched against.
+type subject struct {
+	command        string
+	cardNumber     string
+	amount         int
+	billerClientID string
+}
+
+// matches reports whether the subject satisfies every condition.
+func (sc Scenario) matches(sub subject) bool {
+	switch {
+	case sc.Command != "" && sc.Command != sub.command:
+		return false
+	case sc.CardNumber != "" && sc.CardNumber != sub.cardNumber && maskCardNumber(sc.CardNumber) != sub.cardNumber:
+		return false
+	case sc.Amount != 0 && sc.Amount != sub.amount:
+		return false
+	case sc.BillerClientID != "" && sc.BillerClientID != sub.billerClientID:
+		return false
+	}
+	return true
+}
+
+// String returns the scenario in the format accepted by [ParseScenarios].
+func (sc Scenario) String() string {
+	var conditions []string
+	if sc.Command != "" {
+		conditions = append(conditions, "command "+sc.Command)
+	}
+	if sc.CardNumber != "" {
+		conditions = append(conditions, "card "+sc.CardNumber)
+	}
+	if sc.Amount != 0 {
+		conditions = append(conditions, "amount "+strconv.Itoa(sc.Amount))
+	}
+	if sc.BillerClientID != "" {
+		conditions = append(conditions, "biller "+sc.BillerClientID)
+	}
+	return strings.Join(conditions, " ") + " => " + sc.Outcome.String()
+}
+
+// ParseScenarios parses scenarios, one per line, in the format
+//
+//	<conditions> => <outcome>
+//
+// Conditions are "command C", "card NUMBER", "amount N" and "biller ID". An
+// empty list of conditions matches everything. Outcomes are "approve",
+// "decline CODE", "hang DURATION", "malformed", "http CODE" and
+// "error MESSAGE". Approve and decline may be followed by "3ds VALUE". Empty
+// lines and lines starting with "#" are ignored. For example:
+//
+//	card 4000000000000002 => decline 116 3ds ATTEMPTED
+//	amount 666 => hang 30s
+//	command c biller client-7 => http 500
+//	amount 13 => error limit exceeded
+func ParseScenarios(text string) ([]Scenario, error) {
+	var scenarios []Scenario
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		scenario, err := parseScenario(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios, nil
+}
+
+func parseScenario(line string) (Scenario, error) {
+	conditions, outcome, ok := strings.Cut(line, "=>")
+	if !ok {
+		return Scenario{}, fmt.Errorf("missing \"=>\" in %q", line)
+	}
+
+	var scenario Scenario
+	fields := strings.Fields(conditions)
+	if len(fields)%2 != 0 {
+		return Scenario{}, fmt.Errorf("condition without value in %q", line)
+	}
+	for i := 0; i < len(fields); i += 2 {
+		key, value := fields[i], fields[i+1]
+		switch key {
+		case "command":
+			scenario.Command = value
+		case "card":
+			scenario.CardNumber = value
+		case "amount":
+			amount, err := strconv.Atoi(value)
+			if err != nil {
+				return Scenario{}, fmt.Errorf("parse amount: %w", err)
+			}
+			scenario.Amount = amount
+		case "biller":
+			scenario.BillerClientID = value
+		default:
+			return Scenario{}, fmt.Errorf("unknown condition %q", key)
+		}
+	}
+
+	var err error
+	scenario.Outcome, err = parseOutcome(strings.TrimSpace(outcome))
+	if err != nil {
+		return Scenario{}, err
+	}
+	return scenario, nil
+}
+
+func parseOutcome(s string) (Outcome, error) {
+	name, arg, _ := strings.Cut(s, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "approve", "decline":
+		var threeDSecure string
+		if rest, value, ok := strings.Cut(arg, "3ds "); ok {
+			arg, threeDSecure = strings.TrimSpace(rest), strings.TrimSpace(value)
+		}
+		outcome := Approve()
+		if name == "decline" {
+			code, err := strconv.Atoi(arg)
+			if err != nil {
+				return Outcome{}, fmt.Errorf("parse result code: %w", err)
+			}
+			outcome = Decline(code)
+		} else if arg != "" {
+			return Outcome{}, fmt.Errorf("unexpected %q after approve", arg)
+		}
+		return outcome.WithThreeDSecure(threeDSecure), nil
+	case "hang":
+		d, err := time.ParseDuration(arg)
+		if err != nil {
+			return Outcome{}, fmt.Errorf("parse duration: %w", err)
+		}
+		return Hang(d), nil
+	case "malformed":
+		return MalformedBody(), nil
+	case "http":
+		code, err := strconv.Atoi(arg)
+		if err != nil {
+			return Outcome{}, fmt.Errorf("parse status code: %w", err)
+		}
+		return HTTPStatus(code), nil
+	case "error":
+		return ErrorBody(arg), nil
+	default:
+		return Outcome{}, fmt.Errorf("unknown outcome %q", name)
+	}
+}
+
+// AddScenarios appends scenarios to the ones from Config.Scenarios. The first
+// matching scenario wins.
+func (s *Server) AddScenarios(scenarios ...Scenario) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios = append(s.scenarios, scenarios...)
+}
+
+// outcome returns the first matching outcome of the kind. Must be called with
+// the lock held.
+func (s *Server) outcome(sub subject, payment bool) (Outcome, bool) {
+	for _, scenario := range s.scenarios {
+		if scenario.Outcome.isPayment() == payment && scenario.matches(sub) {
+			return scenario.Outcome, true
+		}
+	}
+	return Outcome{}, false
+}
+
+// paymentOutcome applies a matching [Approve] or [Decline] to the payment of
+// the transaction. Must be called with the lock held.
+func (s *Server) paymentOutcome(t *Transaction, p payment) payment {
+	outcome, ok := s.outcome(subject{
+		command:        t.Command,
+		cardNumber:     p.cardNumber,
+		amount:         t.Amount,
+		billerClientID: t.BillerClientID,
+	}, true)
+	if !ok {
+		return p
+	}
+
+	p.result = maib.ResultOk
+	if outcome.kind == outcomeDecline {
+		p.result = maib.ResultDeclined
+	}
+	p.resultCode = outcome.resultCode
+	if outcome.threeDSecure != "" {
+		p.threeDSecure = outcome.threeDSecure
+	}
+	return p
+}
+
+// requestOutcome returns the first matching outcome for the merchant handler
+// request.
+func (s *Server) requestOutcome(form url.Values) (Outcome, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	amount, _ := strconv.Atoi(form.Get("amount"))
+	sub := subject{
+		command:        form.Get("command"),
+		amount:         amount,
+		billerClientID: form.Get("biller_client_id"),
+	}
+	if t, ok := s.transactions[form.Get("trans_id")]; ok {
+		sub.cardNumber = t.CardNumber
+		if sub.amount == 0 {
+			sub.amount = t.Amount
+		}
+		if sub.billerClientID == "" {
+			sub.billerClientID = t.BillerClientID
+		}
+	}
+	return s.outcome(sub, false)
+}
@@ -0,0 +1,179 @@
+package maibtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+func TestParseScenarios(t *testing.T) {
+	scenarios, err := ParseScenarios(`
+		# Declines
+		card 4000000000000002 => decline 116 3ds ATTEMPTED
+		amount 100 => approve
+
+		amount 666 => hang 30s
+		command c biller client-7 => http 500
+		=> malformed
+		amount 13 => error limit exceeded
+	`)
+	assert.Nil(t, err)
+	assert.Equal(t, []Scenario{
+		{CardNumber: "4000000000000002", Outcome: Decline(116).WithThreeDSecure("ATTEMPTED")},
+		{Amount: 100, Outcome: Approve()},
+		{Amount: 666, Outcome: Hang(30 * time.Second)},
+		{Command: "c", BillerClientID: "client-7", Outcome: HTTPStatus(500)},
+		{Outcome: MalformedBody()},
+		{Amount: 13, Outcome: ErrorBody("limit exceeded")},
+	}, scenarios)
+
+	for _, scenario := range scenarios {
+		parsed, err := ParseScenarios(scenario.String())
+		assert.Nil(t, err)
+		assert.Equal(t, []Scenario{scenario}, parsed)
+	}
+}
+
+func TestParseScenarios_Errors(t *testing.T) {
+	cases := []string{
+		"amount 13",
+		"amount => approve",
+		"amount x => approve",
+		"color red => approve",
+		"=> decline",
+		"=> approve 116",
+		"=> hang forever",
+		"=> http teapot",
+		"=> explode",
+	}
+	for _, c := range cases {
+		t.Run(c, func(t *testing.T) {
+			_, err := ParseScenarios("\n" + c)
+			assert.ErrorContains(t, err, "line 2")
+		})
+	}
+}
+
+func TestScenario_Payment(t *testing.T) {
+	server := NewServer(t, Config{
+		Scenarios: []Scenario{
+			{CardNumber: CardApproved, Amount: 1313, Outcome: Decline(121)},
+			{CardNumber: CardExpired, Outcome: Approve().WithThreeDSecure(ThreeDSecureAttempted)},
+			{BillerClientID: "client-1", Amount: 500, Outcome: Decline(116)},
+		},
+	})
+	client := server.Client(t)
+
+	registered := send(t, client, requests.RegisterTransaction{
+		Amount:          1313,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageRomanian,
+	}, requests.DecodeResponse[requests.RegisterTransactionResult])
+	assert.Nil(t, server.Approve(registered.TransactionID))
+	res := status(t, client, registered.TransactionID)
+	assert.Equal(t, maib.ResultDeclined, res.Result)
+	assert.Equal(t, 121, res.ResultCode)
+
+	id := register(t, client)
+	paid, err := server.Pay(id, Payment{CardNumber: CardExpired})
+	assert.Nil(t, err)
+	assert.Equal(t, maib.ResultOk, paid.Result)
+	assert.Equal(t, ThreeDSecureAttempted, status(t, client, id).ThreeDSecure)
+
+	recurring := send(t, client, requests.RegisterRecurring{
+		TransactionType: requests.RegisterRecurringWithoutPayment,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageRomanian,
+		BillerClientID:  "client-1",
+		PerspayeeExpiry: "1230",
+	}, requests.DecodeResponse[requests.RegisterRecurringResult])
+	assert.Nil(t, server.Approve(recurring.TransactionID))
+
+	execute := requests.ExecuteRecurring{
+		Amount:          500,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		BillerClientID:  "client-1",
+	}
+	executed := send(t, client, execute, requests.DecodeResponse[requests.ExecuteRecurringResult])
+	assert.Equal(t, maib.ResultDeclined, executed.Result)
+	assert.Equal(t, 116, executed.ResultCode)
+	assert.Zero(t, executed.RRN)
+
+	execute.Amount = 501
+	executed = send(t, client, execute, requests.DecodeResponse[requests.ExecuteRecurringResult])
+	assert.Equal(t, maib.ResultOk, executed.Result)
+}
+
+func TestScenario_Errors(t *testing.T) {
+	server := NewServer(t, Config{})
+	client := server.Client(t)
+	id := register(t, client)
+	assert.Nil(t, server.Approve(id))
+
+	scenarios, err := ParseScenarios(`
+		command c card 4111111111111111 => http 500
+		command r amount 7 => malformed
+		command b => error day is closed
+	`)
+	assert.Nil(t, err)
+	server.AddScenarios(scenarios...)
+
+	// Matched by the card of the referenced transaction.
+	_, err = client.Send(ctx, requests.TransactionStatus{TransactionID: id, ClientIPAddress: "127.0.0.1"})
+	eCommErr := &maib.ECommError{}
+	assert.ErrorAs(t, err, &eCommErr)
+	assert.Equal(t, 500, eCommErr.Code)
+
+	_, err = client.Send(ctx, requests.ReverseTransaction{TransactionID: id, Amount: 7})
+	assert.ErrorAs(t, err, new(*maib.ParseError))
+	transaction, _ := server.Transaction(id)
+	assert.Zero(t, transaction.ReversedAmount)
+
+	_, err = client.Send(ctx, requests.CloseDay{})
+	assert.ErrorAs(t, err, &eCommErr)
+	assert.Equal(t, 200, eCommErr.Code)
+	assert.Equal(t, "error: day is closed", eCommErr.Body)
+}
+
+func TestScenario_Hang(t *testing.T) {
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	server := NewServer(t, Config{
+		Clock:     fake,
+		Scenarios: []Scenario{{Command: "v", Amount: 666, Outcome: Hang(time.Minute)}},
+	})
+	client := server.Client(t)
+	register := requests.RegisterTransaction{
+		Amount:          666,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageRomanian,
+	}
+
+	// The client gives up before the emulator responds.
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err := client.Send(timeoutCtx, register)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	// The command is executed once the delay passes.
+	done := make(chan error)
+	go func() {
+		_, err := client.Send(ctx, register)
+		done <- err
+	}()
+	// The abandoned request is still waiting on the clock.
+	fake.BlockUntil(2)
+	fake.Advance(time.Minute)
+	assert.Nil(t, <-done)
+	assert.Len(t, server.Transactions(), 1)
+}
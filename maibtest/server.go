@@ -0,0 +1,385 @@
+/*
+Package maibtest provides an in-process emulator of the MAIB ECommerce merchant
+handler for integration tests.
+
+[NewServer] starts a stateful HTTPS server with mutual TLS, backed by a freshly
+generated [testpki.CA], and [Server.Client] returns a *maib.Client that trusts it. The
+emulator implements the commands v, a, c, t, r, z, d, p, e, f, x and b: it
+issues realistic transaction IDs, moves transactions through RESULT and
+RESULT_PS states, keeps recurring and oneClick registrations keyed by
+biller_client_id, and reports close-day totals.
+
+Registered transactions stay CREATED until the payer completes them on the
+client handler page at [Server.ClientHandlerURL], from a browser or
+programmatically with [Server.Pay]. The page accepts the test cards, like
+[CardInsufficientFunds], and 3-D Secure outcomes, then redirects the payer to
+Config.ReturnURL. [Server.Approve] and [Server.Decline] are shortcuts for the
+common outcomes.
+
+Declines, timeouts and broken responses are scripted with [Scenario], matched
+by test card number, amount or biller_client_id, and listed in
+Config.Scenarios or parsed from text with [ParseScenarios].
+*/
+package maibtest
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/testpki"
+)
+
+const (
+	// Passphrase of the generated client certificate.
+	Passphrase = "maibtest"
+
+	// DefaultCardNumber is the card used by [Server.Approve].
+	DefaultCardNumber = "4111111111111111"
+
+	// DefaultCardExpiry is the expiry of the card used by [Server.Approve].
+	DefaultCardExpiry = "1230"
+)
+
+// Config is the configuration of a [Server].
+type Config struct {
+	// Source of time for transaction timestamps. Default is [clock.Real].
+	Clock clock.Clock
+
+	// URL where the client handler redirects the payer, with the trans_id query
+	// parameter. Optional. If empty, the client handler shows the result.
+	ReturnURL string
+
+	// Scripted outcomes, see [Scenario]. Optional.
+	Scenarios []Scenario
+}
+
+// Transaction is the emulator's view of a transaction.
+type Transaction struct {
+	// ID of the transaction. 28 symbols in base64.
+	ID string
+
+	// Command that created the transaction.
+	Command string
+
+	Amount          int
+	Currency        maib.Currency
+	ClientIPAddress string
+	Description     string
+	Language        maib.Language
+
+	Result       maib.ResultEnum
+	ResultPS     maib.ResultPSEnum
+	ResultCode   int
+	ThreeDSecure string
+	RRN          int
+	ApprovalCode string
+
+	// Masked card number. Set when the payer completes the transaction.
+	CardNumber string
+
+	// Recurring or oneClick payment, if the transaction registers or executes
+	// one.
+	BillerClientID string
+
+	// Amount captured with ExecuteDMS.
+	ExecutedAmount int
+
+	// Amount returned with ReverseTransaction.
+	ReversedAmount int
+
+	CreatedAt time.Time
+}
+
+// Registration is a recurring or oneClick payment.
+type Registration struct {
+	BillerClientID string
+	OneClick       bool
+
+	// Transaction that registered the card.
+	TransactionID string
+
+	// Validity limit of the regular payment in the format "MMYY".
+	PerspayeeExpiry string
+
+	// Expiry of the saved card in the format "MMYY".
+	CardExpiry string
+
+	// Masked number of the saved card.
+	CardNumber string
+
+	// Whether the registering transaction was completed by the payer.
+	Active bool
+}
+
+// Server is the emulated merchant handler. It is safe for concurrent use.
+//
+// Must be initiated with [NewServer].
+type Server struct {
+	// Merchant handler endpoint.
+	URL string
+
+	// Client handler page, where the payer is redirected with the trans_id query
+	// parameter. Served over plain HTTP, without client authentication.
+	ClientHandlerURL string
+
+	clock        clock.Clock
+	returnURL    *url.URL
+	server       *httptest.Server
+	clientServer *httptest.Server
+	pfxPath      string
+	ca           *testpki.CA
+
+	mu            sync.Mutex
+	transactions  map[string]*Transaction
+	order         []string
+	registrations map[string]*Registration
+	// Registrations waiting for the payer, keyed by transaction ID.
+	pending   map[string]*Registration
+	day       dayTotals
+	scenarios []Scenario
+}
+
+// dayTotals are the business day totals reported by close day.
+type dayTotals struct {
+	debitTransactions  int
+	debitAmount        int
+	debitReversals     int
+	debitReversalTotal int
+}
+
+// NewServer starts the emulator. It is closed when the test ends.
+func NewServer(tb testing.TB, config Config) *Server {
+	tb.Helper()
+
+	returnURL, err := parseReturnURL(config.ReturnURL)
+	if err != nil {
+		tb.Fatalf("maibtest: %s", err)
+	}
+	ca, err := testpki.NewCA(testpki.Options{CommonName: "maibtest CA"})
+	if err != nil {
+		tb.Fatalf("maibtest: generate CA: %s", err)
+	}
+	serverCert, err := ca.Server(testpki.Options{CommonName: "maibtest server"})
+	if err != nil {
+		tb.Fatalf("maibtest: generate server certificate: %s", err)
+	}
+	pfxPath := ca.ClientPFXFile(tb, Passphrase, testpki.Options{CommonName: "maibtest merchant"})
+
+	s := &Server{
+		clock:         config.Clock,
+		returnURL:     returnURL,
+		pfxPath:       pfxPath,
+		ca:            ca,
+		transactions:  make(map[string]*Transaction),
+		registrations: make(map[string]*Registration),
+		pending:       make(map[string]*Registration),
+		scenarios:     append([]Scenario(nil), config.Scenarios...),
+	}
+	if s.clock == nil {
+		s.clock = clock.Real
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ecomm/MerchantHandler", s.handleMerchant)
+	s.server = httptest.NewUnstartedServer(mux)
+	s.server.TLS = ca.ServerTLSConfig(serverCert)
+	s.server.StartTLS()
+	s.URL = s.server.URL + "/ecomm/MerchantHandler"
+
+	clientMux := http.NewServeMux()
+	clientMux.HandleFunc("/ecomm/ClientHandler", s.handleClient)
+	s.clientServer = httptest.NewServer(clientMux)
+	s.ClientHandlerURL = s.clientServer.URL + "/ecomm/ClientHandler"
+
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Close shuts down the emulator.
+func (s *Server) Close() {
+	s.server.Close()
+	s.clientServer.Close()
+}
+
+// Config returns the configuration of a client that trusts the emulator and
+// authenticates with the generated certificate.
+func (s *Server) Config() maib.Config {
+	return maib.Config{
+		PFXPath:                 s.pfxPath,
+		Passphrase:              Passphrase,
+		MerchantHandlerEndpoint: s.URL,
+		RootCAs:                 s.ca.Pool(),
+	}
+}
+
+// CA returns the CA that issued the server certificate and the client
+// certificate. Only clients with certificates issued by it are accepted.
+func (s *Server) CA() *testpki.CA {
+	return s.ca
+}
+
+// Client returns a new client configured with [Server.Config].
+func (s *Server) Client(tb testing.TB) *maib.Client {
+	tb.Helper()
+	client, err := maib.NewClient(s.Config())
+	if err != nil {
+		tb.Fatalf("maibtest: create client: %s", err)
+	}
+	return client
+}
+
+// Transaction returns a copy of the transaction, and whether it exists.
+func (s *Server) Transaction(id string) (Transaction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.transactions[id]
+	if !ok {
+		return Transaction{}, false
+	}
+	return *t, true
+}
+
+// Transactions returns a copy of every transaction, ordered by creation.
+func (s *Server) Transactions() []Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transactions := make([]Transaction, 0, len(s.order))
+	for _, id := range s.order {
+		transactions = append(transactions, *s.transactions[id])
+	}
+	return transactions
+}
+
+// Registration returns a copy of the recurring or oneClick payment, and
+// whether it exists.
+func (s *Server) Registration(billerClientID string) (Registration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.registrations[billerClientID]
+	if !ok {
+		return Registration{}, false
+	}
+	return *r, true
+}
+
+// Approve acts as the payer completing the transaction with
+// [DefaultCardNumber], or with the saved card for oneClick executions.
+func (s *Server) Approve(transactionID string) error {
+	return s.complete(transactionID, Payment{}.payment())
+}
+
+// Decline acts as the payer whose card was declined with the result code.
+func (s *Server) Decline(transactionID string, resultCode int) error {
+	return s.complete(transactionID, payment{
+		cardNumber:   DefaultCardNumber,
+		cardExpiry:   DefaultCardExpiry,
+		result:       maib.ResultDeclined,
+		resultCode:   resultCode,
+		threeDSecure: ThreeDSecureAuthenticated,
+	})
+}
+
+// payment is the outcome of the payer's interaction with the client handler.
+type payment struct {
+	cardNumber   string
+	cardExpiry   string
+	result       maib.ResultEnum
+	resultCode   int
+	threeDSecure string
+}
+
+// complete finishes a CREATED transaction with the payment.
+func (s *Server) complete(transactionID string, p payment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.transactions[transactionID]
+	if !ok {
+		return fmt.Errorf("maibtest: transaction %s not found", transactionID)
+	}
+	if t.Result != maib.ResultCreated && t.Result != maib.ResultPending {
+		return fmt.Errorf("maibtest: transaction %s is %s", transactionID, t.Result)
+	}
+
+	if registration := s.registrations[t.BillerClientID]; t.Command == executeOneClickCommand && registration != nil {
+		p.cardNumber = registration.CardNumber
+	}
+	p = s.paymentOutcome(t, p)
+
+	t.Result = p.result
+	t.ResultPS = resultPS(p.result)
+	t.ResultCode = p.resultCode
+	t.ThreeDSecure = p.threeDSecure
+	t.CardNumber = maskCardNumber(p.cardNumber)
+	if p.result != maib.ResultOk {
+		return nil
+	}
+
+	t.RRN = randomRRN()
+	t.ApprovalCode = randomApprovalCode()
+	if t.Amount > 0 && !isDMS(t) {
+		s.day.debitTransactions++
+		s.day.debitAmount += t.Amount
+	}
+	if registration, ok := s.pending[t.ID]; ok {
+		// An overwritten registration is replaced only when the new card is saved.
+		delete(s.pending, t.ID)
+		registration.Active = true
+		registration.CardNumber = t.CardNumber
+		registration.CardExpiry = p.cardExpiry
+		s.registrations[registration.BillerClientID] = registration
+	}
+	return nil
+}
+
+// resultPS returns the Payment Server interpretation of the result.
+func resultPS(result maib.ResultEnum) maib.ResultPSEnum {
+	switch result {
+	case maib.ResultCreated, maib.ResultPending:
+		return maib.ResultPSActive
+	case maib.ResultOk:
+		return maib.ResultPSFinished
+	case maib.ResultReversed, maib.ResultAutoReversed:
+		return maib.ResultPSReturned
+	default:
+		return maib.ResultPSCancelled
+	}
+}
+
+// newTransactionID returns 28 random base64 characters.
+func newTransactionID() string {
+	b := make([]byte, 21)
+	_, _ = rand.Read(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
+
+// randomRRN returns a random 12 digit retrieval reference number.
+func randomRRN() int {
+	n, _ := rand.Int(rand.Reader, big.NewInt(900_000_000_000))
+	return int(n.Int64()) + 100_000_000_000
+}
+
+// randomApprovalCode returns a random 6 digit approval code.
+func randomApprovalCode() string {
+	n, _ := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	return fmt.Sprintf("%06d", n.Int64())
+}
+
+// maskCardNumber keeps the first and last 4 digits of the card number.
+func maskCardNumber(number string) string {
+	if len(number) <= 8 {
+		return number
+	}
+	return number[:4] + strings.Repeat("*", len(number)-8) + number[len(number)-4:]
+}
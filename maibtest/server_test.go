@@ -0,0 +1,284 @@
+package maibtest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+	"github.com/NikSays/go-maib-ecomm/v2/testpki"
+)
+
+var ctx = context.Background()
+
+func send[ResultType any](t *testing.T, client *maib.Client, req maib.Request, decode func(map[string]any) (ResultType, error)) ResultType {
+	t.Helper()
+	res, err := client.Send(ctx, req)
+	assert.Nil(t, err)
+	result, err := decode(res)
+	assert.Nil(t, err)
+	return result
+}
+
+func status(t *testing.T, client *maib.Client, id string) requests.TransactionStatusResult {
+	t.Helper()
+	return send(t, client, requests.TransactionStatus{
+		TransactionID:   id,
+		ClientIPAddress: "127.0.0.1",
+	}, requests.DecodeResponse[requests.TransactionStatusResult])
+}
+
+func TestServer_SMS(t *testing.T) {
+	server := NewServer(t, Config{})
+	client := server.Client(t)
+
+	registered := send(t, client, requests.RegisterTransaction{
+		Amount:          1000,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageRomanian,
+	}, requests.DecodeResponse[requests.RegisterTransactionResult])
+	assert.Len(t, registered.TransactionID, 28)
+
+	created := status(t, client, registered.TransactionID)
+	assert.Equal(t, maib.ResultCreated, created.Result)
+	assert.Equal(t, maib.ResultPSActive, created.ResultPS)
+
+	assert.Nil(t, server.Approve(registered.TransactionID))
+	assert.Error(t, server.Approve(registered.TransactionID))
+
+	approved := status(t, client, registered.TransactionID)
+	assert.Equal(t, maib.ResultOk, approved.Result)
+	assert.Equal(t, maib.ResultPSFinished, approved.ResultPS)
+	assert.Equal(t, "4111********1111", approved.CardNumber)
+	assert.NotZero(t, approved.RRN)
+	assert.Len(t, approved.ApprovalCode, 6)
+
+	// Partial, then full reversal.
+	reversed := send(t, client, requests.ReverseTransaction{
+		TransactionID: registered.TransactionID,
+		Amount:        400,
+	}, requests.DecodeResponse[requests.ReverseTransactionResult])
+	assert.Equal(t, maib.ResultOk, reversed.Result)
+	assert.Equal(t, maib.ResultOk, status(t, client, registered.TransactionID).Result)
+
+	_, err := client.Send(ctx, requests.ReverseTransaction{
+		TransactionID: registered.TransactionID,
+		Amount:        700,
+	})
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+
+	send(t, client, requests.ReverseTransaction{
+		TransactionID: registered.TransactionID,
+		Amount:        600,
+	}, requests.DecodeResponse[requests.ReverseTransactionResult])
+	final := status(t, client, registered.TransactionID)
+	assert.Equal(t, maib.ResultReversed, final.Result)
+	assert.Equal(t, maib.ResultPSReturned, final.ResultPS)
+
+	closed := send(t, client, requests.CloseDay{}, requests.DecodeResponse[requests.CloseDayResult])
+	assert.Equal(t, maib.ResultOk, closed.Result)
+	assert.Equal(t, 500, closed.ResultCode)
+	assert.Equal(t, 1, closed.DebitTransactionNumber)
+	assert.Equal(t, 1000, closed.DebitTransactionAmount)
+	assert.Equal(t, 2, closed.DebitReversalNumber)
+	assert.Equal(t, 1000, closed.DebitReversalAmount)
+
+	// Totals are reset after closing the day.
+	closed = send(t, client, requests.CloseDay{}, requests.DecodeResponse[requests.CloseDayResult])
+	assert.Equal(t, 0, closed.DebitTransactionNumber)
+}
+
+func TestServer_Declined(t *testing.T) {
+	server := NewServer(t, Config{})
+	client := server.Client(t)
+
+	registered := send(t, client, requests.RegisterTransaction{
+		Amount:          1000,
+		Currency:        maib.CurrencyEUR,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageEnglish,
+	}, requests.DecodeResponse[requests.RegisterTransactionResult])
+	assert.Nil(t, server.Decline(registered.TransactionID, 116))
+
+	declined := status(t, client, registered.TransactionID)
+	assert.Equal(t, maib.ResultDeclined, declined.Result)
+	assert.Equal(t, maib.ResultPSCancelled, declined.ResultPS)
+	assert.Equal(t, 116, declined.ResultCode)
+	assert.Zero(t, declined.RRN)
+
+	_, err := client.Send(ctx, requests.ReverseTransaction{
+		TransactionID: registered.TransactionID,
+		Amount:        1000,
+	})
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+}
+
+func TestServer_DMS(t *testing.T) {
+	server := NewServer(t, Config{})
+	client := server.Client(t)
+
+	registered := send(t, client, requests.RegisterTransaction{
+		TransactionType: requests.RegisterTransactionDMS,
+		Amount:          2000,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageRomanian,
+	}, requests.DecodeResponse[requests.RegisterTransactionResult])
+
+	execute := requests.ExecuteDMS{
+		TransactionID:   registered.TransactionID,
+		Amount:          1500,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+	}
+
+	// Not authorized yet.
+	_, err := client.Send(ctx, execute)
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+
+	assert.Nil(t, server.Approve(registered.TransactionID))
+
+	// Authorizations can only be reversed in full.
+	_, err = client.Send(ctx, requests.ReverseTransaction{TransactionID: registered.TransactionID, Amount: 100})
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+
+	// Capture more than authorized.
+	_, err = client.Send(ctx, requests.ExecuteDMS{
+		TransactionID:   registered.TransactionID,
+		Amount:          2001,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+	})
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+
+	executed := send(t, client, execute, requests.DecodeResponse[requests.ExecuteDMSResult])
+	assert.Equal(t, maib.ResultOk, executed.Result)
+	assert.NotZero(t, executed.RRN)
+
+	// Double capture.
+	_, err = client.Send(ctx, execute)
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+
+	transaction, ok := server.Transaction(registered.TransactionID)
+	assert.True(t, ok)
+	assert.Equal(t, 1500, transaction.ExecutedAmount)
+
+	closed := send(t, client, requests.CloseDay{}, requests.DecodeResponse[requests.CloseDayResult])
+	assert.Equal(t, 1, closed.DebitTransactionNumber)
+	assert.Equal(t, 1500, closed.DebitTransactionAmount)
+}
+
+func TestServer_Recurring(t *testing.T) {
+	server := NewServer(t, Config{})
+	client := server.Client(t)
+
+	register := requests.RegisterRecurring{
+		TransactionType: requests.RegisterRecurringWithoutPayment,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageRomanian,
+		BillerClientID:  "client-1",
+		PerspayeeExpiry: "1230",
+	}
+	registered := send(t, client, register, requests.DecodeResponse[requests.RegisterRecurringResult])
+
+	execute := requests.ExecuteRecurring{
+		Amount:          999,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		BillerClientID:  "client-1",
+	}
+
+	// The card is saved only after the payer completes the registration.
+	_, err := client.Send(ctx, execute)
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+	assert.Nil(t, server.Approve(registered.TransactionID))
+
+	saved := status(t, client, registered.TransactionID)
+	assert.Equal(t, "client-1", saved.RecurringPaymentID)
+	assert.Equal(t, DefaultCardExpiry, saved.RecurringPaymentExpiry)
+
+	executed := send(t, client, execute, requests.DecodeResponse[requests.ExecuteRecurringResult])
+	assert.Equal(t, maib.ResultOk, executed.Result)
+	assert.Len(t, executed.TransactionID, 28)
+	assert.Equal(t, maib.ResultOk, status(t, client, executed.TransactionID).Result)
+
+	// Same ID without overwrite.
+	_, err = client.Send(ctx, register)
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+	register.OverwriteExisting = true
+	send(t, client, register, requests.DecodeResponse[requests.RegisterRecurringResult])
+
+	deleted := send(t, client, requests.DeleteRecurring{BillerClientID: "client-1"}, requests.DecodeResponse[requests.DeleteRecurringResult])
+	assert.Equal(t, maib.ResultOk, deleted.Result)
+	_, ok := server.Registration("client-1")
+	assert.False(t, ok)
+
+	deleted = send(t, client, requests.DeleteRecurring{BillerClientID: "client-1"}, requests.DecodeResponse[requests.DeleteRecurringResult])
+	assert.Equal(t, maib.ResultFailed, deleted.Result)
+}
+
+func TestServer_OneClick(t *testing.T) {
+	server := NewServer(t, Config{})
+	client := server.Client(t)
+
+	registered := send(t, client, requests.RegisterOneClick{
+		Amount:          100,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Language:        maib.LanguageRomanian,
+		BillerClientID:  "client-2",
+		PerspayeeExpiry: "1230",
+	}, requests.DecodeResponse[requests.RegisterOneClickResult])
+	assert.Nil(t, server.Approve(registered.TransactionID))
+
+	registration, ok := server.Registration("client-2")
+	assert.True(t, ok)
+	assert.True(t, registration.OneClick)
+	assert.True(t, registration.Active)
+
+	// OneClick registrations can't be used for recurring payments.
+	_, err := client.Send(ctx, requests.ExecuteRecurring{
+		Amount:          100,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		BillerClientID:  "client-2",
+	})
+	assert.ErrorAs(t, err, new(*maib.ECommError))
+
+	executed := send(t, client, requests.ExecuteOneClick{
+		Amount:          250,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		BillerClientID:  "client-2",
+	}, requests.DecodeResponse[requests.ExecuteOneClickResult])
+	assert.Equal(t, maib.ResultCreated, status(t, client, executed.TransactionID).Result)
+
+	assert.Nil(t, server.Approve(executed.TransactionID))
+	assert.Equal(t, maib.ResultOk, status(t, client, executed.TransactionID).Result)
+	assert.Len(t, server.Transactions(), 2)
+}
+
+func TestServer_Errors(t *testing.T) {
+	server := NewServer(t, Config{})
+	client := server.Client(t)
+
+	_, err := client.Send(ctx, requests.TransactionStatus{
+		TransactionID:   "abcdefghijklmnopqrstuvwxyz1=",
+		ClientIPAddress: "127.0.0.1",
+	})
+	eCommErr := &maib.ECommError{}
+	assert.ErrorAs(t, err, &eCommErr)
+	assert.Equal(t, "error: transaction not found", eCommErr.Body)
+
+	// The server requires a client certificate issued by its CA.
+	config := server.Config()
+	config.PFXPath = server.CA().ClientPFXFile(t, Passphrase, testpki.Options{WrongCA: true})
+	foreign, err := maib.NewClient(config)
+	assert.Nil(t, err)
+	_, err = foreign.Send(ctx, requests.CloseDay{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,310 @@
+package maib
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNoMerchant is returned by [MultiClient.Send] when neither the request nor
+// the context names a merchant.
+var ErrNoMerchant = errors.New("maib: no merchant key in the request or the context")
+
+// UnknownMerchantError is returned by [MultiClient.Send] when the merchant has
+// no profile.
+type UnknownMerchantError struct {
+	// Key of the merchant.
+	Merchant string
+}
+
+func (e *UnknownMerchantError) Error() string {
+	return fmt.Sprintf("maib: unknown merchant %q", e.Merchant)
+}
+
+type merchantKey struct{}
+
+// WithMerchant returns a context that routes the requests sent by
+// [MultiClient.Send] to the merchant with the key. A [MerchantRequest]
+// overrides it.
+func WithMerchant(ctx context.Context, merchant string) context.Context {
+	return context.WithValue(ctx, merchantKey{}, merchant)
+}
+
+// MerchantFrom returns the merchant key of the context, set by [WithMerchant].
+func MerchantFrom(ctx context.Context) (string, bool) {
+	merchant, ok := ctx.Value(merchantKey{}).(string)
+	return merchant, ok && merchant != ""
+}
+
+// MerchantRequest routes the wrapped [Request] to the merchant with the key,
+// when sent with [MultiClient.Send].
+type MerchantRequest struct {
+	// Key of the merchant.
+	Merchant string
+
+	// The request to send.
+	Request Request
+}
+
+func (r MerchantRequest) Values() (url.Values, error) {
+	return r.Request.Values()
+}
+
+// MultiConfig is the configuration required to set up a [MultiClient].
+type MultiConfig struct {
+	// Merchant profiles by merchant key, e.g. the name of the legal entity or
+	// the terminal ID. Optional, profiles may be added later with
+	// [MultiClient.Add].
+	Merchants map[string]Config
+}
+
+// MerchantStats are the metrics of one merchant of a [MultiClient].
+type MerchantStats struct {
+	// Key of the merchant.
+	Merchant string
+
+	// Number of requests sent.
+	Requests int
+
+	// Number of requests that returned an error.
+	Failures int
+
+	// When the last request was sent.
+	LastRequestAt time.Time
+
+	// Error returned by the last failed request.
+	LastError error
+
+	// Time after which the client certificate of the merchant is no longer
+	// valid.
+	CertificateExpiry time.Time
+}
+
+// MultiClient holds a [Client] for each merchant profile, and routes every
+// request to one of them. It is safe for concurrent use.
+//
+// Profiles with the same certificate and root CAs share the connection pool,
+// e.g. when one certificate serves several merchant handler endpoints.
+// Profiles with a WrapTransport never share it.
+//
+// Must be initiated with [NewMultiClient].
+type MultiClient struct {
+	mu        sync.RWMutex
+	merchants map[string]*merchant
+	pools     map[poolKey]*pool
+	now       func() time.Time
+}
+
+var _ Sender = (*MultiClient)(nil)
+
+type merchant struct {
+	client *Client
+	pool   poolKey
+	stats  *merchantStats
+}
+
+type merchantStats struct {
+	mu            sync.Mutex
+	requests      int
+	failures      int
+	lastRequestAt time.Time
+	lastError     error
+}
+
+// poolKey identifies the profiles that can share a connection pool. Sharing
+// is safe when the connections use the same certificate and root CAs.
+type poolKey struct {
+	certificate [sha256.Size]byte
+	rootCAs     *x509.CertPool
+}
+
+type pool struct {
+	httpClient  *http.Client
+	certificate *x509.Certificate
+	refs        int
+}
+
+// NewMultiClient sets up a [Client] for each merchant profile and returns a
+// *[MultiClient].
+func NewMultiClient(config MultiConfig) (*MultiClient, error) {
+	m := &MultiClient{
+		merchants: make(map[string]*merchant),
+		pools:     make(map[poolKey]*pool),
+		now:       time.Now,
+	}
+	for key, profile := range config.Merchants {
+		err := m.Add(key, profile)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// Add sets up a [Client] for the merchant profile. An existing profile with
+// the same key is replaced, e.g. to rotate the certificate, and its stats are
+// kept.
+func (m *MultiClient) Add(key string, config Config) error {
+	if key == "" {
+		return errors.New("merchant key is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	config, err := config.withEnvironment()
+	if err != nil {
+		return fmt.Errorf("merchant %q: %w", key, err)
+	}
+	httpClient, certificate, err := newHTTPClient(config)
+	if err != nil {
+		return fmt.Errorf("merchant %q: %w", key, err)
+	}
+	// The certificate is compared rather than the path, so a certificate
+	// renewed in place is not served by the old pool.
+	shared := config.WrapTransport == nil
+	pk := poolKey{certificate: sha256.Sum256(certificate.Raw), rootCAs: config.RootCAs}
+	p, ok := m.pools[pk]
+	if !ok || !shared {
+		p = &pool{httpClient: httpClient, certificate: certificate}
+	}
+	client, err := newClient(config, p.httpClient, p.certificate)
+	if err != nil {
+		return fmt.Errorf("merchant %q: %w", key, err)
+	}
+
+	if shared {
+		p.refs++
+		m.pools[pk] = p
+	} else {
+		// The zero key marks a pool that is not shared.
+		pk = poolKey{}
+	}
+	stats := &merchantStats{}
+	// The old profile is released after the new one takes its pool reference,
+	// so a pool used by both is kept open.
+	if old, ok := m.merchants[key]; ok {
+		stats = old.stats
+		m.release(old)
+	}
+	m.merchants[key] = &merchant{client: client, pool: pk, stats: stats}
+	return nil
+}
+
+// Remove deletes the merchant profile. Requests in flight are completed, and
+// the connection pool is closed when no other profile uses it. It returns
+// false if there was no such profile.
+func (m *MultiClient) Remove(key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	old, ok := m.merchants[key]
+	if !ok {
+		return false
+	}
+	delete(m.merchants, key)
+	m.release(old)
+	return true
+}
+
+// release drops the reference of the merchant to its connection pool.
+func (m *MultiClient) release(old *merchant) {
+	p, ok := m.pools[old.pool]
+	if !ok {
+		old.client.httpClient.CloseIdleConnections()
+		return
+	}
+	p.refs--
+	if p.refs == 0 {
+		delete(m.pools, old.pool)
+		p.httpClient.CloseIdleConnections()
+	}
+}
+
+// Client returns the [Client] of the merchant, e.g. to look up its
+// transactions.
+func (m *MultiClient) Client(key string) (*Client, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	merchant, ok := m.merchants[key]
+	if !ok {
+		return nil, false
+	}
+	return merchant.client, true
+}
+
+// Merchants returns the keys of all merchant profiles, sorted.
+func (m *MultiClient) Merchants() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	keys := make([]string, 0, len(m.merchants))
+	for key := range m.merchants {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Stats returns the metrics of every merchant profile, sorted by key.
+func (m *MultiClient) Stats() []MerchantStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	stats := make([]MerchantStats, 0, len(m.merchants))
+	for key, merchant := range m.merchants {
+		merchant.stats.mu.Lock()
+		stats = append(stats, MerchantStats{
+			Merchant:          key,
+			Requests:          merchant.stats.requests,
+			Failures:          merchant.stats.failures,
+			LastRequestAt:     merchant.stats.lastRequestAt,
+			LastError:         merchant.stats.lastError,
+			CertificateExpiry: merchant.client.CertificateExpiry(),
+		})
+		merchant.stats.mu.Unlock()
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Merchant < stats[j].Merchant
+	})
+	return stats
+}
+
+// Send sends the request with the [Client] of the merchant named by the
+// [MerchantRequest], or else by the context, see [WithMerchant].
+func (m *MultiClient) Send(ctx context.Context, req Request) (map[string]any, error) {
+	var key string
+	if merchantReq, ok := req.(MerchantRequest); ok {
+		key = merchantReq.Merchant
+		req = merchantReq.Request
+	}
+	if key == "" && ctx != nil {
+		key, _ = MerchantFrom(ctx)
+	}
+	if key == "" {
+		return nil, ErrNoMerchant
+	}
+
+	m.mu.RLock()
+	merchant, ok := m.merchants[key]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, &UnknownMerchantError{Merchant: key}
+	}
+
+	res, err := merchant.client.Send(ctx, req)
+
+	merchant.stats.mu.Lock()
+	merchant.stats.requests++
+	merchant.stats.lastRequestAt = m.now()
+	if err != nil {
+		merchant.stats.failures++
+		merchant.stats.lastError = err
+	}
+	merchant.stats.mu.Unlock()
+	return res, err
+}
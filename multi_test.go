@@ -0,0 +1,135 @@
+package maib
+
+import (
+	"io/fs"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2/testpki"
+)
+
+// merchantProfile starts a server that answers with its name, and returns the
+// profile of a merchant that uses it.
+func merchantProfile(t *testing.T, ca *testpki.CA, pfxPath string, name string) Config {
+	serverCert, err := ca.Server(testpki.Options{})
+	require.NoError(t, err)
+	server := createServer(ca, serverCert, func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte("MERCHANT: " + name))
+	})
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return Config{
+		PFXPath:                 pfxPath,
+		Passphrase:              clientCertPass,
+		MerchantHandlerEndpoint: server.URL,
+		RootCAs:                 ca.Pool(),
+	}
+}
+
+func TestMultiClient_Send(t *testing.T) {
+	ca, err := testpki.NewCA(testpki.Options{})
+	require.NoError(t, err)
+	multi, err := NewMultiClient(MultiConfig{Merchants: map[string]Config{
+		"a": merchantProfile(t, ca, ca.ClientPFXFile(t, clientCertPass, testpki.Options{}), "a"),
+		"b": merchantProfile(t, ca, ca.ClientPFXFile(t, clientCertPass, testpki.Options{}), "b"),
+	}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, multi.Merchants())
+
+	res, err := multi.Send(WithMerchant(ctx, "a"), testRequest{true})
+	require.NoError(t, err)
+	assert.Equal(t, "a", res["MERCHANT"])
+
+	// The request overrides the context.
+	res, err = multi.Send(WithMerchant(ctx, "a"), MerchantRequest{Merchant: "b", Request: testRequest{true}})
+	require.NoError(t, err)
+	assert.Equal(t, "b", res["MERCHANT"])
+
+	_, err = multi.Send(ctx, testRequest{true})
+	assert.ErrorIs(t, err, ErrNoMerchant)
+	var unknownErr *UnknownMerchantError
+	_, err = multi.Send(WithMerchant(ctx, "c"), testRequest{true})
+	require.ErrorAs(t, err, &unknownErr)
+	assert.Equal(t, "c", unknownErr.Merchant)
+
+	_, err = multi.Send(WithMerchant(ctx, "b"), testRequest{false})
+	assert.ErrorAs(t, err, new(*ValidationError))
+
+	stats := multi.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "a", stats[0].Merchant)
+	assert.Equal(t, 1, stats[0].Requests)
+	assert.Equal(t, 0, stats[0].Failures)
+	assert.Equal(t, 2, stats[1].Requests)
+	assert.Equal(t, 1, stats[1].Failures)
+	assert.ErrorAs(t, stats[1].LastError, new(*ValidationError))
+	client, _ := multi.Client("a")
+	assert.True(t, stats[0].CertificateExpiry.After(time.Now()))
+	assert.Equal(t, client.CertificateExpiry(), stats[0].CertificateExpiry)
+}
+
+func TestMultiClient_Pools(t *testing.T) {
+	ca, err := testpki.NewCA(testpki.Options{})
+	require.NoError(t, err)
+	pfxPath := ca.ClientPFXFile(t, clientCertPass, testpki.Options{})
+	// CA pools are compared by pointer, so the profiles share one.
+	pool := ca.Pool()
+	profile := func(pfxPath string, name string) Config {
+		config := merchantProfile(t, ca, pfxPath, name)
+		config.RootCAs = pool
+		return config
+	}
+	wrapped := profile(pfxPath, "c")
+	wrapped.WrapTransport = func(rt http.RoundTripper) http.RoundTripper { return rt }
+	multi, err := NewMultiClient(MultiConfig{Merchants: map[string]Config{
+		"a": profile(pfxPath, "a"),
+		"b": profile(pfxPath, "b"),
+		"c": wrapped,
+		"d": profile(ca.ClientPFXFile(t, clientCertPass, testpki.Options{}), "d"),
+	}})
+	require.NoError(t, err)
+
+	clientOf := func(key string) *Client {
+		client, ok := multi.Client(key)
+		require.True(t, ok)
+		return client
+	}
+	assert.Same(t, clientOf("a").httpClient, clientOf("b").httpClient)
+	assert.NotSame(t, clientOf("a").httpClient, clientOf("c").httpClient)
+	assert.NotSame(t, clientOf("a").httpClient, clientOf("d").httpClient)
+
+	_, err = multi.Send(WithMerchant(ctx, "b"), testRequest{true})
+	require.NoError(t, err)
+
+	// The shared pool stays open when one of the profiles is removed.
+	assert.True(t, multi.Remove("a"))
+	assert.False(t, multi.Remove("a"))
+	_, err = multi.Send(WithMerchant(ctx, "a"), testRequest{true})
+	assert.ErrorAs(t, err, new(*UnknownMerchantError))
+	res, err := multi.Send(WithMerchant(ctx, "b"), testRequest{true})
+	require.NoError(t, err)
+	assert.Equal(t, "b", res["MERCHANT"])
+
+	// Stats are kept when a profile is replaced.
+	require.NoError(t, multi.Add("b", profile(pfxPath, "b2")))
+	res, err = multi.Send(WithMerchant(ctx, "b"), testRequest{true})
+	require.NoError(t, err)
+	assert.Equal(t, "b2", res["MERCHANT"])
+	assert.Equal(t, "b", multi.Stats()[0].Merchant)
+	assert.Equal(t, 3, multi.Stats()[0].Requests)
+}
+
+func TestMultiClient_Add(t *testing.T) {
+	multi, err := NewMultiClient(MultiConfig{})
+	require.NoError(t, err)
+	assert.EqualError(t, multi.Add("", Config{}), "merchant key is required")
+
+	err = multi.Add("a", Config{PFXPath: "missing.pfx"})
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	assert.ErrorContains(t, err, `merchant "a": read certificate`)
+	assert.Empty(t, multi.Merchants())
+}
@@ -0,0 +1,433 @@
+/*
+Package outbox executes requests that move money exactly once, after the
+business change that decided them is committed.
+
+Sending a request right after committing a business change, e.g. charging a
+subscription, loses the request if the service crashes in between, and
+sending it before committing doubles it if the commit fails. Instead, the
+request is written as a [Message] to the outbox table with
+[SQLStore.Enqueue], in the same database transaction as the business change.
+The [Dispatcher] then sends the queued messages:
+  - Messages are leased, so several dispatchers can share a [Store] without
+    sending a message twice.
+  - Every message has a unique ID, used to deduplicate enqueues, and sent as
+    the idempotency key, see maib.WithIdempotencyKey. A message sent again
+    after [Dispatcher.Resolve] gets a new key with the attempt number.
+  - A message is marked as being sent before the request, so a request
+    interrupted by a crash is never sent again automatically, but reported as
+    [Unknown].
+  - Requests that create a transaction without a final result, like
+    RegisterTransaction, are followed up with TransactionStatus until the
+    result is final.
+
+Each message ends with an [Outcome]: [Succeeded], [Declined], [Rejected] or
+[Unknown].
+*/
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+const (
+	defaultWorkers         = 4
+	defaultInterval        = time.Second
+	defaultLease           = time.Minute
+	defaultInitialBackoff  = 5 * time.Second
+	defaultMaxBackoff      = 5 * time.Minute
+	defaultFollowUpTimeout = 10 * time.Minute
+)
+
+// Config is the configuration required to set up a [Dispatcher].
+type Config struct {
+	// Sender used to send the requests, usually a *maib.Client. Required.
+	Sender maib.Sender
+
+	// Storage for the messages. Required.
+	Store Store
+
+	// Source of time. Default is [clock.Real].
+	Clock clock.Clock
+
+	// Name of the dispatcher in the leases. Must be unique among the
+	// dispatchers sharing the store. Default is a random name.
+	Owner string
+
+	// Number of messages processed concurrently. Default is 4.
+	Workers int
+
+	// How often [Dispatcher.Run] checks the store for due messages. Default is
+	// one second.
+	Interval time.Duration
+
+	// How long a claimed message is leased to the dispatcher. Must be longer
+	// than a request takes, including its timeout. Default is one minute.
+	Lease time.Duration
+
+	// Delay before the first follow-up with TransactionStatus. It is doubled
+	// after each check that doesn't reach a final result. Default is 5 seconds.
+	InitialBackoff time.Duration
+
+	// Upper limit of the delay between follow-ups. Default is 5 minutes.
+	MaxBackoff time.Duration
+
+	// How long after sending a transaction is followed up, before it is
+	// declined with TIMEOUT. Default is 10 minutes, the lifetime of the payment
+	// page.
+	FollowUpTimeout time.Duration
+
+	// Called with the outcome of each message. Optional. It is called from the
+	// worker goroutines, so it must be safe for concurrent use.
+	OnOutcome func(Outcome)
+}
+
+// Dispatcher sends the messages of the outbox. It is safe for concurrent use.
+//
+// Must be initiated with [New].
+type Dispatcher struct {
+	sender          maib.Sender
+	store           Store
+	clock           clock.Clock
+	owner           string
+	workers         int
+	interval        time.Duration
+	lease           time.Duration
+	initialBackoff  time.Duration
+	maxBackoff      time.Duration
+	followUpTimeout time.Duration
+	onOutcome       func(Outcome)
+}
+
+// New validates the configuration and returns a *[Dispatcher].
+func New(config Config) (*Dispatcher, error) {
+	if config.Sender == nil {
+		return nil, errors.New("sender is required")
+	}
+	if config.Store == nil {
+		return nil, errors.New("store is required")
+	}
+
+	d := &Dispatcher{
+		sender:          config.Sender,
+		store:           config.Store,
+		clock:           config.Clock,
+		owner:           config.Owner,
+		workers:         config.Workers,
+		interval:        config.Interval,
+		lease:           config.Lease,
+		initialBackoff:  config.InitialBackoff,
+		maxBackoff:      config.MaxBackoff,
+		followUpTimeout: config.FollowUpTimeout,
+		onOutcome:       config.OnOutcome,
+	}
+	if d.clock == nil {
+		d.clock = clock.Real
+	}
+	if d.owner == "" {
+		d.owner = "dispatcher-" + rand.Text()
+	}
+	if d.workers <= 0 {
+		d.workers = defaultWorkers
+	}
+	if d.interval <= 0 {
+		d.interval = defaultInterval
+	}
+	if d.lease <= 0 {
+		d.lease = defaultLease
+	}
+	if d.initialBackoff <= 0 {
+		d.initialBackoff = defaultInitialBackoff
+	}
+	if d.maxBackoff <= 0 {
+		d.maxBackoff = defaultMaxBackoff
+	}
+	if d.followUpTimeout <= 0 {
+		d.followUpTimeout = defaultFollowUpTimeout
+	}
+	return d, nil
+}
+
+// Run blocks until the context is done, calling [Dispatcher.DispatchDue] on
+// every interval. It returns early if the store fails.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	for {
+		_, err := d.DispatchDue(ctx)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-d.clock.After(d.interval):
+		}
+	}
+}
+
+// DispatchDue claims and processes the due messages until there are none, and
+// returns how many were processed. Messages claimed by another dispatcher
+// meanwhile are skipped. Only store errors are returned.
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	var processed int
+	for {
+		now := d.clock.Now()
+		messages, err := d.store.Claim(ctx, d.owner, now, now.Add(d.lease), d.workers)
+		if err != nil {
+			return processed, fmt.Errorf("claim messages: %w", err)
+		}
+		if len(messages) == 0 {
+			return processed, nil
+		}
+
+		errs := make([]error, len(messages))
+		var wg sync.WaitGroup
+		for i, m := range messages {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs[i] = d.process(ctx, m)
+			}()
+		}
+		wg.Wait()
+		processed += len(messages)
+
+		err = errors.Join(errs...)
+		if err != nil {
+			return processed, err
+		}
+		if ctx.Err() != nil {
+			return processed, ctx.Err()
+		}
+	}
+}
+
+// Outcome returns the outcome of the message, or false if it is not final yet.
+// The error of a loaded outcome only keeps the message of the original one.
+func (d *Dispatcher) Outcome(ctx context.Context, id string) (Outcome, bool, error) {
+	m, err := d.load(ctx, id)
+	if err != nil {
+		return nil, false, err
+	}
+	if !m.Status.IsFinal() {
+		return nil, false, nil
+	}
+	var sendErr error
+	if m.Error != "" {
+		sendErr = errors.New(m.Error)
+	}
+	return outcome(m, sendErr), true, nil
+}
+
+// Resolve settles a message in [StatusUnknown], after the transaction was
+// checked manually. If executed is true, the message succeeds with the
+// transaction ID. Otherwise it is sent again, with a new idempotency key,
+// because the key of the unknown attempt stays pending in the
+// maib.IdempotencyStore of the client.
+func (d *Dispatcher) Resolve(ctx context.Context, id string, executed bool, transactionID string) error {
+	m, err := d.load(ctx, id)
+	if err != nil {
+		return err
+	}
+	if m.Status != StatusUnknown {
+		return fmt.Errorf("message %s is %s, not %s", id, m.Status, StatusUnknown)
+	}
+
+	if executed {
+		m.Status = StatusSucceeded
+		m.TransactionID = transactionID
+	} else {
+		m.Status = StatusPending
+		m.NextAttemptAt = d.clock.Now()
+	}
+	m.Error = ""
+	err = d.store.Save(ctx, m)
+	if err != nil {
+		return fmt.Errorf("save message: %w", err)
+	}
+	return nil
+}
+
+// process advances a claimed message. Only store errors are returned.
+func (d *Dispatcher) process(ctx context.Context, m Message) error {
+	var err error
+	switch m.Status {
+	case StatusPending:
+		err = d.send(ctx, m)
+	case StatusSending:
+		m.Status = StatusUnknown
+		m.Error = ErrInterrupted.Error()
+		err = d.finish(ctx, m, ErrInterrupted)
+	case StatusChecking:
+		err = d.check(ctx, m)
+	}
+	if errors.Is(err, ErrLeaseLost) {
+		return nil
+	}
+	return err
+}
+
+// send marks the message as being sent, sends the request and saves its
+// outcome.
+func (d *Dispatcher) send(ctx context.Context, m Message) error {
+	m.Status = StatusSending
+	m.Attempts++
+	m.SentAt = d.clock.Now()
+	m.TransactionID = m.Values.Get(string(maib.FieldTransactionID))
+	m.Error = ""
+	err := d.store.Save(ctx, m)
+	if err != nil {
+		return fmt.Errorf("save message: %w", err)
+	}
+
+	sendCtx := maib.WithIdempotencyKey(ctx, idempotencyKey(m))
+	if m.MerchantReference != "" {
+		sendCtx = maib.WithMerchantReference(sendCtx, m.MerchantReference)
+	}
+	res, sendErr := d.sender.Send(sendCtx, payload(m.Values))
+	if sendErr != nil {
+		m.Error = sendErr.Error()
+		if maib.IsRejected(sendErr) {
+			m.Status = StatusRejected
+		} else {
+			m.Status = StatusUnknown
+		}
+		return d.finish(ctx, m, sendErr)
+	}
+
+	m.Response = res
+	if id, ok := res["TRANSACTION_ID"].(string); ok {
+		m.TransactionID = id
+	}
+	result, hasResult := res["RESULT"].(string)
+	m.Result = maib.ResultEnum(result)
+	switch {
+	case hasResult && m.Result.IsFinal():
+		return d.complete(ctx, m)
+	case m.TransactionID != "" && m.Values.Has(string(maib.FieldClientIPAddress)):
+		m.Result = ""
+		m.Status = StatusChecking
+		return d.schedule(ctx, m)
+	default:
+		// Nothing to follow up, e.g. CloseDay, or a command without a
+		// transaction result.
+		m.Status = StatusSucceeded
+		return d.finish(ctx, m, nil)
+	}
+}
+
+// check follows up the transaction of the message with TransactionStatus.
+func (d *Dispatcher) check(ctx context.Context, m Message) error {
+	m.Checks++
+	status, err := d.status(ctx, m)
+	if ctx.Err() != nil {
+		return nil
+	}
+	m.Error = ""
+	switch {
+	case err != nil:
+		m.Error = err.Error()
+	case status.Result.IsFinal():
+		m.Result = status.Result
+		return d.complete(ctx, m)
+	}
+
+	if d.clock.Now().Sub(m.SentAt) >= d.followUpTimeout {
+		m.Result = maib.ResultTimeout
+		m.Status = StatusDeclined
+		return d.finish(ctx, m, nil)
+	}
+	return d.schedule(ctx, m)
+}
+
+func (d *Dispatcher) status(ctx context.Context, m Message) (requests.TransactionStatusResult, error) {
+	if m.MerchantReference != "" {
+		ctx = maib.WithMerchantReference(ctx, m.MerchantReference)
+	}
+	res, err := d.sender.Send(ctx, requests.TransactionStatus{
+		TransactionID:   m.TransactionID,
+		ClientIPAddress: m.Values.Get(string(maib.FieldClientIPAddress)),
+	})
+	if err != nil {
+		return requests.TransactionStatusResult{}, err
+	}
+	status, err := requests.DecodeResponse[requests.TransactionStatusResult](res)
+	if err != nil {
+		return requests.TransactionStatusResult{}, fmt.Errorf("decode response: %w", err)
+	}
+	return status, nil
+}
+
+// complete finishes the message by its final transaction result.
+func (d *Dispatcher) complete(ctx context.Context, m Message) error {
+	m.Status = StatusDeclined
+	if m.Result == maib.ResultOk {
+		m.Status = StatusSucceeded
+	}
+	return d.finish(ctx, m, nil)
+}
+
+// schedule saves the message to be checked again after a backoff, and
+// releases the lease.
+func (d *Dispatcher) schedule(ctx context.Context, m Message) error {
+	m.NextAttemptAt = d.clock.Now().Add(d.backoff(m.Checks))
+	m.LeaseUntil = time.Time{}
+	err := d.store.Save(context.WithoutCancel(ctx), m)
+	if err != nil {
+		return fmt.Errorf("save message: %w", err)
+	}
+	return nil
+}
+
+// finish saves the message in its final status and reports the outcome with
+// the error of the request, if any. The outcome is saved even if the context
+// is done.
+func (d *Dispatcher) finish(ctx context.Context, m Message, sendErr error) error {
+	m.LeaseUntil = time.Time{}
+	err := d.store.Save(context.WithoutCancel(ctx), m)
+	if err != nil {
+		return fmt.Errorf("save message: %w", err)
+	}
+	if d.onOutcome != nil {
+		d.onOutcome(outcome(m, sendErr))
+	}
+	return nil
+}
+
+func (d *Dispatcher) load(ctx context.Context, id string) (Message, error) {
+	m, ok, err := d.store.Load(ctx, id)
+	if err != nil {
+		return Message{}, fmt.Errorf("load message: %w", err)
+	}
+	if !ok {
+		return Message{}, fmt.Errorf("%w: %s", ErrUnknownMessage, id)
+	}
+	return m, nil
+}
+
+// backoff returns the delay before the next check after the given number of
+// checks.
+func (d *Dispatcher) backoff(checks int) time.Duration {
+	delay := d.initialBackoff
+	for i := 0; i < checks && delay < d.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, d.maxBackoff)
+}
+
+// idempotencyKey returns the idempotency key of the attempt: the message ID,
+// followed by the attempt number after the first one.
+func idempotencyKey(m Message) string {
+	if m.Attempts <= 1 {
+		return m.ID
+	}
+	return fmt.Sprintf("%s/%d", m.ID, m.Attempts)
+}
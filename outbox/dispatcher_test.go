@@ -0,0 +1,414 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/maibtest"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+const transactionID = "abcdefghijklmnopqrstuvwxyz1="
+
+var register = requests.RegisterTransaction{
+	TransactionType: requests.RegisterTransactionSMS,
+	Amount:          1000,
+	Currency:        maib.CurrencyMDL,
+	ClientIPAddress: "127.0.0.1",
+	Language:        maib.LanguageEnglish,
+}
+
+var reverse = requests.ReverseTransaction{
+	TransactionID: transactionID,
+	Amount:        500,
+}
+
+type senderFunc func(ctx context.Context, req maib.Request) (map[string]any, error)
+
+func (f senderFunc) Send(ctx context.Context, req maib.Request) (map[string]any, error) {
+	return f(ctx, req)
+}
+
+// outcomes collects the outcomes reported by the dispatcher.
+type outcomes struct {
+	mu   sync.Mutex
+	list []Outcome
+}
+
+func (o *outcomes) add(outcome Outcome) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.list = append(o.list, outcome)
+}
+
+func (o *outcomes) get() []Outcome {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.list
+}
+
+func setup(t *testing.T, sender maib.Sender, store Store) (*Dispatcher, *clock.Fake, *outcomes) {
+	fakeClock := clock.NewFake(now)
+	reported := &outcomes{}
+	d, err := New(Config{
+		Sender:    sender,
+		Store:     store,
+		Clock:     fakeClock,
+		OnOutcome: reported.add,
+	})
+	require.NoError(t, err)
+	return d, fakeClock, reported
+}
+
+func enqueue(t *testing.T, store Store, id string, req maib.Request) {
+	m, err := NewMessage(context.Background(), id, req, now)
+	require.NoError(t, err)
+	require.NoError(t, store.Enqueue(context.Background(), m))
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{Store: NewMemoryStore()})
+	assert.EqualError(t, err, "sender is required")
+	_, err = New(Config{Sender: senderFunc(nil)})
+	assert.EqualError(t, err, "store is required")
+}
+
+func TestNewMessage(t *testing.T) {
+	ctx := maib.WithMerchantReference(context.Background(), "order-1")
+	m, err := NewMessage(ctx, "refund-1", reverse, now)
+	require.NoError(t, err)
+	assert.Equal(t, "requests.ReverseTransaction", m.RequestType)
+	assert.Equal(t, "order-1", m.MerchantReference)
+	assert.Equal(t, StatusPending, m.Status)
+	assert.Equal(t, now, m.CreatedAt)
+	assert.Equal(t, "500", m.Values.Get("amount"))
+
+	_, err = NewMessage(ctx, "", reverse, now)
+	assert.EqualError(t, err, "id is required")
+	_, err = NewMessage(ctx, "refund-2", requests.ReverseTransaction{}, now)
+	var validationErr *maib.ValidationError
+	assert.ErrorAs(t, err, &validationErr)
+}
+
+func TestDispatcher_Client(t *testing.T) {
+	server := maibtest.NewServer(t, maibtest.Config{})
+	store := NewMemoryStore()
+	d, fakeClock, reported := setup(t, server.Client(t), store)
+	enqueue(t, store, "order-1", register)
+	enqueue(t, store, "close-day", requests.CloseDay{})
+
+	n, err := d.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	// CloseDay has nothing to follow up.
+	require.Len(t, reported.get(), 1)
+	assert.Equal(t, "close-day", reported.get()[0].Message().ID)
+	assert.IsType(t, Succeeded{}, reported.get()[0])
+
+	// The registered transaction is followed up until it is paid.
+	m, _, err := store.Load(context.Background(), "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusChecking, m.Status)
+	_, ok, err := d.Outcome(context.Background(), "order-1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	fakeClock.Advance(defaultInitialBackoff)
+	_, err = d.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, reported.get(), 1)
+
+	require.NoError(t, server.Approve(m.TransactionID))
+	fakeClock.Advance(2 * defaultInitialBackoff)
+	_, err = d.DispatchDue(context.Background())
+	require.NoError(t, err)
+	require.Len(t, reported.get(), 2)
+
+	outcome, ok, err := d.Outcome(context.Background(), "order-1")
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.IsType(t, Succeeded{}, outcome)
+	assert.Equal(t, maib.ResultOk, outcome.Message().Result)
+	assert.Equal(t, 1, outcome.Message().Attempts)
+	assert.Equal(t, 2, outcome.Message().Checks)
+	assert.Len(t, server.Transactions(), 1)
+}
+
+func TestDispatcher_Outcomes(t *testing.T) {
+	lost := errors.New("connection reset")
+	tests := []struct {
+		name   string
+		req    maib.Request
+		res    map[string]any
+		err    error
+		status Status
+		want   Outcome
+	}{
+		{
+			name:   "succeeded",
+			req:    reverse,
+			res:    map[string]any{"RESULT": "OK", "RESULT_CODE": "400"},
+			status: StatusSucceeded,
+			want:   Succeeded{},
+		},
+		{
+			name:   "declined",
+			req:    requests.ExecuteRecurring{BillerClientID: "sub-1", Amount: 100, Currency: maib.CurrencyMDL, ClientIPAddress: "127.0.0.1", Description: "Plan"},
+			res:    map[string]any{"TRANSACTION_ID": transactionID, "RESULT": "DECLINED", "RESULT_CODE": "116"},
+			status: StatusDeclined,
+			want:   Declined{},
+		},
+		{
+			name:   "rejected",
+			req:    reverse,
+			err:    &maib.ECommError{Code: http.StatusOK, Body: "error: wrong transaction"},
+			status: StatusRejected,
+			want:   Rejected{Err: &maib.ECommError{Code: http.StatusOK, Body: "error: wrong transaction"}},
+		},
+		{
+			name:   "bad gateway",
+			req:    reverse,
+			err:    &maib.ECommError{Code: http.StatusBadGateway, Body: "Bad Gateway"},
+			status: StatusUnknown,
+			want:   Unknown{Err: &maib.ECommError{Code: http.StatusBadGateway, Body: "Bad Gateway"}},
+		},
+		{
+			name:   "unknown",
+			req:    reverse,
+			err:    lost,
+			status: StatusUnknown,
+			want:   Unknown{Err: lost},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var key, reference string
+			sender := senderFunc(func(ctx context.Context, req maib.Request) (map[string]any, error) {
+				key, _ = maib.IdempotencyKeyFrom(ctx)
+				reference, _ = maib.MerchantReferenceFrom(ctx)
+				return tt.res, tt.err
+			})
+			store := NewMemoryStore()
+			d, _, reported := setup(t, sender, store)
+			m, err := NewMessage(maib.WithMerchantReference(context.Background(), "order-1"), "message-1", tt.req, now)
+			require.NoError(t, err)
+			require.NoError(t, store.Enqueue(context.Background(), m))
+
+			_, err = d.DispatchDue(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, "message-1", key)
+			assert.Equal(t, "order-1", reference)
+
+			require.Len(t, reported.get(), 1)
+			outcome := reported.get()[0]
+			assert.IsType(t, tt.want, outcome)
+			assert.Equal(t, tt.status, outcome.Message().Status)
+			assert.Equal(t, transactionID, outcome.Message().TransactionID)
+			switch o := outcome.(type) {
+			case Rejected:
+				assert.Equal(t, tt.err, o.Err)
+			case Unknown:
+				assert.Equal(t, tt.err, o.Err)
+			}
+
+			// Final messages are not sent again.
+			n, err := d.DispatchDue(context.Background())
+			require.NoError(t, err)
+			assert.Zero(t, n)
+		})
+	}
+}
+
+func TestDispatcher_FollowUpTimeout(t *testing.T) {
+	var checks atomic.Int32
+	sender := senderFunc(func(_ context.Context, req maib.Request) (map[string]any, error) {
+		if _, ok := req.(requests.TransactionStatus); ok {
+			checks.Add(1)
+			return map[string]any{"RESULT": "PENDING"}, nil
+		}
+		return map[string]any{"TRANSACTION_ID": transactionID}, nil
+	})
+	store := NewMemoryStore()
+	d, fakeClock, reported := setup(t, sender, store)
+	enqueue(t, store, "order-1", register)
+
+	for len(reported.get()) == 0 {
+		_, err := d.DispatchDue(context.Background())
+		require.NoError(t, err)
+		fakeClock.Advance(time.Minute)
+	}
+	outcome := reported.get()[0]
+	assert.IsType(t, Declined{}, outcome)
+	assert.Equal(t, maib.ResultTimeout, outcome.Message().Result)
+	assert.Equal(t, int32(outcome.Message().Checks), checks.Load())
+}
+
+func TestDispatcher_Interrupted(t *testing.T) {
+	var sent atomic.Int32
+	sender := senderFunc(func(context.Context, maib.Request) (map[string]any, error) {
+		sent.Add(1)
+		return map[string]any{"RESULT": "OK"}, nil
+	})
+	store := NewMemoryStore()
+	d, fakeClock, reported := setup(t, sender, store)
+	enqueue(t, store, "refund-1", reverse)
+
+	// A dispatcher claimed the message, and stopped while sending it.
+	claimed, err := store.Claim(context.Background(), "crashed", now, now.Add(time.Minute), 1)
+	require.NoError(t, err)
+	claimed[0].Status = StatusSending
+	require.NoError(t, store.Save(context.Background(), claimed[0]))
+
+	n, err := d.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	fakeClock.Advance(time.Minute)
+	_, err = d.DispatchDue(context.Background())
+	require.NoError(t, err)
+	require.Len(t, reported.get(), 1)
+	assert.Equal(t, Unknown{Record: reported.get()[0].Message(), Err: ErrInterrupted}, reported.get()[0])
+	assert.Zero(t, sent.Load())
+
+	err = d.Resolve(context.Background(), "refund-1", false, "")
+	require.NoError(t, err)
+	_, err = d.DispatchDue(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), sent.Load())
+	outcome, ok, err := d.Outcome(context.Background(), "refund-1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.IsType(t, Succeeded{}, outcome)
+
+	err = d.Resolve(context.Background(), "refund-1", true, transactionID)
+	assert.EqualError(t, err, "message refund-1 is SUCCEEDED, not UNKNOWN")
+	err = d.Resolve(context.Background(), "refund-2", true, transactionID)
+	assert.ErrorIs(t, err, ErrUnknownMessage)
+}
+
+func TestDispatcher_Resolve_Executed(t *testing.T) {
+	sender := senderFunc(func(context.Context, maib.Request) (map[string]any, error) {
+		return nil, errors.New("timeout")
+	})
+	store := NewMemoryStore()
+	d, _, _ := setup(t, sender, store)
+	enqueue(t, store, "charge-1", requests.CloseDay{})
+	_, err := d.DispatchDue(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, d.Resolve(context.Background(), "charge-1", true, transactionID))
+	outcome, ok, err := d.Outcome(context.Background(), "charge-1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.IsType(t, Succeeded{}, outcome)
+	assert.Equal(t, transactionID, outcome.Message().TransactionID)
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestDispatcher_Resolve_NotExecuted(t *testing.T) {
+	server := maibtest.NewServer(t, maibtest.Config{})
+	config := server.Config()
+	var failed atomic.Bool
+	config.WrapTransport = func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if failed.CompareAndSwap(false, true) {
+				return &http.Response{
+					StatusCode: http.StatusBadGateway,
+					Body:       io.NopCloser(strings.NewReader("Bad Gateway")),
+					Request:    req,
+				}, nil
+			}
+			return next.RoundTrip(req)
+		})
+	}
+	client, err := maib.NewClient(config)
+	require.NoError(t, err)
+	store := NewMemoryStore()
+	d, _, reported := setup(t, client, store)
+	enqueue(t, store, "close-day", requests.CloseDay{})
+
+	_, err = d.DispatchDue(context.Background())
+	require.NoError(t, err)
+	require.Len(t, reported.get(), 1)
+	assert.IsType(t, Unknown{}, reported.get()[0])
+
+	// The key of the first attempt is pending in the client, so the message is
+	// sent again with another one.
+	require.NoError(t, d.Resolve(context.Background(), "close-day", false, ""))
+	_, err = d.DispatchDue(context.Background())
+	require.NoError(t, err)
+	require.Len(t, reported.get(), 2)
+	assert.IsType(t, Succeeded{}, reported.get()[1])
+	assert.Equal(t, 2, reported.get()[1].Message().Attempts)
+}
+
+func TestDispatcher_SharedStore(t *testing.T) {
+	var sent atomic.Int32
+	sender := senderFunc(func(context.Context, maib.Request) (map[string]any, error) {
+		sent.Add(1)
+		return map[string]any{"RESULT": "OK"}, nil
+	})
+	store := NewMemoryStore()
+	for _, id := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		enqueue(t, store, id, reverse)
+	}
+
+	var wg sync.WaitGroup
+	var processed atomic.Int32
+	for range 3 {
+		d, _, _ := setup(t, sender, store)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := d.DispatchDue(context.Background())
+			assert.NoError(t, err)
+			processed.Add(int32(n))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(10), sent.Load())
+	assert.Equal(t, int32(10), processed.Load())
+}
+
+func TestDispatcher_Run(t *testing.T) {
+	var sent atomic.Int32
+	sender := senderFunc(func(context.Context, maib.Request) (map[string]any, error) {
+		sent.Add(1)
+		return map[string]any{"RESULT": "OK"}, nil
+	})
+	store := NewMemoryStore()
+	d, fakeClock, _ := setup(t, sender, store)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- d.Run(ctx) }()
+
+	fakeClock.BlockUntil(1)
+	enqueue(t, store, "refund-1", reverse)
+	fakeClock.Advance(defaultInterval)
+	fakeClock.BlockUntil(1)
+	assert.Equal(t, int32(1), sent.Load())
+
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
@@ -0,0 +1,162 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// Status is the state of a [Message] in the outbox.
+type Status string
+
+const (
+	// StatusPending - the message is waiting to be sent.
+	StatusPending Status = "PENDING"
+
+	// StatusSending - the request is being sent. A message left in this status
+	// by a stopped dispatcher becomes [StatusUnknown].
+	StatusSending Status = "SENDING"
+
+	// StatusChecking - the request was executed, and the created transaction is
+	// polled with TransactionStatus until its result is final.
+	StatusChecking Status = "CHECKING"
+
+	// StatusSucceeded - the request was executed, and the transaction result,
+	// if any, is OK. Final.
+	StatusSucceeded Status = "SUCCEEDED"
+
+	// StatusDeclined - the request was executed, but the transaction reached a
+	// final result other than OK, e.g. the card was declined. Final.
+	StatusDeclined Status = "DECLINED"
+
+	// StatusRejected - the request was refused without being executed, see
+	// maib.IsRejected. Final.
+	StatusRejected Status = "REJECTED"
+
+	// StatusUnknown - the request failed without a response or with a 5xx
+	// status, so it may or may not have been executed. Requires manual handling
+	// with [Dispatcher.Resolve].
+	StatusUnknown Status = "UNKNOWN"
+)
+
+// IsFinal reports whether the dispatcher is done with the message.
+func (s Status) IsFinal() bool {
+	switch s {
+	case StatusSucceeded, StatusDeclined, StatusRejected, StatusUnknown:
+		return true
+	default:
+		return false
+	}
+}
+
+var (
+	// ErrDuplicate is returned when a message is enqueued with the ID of a
+	// message with a different payload.
+	ErrDuplicate = errors.New("outbox: message ID is used by another payload")
+
+	// ErrUnknownMessage is returned when the message is not in the store.
+	ErrUnknownMessage = errors.New("outbox: message is not in the store")
+
+	// ErrLeaseLost is returned by [Store.Save] when the message was claimed by
+	// another dispatcher since it was loaded.
+	ErrLeaseLost = errors.New("outbox: message is leased by another dispatcher")
+
+	// ErrInterrupted is the error of a message whose dispatcher stopped while
+	// sending it.
+	ErrInterrupted = errors.New("outbox: dispatcher stopped while sending the request")
+)
+
+// Message is a request queued in the outbox, and its progress.
+type Message struct {
+	// Unique ID chosen by the merchant, like "charge-sub-42-2026-03". A message
+	// is enqueued only once per ID, and it is first sent with the ID as the
+	// idempotency key.
+	ID string
+
+	// Go type of the request, like "requests.ExecuteRecurring".
+	RequestType string
+
+	// Payload of the request.
+	Values url.Values
+
+	// Merchant's reference, set by maib.WithMerchantReference when enqueued,
+	// and sent with the request.
+	MerchantReference string
+
+	// When the message was created.
+	CreatedAt time.Time
+
+	// State of the message.
+	Status Status
+
+	// Number of times the request was sent.
+	Attempts int
+
+	// When the request was last sent.
+	SentAt time.Time
+
+	// Response of the ECommerce system.
+	Response map[string]any
+
+	// ID of the transaction created or affected by the request, if any.
+	TransactionID string
+
+	// Final result of the transaction, if known.
+	Result maib.ResultEnum
+
+	// Number of TransactionStatus requests sent to follow up.
+	Checks int
+
+	// Error of the last attempt or check.
+	Error string
+
+	// When the message is due to be processed.
+	NextAttemptAt time.Time
+
+	// Dispatcher that last claimed the message.
+	LeaseOwner string
+
+	// Until when the message is claimed by LeaseOwner.
+	LeaseUntil time.Time
+}
+
+// NewMessage validates the request and returns a pending message with the ID,
+// created at the time, usually the Now of the clock of the [Dispatcher]. The
+// merchant reference is taken from the context.
+func NewMessage(ctx context.Context, id string, req maib.Request, createdAt time.Time) (Message, error) {
+	if id == "" {
+		return Message{}, errors.New("id is required")
+	}
+	values, err := req.Values()
+	if err != nil {
+		return Message{}, fmt.Errorf("get request values: %w", err)
+	}
+	reference, _ := maib.MerchantReferenceFrom(ctx)
+	return Message{
+		ID:                id,
+		RequestType:       reflect.TypeOf(req).String(),
+		Values:            values,
+		MerchantReference: reference,
+		CreatedAt:         createdAt,
+		Status:            StatusPending,
+	}, nil
+}
+
+// samePayload reports whether the messages send the same request.
+func (m Message) samePayload(other Message) bool {
+	return maps.EqualFunc(m.Values, other.Values, slices.Equal)
+}
+
+// payload is the queued payload, sent as a [maib.Request].
+type payload url.Values
+
+func (p payload) Values() (url.Values, error) {
+	return url.Values(p), nil
+}
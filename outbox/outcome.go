@@ -0,0 +1,63 @@
+package outbox
+
+// Outcome is the final outcome of a [Message]. Use a type switch to
+// distinguish [Succeeded], [Declined], [Rejected] and [Unknown].
+type Outcome interface {
+	// Message returns the message as it was saved with the outcome.
+	Message() Message
+}
+
+// Succeeded is the outcome of a request that was executed, with the final
+// result of its transaction, if any, being OK.
+type Succeeded struct {
+	Record Message
+}
+
+func (o Succeeded) Message() Message { return o.Record }
+
+// Declined is the outcome of a request that was executed, but whose
+// transaction reached a final result other than OK, or didn't reach a final
+// result before the follow-up timeout, in which case the result is TIMEOUT.
+type Declined struct {
+	Record Message
+}
+
+func (o Declined) Message() Message { return o.Record }
+
+// Rejected is the outcome of a request that was refused without being
+// executed, see maib.IsRejected.
+type Rejected struct {
+	Record Message
+
+	// Error returned by the sender.
+	Err error
+}
+
+func (o Rejected) Message() Message { return o.Record }
+
+// Unknown is the outcome of a request that failed without a response or with
+// a 5xx status, so it may or may not have been executed. Check the transaction, and resolve the
+// message with [Dispatcher.Resolve].
+type Unknown struct {
+	Record Message
+
+	// Error returned by the sender, or [ErrInterrupted].
+	Err error
+}
+
+func (o Unknown) Message() Message { return o.Record }
+
+// outcome returns the outcome of the message in a final status, with the
+// error of the request.
+func outcome(m Message, err error) Outcome {
+	switch m.Status {
+	case StatusSucceeded:
+		return Succeeded{Record: m}
+	case StatusDeclined:
+		return Declined{Record: m}
+	case StatusRejected:
+		return Rejected{Record: m, Err: err}
+	default:
+		return Unknown{Record: m, Err: err}
+	}
+}
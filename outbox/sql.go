@@ -0,0 +1,261 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultTable is the default table of the [SQLStore].
+const DefaultTable = "maib_outbox"
+
+var tableName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Conn executes statements, like *sql.DB and *sql.Tx.
+type Conn interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// SQLConfig is the configuration required to set up a [SQLStore].
+type SQLConfig struct {
+	// Database with the outbox table. The driver is chosen by the caller.
+	// Required.
+	DB *sql.DB
+
+	// Name of the outbox table, optionally with the schema. Default is
+	// [DefaultTable].
+	Table string
+
+	// Use numbered placeholders, like $1, as in PostgreSQL, instead of ?.
+	NumberedPlaceholders bool
+}
+
+// SQLStore is a [Store] that keeps messages in a database/sql table with the
+// columns:
+//
+//	id              TEXT PRIMARY KEY -- Message.ID
+//	status          TEXT NOT NULL    -- Message.Status
+//	next_attempt_at BIGINT NOT NULL  -- Message.NextAttemptAt in Unix milliseconds
+//	lease_owner     TEXT NOT NULL    -- Message.LeaseOwner
+//	lease_until     BIGINT NOT NULL  -- Message.LeaseUntil in Unix milliseconds
+//	message         TEXT NOT NULL    -- JSON encoding of the Message
+//
+// Create the table with [SQLStore.CreateTable], or with a migration. Enqueue
+// messages within the transaction of the business change through
+// [SQLStore.WithTx]. It is safe for concurrent use.
+//
+// Must be initiated with [NewSQLStore].
+type SQLStore struct {
+	conn     Conn
+	table    string
+	numbered bool
+}
+
+// NewSQLStore validates the configuration and returns a *[SQLStore].
+func NewSQLStore(config SQLConfig) (*SQLStore, error) {
+	if config.DB == nil {
+		return nil, errors.New("db is required")
+	}
+	table := config.Table
+	if table == "" {
+		table = DefaultTable
+	}
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	return &SQLStore{
+		conn:     config.DB,
+		table:    table,
+		numbered: config.NumberedPlaceholders,
+	}, nil
+}
+
+// WithTx returns a store that executes its statements within the transaction,
+// so that a message is enqueued only if the transaction is committed:
+//
+//	tx, err := db.BeginTx(ctx, nil)
+//	// ... save the business change with tx ...
+//	err = store.WithTx(tx).Enqueue(ctx, message)
+//	// ...
+//	err = tx.Commit()
+func (s *SQLStore) WithTx(tx Conn) *SQLStore {
+	withTx := *s
+	withTx.conn = tx
+	return &withTx
+}
+
+// CreateTable creates the outbox table if it doesn't exist.
+func (s *SQLStore) CreateTable(ctx context.Context) error {
+	_, err := s.conn.ExecContext(ctx, fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s ("+
+		"id TEXT PRIMARY KEY, "+
+		"status TEXT NOT NULL, "+
+		"next_attempt_at BIGINT NOT NULL, "+
+		"lease_owner TEXT NOT NULL, "+
+		"lease_until BIGINT NOT NULL, "+
+		"message TEXT NOT NULL)", s.table))
+	return err
+}
+
+func (s *SQLStore) Enqueue(ctx context.Context, message Message) error {
+	existing, ok, err := s.Load(ctx, message.ID)
+	if err != nil {
+		return err
+	}
+	if ok {
+		if !existing.samePayload(message) {
+			return ErrDuplicate
+		}
+		return nil
+	}
+
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.ExecContext(ctx, s.query(
+		"INSERT INTO %s (id, status, next_attempt_at, lease_owner, lease_until, message) VALUES (?, ?, ?, ?, ?, ?)"),
+		message.ID, string(message.Status), millis(message.NextAttemptAt), message.LeaseOwner, millis(message.LeaseUntil), string(data))
+	return err
+}
+
+func (s *SQLStore) Claim(ctx context.Context, owner string, now, leaseUntil time.Time, limit int) ([]Message, error) {
+	rows, err := s.conn.QueryContext(ctx, s.query(
+		"SELECT message FROM %s WHERE status IN (?, ?, ?) AND next_attempt_at <= ? AND lease_until <= ? ORDER BY next_attempt_at, id LIMIT ?"),
+		string(StatusPending), string(StatusSending), string(StatusChecking), millis(now), millis(now), limit)
+	if err != nil {
+		return nil, err
+	}
+	var due []Message
+	for rows.Next() {
+		var data string
+		err = rows.Scan(&data)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		var m Message
+		err = json.Unmarshal([]byte(data), &m)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, m)
+	}
+	err = errors.Join(rows.Err(), rows.Close())
+	if err != nil {
+		return nil, err
+	}
+
+	// Another dispatcher may claim and process the same messages meanwhile,
+	// and keep the lease owner when it releases them, so each one is claimed
+	// only if its status, next attempt and lease are unchanged.
+	var claimed []Message
+	for _, m := range due {
+		previousOwner := m.LeaseOwner
+		m.LeaseOwner = owner
+		m.LeaseUntil = leaseUntil
+		data, err := json.Marshal(m)
+		if err != nil {
+			return nil, err
+		}
+		res, err := s.conn.ExecContext(ctx, s.query(
+			"UPDATE %s SET lease_owner = ?, lease_until = ?, message = ? "+
+				"WHERE id = ? AND status = ? AND next_attempt_at = ? AND lease_owner = ? AND lease_until <= ?"),
+			owner, millis(leaseUntil), string(data),
+			m.ID, string(m.Status), millis(m.NextAttemptAt), previousOwner, millis(now))
+		if err != nil {
+			return nil, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return nil, err
+		}
+		if n == 1 {
+			claimed = append(claimed, m)
+		}
+	}
+	return claimed, nil
+}
+
+func (s *SQLStore) Save(ctx context.Context, message Message) error {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	res, err := s.conn.ExecContext(ctx, s.query(
+		"UPDATE %s SET status = ?, next_attempt_at = ?, lease_until = ?, message = ? WHERE id = ? AND lease_owner = ?"),
+		string(message.Status), millis(message.NextAttemptAt), millis(message.LeaseUntil), string(data), message.ID, message.LeaseOwner)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 1 {
+		return nil
+	}
+
+	_, ok, err := s.Load(ctx, message.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUnknownMessage
+	}
+	return ErrLeaseLost
+}
+
+func (s *SQLStore) Load(ctx context.Context, id string) (Message, bool, error) {
+	var data string
+	err := s.conn.QueryRowContext(ctx, s.query("SELECT message FROM %s WHERE id = ?"), id).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Message{}, false, nil
+	}
+	if err != nil {
+		return Message{}, false, err
+	}
+	var m Message
+	err = json.Unmarshal([]byte(data), &m)
+	if err != nil {
+		return Message{}, false, err
+	}
+	return m, true, nil
+}
+
+// query inserts the table into the statement, and numbers its placeholders
+// if configured.
+func (s *SQLStore) query(format string) string {
+	q := fmt.Sprintf(format, s.table)
+	if !s.numbered {
+		return q
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range q {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// millis returns the time in Unix milliseconds, or 0 for the zero time.
+func millis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
@@ -0,0 +1,247 @@
+package outbox
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2/internal/sqlfake"
+)
+
+// outboxRow is a row of the outbox table.
+type outboxRow struct {
+	status        string
+	nextAttemptAt int64
+	leaseOwner    string
+	leaseUntil    int64
+	message       string
+}
+
+// outboxTable emulates the outbox table for the statements of the
+// [SQLStore]. Writes within a transaction are discarded on rollback.
+type outboxTable struct {
+	mu       sync.Mutex
+	queries  []string
+	rows     map[string]outboxRow
+	snapshot map[string]outboxRow
+
+	// Called once before the next claim of a selected row.
+	beforeClaim func()
+}
+
+func newOutboxTable() *outboxTable {
+	return &outboxTable{rows: make(map[string]outboxRow)}
+}
+
+func (tb *outboxTable) Begin() error {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.snapshot = maps.Clone(tb.rows)
+	return nil
+}
+
+func (tb *outboxTable) Commit() error {
+	return nil
+}
+
+func (tb *outboxTable) Rollback() error {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.rows = tb.snapshot
+	return nil
+}
+
+func (tb *outboxTable) Exec(query string, args []driver.Value) (int64, error) {
+	tb.mu.Lock()
+	beforeClaim := tb.beforeClaim
+	if strings.Contains(query, "SET lease_owner") {
+		tb.beforeClaim = nil
+	} else {
+		beforeClaim = nil
+	}
+	tb.mu.Unlock()
+	if beforeClaim != nil {
+		beforeClaim()
+	}
+
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.queries = append(tb.queries, query)
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE"):
+		return 0, nil
+
+	case strings.HasPrefix(query, "INSERT"):
+		id := args[0].(string)
+		if _, ok := tb.rows[id]; ok {
+			return 0, errors.New("duplicate key")
+		}
+		tb.rows[id] = outboxRow{
+			status:        args[1].(string),
+			nextAttemptAt: args[2].(int64),
+			leaseOwner:    args[3].(string),
+			leaseUntil:    args[4].(int64),
+			message:       args[5].(string),
+		}
+		return 1, nil
+
+	case strings.Contains(query, "SET lease_owner"):
+		id := args[3].(string)
+		row, ok := tb.rows[id]
+		if !ok || row.status != args[4].(string) || row.nextAttemptAt != args[5].(int64) ||
+			row.leaseOwner != args[6].(string) || row.leaseUntil > args[7].(int64) {
+			return 0, nil
+		}
+		row.leaseOwner = args[0].(string)
+		row.leaseUntil = args[1].(int64)
+		row.message = args[2].(string)
+		tb.rows[id] = row
+		return 1, nil
+
+	case strings.Contains(query, "SET status"):
+		id := args[4].(string)
+		row, ok := tb.rows[id]
+		if !ok || row.leaseOwner != args[5].(string) {
+			return 0, nil
+		}
+		row.status = args[0].(string)
+		row.nextAttemptAt = args[1].(int64)
+		row.leaseUntil = args[2].(int64)
+		row.message = args[3].(string)
+		tb.rows[id] = row
+		return 1, nil
+	}
+	return 0, fmt.Errorf("unexpected exec %q", query)
+}
+
+func (tb *outboxTable) Query(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.queries = append(tb.queries, query)
+	columns := []string{"message"}
+	switch {
+	case strings.Contains(query, "WHERE id"):
+		row, ok := tb.rows[args[0].(string)]
+		if !ok {
+			return columns, nil, nil
+		}
+		return columns, [][]driver.Value{{row.message}}, nil
+
+	case strings.Contains(query, "WHERE status IN"):
+		var ids []string
+		for id, row := range tb.rows {
+			if slices.Contains(args[:3], driver.Value(row.status)) &&
+				row.nextAttemptAt <= args[3].(int64) && row.leaseUntil <= args[4].(int64) {
+				ids = append(ids, id)
+			}
+		}
+		slices.SortFunc(ids, func(a, b string) int {
+			if d := tb.rows[a].nextAttemptAt - tb.rows[b].nextAttemptAt; d != 0 {
+				return int(d)
+			}
+			return strings.Compare(a, b)
+		})
+		var rows [][]driver.Value
+		for _, id := range ids[:min(len(ids), int(args[5].(int64)))] {
+			rows = append(rows, []driver.Value{tb.rows[id].message})
+		}
+		return columns, rows, nil
+	}
+	return nil, nil, fmt.Errorf("unexpected query %q", query)
+}
+
+func TestNewSQLStore(t *testing.T) {
+	_, err := NewSQLStore(SQLConfig{})
+	assert.EqualError(t, err, "db is required")
+
+	db := sqlfake.Open(newOutboxTable())
+	_, err = NewSQLStore(SQLConfig{DB: db, Table: "outbox; DROP TABLE orders"})
+	assert.EqualError(t, err, `invalid table name "outbox; DROP TABLE orders"`)
+	_, err = NewSQLStore(SQLConfig{DB: db, Table: "billing.outbox"})
+	assert.NoError(t, err)
+}
+
+func TestSQLStore(t *testing.T) {
+	table := newOutboxTable()
+	store, err := NewSQLStore(SQLConfig{DB: sqlfake.Open(table)})
+	require.NoError(t, err)
+	require.NoError(t, store.CreateTable(context.Background()))
+	testStore(t, store)
+}
+
+func TestSQLStore_Claim_Interleaved(t *testing.T) {
+	ctx := context.Background()
+	table := newOutboxTable()
+	store, err := NewSQLStore(SQLConfig{DB: sqlfake.Open(table)})
+	require.NoError(t, err)
+	enqueue(t, store, "order-1", register)
+
+	// The second dispatcher sent the message, and scheduled a follow-up,
+	// keeping the lease owner.
+	claimed, err := store.Claim(ctx, "second", now, now.Add(time.Minute), 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	m := claimed[0]
+	m.Status = StatusChecking
+	m.NextAttemptAt = now.Add(time.Second)
+	m.LeaseUntil = time.Time{}
+	require.NoError(t, store.Save(ctx, m))
+
+	// After the first dispatcher has selected the message, the second one
+	// claims it again and finishes it.
+	table.beforeClaim = func() {
+		claimed, err := store.Claim(ctx, "second", now.Add(time.Second), now.Add(time.Minute), 1)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		m := claimed[0]
+		m.Status = StatusSucceeded
+		m.LeaseUntil = time.Time{}
+		require.NoError(t, store.Save(ctx, m))
+	}
+	claimed, err = store.Claim(ctx, "first", now.Add(time.Second), now.Add(time.Minute), 1)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	loaded, _, err := store.Load(ctx, "order-1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, loaded.Status)
+	assert.Equal(t, "second", loaded.LeaseOwner)
+}
+
+func TestSQLStore_WithTx(t *testing.T) {
+	ctx := context.Background()
+	table := newOutboxTable()
+	db := sqlfake.Open(table)
+	store, err := NewSQLStore(SQLConfig{DB: db, NumberedPlaceholders: true})
+	require.NoError(t, err)
+
+	// The business change fails, so the message is not enqueued.
+	tx, err := db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	enqueue(t, store.WithTx(tx), "refund-1", reverse)
+	require.NoError(t, tx.Rollback())
+	_, ok, err := store.Load(ctx, "refund-1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	tx, err = db.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	enqueue(t, store.WithTx(tx), "refund-1", reverse)
+	require.NoError(t, tx.Commit())
+	_, ok, err = store.Load(ctx, "refund-1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.Contains(t, table.queries,
+		"INSERT INTO maib_outbox (id, status, next_attempt_at, lease_owner, lease_until, message) VALUES ($1, $2, $3, $4, $5, $6)")
+}
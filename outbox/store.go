@@ -0,0 +1,117 @@
+package outbox
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Store persists the messages of the outbox.
+type Store interface {
+	// Enqueue adds a pending message. Enqueueing a message with the ID and the
+	// payload of an existing one does nothing, and with another payload fails
+	// with [ErrDuplicate].
+	Enqueue(ctx context.Context, message Message) error
+
+	// Claim leases up to limit messages that are not final, are due at now,
+	// and are not leased, to the owner until leaseUntil. It returns them
+	// ordered by NextAttemptAt, with the lease set.
+	Claim(ctx context.Context, owner string, now, leaseUntil time.Time, limit int) ([]Message, error)
+
+	// Save replaces the message, if it is still leased to its LeaseOwner.
+	// Otherwise it fails with [ErrLeaseLost].
+	Save(ctx context.Context, message Message) error
+
+	// Load returns the message with the ID, or false if there is none.
+	Load(ctx context.Context, id string) (Message, bool, error)
+}
+
+// MemoryStore is a [Store] that keeps messages in memory. It can't enqueue
+// within a database transaction, so it only suits tests and single-process
+// deployments without one. It is safe for concurrent use.
+type MemoryStore struct {
+	mu       sync.Mutex
+	messages map[string]Message
+}
+
+// NewMemoryStore returns an empty *[MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{messages: make(map[string]Message)}
+}
+
+func (s *MemoryStore) Enqueue(_ context.Context, message Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.messages[message.ID]
+	if ok {
+		if !existing.samePayload(message) {
+			return ErrDuplicate
+		}
+		return nil
+	}
+	s.messages[message.ID] = clone(message)
+	return nil
+}
+
+func (s *MemoryStore) Claim(_ context.Context, owner string, now, leaseUntil time.Time, limit int) ([]Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Message
+	for _, m := range s.messages {
+		if !m.Status.IsFinal() && !m.NextAttemptAt.After(now) && !m.LeaseUntil.After(now) {
+			due = append(due, m)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].NextAttemptAt.Equal(due[j].NextAttemptAt) {
+			return due[i].ID < due[j].ID
+		}
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for i := range due {
+		due[i].LeaseOwner = owner
+		due[i].LeaseUntil = leaseUntil
+		s.messages[due[i].ID] = clone(due[i])
+	}
+	return due, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, message Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.messages[message.ID]
+	if !ok {
+		return ErrUnknownMessage
+	}
+	if existing.LeaseOwner != message.LeaseOwner {
+		return ErrLeaseLost
+	}
+	s.messages[message.ID] = clone(message)
+	return nil
+}
+
+func (s *MemoryStore) Load(_ context.Context, id string) (Message, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	m, ok := s.messages[id]
+	return clone(m), ok, nil
+}
+
+// clone copies the maps of the message, so that the stored one doesn't change.
+func clone(m Message) Message {
+	if m.Values != nil {
+		m.Values = maps.Clone(m.Values)
+		for key, v := range m.Values {
+			m.Values[key] = slices.Clone(v)
+		}
+	}
+	m.Response = maps.Clone(m.Response)
+	return m
+}
@@ -0,0 +1,90 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+// testStore checks the behavior shared by every [Store].
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+	enqueue(t, store, "b", reverse)
+	enqueue(t, store, "a", reverse)
+
+	// Enqueueing again is deduplicated by the ID.
+	enqueue(t, store, "a", reverse)
+	m, err := NewMessage(ctx, "a", requests.CloseDay{}, now)
+	require.NoError(t, err)
+	assert.ErrorIs(t, store.Enqueue(ctx, m), ErrDuplicate)
+
+	claimed, err := store.Claim(ctx, "first", now, now.Add(time.Minute), 1)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "a", claimed[0].ID)
+	assert.Equal(t, "first", claimed[0].LeaseOwner)
+	assert.Equal(t, now.Add(time.Minute), claimed[0].LeaseUntil)
+
+	// Leased messages are not claimed until the lease expires.
+	claimed, err = store.Claim(ctx, "second", now, now.Add(2*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "b", claimed[0].ID)
+	claimed, err = store.Claim(ctx, "second", now.Add(time.Minute), now.Add(3*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "a", claimed[0].ID)
+
+	// The first owner has lost the lease.
+	lost, ok, err := store.Load(ctx, "a")
+	require.NoError(t, err)
+	require.True(t, ok)
+	lost.LeaseOwner = "first"
+	assert.ErrorIs(t, store.Save(ctx, lost), ErrLeaseLost)
+
+	// Messages are claimed when due.
+	m = claimed[0]
+	m.Status = StatusChecking
+	m.NextAttemptAt = now.Add(time.Hour)
+	m.LeaseUntil = time.Time{}
+	require.NoError(t, store.Save(ctx, m))
+	claimed, err = store.Claim(ctx, "third", now.Add(3*time.Minute), now.Add(4*time.Minute), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "b", claimed[0].ID)
+	claimed, err = store.Claim(ctx, "third", now.Add(time.Hour), now.Add(2*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, "b", claimed[0].ID)
+	assert.Equal(t, "a", claimed[1].ID)
+
+	// Final messages are never claimed.
+	for _, m := range claimed {
+		m.Status = StatusSucceeded
+		m.Response = map[string]any{"RESULT": "OK"}
+		require.NoError(t, store.Save(ctx, m))
+	}
+	claimed, err = store.Claim(ctx, "fourth", now.Add(24*time.Hour), now.Add(25*time.Hour), 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	loaded, ok, err := store.Load(ctx, "b")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, StatusSucceeded, loaded.Status)
+	assert.Equal(t, map[string]any{"RESULT": "OK"}, loaded.Response)
+	assert.Equal(t, "500", loaded.Values.Get("amount"))
+	_, ok, err = store.Load(ctx, "c")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.ErrorIs(t, store.Save(ctx, Message{ID: "c"}), ErrUnknownMessage)
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
@@ -0,0 +1,70 @@
+package poller
+
+import (
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+// Event is emitted by the [Poller] after each poll. Use a type switch to
+// distinguish [Pending], [Finalized], [TimedOut] and [Failed].
+type Event interface {
+	// Transaction returns the transaction as it was saved after the poll.
+	Transaction() Transaction
+}
+
+// Pending is emitted when a poll returns a status that is not final yet.
+// Compare Previous with Record.Result to see if the status has changed, e.g.
+// from CREATED to PENDING.
+type Pending struct {
+	Record Transaction
+
+	// Status before the poll.
+	Previous maib.ResultEnum
+
+	// Decoded TransactionStatus response.
+	Status requests.TransactionStatusResult
+}
+
+func (e Pending) Transaction() Transaction { return e.Record }
+
+// Finalized is emitted when a transaction reaches a final status. It is not
+// polled anymore.
+type Finalized struct {
+	Record Transaction
+
+	// Status before the poll.
+	Previous maib.ResultEnum
+
+	// Decoded TransactionStatus response.
+	Status requests.TransactionStatusResult
+}
+
+func (e Finalized) Transaction() Transaction { return e.Record }
+
+// TimedOut is emitted when a transaction is still not final after the payment
+// page lifetime, or its poll still fails. Its Result is set to TIMEOUT locally
+// and it is not polled anymore.
+type TimedOut struct {
+	Record Transaction
+
+	// Status before the timeout.
+	Previous maib.ResultEnum
+
+	// Error of the last poll, if it failed. The transaction may have been
+	// finalized meanwhile, so check it manually.
+	Err error
+}
+
+func (e TimedOut) Transaction() Transaction { return e.Record }
+
+// Failed is emitted when the TransactionStatus request fails. The transaction
+// is polled again after a backoff, until the payment page lifetime, see
+// [TimedOut].
+type Failed struct {
+	Record Transaction
+
+	// Error returned by the sender or the decoder.
+	Err error
+}
+
+func (e Failed) Transaction() Transaction { return e.Record }
@@ -0,0 +1,295 @@
+/*
+Package poller periodically checks transactions that are not final yet with
+[requests.TransactionStatus].
+
+Payers often close the browser before returning to the merchant, so a
+transaction can stay CREATED or PENDING in the merchant's system forever. The
+[Poller] keeps polling such transactions with per-transaction exponential
+backoff until they reach a final [maib.ResultEnum], and emits an [Event] for
+each poll. Transactions that are still not final after the payment page
+lifetime are marked as TIMEOUT locally.
+*/
+package poller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+const (
+	defaultWorkers        = 4
+	defaultInterval       = 10 * time.Second
+	defaultInitialBackoff = 5 * time.Second
+	defaultMaxBackoff     = 5 * time.Minute
+	defaultPageLifetime   = 10 * time.Minute
+)
+
+// Config is the configuration required to set up a [Poller].
+type Config struct {
+	// Sender used to send TransactionStatus. Required.
+	Sender maib.Sender
+
+	// Storage for the tracked transactions. Default is a new [MemoryStore].
+	Store Store
+
+	// Source of time. Default is [clock.Real].
+	Clock clock.Clock
+
+	// Number of concurrent TransactionStatus requests. Default is 4.
+	Workers int
+
+	// How often the store is checked for due transactions. Default is 10 seconds.
+	Interval time.Duration
+
+	// Delay before the first poll of a transaction. It is doubled after each poll
+	// that doesn't change the status. Default is 5 seconds.
+	InitialBackoff time.Duration
+
+	// Upper limit of the delay between polls. Default is 5 minutes.
+	MaxBackoff time.Duration
+
+	// Lifetime of the payment page on the client handler. A transaction that is
+	// not final after this time since registration is considered abandoned.
+	// Default is 10 minutes.
+	PageLifetime time.Duration
+
+	// Called after each poll. Optional. It is called from the worker goroutines,
+	// so it must be safe for concurrent use.
+	OnEvent func(Event)
+}
+
+// Poller polls transactions that are not final. Must be initiated with [New].
+type Poller struct {
+	sender         maib.Sender
+	store          Store
+	clock          clock.Clock
+	workers        int
+	interval       time.Duration
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	pageLifetime   time.Duration
+	onEvent        func(Event)
+
+	mu       sync.Mutex
+	inFlight map[string]bool
+}
+
+// New validates the configuration and returns a *[Poller].
+func New(config Config) (*Poller, error) {
+	if config.Sender == nil {
+		return nil, errors.New("sender is required")
+	}
+
+	p := &Poller{
+		sender:         config.Sender,
+		store:          config.Store,
+		clock:          config.Clock,
+		workers:        config.Workers,
+		interval:       config.Interval,
+		initialBackoff: config.InitialBackoff,
+		maxBackoff:     config.MaxBackoff,
+		pageLifetime:   config.PageLifetime,
+		onEvent:        config.OnEvent,
+		inFlight:       make(map[string]bool),
+	}
+	if p.store == nil {
+		p.store = NewMemoryStore()
+	}
+	if p.clock == nil {
+		p.clock = clock.Real
+	}
+	if p.workers <= 0 {
+		p.workers = defaultWorkers
+	}
+	if p.interval <= 0 {
+		p.interval = defaultInterval
+	}
+	if p.initialBackoff <= 0 {
+		p.initialBackoff = defaultInitialBackoff
+	}
+	if p.maxBackoff <= 0 {
+		p.maxBackoff = defaultMaxBackoff
+	}
+	if p.pageLifetime <= 0 {
+		p.pageLifetime = defaultPageLifetime
+	}
+	return p, nil
+}
+
+// Track starts polling a newly registered transaction. The first poll happens
+// after the initial backoff. The merchant reference of the context, set by
+// maib.WithMerchantReference, is kept with the transaction.
+func (p *Poller) Track(ctx context.Context, transactionID string, clientIPAddress string) error {
+	now := p.clock.Now()
+	reference, _ := maib.MerchantReferenceFrom(ctx)
+	err := p.store.Save(ctx, Transaction{
+		ID:                transactionID,
+		ClientIPAddress:   clientIPAddress,
+		MerchantReference: reference,
+		RegisteredAt:      now,
+		Result:            maib.ResultCreated,
+		NextPollAt:        now.Add(p.initialBackoff),
+	})
+	if err != nil {
+		return fmt.Errorf("save transaction: %w", err)
+	}
+	return nil
+}
+
+// Run blocks until the context is done, polling due transactions on every
+// interval. It returns early if the store fails.
+func (p *Poller) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	jobs := make(chan Transaction)
+	var wg sync.WaitGroup
+	for range p.workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for t := range jobs {
+				err := p.poll(ctx, t)
+				if err != nil {
+					cancel(err)
+				}
+				p.mu.Lock()
+				delete(p.inFlight, t.ID)
+				p.mu.Unlock()
+			}
+		}()
+	}
+
+	err := p.dispatch(ctx, jobs)
+	close(jobs)
+	wg.Wait()
+
+	p.mu.Lock()
+	clear(p.inFlight)
+	p.mu.Unlock()
+
+	if cause := context.Cause(ctx); cause != nil && !errors.Is(cause, context.Canceled) {
+		return cause
+	}
+	return err
+}
+
+// dispatch sends due transactions to the workers until the context is done.
+func (p *Poller) dispatch(ctx context.Context, jobs chan<- Transaction) error {
+	for {
+		due, err := p.store.Due(ctx, p.clock.Now())
+		if err != nil {
+			return fmt.Errorf("get due transactions: %w", err)
+		}
+
+		for _, t := range due {
+			p.mu.Lock()
+			skip := p.inFlight[t.ID]
+			p.inFlight[t.ID] = true
+			p.mu.Unlock()
+			if skip {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case jobs <- t:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-p.clock.After(p.interval):
+		}
+	}
+}
+
+// poll sends TransactionStatus for one transaction, saves the new state and
+// emits an event. Only store errors are returned.
+func (p *Poller) poll(ctx context.Context, t Transaction) error {
+	status, err := p.status(ctx, t)
+	if ctx.Err() != nil {
+		return nil
+	}
+	now := p.clock.Now()
+	previous := t.Result
+
+	var event Event
+	expired := now.Sub(t.RegisteredAt) >= p.pageLifetime
+	switch {
+	case err != nil && expired:
+		// A transaction that can't be polled stops being polled too.
+		t.Result = maib.ResultTimeout
+		event = TimedOut{Record: t, Previous: previous, Err: err}
+
+	case err != nil:
+		t.Attempts++
+		t.NextPollAt = now.Add(p.backoff(t.Attempts))
+		event = Failed{Record: t, Err: err}
+
+	case status.Result.IsFinal():
+		t.Result = status.Result
+		event = Finalized{Record: t, Previous: previous, Status: status}
+
+	case expired:
+		t.Result = maib.ResultTimeout
+		event = TimedOut{Record: t, Previous: previous}
+
+	case status.Result != previous:
+		t.Result = status.Result
+		t.Attempts = 0
+		t.NextPollAt = now.Add(p.initialBackoff)
+		event = Pending{Record: t, Previous: previous, Status: status}
+
+	default:
+		t.Attempts++
+		t.NextPollAt = now.Add(p.backoff(t.Attempts))
+		event = Pending{Record: t, Previous: previous, Status: status}
+	}
+
+	err = p.store.Save(ctx, t)
+	if err != nil {
+		return fmt.Errorf("save transaction: %w", err)
+	}
+	if p.onEvent != nil {
+		p.onEvent(event)
+	}
+	return nil
+}
+
+func (p *Poller) status(ctx context.Context, t Transaction) (requests.TransactionStatusResult, error) {
+	if t.MerchantReference != "" {
+		ctx = maib.WithMerchantReference(ctx, t.MerchantReference)
+	}
+	res, err := p.sender.Send(ctx, requests.TransactionStatus{
+		TransactionID:   t.ID,
+		ClientIPAddress: t.ClientIPAddress,
+	})
+	if err != nil {
+		return requests.TransactionStatusResult{}, err
+	}
+	status, err := requests.DecodeResponse[requests.TransactionStatusResult](res)
+	if err != nil {
+		return requests.TransactionStatusResult{}, fmt.Errorf("decode response: %w", err)
+	}
+	return status, nil
+}
+
+// backoff returns the delay before the next poll after the given number of
+// unchanged polls.
+func (p *Poller) backoff(attempts int) time.Duration {
+	delay := p.initialBackoff
+	for i := 0; i < attempts && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.maxBackoff)
+}
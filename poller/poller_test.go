@@ -0,0 +1,250 @@
+package poller
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+const (
+	transactionA = "aaaaaaaaaaaaaaaaaaaaaaaaaaa="
+	transactionB = "bbbbbbbbbbbbbbbbbbbbbbbbbbb="
+)
+
+// fakeSender returns the scripted statuses for each transaction in order. The
+// last status is repeated. A nil status results in an ECommError.
+type fakeSender struct {
+	mu       sync.Mutex
+	statuses map[string][]maib.ResultEnum
+	calls    map[string]int
+}
+
+func (f *fakeSender) Send(_ context.Context, req maib.Request) (map[string]any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	id := req.(requests.TransactionStatus).TransactionID
+	f.calls[id]++
+	statuses := f.statuses[id]
+	status := statuses[0]
+	if len(statuses) > 1 {
+		f.statuses[id] = statuses[1:]
+	}
+	if status == "" {
+		return nil, &maib.ECommError{Code: 500}
+	}
+	return map[string]any{"RESULT": string(status)}, nil
+}
+
+func (f *fakeSender) Calls(id string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[id]
+}
+
+type fixture struct {
+	clock  *clock.Fake
+	store  *MemoryStore
+	sender *fakeSender
+	poller *Poller
+	events chan Event
+	stop   func() error
+}
+
+func setup(t *testing.T, statuses map[string][]maib.ResultEnum) *fixture {
+	f := &fixture{
+		clock:  clock.NewFake(time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)),
+		store:  NewMemoryStore(),
+		sender: &fakeSender{statuses: statuses, calls: make(map[string]int)},
+		events: make(chan Event, 100),
+	}
+	var err error
+	f.poller, err = New(Config{
+		Sender:         f.sender,
+		Store:          f.store,
+		Clock:          f.clock,
+		Workers:        2,
+		Interval:       time.Second,
+		InitialBackoff: time.Second,
+		MaxBackoff:     8 * time.Second,
+		PageLifetime:   time.Minute,
+		OnEvent: func(event Event) {
+			f.events <- event
+		},
+	})
+	assert.Nil(t, err)
+	for id := range statuses {
+		assert.Nil(t, f.poller.Track(context.Background(), id, "127.0.0.1"))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- f.poller.Run(ctx)
+	}()
+	f.stop = func() error {
+		cancel()
+		return <-done
+	}
+	return f
+}
+
+// tick advances the clock by one interval once the dispatcher is waiting.
+func (f *fixture) tick() {
+	f.clock.BlockUntil(1)
+	f.clock.Advance(time.Second)
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.Error(t, err)
+}
+
+func TestPoller_Finalized(t *testing.T) {
+	f := setup(t, map[string][]maib.ResultEnum{
+		transactionA: {maib.ResultPending, maib.ResultOk},
+	})
+
+	f.tick()
+	event := (<-f.events).(Pending)
+	assert.Equal(t, maib.ResultCreated, event.Previous)
+	assert.Equal(t, maib.ResultPending, event.Record.Result)
+
+	f.tick()
+	finalized := (<-f.events).(Finalized)
+	assert.Equal(t, maib.ResultPending, finalized.Previous)
+	assert.Equal(t, maib.ResultOk, finalized.Status.Result)
+
+	// Final transactions are not polled anymore.
+	for range 5 {
+		f.tick()
+	}
+	assert.ErrorIs(t, f.stop(), context.Canceled)
+	assert.Equal(t, 2, f.sender.Calls(transactionA))
+
+	saved, ok := f.store.Get(transactionA)
+	assert.True(t, ok)
+	assert.Equal(t, maib.ResultOk, saved.Result)
+}
+
+func TestPoller_Backoff(t *testing.T) {
+	f := setup(t, map[string][]maib.ResultEnum{
+		transactionA: {maib.ResultCreated},
+	})
+
+	// Polls happen after 1s, then 2s, then 4s.
+	f.tick()
+	event := (<-f.events).(Pending)
+	assert.Equal(t, 2*time.Second, event.Record.NextPollAt.Sub(f.clock.Now()))
+
+	f.clock.BlockUntil(1)
+	f.clock.Set(event.Record.NextPollAt)
+	event = (<-f.events).(Pending)
+	assert.Equal(t, 4*time.Second, event.Record.NextPollAt.Sub(f.clock.Now()))
+	assert.Equal(t, 2, event.Record.Attempts)
+
+	assert.ErrorIs(t, f.stop(), context.Canceled)
+}
+
+func TestPoller_TimedOut(t *testing.T) {
+	f := setup(t, map[string][]maib.ResultEnum{
+		transactionA: {maib.ResultCreated},
+	})
+
+	f.clock.BlockUntil(1)
+	f.clock.Advance(time.Minute)
+	timedOut := (<-f.events).(TimedOut)
+	assert.Equal(t, maib.ResultCreated, timedOut.Previous)
+	assert.Equal(t, maib.ResultTimeout, timedOut.Record.Result)
+	assert.ErrorIs(t, f.stop(), context.Canceled)
+
+	saved, _ := f.store.Get(transactionA)
+	assert.Equal(t, maib.ResultTimeout, saved.Result)
+}
+
+func TestPoller_Failed(t *testing.T) {
+	f := setup(t, map[string][]maib.ResultEnum{
+		transactionA: {"", maib.ResultDeclined},
+		transactionB: {maib.ResultFailed},
+	})
+
+	f.tick()
+	var failed Failed
+	var finalized Finalized
+	for range 2 {
+		switch event := (<-f.events).(type) {
+		case Failed:
+			failed = event
+		case Finalized:
+			finalized = event
+		}
+	}
+	assert.Equal(t, transactionA, failed.Record.ID)
+	assert.ErrorAs(t, failed.Err, new(*maib.ECommError))
+	assert.Equal(t, transactionB, finalized.Record.ID)
+
+	f.tick()
+	f.tick()
+	finalized = (<-f.events).(Finalized)
+	assert.Equal(t, transactionA, finalized.Record.ID)
+	assert.Equal(t, maib.ResultDeclined, finalized.Record.Result)
+	assert.ErrorIs(t, f.stop(), context.Canceled)
+}
+
+func TestPoller_Failed_TimedOut(t *testing.T) {
+	f := setup(t, map[string][]maib.ResultEnum{
+		transactionA: {""},
+	})
+
+	f.tick()
+	failed := (<-f.events).(Failed)
+	assert.Equal(t, 1, failed.Record.Attempts)
+
+	f.clock.BlockUntil(1)
+	f.clock.Advance(time.Minute)
+	timedOut := (<-f.events).(TimedOut)
+	assert.Equal(t, maib.ResultTimeout, timedOut.Record.Result)
+	assert.ErrorAs(t, timedOut.Err, new(*maib.ECommError))
+
+	// Timed out transactions are not polled anymore.
+	for range 5 {
+		f.tick()
+	}
+	assert.ErrorIs(t, f.stop(), context.Canceled)
+	assert.Equal(t, 2, f.sender.Calls(transactionA))
+}
+
+func TestPoller_Track(t *testing.T) {
+	store := NewMemoryStore()
+	p, err := New(Config{Sender: &fakeSender{}, Store: store, Clock: clock.NewFake(time.Time{})})
+	assert.Nil(t, err)
+
+	ctx := maib.WithMerchantReference(context.Background(), "order-1")
+	assert.Nil(t, p.Track(ctx, transactionA, "127.0.0.1"))
+	tracked, ok := store.Get(transactionA)
+	assert.True(t, ok)
+	assert.Equal(t, "order-1", tracked.MerchantReference)
+	assert.Equal(t, maib.ResultCreated, tracked.Result)
+}
+
+func TestPoller_backoff(t *testing.T) {
+	p, err := New(Config{
+		Sender:         &fakeSender{},
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+	})
+	assert.Nil(t, err)
+
+	assert.Equal(t, time.Second, p.backoff(0))
+	assert.Equal(t, 2*time.Second, p.backoff(1))
+	assert.Equal(t, 4*time.Second, p.backoff(2))
+	assert.Equal(t, 5*time.Second, p.backoff(3))
+	assert.Equal(t, 5*time.Second, p.backoff(100))
+}
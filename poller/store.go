@@ -0,0 +1,94 @@
+package poller
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// Transaction is the local record of a transaction tracked by the [Poller].
+type Transaction struct {
+	// ID of the transaction. 28 symbols in base64.
+	ID string
+
+	// Client's IP address in quad-dotted notation, like "127.0.0.1". Sent with
+	// every TransactionStatus request.
+	ClientIPAddress string
+
+	// Merchant's reference of the transaction, set by maib.WithMerchantReference
+	// on the context passed to [Poller.Track]. Optional. Set on the context of
+	// every TransactionStatus request, for the logs and errors of the client.
+	// It is not sent to the ECommerce system.
+	MerchantReference string
+
+	// When the transaction was registered. Used to detect abandoned payment
+	// pages.
+	RegisteredAt time.Time
+
+	// Last known status. [maib.ResultCreated] until a poll returns another one.
+	Result maib.ResultEnum
+
+	// Number of polls since the status has last changed. Used for backoff.
+	Attempts int
+
+	// When the transaction should be polled next.
+	NextPollAt time.Time
+}
+
+// Store persists the tracked transactions.
+type Store interface {
+	// Due returns the transactions with a non-final Result whose NextPollAt is not
+	// after now, ordered by NextPollAt.
+	Due(ctx context.Context, now time.Time) ([]Transaction, error)
+
+	// Save creates or replaces the transaction with the same ID.
+	Save(ctx context.Context, transaction Transaction) error
+}
+
+// MemoryStore is a [Store] that keeps transactions in memory. It is safe for
+// concurrent use.
+type MemoryStore struct {
+	mu           sync.Mutex
+	transactions map[string]Transaction
+}
+
+// NewMemoryStore returns an empty *[MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		transactions: make(map[string]Transaction),
+	}
+}
+
+func (s *MemoryStore) Due(_ context.Context, now time.Time) ([]Transaction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Transaction
+	for _, t := range s.transactions {
+		if !t.Result.IsFinal() && !t.NextPollAt.After(now) {
+			due = append(due, t)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextPollAt.Before(due[j].NextPollAt)
+	})
+	return due, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, transaction Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.transactions[transaction.ID] = transaction
+	return nil
+}
+
+// Get returns the transaction with the given ID, and whether it was found.
+func (s *MemoryStore) Get(id string) (Transaction, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.transactions[id]
+	return t, ok
+}
@@ -0,0 +1,33 @@
+package poller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+func TestMemoryStore_Due(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	assert.Nil(t, store.Save(ctx, Transaction{ID: "later", Result: maib.ResultCreated, NextPollAt: now.Add(time.Second)}))
+	assert.Nil(t, store.Save(ctx, Transaction{ID: "second", Result: maib.ResultPending, NextPollAt: now}))
+	assert.Nil(t, store.Save(ctx, Transaction{ID: "first", Result: maib.ResultCreated, NextPollAt: now.Add(-time.Second)}))
+	assert.Nil(t, store.Save(ctx, Transaction{ID: "final", Result: maib.ResultOk, NextPollAt: now.Add(-time.Hour)}))
+
+	due, err := store.Due(ctx, now)
+	assert.Nil(t, err)
+	assert.Len(t, due, 2)
+	assert.Equal(t, "first", due[0].ID)
+	assert.Equal(t, "second", due[1].ID)
+
+	_, ok := store.Get("final")
+	assert.True(t, ok)
+	_, ok = store.Get("missing")
+	assert.False(t, ok)
+}
@@ -0,0 +1,74 @@
+package maib
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// ExchangeStage is the point of [Client.Send] at which an [Exchange] is
+// recorded.
+type ExchangeStage string
+
+const (
+	// StageSent - the request is about to be sent. The exchange has no
+	// Response and no Err.
+	StageSent ExchangeStage = "SENT"
+
+	// StageCompleted - the request has returned a response or an error.
+	StageCompleted ExchangeStage = "COMPLETED"
+)
+
+// Exchange is a request sent by [Client.Send] to the ECommerce system, and its
+// outcome.
+type Exchange struct {
+	// When the exchange is recorded.
+	Stage ExchangeStage
+
+	// The sent request.
+	Request Request
+
+	// Payload of the request.
+	Values url.Values
+
+	// Parsed response, if the request succeeded.
+	Response map[string]any
+
+	// Error, if the request failed.
+	Err error
+
+	// When the request was sent.
+	SentAt time.Time
+}
+
+// Recorder records every request sent by the [Client], e.g. to keep an audit
+// journal. Requests that fail validation, and requests answered from the
+// [IdempotencyStore], are not sent, so they are not recorded.
+//
+// Each request is recorded twice: at [StageSent] before it is sent, so a
+// request whose outcome is lost, e.g. by a crash, is still recorded, and at
+// [StageCompleted] with its outcome.
+type Recorder interface {
+	// RecordExchange records the exchange. If the exchange at StageSent can't
+	// be recorded, the request is not sent, and [Client.Send] returns a
+	// *[RecordError]. An error at StageCompleted is reported to
+	// [Config.OnStoreError] instead, because the request may have been
+	// executed.
+	RecordExchange(ctx context.Context, exchange Exchange) error
+}
+
+// RecordError is returned by [Client.Send] when the [Recorder] fails to record
+// the request before it is sent. Nothing is sent.
+type RecordError struct {
+	// Error returned by the Recorder.
+	Err error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("record exchange: %v", e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,145 @@
+package maib
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type exchangeRecorder struct {
+	mu        sync.Mutex
+	exchanges []Exchange
+	ctxErrs   []error
+
+	// Returned when an exchange at errStage is recorded.
+	err      error
+	errStage ExchangeStage
+
+	// Called after an exchange is recorded.
+	onRecord func(Exchange)
+}
+
+func (r *exchangeRecorder) RecordExchange(ctx context.Context, exchange Exchange) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.exchanges = append(r.exchanges, exchange)
+	r.ctxErrs = append(r.ctxErrs, ctx.Err())
+	if r.onRecord != nil {
+		r.onRecord(exchange)
+	}
+	if exchange.Stage == r.errStage {
+		return r.err
+	}
+	return nil
+}
+
+func createRecordingClient(t *testing.T, status int) (*Client, *exchangeRecorder) {
+	client, _ := createCountingClient(t, status)
+	recorder := &exchangeRecorder{}
+	client.recorder = recorder
+	client.now = func() time.Time {
+		return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	}
+	return client, recorder
+}
+
+func TestClient_Send_Recorder(t *testing.T) {
+	client, recorder := createRecordingClient(t, http.StatusOK)
+
+	res, err := client.Send(ctx, amountRequest{100})
+	require.NoError(t, err)
+	_, err = client.Send(ctx, testRequest{isValid: false})
+	require.Error(t, err)
+
+	require.Len(t, recorder.exchanges, 2)
+	assert.Equal(t, Exchange{
+		Stage:   StageSent,
+		Request: amountRequest{100},
+		Values:  url.Values{"command": {testCommand}, "amount": {"100"}},
+		SentAt:  time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, recorder.exchanges[0])
+	assert.Equal(t, Exchange{
+		Stage:    StageCompleted,
+		Request:  amountRequest{100},
+		Values:   url.Values{"command": {testCommand}, "amount": {"100"}},
+		Response: res,
+		SentAt:   time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+	}, recorder.exchanges[1])
+}
+
+func TestClient_Send_RecorderFailedRequest(t *testing.T) {
+	client, recorder := createRecordingClient(t, http.StatusInternalServerError)
+
+	_, err := client.Send(ctx, amountRequest{100})
+	var eCommErr *ECommError
+	require.ErrorAs(t, err, &eCommErr)
+
+	require.Len(t, recorder.exchanges, 2)
+	assert.Equal(t, StageCompleted, recorder.exchanges[1].Stage)
+	assert.Nil(t, recorder.exchanges[1].Response)
+	assert.Equal(t, err, recorder.exchanges[1].Err)
+}
+
+func TestClient_Send_RecorderCanceled(t *testing.T) {
+	client, recorder := createRecordingClient(t, http.StatusOK)
+	canceled, cancel := context.WithCancel(ctx)
+	defer cancel()
+	recorder.onRecord = func(exchange Exchange) {
+		if exchange.Stage == StageSent {
+			cancel()
+		}
+	}
+
+	// The outcome is recorded even though the context is done.
+	_, err := client.Send(canceled, amountRequest{100})
+	require.ErrorIs(t, err, context.Canceled)
+	require.Len(t, recorder.exchanges, 2)
+	assert.ErrorIs(t, recorder.exchanges[1].Err, context.Canceled)
+	assert.NoError(t, recorder.ctxErrs[1])
+}
+
+func TestClient_Send_RecorderSentError(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusOK)
+	recorder := &exchangeRecorder{err: errors.New("disk full"), errStage: StageSent}
+	client.recorder = recorder
+	keyed := WithIdempotencyKey(ctx, "order-1")
+
+	// The request can't be recorded, so it is not sent, and the key is released.
+	_, err := client.Send(keyed, amountRequest{100})
+	var recordErr *RecordError
+	require.ErrorAs(t, err, &recordErr)
+	assert.EqualError(t, err, "record exchange: disk full")
+	assert.True(t, IsRejected(err))
+	assert.Len(t, recorder.exchanges, 1)
+	assert.Zero(t, hits.Load())
+
+	recorder.err = nil
+	_, err = client.Send(keyed, amountRequest{100})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), hits.Load())
+}
+
+func TestClient_Send_RecorderError(t *testing.T) {
+	client, recorder := createRecordingClient(t, http.StatusOK)
+	recordErr := errors.New("disk full")
+	recorder.err = recordErr
+	recorder.errStage = StageCompleted
+
+	var storeErr error
+	client.onStoreError = func(_ context.Context, err error) {
+		storeErr = err
+	}
+
+	// The request was executed, so Send doesn't fail.
+	res, err := client.Send(ctx, amountRequest{100})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"TRANSACTION_ID": "1"}, res)
+	assert.ErrorIs(t, storeErr, recordErr)
+}
@@ -0,0 +1,214 @@
+package maib
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+type merchantReferenceKey struct{}
+
+// WithMerchantReference returns a context that links the requests sent by
+// [Client.Send] to the merchant's own reference, e.g. the order number.
+//
+// When a request sent with the context returns a new TRANSACTION_ID, like
+// RegisterTransaction or ExecuteRecurring do, a [TransactionRecord] linking
+// the ID to the reference is saved in the [TransactionStore] of the client.
+// The reference is attached to the logs of the client and to the returned
+// errors as [ReferenceError], and it is looked up by the transaction ID for
+// requests that are sent without it, like TransactionStatus.
+func WithMerchantReference(ctx context.Context, reference string) context.Context {
+	return context.WithValue(ctx, merchantReferenceKey{}, reference)
+}
+
+// MerchantReferenceFrom returns the merchant reference of the context, set by
+// [WithMerchantReference].
+func MerchantReferenceFrom(ctx context.Context) (string, bool) {
+	reference, ok := ctx.Value(merchantReferenceKey{}).(string)
+	return reference, ok && reference != ""
+}
+
+// TransactionRecord links a transaction to the merchant reference it was
+// created with.
+type TransactionRecord struct {
+	// ID of the transaction. 28 symbols in base64.
+	TransactionID string
+
+	// Merchant's reference, set by [WithMerchantReference].
+	MerchantReference string
+
+	// Command field of the request that created the transaction, like "v" for
+	// RegisterTransaction with SMS type.
+	Command string
+
+	// When the transaction was created.
+	CreatedAt time.Time
+}
+
+// TransactionStore persists [TransactionRecord]s.
+type TransactionStore interface {
+	// Save creates or replaces the record with the same TransactionID.
+	Save(ctx context.Context, record TransactionRecord) error
+
+	// FindByTransactionID returns the record of the transaction, or false if
+	// there is none.
+	FindByTransactionID(ctx context.Context, transactionID string) (TransactionRecord, bool, error)
+
+	// FindByMerchantReference returns the records with the reference, ordered
+	// by CreatedAt. One reference may have several transactions, e.g. when a
+	// payment is retried.
+	FindByMerchantReference(ctx context.Context, reference string) ([]TransactionRecord, error)
+}
+
+// ReferenceError is returned by [Client.Send] when a request linked to a
+// merchant reference fails. Use [errors.As] on it, or on the returned error
+// directly, to get the cause.
+type ReferenceError struct {
+	// Merchant's reference, set by [WithMerchantReference].
+	MerchantReference string
+
+	// ID of the transaction, if it is known.
+	TransactionID string
+
+	// The cause.
+	Err error
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("merchant reference %q: %s", e.MerchantReference, e.Err)
+}
+
+func (e *ReferenceError) Unwrap() error {
+	return e.Err
+}
+
+// MemoryTransactionStore is a [TransactionStore] that keeps records in memory.
+// It is safe for concurrent use.
+type MemoryTransactionStore struct {
+	mu      sync.Mutex
+	records map[string]TransactionRecord
+}
+
+// NewMemoryTransactionStore returns an empty *[MemoryTransactionStore].
+func NewMemoryTransactionStore() *MemoryTransactionStore {
+	return &MemoryTransactionStore{records: make(map[string]TransactionRecord)}
+}
+
+func (s *MemoryTransactionStore) Save(_ context.Context, record TransactionRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[record.TransactionID] = record
+	return nil
+}
+
+func (s *MemoryTransactionStore) FindByTransactionID(_ context.Context, transactionID string) (TransactionRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[transactionID]
+	return record, ok, nil
+}
+
+func (s *MemoryTransactionStore) FindByMerchantReference(_ context.Context, reference string) ([]TransactionRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var found []TransactionRecord
+	for _, r := range s.records {
+		if r.MerchantReference == reference {
+			found = append(found, r)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].CreatedAt.Before(found[j].CreatedAt)
+	})
+	return found, nil
+}
+
+// FindByMerchantReference returns the transactions created by requests sent
+// with the merchant reference, see [WithMerchantReference].
+func (c *Client) FindByMerchantReference(ctx context.Context, reference string) ([]TransactionRecord, error) {
+	records, err := c.transactionStore.FindByMerchantReference(ctx, reference)
+	if err != nil {
+		return nil, fmt.Errorf("find transactions: %w", err)
+	}
+	return records, nil
+}
+
+// FindByTransactionID returns the record of a transaction created by a request
+// sent with a merchant reference, or false if there is none.
+func (c *Client) FindByTransactionID(ctx context.Context, transactionID string) (TransactionRecord, bool, error) {
+	record, ok, err := c.transactionStore.FindByTransactionID(ctx, transactionID)
+	if err != nil {
+		return TransactionRecord{}, false, fmt.Errorf("find transaction: %w", err)
+	}
+	return record, ok, nil
+}
+
+// correlate links the result of Send to the merchant reference: it saves the
+// returned transaction ID, logs the request, and wraps the error.
+func (c *Client) correlate(ctx context.Context, values url.Values, res map[string]any, err error) (map[string]any, error) {
+	command := values.Get(string(FieldCommand))
+	transactionID := values.Get(string(FieldTransactionID))
+	createdID, created := res["TRANSACTION_ID"].(string)
+	if created {
+		transactionID = createdID
+	}
+
+	reference, ok := MerchantReferenceFrom(ctx)
+	switch {
+	case ok && created:
+		saveErr := c.transactionStore.Save(context.WithoutCancel(ctx), TransactionRecord{
+			TransactionID:     transactionID,
+			MerchantReference: reference,
+			Command:           command,
+			CreatedAt:         c.now(),
+		})
+		if saveErr != nil {
+			c.storeFailed(ctx, fmt.Errorf("save transaction record: %w", saveErr))
+		}
+	case !ok && transactionID != "" && (err != nil || c.logger != nil):
+		record, found, findErr := c.transactionStore.FindByTransactionID(ctx, transactionID)
+		if findErr == nil && found {
+			reference = record.MerchantReference
+		}
+	}
+
+	if c.logger != nil {
+		attrs := []slog.Attr{slog.String("command", command)}
+		if transactionID != "" {
+			attrs = append(attrs, slog.String("transaction_id", transactionID))
+		}
+		if reference != "" {
+			attrs = append(attrs, slog.String("merchant_reference", reference))
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", err.Error()))
+			c.logger.LogAttrs(ctx, slog.LevelWarn, "maib request failed", attrs...)
+		} else {
+			c.logger.LogAttrs(ctx, slog.LevelDebug, "maib request sent", attrs...)
+		}
+	}
+
+	if err != nil && reference != "" {
+		err = &ReferenceError{
+			MerchantReference: reference,
+			TransactionID:     transactionID,
+			Err:               err,
+		}
+	}
+	return res, err
+}
+
+// storeFailed reports an error saving the records of a sent request, which
+// doesn't fail the request.
+func (c *Client) storeFailed(ctx context.Context, err error) {
+	if c.logger != nil {
+		c.logger.LogAttrs(ctx, slog.LevelError, "maib request record not saved", slog.String("error", err.Error()))
+	}
+	if c.onStoreError != nil {
+		c.onStoreError(ctx, err)
+	}
+}
@@ -0,0 +1,146 @@
+package maib
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const referenceTransactionID = "abcdefghijklmnopqrstuvwxyz0="
+
+type transactionRequest struct {
+	id string
+}
+
+func (r transactionRequest) Values() (url.Values, error) {
+	return url.Values{"command": {"c"}, "trans_id": {r.id}}, nil
+}
+
+// createReferenceClient returns a client whose server creates a transaction
+// for requests without trans_id, and rejects the others.
+func createReferenceClient(t *testing.T, logs *bytes.Buffer) *Client {
+	ca, serverCert := loadCerts(t)
+	server := createServer(ca, serverCert, func(writer http.ResponseWriter, request *http.Request) {
+		if request.FormValue("trans_id") != "" {
+			_, _ = writer.Write([]byte("error: wrong trans_id"))
+			return
+		}
+		_, _ = writer.Write([]byte("TRANSACTION_ID: " + referenceTransactionID))
+	})
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	client, err := createTrustingClient(t, server.URL, ca, ca.Pool())
+	require.NoError(t, err)
+	if logs != nil {
+		client.logger = slog.New(slog.NewTextHandler(logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+	return client
+}
+
+func TestClient_Send_MerchantReference(t *testing.T) {
+	var logs bytes.Buffer
+	client := createReferenceClient(t, &logs)
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	client.now = func() time.Time { return now }
+
+	_, err := client.Send(WithMerchantReference(ctx, "order-1"), amountRequest{100})
+	require.NoError(t, err)
+	assert.Contains(t, logs.String(), "merchant_reference=order-1")
+
+	records, err := client.FindByMerchantReference(ctx, "order-1")
+	require.NoError(t, err)
+	expected := TransactionRecord{
+		TransactionID:     referenceTransactionID,
+		MerchantReference: "order-1",
+		Command:           testCommand,
+		CreatedAt:         now,
+	}
+	assert.Equal(t, []TransactionRecord{expected}, records)
+	record, ok, err := client.FindByTransactionID(ctx, referenceTransactionID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, expected, record)
+
+	// The reference is found by the transaction ID of a later request.
+	logs.Reset()
+	_, err = client.Send(ctx, transactionRequest{referenceTransactionID})
+	var referenceErr *ReferenceError
+	require.ErrorAs(t, err, &referenceErr)
+	assert.Equal(t, "order-1", referenceErr.MerchantReference)
+	assert.Equal(t, referenceTransactionID, referenceErr.TransactionID)
+	assert.ErrorAs(t, err, new(*ECommError))
+	assert.Contains(t, logs.String(), "level=WARN")
+	assert.Contains(t, logs.String(), "merchant_reference=order-1")
+}
+
+func TestClient_Send_WithoutMerchantReference(t *testing.T) {
+	client := createReferenceClient(t, nil)
+
+	_, err := client.Send(ctx, amountRequest{100})
+	require.NoError(t, err)
+	_, ok, err := client.FindByTransactionID(ctx, referenceTransactionID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	_, err = client.Send(ctx, transactionRequest{referenceTransactionID})
+	assert.ErrorAs(t, err, new(*ECommError))
+	assert.False(t, errors.As(err, new(*ReferenceError)))
+}
+
+type failingTransactionStore struct {
+	*MemoryTransactionStore
+}
+
+func (failingTransactionStore) Save(_ context.Context, _ TransactionRecord) error {
+	return errors.New("disk full")
+}
+
+func TestClient_Send_MerchantReferenceSaveError(t *testing.T) {
+	client := createReferenceClient(t, nil)
+	client.transactionStore = failingTransactionStore{NewMemoryTransactionStore()}
+
+	var logs bytes.Buffer
+	client.logger = slog.New(slog.NewTextHandler(&logs, nil))
+	var storeErr error
+	client.onStoreError = func(_ context.Context, err error) {
+		storeErr = err
+	}
+
+	// The request was executed, so Send doesn't fail.
+	res, err := client.Send(WithMerchantReference(ctx, "order-1"), amountRequest{100})
+	require.NoError(t, err)
+	assert.Equal(t, referenceTransactionID, res["TRANSACTION_ID"])
+	assert.EqualError(t, storeErr, "save transaction record: disk full")
+	assert.Contains(t, logs.String(), `level=ERROR msg="maib request record not saved" error="save transaction record: disk full"`)
+}
+
+func TestMemoryTransactionStore(t *testing.T) {
+	store := NewMemoryTransactionStore()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Save(ctx, TransactionRecord{TransactionID: "b", MerchantReference: "order-1", CreatedAt: now.Add(time.Minute)}))
+	require.NoError(t, store.Save(ctx, TransactionRecord{TransactionID: "a", MerchantReference: "order-1", CreatedAt: now}))
+	require.NoError(t, store.Save(ctx, TransactionRecord{TransactionID: "c", MerchantReference: "order-2", CreatedAt: now}))
+
+	records, err := store.FindByMerchantReference(ctx, "order-1")
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "a", records[0].TransactionID)
+	assert.Equal(t, "b", records[1].TransactionID)
+
+	records, err = store.FindByMerchantReference(ctx, "order-3")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	record, ok, err := store.FindByTransactionID(ctx, "c")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "order-2", record.MerchantReference)
+}
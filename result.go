@@ -49,3 +49,14 @@ const (
 	// ResultPSReturned - payment was returned.
 	ResultPSReturned ResultPSEnum = "RETURNED"
 )
+
+// IsFinal reports whether the transaction has reached a status that will not
+// change on its own: OK, FAILED, DECLINED, REVERSED, AUTOREVERSED or TIMEOUT.
+func (r ResultEnum) IsFinal() bool {
+	switch r {
+	case ResultOk, ResultFailed, ResultDeclined, ResultReversed, ResultAutoReversed, ResultTimeout:
+		return true
+	default:
+		return false
+	}
+}
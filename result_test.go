@@ -0,0 +1,20 @@
+package maib
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultEnum_IsFinal(t *testing.T) {
+	assert.True(t, ResultOk.IsFinal())
+	assert.True(t, ResultFailed.IsFinal())
+	assert.True(t, ResultDeclined.IsFinal())
+	assert.True(t, ResultReversed.IsFinal())
+	assert.True(t, ResultAutoReversed.IsFinal())
+	assert.True(t, ResultTimeout.IsFinal())
+
+	assert.False(t, ResultCreated.IsFinal())
+	assert.False(t, ResultPending.IsFinal())
+	assert.False(t, ResultEnum("").IsFinal())
+}
@@ -0,0 +1,371 @@
+/*
+Package reversal tracks the partial reversals of transactions, so that the
+refunded amount never exceeds the charged one.
+
+[requests.ReverseTransaction] accepts partial amounts, and the ECommerce system
+doesn't stop a merchant from reversing the same transaction twice, e.g. after
+a double click in the back office. The [Ledger] records each charged
+transaction, reserves the amount of every reversal before sending it, and
+refuses a reversal that exceeds the refundable balance. Reversals with
+suspected fraud, and reversals of DMS authorizations that are not executed
+yet, must be for the full amount.
+*/
+package reversal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+// ErrUnknownTransaction is returned when the transaction was not recorded in
+// the [Ledger].
+var ErrUnknownTransaction = errors.New("reversal: transaction is not recorded")
+
+// ExceedsBalanceError is returned by [Ledger.Reverse] when the amount is more
+// than the refundable balance. Nothing is sent.
+type ExceedsBalanceError struct {
+	// ID of the transaction.
+	TransactionID string
+
+	// Requested amount.
+	Amount int
+
+	// Amount that can still be reversed.
+	Refundable int
+}
+
+func (e *ExceedsBalanceError) Error() string {
+	return fmt.Sprintf("reversal of %d exceeds the refundable balance %d of transaction %s", e.Amount, e.Refundable, e.TransactionID)
+}
+
+// FullReversalRequiredError is returned by [Ledger.Reverse] when a partial
+// amount is requested for a transaction that can only be reversed in full.
+// Nothing is sent.
+type FullReversalRequiredError struct {
+	// ID of the transaction.
+	TransactionID string
+
+	// Requested amount.
+	Amount int
+
+	// The only amount that can be reversed: the full amount of the
+	// transaction, or 0 if it was already partially reversed.
+	Required int
+
+	// Why only a full reversal is allowed.
+	Reason string
+}
+
+func (e *FullReversalRequiredError) Error() string {
+	return fmt.Sprintf("transaction %s can only be reversed in full (%d), not %d: %s", e.TransactionID, e.Required, e.Amount, e.Reason)
+}
+
+// Config is the configuration required to set up a [Ledger].
+type Config struct {
+	// Sender used to send ReverseTransaction. Required.
+	Sender maib.Sender
+
+	// Storage for the transactions. Default is a new [MemoryStore].
+	Store Store
+
+	// Source of time. Default is [clock.Real].
+	Clock clock.Clock
+
+	// How long a reversal may stay pending before it can be resolved with
+	// [Ledger.Settle], e.g. after the process crashed while sending it. Must
+	// be longer than the timeout of the Sender. Default is 10 minutes.
+	PendingTimeout time.Duration
+}
+
+const defaultPendingTimeout = 10 * time.Minute
+
+// Ledger sends reversals that never exceed the charged amount. It is safe for
+// concurrent use, but a [Store] shared by several processes needs a single
+// Ledger in front of it, as the balance is checked within a process.
+//
+// Must be initiated with [New].
+type Ledger struct {
+	sender         maib.Sender
+	store          Store
+	clock          clock.Clock
+	pendingTimeout time.Duration
+
+	// mu guards the check and the reservation of the balance, but not the
+	// sending.
+	mu sync.Mutex
+}
+
+// New validates the configuration and returns a *[Ledger].
+func New(config Config) (*Ledger, error) {
+	if config.Sender == nil {
+		return nil, errors.New("sender is required")
+	}
+
+	l := &Ledger{
+		sender:         config.Sender,
+		store:          config.Store,
+		clock:          config.Clock,
+		pendingTimeout: config.PendingTimeout,
+	}
+	if l.store == nil {
+		l.store = NewMemoryStore()
+	}
+	if l.clock == nil {
+		l.clock = clock.Real
+	}
+	if l.pendingTimeout == 0 {
+		l.pendingTimeout = defaultPendingTimeout
+	}
+	return l, nil
+}
+
+// Record starts tracking a charged transaction, e.g. after its
+// TransactionStatus returned OK. Its Reversals are ignored.
+func (l *Ledger) Record(ctx context.Context, transaction Transaction) error {
+	if transaction.Amount <= 0 {
+		return errors.New("amount must be positive")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	_, ok, err := l.store.Load(ctx, transaction.ID)
+	if err != nil {
+		return fmt.Errorf("load transaction: %w", err)
+	}
+	if ok {
+		return fmt.Errorf("transaction %s is already recorded", transaction.ID)
+	}
+	transaction.Reversals = nil
+	err = l.store.Save(ctx, transaction)
+	if err != nil {
+		return fmt.Errorf("save transaction: %w", err)
+	}
+	return nil
+}
+
+// Captured marks a DMS authorization as executed with the amount, after which
+// it can be reversed partially.
+func (l *Ledger) Captured(ctx context.Context, transactionID string, amount int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t, err := l.load(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	if !t.Authorization {
+		return fmt.Errorf("transaction %s is not an authorization", transactionID)
+	}
+	if len(t.Reversals) > 0 {
+		return fmt.Errorf("transaction %s has reversals", transactionID)
+	}
+	if amount <= 0 || amount > t.Amount {
+		return fmt.Errorf("captured amount %d is not within the authorized %d", amount, t.Amount)
+	}
+	t.Authorization = false
+	t.Amount = amount
+	err = l.store.Save(ctx, t)
+	if err != nil {
+		return fmt.Errorf("save transaction: %w", err)
+	}
+	return nil
+}
+
+// Balance returns the amount of the transaction that can still be reversed.
+func (l *Ledger) Balance(ctx context.Context, transactionID string) (int, error) {
+	t, err := l.load(ctx, transactionID)
+	if err != nil {
+		return 0, err
+	}
+	return t.Refundable(), nil
+}
+
+// Transaction returns the recorded transaction with its reversals.
+func (l *Ledger) Transaction(ctx context.Context, transactionID string) (Transaction, error) {
+	return l.load(ctx, transactionID)
+}
+
+// Reverse checks the amount against the refundable balance and sends
+// ReverseTransaction. The amount is reserved until the ECommerce system
+// responds, so concurrent reversals can't exceed the balance together.
+//
+// If the request fails without being rejected, e.g. with a network error or a
+// 5xx status, see maib.IsRejected, the outcome is unknown and the amount stays
+// reserved. Check the transaction, and resolve the reversal with
+// [Ledger.Settle]. Its index is the last one in the Reversals of
+// [Ledger.Transaction] right after Reverse returns, or the one with the
+// matching At and Amount.
+func (l *Ledger) Reverse(ctx context.Context, transactionID string, amount int, suspectedFraud bool) (requests.ReverseTransactionResult, error) {
+	index, err := l.reserve(ctx, transactionID, amount, suspectedFraud)
+	if err != nil {
+		return requests.ReverseTransactionResult{}, err
+	}
+
+	result, sendErr := l.send(ctx, transactionID, amount, suspectedFraud)
+	status := StatusReversed
+	switch {
+	case sendErr != nil && maib.IsRejected(sendErr):
+		status = StatusFailed
+	case sendErr != nil:
+		status = StatusUnknown
+	case result.Result != maib.ResultOk:
+		status = StatusFailed
+	}
+
+	// The outcome is saved even if the context is done.
+	err = l.complete(context.WithoutCancel(ctx), transactionID, index, status, sendErr)
+	if sendErr != nil {
+		return result, sendErr
+	}
+	return result, err
+}
+
+// Settle resolves the reversal with the index in the Reversals of the
+// transaction as reversed or failed, e.g. after checking the transaction with
+// MAIB support. Only reversals with unknown outcome can be settled, and
+// reversals pending for longer than Config.PendingTimeout, whose Reverse has
+// likely crashed.
+func (l *Ledger) Settle(ctx context.Context, transactionID string, index int, reversed bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t, err := l.load(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	if index < 0 || index >= len(t.Reversals) {
+		return fmt.Errorf("transaction %s has no reversal %d", transactionID, index)
+	}
+	r := &t.Reversals[index]
+	switch {
+	case r.Status == StatusUnknown:
+	case r.Status == StatusPending && l.clock.Now().Sub(r.At) > l.pendingTimeout:
+	case r.Status == StatusPending:
+		return fmt.Errorf("reversal %d of transaction %s is still being sent", index, transactionID)
+	default:
+		return fmt.Errorf("reversal %d of transaction %s is already %s", index, transactionID, r.Status)
+	}
+	r.Status = StatusFailed
+	if reversed {
+		r.Status = StatusReversed
+	}
+	err = l.store.Save(ctx, t)
+	if err != nil {
+		return fmt.Errorf("save transaction: %w", err)
+	}
+	return nil
+}
+
+// reserve checks the reversal, and saves it as pending. It returns the index of
+// the reversal.
+func (l *Ledger) reserve(ctx context.Context, transactionID string, amount int, suspectedFraud bool) (int, error) {
+	if amount <= 0 {
+		return 0, errors.New("amount must be positive")
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t, err := l.load(ctx, transactionID)
+	if err != nil {
+		return 0, err
+	}
+
+	refundable := t.Refundable()
+	var reason string
+	switch {
+	case suspectedFraud:
+		reason = "reversals with suspected fraud are full-amount only"
+	case t.Authorization:
+		reason = "DMS authorizations that are not executed are reversed in full"
+	}
+	if reason != "" {
+		required := t.Amount
+		if refundable != t.Amount {
+			// Part of the amount is already reversed or reserved.
+			required = 0
+		}
+		if amount != required {
+			return 0, &FullReversalRequiredError{
+				TransactionID: transactionID,
+				Amount:        amount,
+				Required:      required,
+				Reason:        reason,
+			}
+		}
+	}
+	if amount > refundable {
+		return 0, &ExceedsBalanceError{
+			TransactionID: transactionID,
+			Amount:        amount,
+			Refundable:    refundable,
+		}
+	}
+
+	t.Reversals = append(t.Reversals, Reversal{
+		Amount:         amount,
+		SuspectedFraud: suspectedFraud,
+		At:             l.clock.Now(),
+		Status:         StatusPending,
+	})
+	err = l.store.Save(ctx, t)
+	if err != nil {
+		return 0, fmt.Errorf("save transaction: %w", err)
+	}
+	return len(t.Reversals) - 1, nil
+}
+
+// complete saves the outcome of the reversal with the index.
+func (l *Ledger) complete(ctx context.Context, transactionID string, index int, status Status, sendErr error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t, err := l.load(ctx, transactionID)
+	if err != nil {
+		return err
+	}
+	if t.Reversals[index].Status != StatusPending {
+		// The reversal was settled after its PendingTimeout, so the outcome
+		// chosen there is kept.
+		return fmt.Errorf("reversal %d of transaction %s was settled as %s, not saved as %s", index, transactionID, t.Reversals[index].Status, status)
+	}
+	t.Reversals[index].Status = status
+	if sendErr != nil {
+		t.Reversals[index].Error = sendErr.Error()
+	}
+	err = l.store.Save(ctx, t)
+	if err != nil {
+		return fmt.Errorf("save transaction: %w", err)
+	}
+	return nil
+}
+
+func (l *Ledger) send(ctx context.Context, transactionID string, amount int, suspectedFraud bool) (requests.ReverseTransactionResult, error) {
+	res, err := l.sender.Send(ctx, requests.ReverseTransaction{
+		TransactionID:  transactionID,
+		Amount:         amount,
+		SuspectedFraud: suspectedFraud,
+	})
+	if err != nil {
+		return requests.ReverseTransactionResult{}, err
+	}
+	result, err := requests.DecodeResponse[requests.ReverseTransactionResult](res)
+	if err != nil {
+		return requests.ReverseTransactionResult{}, fmt.Errorf("decode response: %w", err)
+	}
+	return result, nil
+}
+
+func (l *Ledger) load(ctx context.Context, transactionID string) (Transaction, error) {
+	t, ok, err := l.store.Load(ctx, transactionID)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("load transaction: %w", err)
+	}
+	if !ok {
+		return Transaction{}, fmt.Errorf("%w: %s", ErrUnknownTransaction, transactionID)
+	}
+	return t, nil
+}
@@ -0,0 +1,229 @@
+package reversal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/maibfake"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+const transactionID = "abcdefghijklmnopqrstuvwxyz0="
+
+var ctx = context.Background()
+
+var reversed = maibfake.Result(requests.ReverseTransactionResult{Result: maib.ResultOk, ResultCode: 400})
+
+func newLedger(t *testing.T, fake *maibfake.Client, transaction Transaction) *Ledger {
+	ledger, err := New(Config{Sender: fake, Clock: clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))})
+	require.NoError(t, err)
+	require.NoError(t, ledger.Record(ctx, transaction))
+	return ledger
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{})
+	assert.EqualError(t, err, "sender is required")
+}
+
+func TestLedger_Reverse_Partial(t *testing.T) {
+	fake := maibfake.New()
+	fake.Respond(requests.ReverseTransaction{}, reversed)
+	ledger := newLedger(t, fake, Transaction{ID: transactionID, Amount: 1000, Currency: maib.CurrencyMDL})
+
+	_, err := ledger.Reverse(ctx, transactionID, 300, false)
+	require.NoError(t, err)
+	_, err = ledger.Reverse(ctx, transactionID, 500, false)
+	require.NoError(t, err)
+	balance, err := ledger.Balance(ctx, transactionID)
+	require.NoError(t, err)
+	assert.Equal(t, 200, balance)
+
+	_, err = ledger.Reverse(ctx, transactionID, 300, false)
+	var exceedsErr *ExceedsBalanceError
+	require.ErrorAs(t, err, &exceedsErr)
+	assert.Equal(t, 200, exceedsErr.Refundable)
+	fake.AssertSent(t, requests.ReverseTransaction{}, 2)
+
+	_, err = ledger.Reverse(ctx, transactionID, 200, false)
+	require.NoError(t, err)
+	transaction, err := ledger.Transaction(ctx, transactionID)
+	require.NoError(t, err)
+	assert.Equal(t, 1000, transaction.Reversed())
+	assert.Equal(t, 0, transaction.Refundable())
+}
+
+func TestLedger_Reverse_Concurrent(t *testing.T) {
+	fake := maibfake.New()
+	ledger := newLedger(t, fake, Transaction{ID: transactionID, Amount: 1000})
+
+	// A double click: the second reversal is requested while the first one is
+	// being sent.
+	var secondErr error
+	fake.RespondFunc(requests.ReverseTransaction{}, func(maib.Request) maibfake.Response {
+		if secondErr == nil {
+			_, secondErr = ledger.Reverse(ctx, transactionID, 1000, false)
+		}
+		return reversed
+	})
+
+	_, err := ledger.Reverse(ctx, transactionID, 1000, false)
+	require.NoError(t, err)
+	assert.ErrorAs(t, secondErr, new(*ExceedsBalanceError))
+	fake.AssertSentOnce(t, requests.ReverseTransaction{})
+}
+
+func TestLedger_Reverse_FullOnly(t *testing.T) {
+	fake := maibfake.New()
+	fake.Respond(requests.ReverseTransaction{}, reversed)
+
+	t.Run("suspected fraud", func(t *testing.T) {
+		ledger := newLedger(t, fake, Transaction{ID: transactionID, Amount: 1000})
+		_, err := ledger.Reverse(ctx, transactionID, 500, true)
+		var fullErr *FullReversalRequiredError
+		require.ErrorAs(t, err, &fullErr)
+		assert.Equal(t, 1000, fullErr.Required)
+
+		_, err = ledger.Reverse(ctx, transactionID, 500, false)
+		require.NoError(t, err)
+		_, err = ledger.Reverse(ctx, transactionID, 500, true)
+		require.ErrorAs(t, err, &fullErr)
+		assert.Equal(t, 0, fullErr.Required)
+	})
+
+	t.Run("DMS authorization", func(t *testing.T) {
+		ledger := newLedger(t, fake, Transaction{ID: transactionID, Amount: 1000, Authorization: true})
+		_, err := ledger.Reverse(ctx, transactionID, 500, false)
+		assert.ErrorAs(t, err, new(*FullReversalRequiredError))
+
+		_, err = ledger.Reverse(ctx, transactionID, 1000, false)
+		require.NoError(t, err)
+	})
+
+	t.Run("captured DMS authorization", func(t *testing.T) {
+		ledger := newLedger(t, fake, Transaction{ID: transactionID, Amount: 1000, Authorization: true})
+		assert.Error(t, ledger.Captured(ctx, transactionID, 1001))
+		require.NoError(t, ledger.Captured(ctx, transactionID, 800))
+		_, err := ledger.Reverse(ctx, transactionID, 500, false)
+		require.NoError(t, err)
+		balance, _ := ledger.Balance(ctx, transactionID)
+		assert.Equal(t, 300, balance)
+	})
+}
+
+func TestLedger_Reverse_Outcomes(t *testing.T) {
+	cases := []struct {
+		name     string
+		response maibfake.Response
+		status   Status
+		balance  int
+	}{
+		{
+			name:     "declined",
+			response: maibfake.Result(requests.ReverseTransactionResult{Result: maib.ResultFailed}),
+			status:   StatusFailed,
+			balance:  1000,
+		},
+		{
+			name:     "rejected",
+			response: maibfake.Error(&maib.ECommError{Code: 200, Body: "error: wrong amount"}),
+			status:   StatusFailed,
+			balance:  1000,
+		},
+		{
+			name:     "bad gateway",
+			response: maibfake.Error(&maib.ECommError{Code: 502, Body: "Bad Gateway"}),
+			status:   StatusUnknown,
+			balance:  400,
+		},
+		{
+			name:     "no response",
+			response: maibfake.Error(errors.New("connection reset")),
+			status:   StatusUnknown,
+			balance:  400,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fake := maibfake.New()
+			fake.Respond(requests.ReverseTransaction{}, c.response)
+			ledger := newLedger(t, fake, Transaction{ID: transactionID, Amount: 1000})
+
+			_, _ = ledger.Reverse(ctx, transactionID, 600, false)
+			transaction, err := ledger.Transaction(ctx, transactionID)
+			require.NoError(t, err)
+			require.Len(t, transaction.Reversals, 1)
+			assert.Equal(t, c.status, transaction.Reversals[0].Status)
+			assert.Equal(t, c.balance, transaction.Refundable())
+		})
+	}
+}
+
+func TestLedger_Settle(t *testing.T) {
+	fake := maibfake.New()
+	fake.Respond(requests.ReverseTransaction{}, maibfake.Error(context.DeadlineExceeded))
+	ledger := newLedger(t, fake, Transaction{ID: transactionID, Amount: 1000})
+
+	_, err := ledger.Reverse(ctx, transactionID, 600, false)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = ledger.Reverse(ctx, transactionID, 400, false)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, ledger.Settle(ctx, transactionID, 0, false))
+	balance, _ := ledger.Balance(ctx, transactionID)
+	assert.Equal(t, 600, balance)
+	require.NoError(t, ledger.Settle(ctx, transactionID, 1, true))
+	balance, _ = ledger.Balance(ctx, transactionID)
+	assert.Equal(t, 600, balance)
+
+	assert.ErrorContains(t, ledger.Settle(ctx, transactionID, 1, false), "already REVERSED")
+	assert.ErrorContains(t, ledger.Settle(ctx, transactionID, 2, false), "has no reversal 2")
+}
+
+func TestLedger_Settle_Pending(t *testing.T) {
+	fakeClock := clock.NewFake(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := NewMemoryStore()
+	ledger, err := New(Config{Sender: maibfake.New(), Store: store, Clock: fakeClock})
+	require.NoError(t, err)
+	require.NoError(t, ledger.Record(ctx, Transaction{ID: transactionID, Amount: 1000}))
+
+	// The process crashed after reserving the reversal.
+	_, err = ledger.reserve(ctx, transactionID, 600, false)
+	require.NoError(t, err)
+	assert.ErrorContains(t, ledger.Settle(ctx, transactionID, 0, false), "still being sent")
+
+	fakeClock.Advance(defaultPendingTimeout + time.Second)
+	require.NoError(t, ledger.Settle(ctx, transactionID, 0, false))
+	balance, _ := ledger.Balance(ctx, transactionID)
+	assert.Equal(t, 1000, balance)
+
+	// A late outcome doesn't replace the settled one.
+	err = ledger.complete(ctx, transactionID, 0, StatusReversed, nil)
+	assert.ErrorContains(t, err, "was settled as FAILED")
+	balance, _ = ledger.Balance(ctx, transactionID)
+	assert.Equal(t, 1000, balance)
+}
+
+func TestLedger_Errors(t *testing.T) {
+	ledger := newLedger(t, maibfake.New(), Transaction{ID: transactionID, Amount: 1000})
+
+	assert.ErrorContains(t, ledger.Record(ctx, Transaction{ID: transactionID, Amount: 1000}), "already recorded")
+	assert.EqualError(t, ledger.Record(ctx, Transaction{ID: "other"}), "amount must be positive")
+	assert.ErrorContains(t, ledger.Captured(ctx, transactionID, 1000), "not an authorization")
+
+	_, err := ledger.Reverse(ctx, "unknown", 100, false)
+	assert.ErrorIs(t, err, ErrUnknownTransaction)
+	_, err = ledger.Balance(ctx, "unknown")
+	assert.ErrorIs(t, err, ErrUnknownTransaction)
+	_, err = ledger.Reverse(ctx, transactionID, 0, false)
+	assert.EqualError(t, err, "amount must be positive")
+}
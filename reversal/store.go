@@ -0,0 +1,133 @@
+package reversal
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// Status is the outcome of a [Reversal].
+type Status string
+
+const (
+	// StatusPending - the reversal is being sent. Its amount is not refundable.
+	// If it stays pending, e.g. after a crash, it is resolved with
+	// [Ledger.Settle].
+	StatusPending Status = "PENDING"
+
+	// StatusReversed - the ECommerce system has reversed the amount.
+	StatusReversed Status = "REVERSED"
+
+	// StatusFailed - the ECommerce system has refused the reversal. Its amount
+	// is refundable again.
+	StatusFailed Status = "FAILED"
+
+	// StatusUnknown - the request failed before a response was received, so the
+	// amount may have been reversed. It is not refundable until the reversal is
+	// resolved with [Ledger.Settle].
+	StatusUnknown Status = "UNKNOWN"
+)
+
+// Reversal is one ReverseTransaction request sent by the [Ledger].
+type Reversal struct {
+	// Reversed amount. Positive integer with last 2 digits being the cents.
+	Amount int
+
+	// The reversal was sent with the suspected fraud flag.
+	SuspectedFraud bool
+
+	// When the reversal was requested.
+	At time.Time
+
+	// Outcome of the reversal.
+	Status Status
+
+	// Error message, if the reversal has failed or its outcome is unknown.
+	Error string
+}
+
+// Transaction is the local record of a charged transaction and its reversals.
+type Transaction struct {
+	// ID of the transaction. 28 symbols in base64.
+	ID string
+
+	// Charged amount. Positive integer with last 2 digits being the cents.
+	Amount int
+
+	// Transaction currency in ISO4217 3 digit format.
+	Currency maib.Currency
+
+	// The transaction is a DMS authorization that is not executed yet. It can
+	// only be reversed in full.
+	Authorization bool
+
+	// Reversals in the order they were requested.
+	Reversals []Reversal
+}
+
+// Reversed returns the amount that the ECommerce system has reversed.
+func (t Transaction) Reversed() int {
+	var reversed int
+	for _, r := range t.Reversals {
+		if r.Status == StatusReversed {
+			reversed += r.Amount
+		}
+	}
+	return reversed
+}
+
+// Refundable returns the amount that can still be reversed. The amounts of
+// pending reversals and reversals with unknown outcome are not refundable.
+func (t Transaction) Refundable() int {
+	refundable := t.Amount
+	for _, r := range t.Reversals {
+		if r.Status != StatusFailed {
+			refundable -= r.Amount
+		}
+	}
+	return refundable
+}
+
+// Store persists the transactions of the [Ledger].
+type Store interface {
+	// Load returns the transaction with the ID, or false if there is none.
+	Load(ctx context.Context, id string) (Transaction, bool, error)
+
+	// Save creates or replaces the transaction with the same ID.
+	Save(ctx context.Context, transaction Transaction) error
+}
+
+// MemoryStore is a [Store] that keeps transactions in memory. It is safe for
+// concurrent use.
+type MemoryStore struct {
+	mu           sync.Mutex
+	transactions map[string]Transaction
+}
+
+// NewMemoryStore returns an empty *[MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		transactions: make(map[string]Transaction),
+	}
+}
+
+func (s *MemoryStore) Load(_ context.Context, id string) (Transaction, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.transactions[id]
+	if !ok {
+		return Transaction{}, false, nil
+	}
+	t.Reversals = append([]Reversal(nil), t.Reversals...)
+	return t, true, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, transaction Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	transaction.Reversals = append([]Reversal(nil), transaction.Reversals...)
+	s.transactions[transaction.ID] = transaction
+	return nil
+}
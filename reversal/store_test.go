@@ -0,0 +1,35 @@
+package reversal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransaction_Refundable(t *testing.T) {
+	transaction := Transaction{Amount: 1000, Reversals: []Reversal{
+		{Amount: 100, Status: StatusReversed},
+		{Amount: 200, Status: StatusPending},
+		{Amount: 300, Status: StatusFailed},
+		{Amount: 50, Status: StatusUnknown},
+	}}
+	assert.Equal(t, 100, transaction.Reversed())
+	assert.Equal(t, 650, transaction.Refundable())
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	_, ok, err := store.Load(ctx, transactionID)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	transaction := Transaction{ID: transactionID, Amount: 1000, Reversals: []Reversal{{Amount: 100}}}
+	require.NoError(t, store.Save(ctx, transaction))
+	transaction.Reversals[0].Amount = 200
+
+	loaded, ok, err := store.Load(ctx, transactionID)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 100, loaded.Reversals[0].Amount)
+}
@@ -2,6 +2,7 @@ package maib
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -16,20 +17,93 @@ type Request interface {
 	Values() (url.Values, error)
 }
 
+// Sender sends a [Request] to the ECommerce system. It is implemented by
+// *[Client], and lets applications replace the client in tests, e.g. with
+// maibfake.Client.
+type Sender interface {
+	Send(ctx context.Context, req Request) (map[string]any, error)
+}
+
+var _ Sender = (*Client)(nil)
+
 // Send validates a [Request] and sends it to the ECommerce system. The value
 // returned on success can be parsed into a result struct using
 // requests.DecodeResponse.
 //
-// The request is cancelled when the context is done.
+// The request is cancelled when the context is done. If the context has an
+// idempotency key, the request is sent at most once per key, see
+// [WithIdempotencyKey]. If it has a merchant reference, the reference is linked
+// to the transaction, see [WithMerchantReference].
+//
+// Send doesn't fail when the request was sent, but saving its records failed,
+// e.g. the idempotency record or the transaction record: the error is reported
+// to [Config.OnStoreError] and to the logger instead.
 func (c *Client) Send(ctx context.Context, req Request) (map[string]any, error) {
-	reqURL, err := url.Parse(c.merchantHandlerEndpoint)
+	values, err := req.Values()
 	if err != nil {
-		return nil, fmt.Errorf("parse url: %w", err)
+		err = fmt.Errorf("get request values: %w", err)
+	}
+	if ctx == nil {
+		if err != nil {
+			return nil, err
+		}
+		return c.send(ctx, req, values)
 	}
 
-	queryValues, err := req.Values()
+	var res map[string]any
+	key, keyed := IdempotencyKeyFrom(ctx)
+	switch {
+	case err != nil:
+	case keyed:
+		res, err = c.sendIdempotent(ctx, key, req, values)
+	default:
+		res, err = c.send(ctx, req, values)
+	}
+	return c.correlate(ctx, values, res, err)
+}
+
+// send sends the validated request to the ECommerce system.
+func (c *Client) send(ctx context.Context, req Request, queryValues url.Values) (map[string]any, error) {
+	record := c.recorder != nil && ctx != nil
+	exchange := Exchange{
+		Stage:   StageSent,
+		Request: req,
+		Values:  queryValues,
+	}
+	if record {
+		exchange.SentAt = c.now()
+		err := c.recorder.RecordExchange(ctx, exchange)
+		if err != nil {
+			return nil, &RecordError{Err: err}
+		}
+	}
+
+	var res map[string]any
+	var err error
+	if c.secondaryEndpoint != "" {
+		res, err = c.sendFailover(ctx, queryValues)
+	} else {
+		res, err = c.post(ctx, c.merchantHandlerEndpoint, queryValues)
+	}
+
+	if record {
+		exchange.Stage = StageCompleted
+		exchange.Response = res
+		exchange.Err = err
+		// The outcome is recorded even if the context is done.
+		recordErr := c.recorder.RecordExchange(context.WithoutCancel(ctx), exchange)
+		if recordErr != nil {
+			c.storeFailed(ctx, fmt.Errorf("record exchange: %w", recordErr))
+		}
+	}
+	return res, err
+}
+
+// post sends the payload to the endpoint and parses the response.
+func (c *Client) post(ctx context.Context, endpoint string, queryValues url.Values) (map[string]any, error) {
+	reqURL, err := url.Parse(endpoint)
 	if err != nil {
-		return nil, fmt.Errorf("get request values: %w", err)
+		return nil, fmt.Errorf("parse url: %w", err)
 	}
 	reqURL.RawQuery = queryValues.Encode()
 	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, reqURL.String(), nil)
@@ -82,3 +156,24 @@ type ECommError struct {
 func (e *ECommError) Error() string {
 	return fmt.Sprintf("maib ecomm returned %d: %s", e.Code, e.Body)
 }
+
+// IsRejected reports whether the error of [Client.Send] shows that the request
+// was refused without being executed: by the client before sending, like a
+// [ValidationError], an [IdempotencyConflictError] or a [RecordError], or by
+// the ECommerce system with an "error:" body or a 4xx status.
+//
+// Other errors, like network errors, timeouts, a [ParseError] or a 5xx status,
+// leave the outcome unknown. A request that moves money may have been executed,
+// and must be checked with TransactionStatus before it is repeated.
+func IsRejected(err error) bool {
+	var ecommErr *ECommError
+	if errors.As(err, &ecommErr) {
+		return ecommErr.Code == http.StatusOK ||
+			ecommErr.Code >= http.StatusBadRequest && ecommErr.Code < http.StatusInternalServerError
+	}
+	return errors.As(err, new(*ValidationError)) ||
+		errors.As(err, new(*IdempotencyConflictError)) ||
+		errors.As(err, new(*RecordError)) ||
+		errors.As(err, new(*UnknownMerchantError)) ||
+		errors.Is(err, ErrNoMerchant)
+}
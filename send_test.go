@@ -5,25 +5,21 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
-	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
-)
-
-const (
-	caPath = "testdata/certs/ca.crt"
 
-	serverCertPath = "testdata/certs/server.crt"
-	serverKeyPath  = "testdata/certs/server.key"
-
-	testCommand = "q"
+	"github.com/NikSays/go-maib-ecomm/v2/testpki"
 )
 
+const testCommand = "q"
+
 var ctx = context.Background()
 
 type testRequest struct {
@@ -41,55 +37,30 @@ func (t testRequest) Values() (url.Values, error) {
 	}
 }
 
-func loadCerts() (caPool *x509.CertPool, serverCert tls.Certificate, err error) {
-	// Read CA certificate
-	caCert, err := os.ReadFile(caPath)
-	if err != nil {
-		return nil, tls.Certificate{}, fmt.Errorf("read CA: %w", err)
-	}
-
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
-
-	// Read server's HTTPS certificate
-	serverCert, err = tls.LoadX509KeyPair(serverCertPath, serverKeyPath)
-	if err != nil {
-		return nil, tls.Certificate{}, fmt.Errorf("load server certificate: %w", err)
-	}
-
-	return caCertPool, serverCert, nil
+func loadCerts(t *testing.T) (ca *testpki.CA, serverCert tls.Certificate) {
+	ca, err := testpki.NewCA(testpki.Options{})
+	assert.Nil(t, err)
+	serverCert, err = ca.Server(testpki.Options{})
+	assert.Nil(t, err)
+	return ca, serverCert
 }
 
-func createServer(caPool *x509.CertPool, serverCert tls.Certificate, handler http.HandlerFunc) *httptest.Server {
-	tlsConfig := &tls.Config{
-		// HTTPS certificate
-		Certificates: []tls.Certificate{serverCert},
-		// mTLS Client verification
-		ClientCAs:  caPool,
-		ClientAuth: tls.RequireAndVerifyClientCert,
-	}
-
+func createServer(ca *testpki.CA, serverCert tls.Certificate, handler http.HandlerFunc) *httptest.Server {
 	// Create a mTLS Server instance
 	server := httptest.NewUnstartedServer(handler)
-	server.TLS = tlsConfig
+	server.TLS = ca.ServerTLSConfig(serverCert)
 	return server
 }
 
-func createTrustingClient(endpointURL string, caPool *x509.CertPool) (*Client, error) {
+func createTrustingClient(t *testing.T, endpointURL string, ca *testpki.CA, caPool *x509.CertPool) (*Client, error) {
 	// Create client
-	client, err := NewClient(Config{
-		PFXPath:                 clientCertPath,
+	return NewClient(Config{
+		PFXPath:                 ca.ClientPFXFile(t, clientCertPass, testpki.Options{}),
 		Passphrase:              clientCertPass,
 		MerchantHandlerEndpoint: endpointURL,
+		// Trust the local CA
+		RootCAs: caPool,
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	// Trust the local CA
-	client.httpClient.Transport.(*http.Transport).TLSClientConfig.RootCAs = caPool
-
-	return client, nil
 }
 
 func TestClient_Send_InvalidRequest(t *testing.T) {
@@ -120,15 +91,14 @@ func TestClient_Send_InvalidContext(t *testing.T) {
 }
 
 func TestClient_Send_WithCerts(t *testing.T) {
-	caPool, serverCert, err := loadCerts()
-	assert.Nil(t, err)
+	ca, serverCert := loadCerts(t)
 
 	t.Run("OK", func(t *testing.T) {
-		server := createServer(caPool, serverCert, func(writer http.ResponseWriter, request *http.Request) {
+		server := createServer(ca, serverCert, func(writer http.ResponseWriter, request *http.Request) {
 			assert.Equal(t, testCommand, request.FormValue("command"))
 		})
 		server.StartTLS()
-		client, err := createTrustingClient(server.URL, caPool)
+		client, err := createTrustingClient(t, server.URL, ca, ca.Pool())
 		assert.Nil(t, err)
 
 		_, err = client.Send(ctx, testRequest{true})
@@ -136,9 +106,9 @@ func TestClient_Send_WithCerts(t *testing.T) {
 	})
 
 	t.Run("TLS fail", func(t *testing.T) {
-		server := createServer(caPool, serverCert, func(writer http.ResponseWriter, request *http.Request) {})
+		server := createServer(ca, serverCert, func(writer http.ResponseWriter, request *http.Request) {})
 		server.StartTLS()
-		client, err := createTrustingClient(server.URL, &x509.CertPool{})
+		client, err := createTrustingClient(t, server.URL, ca, &x509.CertPool{})
 		assert.Nil(t, err)
 
 		_, err = client.Send(ctx, testRequest{true})
@@ -147,11 +117,11 @@ func TestClient_Send_WithCerts(t *testing.T) {
 	})
 
 	t.Run("Bad status", func(t *testing.T) {
-		server := createServer(caPool, serverCert, func(writer http.ResponseWriter, request *http.Request) {
+		server := createServer(ca, serverCert, func(writer http.ResponseWriter, request *http.Request) {
 			writer.WriteHeader(http.StatusInternalServerError)
 		})
 		server.StartTLS()
-		client, err := createTrustingClient(server.URL, caPool)
+		client, err := createTrustingClient(t, server.URL, ca, ca.Pool())
 		assert.Nil(t, err)
 
 		_, err = client.Send(ctx, testRequest{true})
@@ -159,12 +129,12 @@ func TestClient_Send_WithCerts(t *testing.T) {
 	})
 
 	t.Run("Error response", func(t *testing.T) {
-		server := createServer(caPool, serverCert, func(writer http.ResponseWriter, request *http.Request) {
+		server := createServer(ca, serverCert, func(writer http.ResponseWriter, request *http.Request) {
 			_, err := writer.Write([]byte("error: ecommerce has encountered an unknown error"))
 			assert.Nil(t, err)
 		})
 		server.StartTLS()
-		client, err := createTrustingClient(server.URL, caPool)
+		client, err := createTrustingClient(t, server.URL, ca, ca.Pool())
 		assert.Nil(t, err)
 
 		_, err = client.Send(ctx, testRequest{true})
@@ -172,12 +142,12 @@ func TestClient_Send_WithCerts(t *testing.T) {
 	})
 
 	t.Run("Malformed response", func(t *testing.T) {
-		server := createServer(caPool, serverCert, func(writer http.ResponseWriter, request *http.Request) {
+		server := createServer(ca, serverCert, func(writer http.ResponseWriter, request *http.Request) {
 			_, err := writer.Write([]byte("welcome"))
 			assert.Nil(t, err)
 		})
 		server.StartTLS()
-		client, err := createTrustingClient(server.URL, caPool)
+		client, err := createTrustingClient(t, server.URL, ca, ca.Pool())
 		assert.Nil(t, err)
 
 		_, err = client.Send(ctx, testRequest{true})
@@ -186,11 +156,11 @@ func TestClient_Send_WithCerts(t *testing.T) {
 
 	t.Run("Timeout", func(t *testing.T) {
 		const timeout = 100 * time.Millisecond
-		server := createServer(caPool, serverCert, func(writer http.ResponseWriter, request *http.Request) {
+		server := createServer(ca, serverCert, func(writer http.ResponseWriter, request *http.Request) {
 			time.Sleep(2 * timeout)
 		})
 		server.StartTLS()
-		client, err := createTrustingClient(server.URL, caPool)
+		client, err := createTrustingClient(t, server.URL, ca, ca.Pool())
 		assert.Nil(t, err)
 
 		timeoutCtx, cancel := context.WithTimeout(context.Background(), timeout)
@@ -200,3 +170,81 @@ func TestClient_Send_WithCerts(t *testing.T) {
 		assert.ErrorIs(t, err, context.DeadlineExceeded)
 	})
 }
+
+func TestClient_Send_CertificateFailures(t *testing.T) {
+	cases := []struct {
+		name   string
+		server testpki.Options
+		client testpki.Options
+		// The client rejects the server certificate.
+		serverErr bool
+		hostErr   bool
+	}{
+		{name: "Server expired", server: testpki.Options{Expired: true}, serverErr: true},
+		{name: "Server not yet valid", server: testpki.Options{NotYetValid: true}, serverErr: true},
+		{name: "Server wrong CA", server: testpki.Options{WrongCA: true}, serverErr: true},
+		{name: "Server wrong key usage", server: testpki.Options{WrongKeyUsage: true}, serverErr: true},
+		{name: "Server wrong host", server: testpki.Options{Hosts: []string{"ecomm.maib.md"}}, hostErr: true},
+		{name: "Client expired", client: testpki.Options{Expired: true}},
+		{name: "Client not yet valid", client: testpki.Options{NotYetValid: true}},
+		{name: "Client wrong CA", client: testpki.Options{WrongCA: true}},
+		{name: "Client wrong key usage", client: testpki.Options{WrongKeyUsage: true}},
+	}
+
+	ca, err := testpki.NewCA(testpki.Options{})
+	assert.Nil(t, err)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			serverCert, err := ca.Server(c.server)
+			assert.Nil(t, err)
+			server := createServer(ca, serverCert, func(http.ResponseWriter, *http.Request) {
+				t.Error("request must not be handled")
+			})
+			server.Config.ErrorLog = log.New(io.Discard, "", 0)
+			server.StartTLS()
+			defer server.Close()
+
+			client, err := NewClient(Config{
+				PFXPath:                 ca.ClientPFXFile(t, clientCertPass, c.client),
+				Passphrase:              clientCertPass,
+				MerchantHandlerEndpoint: server.URL,
+				RootCAs:                 ca.Pool(),
+			})
+			assert.Nil(t, err)
+
+			_, err = client.Send(ctx, testRequest{true})
+			assert.Error(t, err)
+			switch {
+			case c.hostErr:
+				assert.ErrorAs(t, err, new(x509.HostnameError))
+			case c.serverErr:
+				var certErr *tls.CertificateVerificationError
+				assert.ErrorAs(t, err, &certErr)
+			}
+		})
+	}
+}
+
+func TestIsRejected(t *testing.T) {
+	cases := []struct {
+		name     string
+		err      error
+		rejected bool
+	}{
+		{name: "Error body", err: &ECommError{Code: http.StatusOK, Body: "error: declined"}, rejected: true},
+		{name: "Client error", err: &ECommError{Code: http.StatusBadRequest}, rejected: true},
+		{name: "Server error", err: &ECommError{Code: http.StatusInternalServerError}},
+		{name: "Bad gateway", err: fmt.Errorf("wrapped: %w", &ECommError{Code: http.StatusBadGateway})},
+		{name: "Validation", err: &ValidationError{Field: FieldAmount}, rejected: true},
+		{name: "Idempotency conflict", err: &IdempotencyConflictError{Key: "k"}, rejected: true},
+		{name: "Record", err: &RecordError{Err: io.ErrShortWrite}, rejected: true},
+		{name: "Parse", err: &ParseError{Err: io.ErrUnexpectedEOF}},
+		{name: "Timeout", err: context.DeadlineExceeded},
+		{name: "Nil", err: nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.rejected, IsRejected(c.err))
+		})
+	}
+}
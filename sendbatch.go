@@ -0,0 +1,197 @@
+package maib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultBatchWorkers = 4
+
+// ErrBatchStopped is the error of the requests of a batch that were not sent,
+// because the batch was stopped by [FailFast] or by its context.
+var ErrBatchStopped = errors.New("maib: batch stopped before sending the request")
+
+// BatchPolicy decides how [Client.SendBatch] handles a failed request.
+type BatchPolicy int
+
+const (
+	// ContinueOnError sends every request, whatever the errors of the others.
+	ContinueOnError BatchPolicy = iota
+
+	// FailFast stops sending after the first failed request. The requests in
+	// flight are completed.
+	FailFast
+)
+
+// BatchOptions configure [Client.SendBatch].
+type BatchOptions struct {
+	// Number of concurrent requests. Default is 4.
+	Workers int
+
+	// Maximum number of requests per second. Default is no limit.
+	Rate float64
+
+	// How failed requests are handled. Default is [ContinueOnError].
+	Policy BatchPolicy
+
+	// Called after each request completes. Optional. Calls are serialized, in
+	// the order the requests complete.
+	OnProgress func(BatchProgress)
+
+	// Receives the same updates as OnProgress. Optional. The batch waits for
+	// each update to be received, and the channel is not closed.
+	Progress chan<- BatchProgress
+}
+
+// BatchResult is the outcome of one request of a batch.
+type BatchResult struct {
+	// Position of the request in the batch.
+	Index int
+
+	// The sent request.
+	Request Request
+
+	// Response, if the request succeeded.
+	Response map[string]any
+
+	// Error, if the request failed, or [ErrBatchStopped] if it was not sent.
+	Err error
+}
+
+// BatchProgress is reported by [Client.SendBatch] after each request
+// completes.
+type BatchProgress struct {
+	// The completed request.
+	Result BatchResult
+
+	// Number of completed requests, including this one.
+	Completed int
+
+	// Number of completed requests that failed.
+	Failed int
+
+	// Number of requests in the batch.
+	Total int
+}
+
+// SendBatch sends the requests concurrently with [Client.Send], and returns
+// their results in the order of the requests. An idempotency key of the
+// context, set by [WithIdempotencyKey], is suffixed with "/" and the index of
+// each request.
+//
+// With [ContinueOnError], the error is only returned if the context is done.
+// With [FailFast], the error of the first failed request is returned as well.
+// The requests that were not sent have [ErrBatchStopped] as their error.
+func (c *Client) SendBatch(ctx context.Context, reqs []Request, opts BatchOptions) ([]BatchResult, error) {
+	if opts.Rate < 0 {
+		return nil, errors.New("rate must not be negative")
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = defaultBatchWorkers
+	}
+	var interval time.Duration
+	if opts.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / opts.Rate)
+	}
+
+	results := make([]BatchResult, len(reqs))
+	for i, req := range reqs {
+		results[i] = BatchResult{Index: i, Request: req, Err: ErrBatchStopped}
+	}
+
+	// stop ends the dispatch without canceling the requests in flight.
+	stop, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var mu sync.Mutex
+	progress := BatchProgress{Total: len(reqs)}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, len(reqs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				result := c.sendBatchItem(ctx, i, reqs[i])
+
+				mu.Lock()
+				results[i] = result
+				progress.Result = result
+				progress.Completed++
+				if result.Err != nil {
+					progress.Failed++
+					if opts.Policy == FailFast {
+						cancel(fmt.Errorf("request %d: %w", i, result.Err))
+					}
+				}
+				reportProgress(ctx, opts, progress)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	dispatchBatch(stop, len(reqs), interval, jobs)
+	close(jobs)
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+	if cause := context.Cause(stop); cause != nil && !errors.Is(cause, context.Canceled) {
+		return results, cause
+	}
+	return results, nil
+}
+
+// sendBatchItem sends the request with the index of a batch.
+func (c *Client) sendBatchItem(ctx context.Context, i int, req Request) BatchResult {
+	if key, ok := IdempotencyKeyFrom(ctx); ok {
+		ctx = WithIdempotencyKey(ctx, key+"/"+strconv.Itoa(i))
+	}
+	res, err := c.Send(ctx, req)
+	return BatchResult{Index: i, Request: req, Response: res, Err: err}
+}
+
+// reportProgress reports the progress to the callback and the channel of the
+// options.
+func reportProgress(ctx context.Context, opts BatchOptions, progress BatchProgress) {
+	if opts.OnProgress != nil {
+		opts.OnProgress(progress)
+	}
+	if opts.Progress != nil {
+		select {
+		case <-ctx.Done():
+		case opts.Progress <- progress:
+		}
+	}
+}
+
+// dispatchBatch hands the indices of n requests to the workers, waiting
+// between them to respect the rate limit. It returns early if the context is
+// done.
+func dispatchBatch(ctx context.Context, n int, interval time.Duration, jobs chan<- int) {
+	for i := range n {
+		if ctx.Err() != nil {
+			return
+		}
+		if i > 0 && interval > 0 {
+			timer := time.NewTimer(interval)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case jobs <- i:
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package maib
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func amountRequests(n int) []Request {
+	reqs := make([]Request, n)
+	for i := range reqs {
+		reqs[i] = amountRequest{100 + i}
+	}
+	return reqs
+}
+
+func TestClient_SendBatch(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusOK)
+	reqs := amountRequests(10)
+	var reported []BatchProgress
+	progress := make(chan BatchProgress, len(reqs))
+
+	results, err := client.SendBatch(ctx, reqs, BatchOptions{
+		Workers: 3,
+		OnProgress: func(p BatchProgress) {
+			reported = append(reported, p)
+		},
+		Progress: progress,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(10), hits.Load())
+
+	require.Len(t, results, len(reqs))
+	for i, result := range results {
+		assert.Equal(t, i, result.Index)
+		assert.Equal(t, reqs[i], result.Request)
+		assert.NoError(t, result.Err)
+		assert.Contains(t, result.Response, "TRANSACTION_ID")
+	}
+
+	require.Len(t, reported, len(reqs))
+	for i, p := range reported {
+		assert.Equal(t, i+1, p.Completed)
+		assert.Equal(t, len(reqs), p.Total)
+		assert.Zero(t, p.Failed)
+		assert.Equal(t, results[p.Result.Index], p.Result)
+		assert.Equal(t, p, <-progress)
+	}
+}
+
+func TestClient_SendBatch_ContinueOnError(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusOK)
+	reqs := amountRequests(5)
+	reqs[2] = testRequest{isValid: false}
+	var last BatchProgress
+
+	results, err := client.SendBatch(ctx, reqs, BatchOptions{
+		OnProgress: func(p BatchProgress) {
+			last = p
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), hits.Load())
+	var validationErr *ValidationError
+	assert.ErrorAs(t, results[2].Err, &validationErr)
+	for _, i := range []int{0, 1, 3, 4} {
+		assert.NoError(t, results[i].Err)
+	}
+	assert.Equal(t, BatchProgress{Result: last.Result, Completed: 5, Failed: 1, Total: 5}, last)
+}
+
+func TestClient_SendBatch_FailFast(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusOK)
+	reqs := amountRequests(5)
+	reqs[2] = testRequest{isValid: false}
+
+	results, err := client.SendBatch(ctx, reqs, BatchOptions{Workers: 1, Policy: FailFast})
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.ErrorContains(t, err, "request 2: ")
+	assert.Equal(t, int32(2), hits.Load())
+
+	require.Len(t, results, 5)
+	assert.NoError(t, results[0].Err)
+	assert.NoError(t, results[1].Err)
+	assert.ErrorAs(t, results[2].Err, &validationErr)
+	assert.ErrorIs(t, results[3].Err, ErrBatchStopped)
+	assert.ErrorIs(t, results[4].Err, ErrBatchStopped)
+	assert.Equal(t, reqs[4], results[4].Request)
+}
+
+func TestClient_SendBatch_Rate(t *testing.T) {
+	client, _ := createCountingClient(t, http.StatusOK)
+
+	start := time.Now()
+	_, err := client.SendBatch(ctx, amountRequests(4), BatchOptions{Workers: 4, Rate: 50})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 60*time.Millisecond)
+
+	_, err = client.SendBatch(ctx, amountRequests(4), BatchOptions{Rate: -1})
+	assert.EqualError(t, err, "rate must not be negative")
+}
+
+func TestClient_SendBatch_IdempotencyKey(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusOK)
+	keyed := WithIdempotencyKey(ctx, "refunds-2026-03")
+	reqs := amountRequests(3)
+
+	first, err := client.SendBatch(keyed, reqs, BatchOptions{})
+	require.NoError(t, err)
+	second, err := client.SendBatch(keyed, reqs, BatchOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, int32(3), hits.Load())
+}
+
+func TestClient_SendBatch_Canceled(t *testing.T) {
+	client, hits := createCountingClient(t, http.StatusOK)
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	results, err := client.SendBatch(canceled, amountRequests(3), BatchOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, hits.Load())
+	for _, result := range results {
+		assert.ErrorIs(t, result.Err, ErrBatchStopped)
+	}
+}
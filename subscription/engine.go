@@ -0,0 +1,392 @@
+/*
+Package subscription runs recurring billing on top of
+[requests.RegisterRecurring] and [requests.ExecuteRecurring].
+
+A [Subscription] binds a [Plan] to a card that was saved with
+RegisterRecurring under a BillerClientID. The [Engine] charges every due
+subscription with ExecuteRecurring, computes the next charge from the plan
+interval, and retries soft declines with dunning delays. Subscriptions are
+cancelled with [requests.DeleteRecurring].
+
+Every period is charged at most once: a pending [Charge] is recorded in the
+[Store] before ExecuteRecurring is sent. If the outcome of a charge is unknown,
+for example because the connection was lost, the subscription is marked
+[StatusNeedsReview] instead of being charged again, and must be resolved with
+[Engine.Resolve].
+*/
+package subscription
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+const defaultInterval = 15 * time.Minute
+
+var defaultRetries = []time.Duration{24 * time.Hour, 48 * time.Hour, 96 * time.Hour}
+
+// SoftDecline reports whether a declined charge with the result code may
+// succeed later: insufficient funds (116), exceeded amount or frequency limits
+// (121, 123), and unavailable issuer (907, 909, 911, 912).
+func SoftDecline(resultCode int) bool {
+	switch resultCode {
+	case 116, 121, 123, 907, 909, 911, 912:
+		return true
+	default:
+		return false
+	}
+}
+
+// Config is the configuration required to set up an [Engine].
+type Config struct {
+	// Sender used to send the requests. Required.
+	Sender maib.Sender
+
+	// Storage for subscriptions and charges. Default is a new [MemoryStore].
+	Store Store
+
+	// Source of time. Default is [clock.Real].
+	Clock clock.Clock
+
+	// How often [Engine.Run] looks for due subscriptions. Default is 15 minutes.
+	Interval time.Duration
+
+	// Delays before each retry of a softly declined charge. When they are
+	// exhausted, the subscription is suspended. Default is 1, 2 and 4 days.
+	Retries []time.Duration
+
+	// Decides if a declined charge is retried, given its result code. Default
+	// is [SoftDecline].
+	IsSoftDecline func(resultCode int) bool
+
+	// Called after each charge attempt. Optional.
+	OnCharge func(Subscription, Charge)
+}
+
+// Engine charges subscriptions. Must be initiated with [New].
+type Engine struct {
+	sender        maib.Sender
+	store         Store
+	clock         clock.Clock
+	interval      time.Duration
+	retries       []time.Duration
+	isSoftDecline func(int) bool
+	onCharge      func(Subscription, Charge)
+}
+
+// New validates the configuration and returns an *[Engine].
+func New(config Config) (*Engine, error) {
+	if config.Sender == nil {
+		return nil, errors.New("sender is required")
+	}
+
+	e := &Engine{
+		sender:        config.Sender,
+		store:         config.Store,
+		clock:         config.Clock,
+		interval:      config.Interval,
+		retries:       config.Retries,
+		isSoftDecline: config.IsSoftDecline,
+		onCharge:      config.OnCharge,
+	}
+	if e.store == nil {
+		e.store = NewMemoryStore()
+	}
+	if e.clock == nil {
+		e.clock = clock.Real
+	}
+	if e.interval <= 0 {
+		e.interval = defaultInterval
+	}
+	if e.retries == nil {
+		e.retries = defaultRetries
+	}
+	if e.isSoftDecline == nil {
+		e.isSoftDecline = SoftDecline
+	}
+	return e, nil
+}
+
+// Subscribe validates and stores a new subscription. The card must already be
+// registered with RegisterRecurring under sub.BillerClientID. The first charge
+// is due at the time of sub.Period computed from sub.Anchor.
+func (e *Engine) Subscribe(ctx context.Context, sub Subscription) error {
+	if sub.ID == "" {
+		return errors.New("subscription ID is required")
+	}
+	if sub.Plan.Interval.Months <= 0 && sub.Plan.Interval.Days <= 0 {
+		return errors.New("plan interval must be positive")
+	}
+	_, err := executeRequest(sub).Values()
+	if err != nil {
+		return fmt.Errorf("validate subscription: %w", err)
+	}
+
+	_, err = e.store.Get(ctx, sub.ID)
+	if err == nil {
+		return fmt.Errorf("subscription %s already exists", sub.ID)
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("get subscription: %w", err)
+	}
+
+	sub.Status = StatusActive
+	sub.Failures = 0
+	sub.NextChargeAt = sub.Plan.ChargeAt(sub.Anchor, sub.Period)
+	err = e.store.Save(ctx, sub)
+	if err != nil {
+		return fmt.Errorf("save subscription: %w", err)
+	}
+	return nil
+}
+
+// Cancel deletes the recurring payment with DeleteRecurring and marks the
+// subscription as cancelled. Cancelling a cancelled subscription does nothing.
+func (e *Engine) Cancel(ctx context.Context, id string) error {
+	sub, err := e.store.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get subscription: %w", err)
+	}
+	if sub.Status == StatusCancelled {
+		return nil
+	}
+
+	res, err := e.sender.Send(ctx, requests.DeleteRecurring{
+		BillerClientID: sub.BillerClientID,
+	})
+	if err != nil {
+		return fmt.Errorf("delete recurring: %w", err)
+	}
+	result, err := requests.DecodeResponse[requests.DeleteRecurringResult](res)
+	if err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	if result.Result != maib.ResultOk {
+		return fmt.Errorf("delete recurring returned %s", result.Result)
+	}
+
+	sub.Status = StatusCancelled
+	err = e.store.Save(ctx, sub)
+	if err != nil {
+		return fmt.Errorf("save subscription: %w", err)
+	}
+	return nil
+}
+
+// Resolve settles the unknown outcome of the last charge of a subscription in
+// [StatusNeedsReview], after it was checked manually. If paid is false, the
+// charge counts as a soft decline.
+func (e *Engine) Resolve(ctx context.Context, id string, paid bool, transactionID string) error {
+	sub, err := e.store.Get(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get subscription: %w", err)
+	}
+	if sub.Status != StatusNeedsReview {
+		return fmt.Errorf("subscription %s is %s, not %s", id, sub.Status, StatusNeedsReview)
+	}
+
+	charges, err := e.store.Charges(ctx, id)
+	if err != nil {
+		return fmt.Errorf("get charges: %w", err)
+	}
+	i := slices.IndexFunc(charges, func(c Charge) bool {
+		return c.Period == sub.Period && c.Status == ChargePending
+	})
+	if i < 0 {
+		return fmt.Errorf("no pending charge for period %d", sub.Period)
+	}
+
+	charge := charges[i]
+	charge.TransactionID = transactionID
+	if paid {
+		charge.Status = ChargePaid
+	} else {
+		charge.Status = ChargeDeclined
+	}
+	err = e.store.FinishCharge(ctx, charge)
+	if err != nil {
+		return fmt.Errorf("finish charge: %w", err)
+	}
+	e.apply(&sub, charge)
+	err = e.store.Save(ctx, sub)
+	if err != nil {
+		return fmt.Errorf("save subscription: %w", err)
+	}
+	return nil
+}
+
+// Run blocks until the context is done, calling [Engine.ChargeDue] on every
+// interval. It returns early if the store fails.
+func (e *Engine) Run(ctx context.Context) error {
+	for {
+		_, err := e.ChargeDue(ctx)
+		if err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-e.clock.After(e.interval):
+		}
+	}
+}
+
+// ChargeDue charges every due subscription once, and returns the charge
+// attempts. Only store errors are returned; the outcome of each charge is in
+// its Status.
+func (e *Engine) ChargeDue(ctx context.Context) ([]Charge, error) {
+	due, err := e.store.Due(ctx, e.clock.Now())
+	if err != nil {
+		return nil, fmt.Errorf("get due subscriptions: %w", err)
+	}
+
+	var charges []Charge
+	for _, sub := range due {
+		if ctx.Err() != nil {
+			return charges, ctx.Err()
+		}
+
+		charge, err := e.charge(ctx, sub)
+		if err != nil {
+			return charges, err
+		}
+		charges = append(charges, charge)
+	}
+	return charges, nil
+}
+
+// charge charges the current period of the subscription, and saves the
+// outcome.
+func (e *Engine) charge(ctx context.Context, sub Subscription) (Charge, error) {
+	charge := Charge{
+		SubscriptionID: sub.ID,
+		Period:         sub.Period,
+		Attempt:        sub.Failures + 1,
+		At:             e.clock.Now(),
+		Amount:         sub.Plan.Amount,
+		Currency:       sub.Plan.Currency,
+		Status:         ChargePending,
+	}
+	err := e.store.BeginCharge(ctx, charge)
+	if errors.Is(err, ErrChargeExists) {
+		return e.recover(ctx, sub)
+	}
+	if err != nil {
+		return Charge{}, fmt.Errorf("begin charge: %w", err)
+	}
+
+	res, err := e.sender.Send(ctx, executeRequest(sub))
+	var result requests.ExecuteRecurringResult
+	if err == nil {
+		result, err = requests.DecodeResponse[requests.ExecuteRecurringResult](res)
+		if err != nil {
+			err = fmt.Errorf("decode response: %w", err)
+		}
+	}
+	charge.TransactionID = result.TransactionID
+	charge.Result = result.Result
+	charge.ResultCode = result.ResultCode
+	if err != nil {
+		charge.Error = err.Error()
+	}
+
+	switch {
+	case errors.As(err, new(*maib.ValidationError)):
+		// Nothing was sent.
+		charge.Status = ChargeFailed
+	case maib.IsRejected(err):
+		// The request was refused without executing the transaction.
+		charge.Status = ChargeDeclined
+	case err != nil:
+		// The transaction may or may not have been executed.
+		charge.Status = ChargePending
+	case result.Result == maib.ResultOk:
+		charge.Status = ChargePaid
+	case e.isSoftDecline(result.ResultCode):
+		charge.Status = ChargeDeclined
+	default:
+		charge.Status = ChargeFailed
+	}
+
+	err = e.store.FinishCharge(ctx, charge)
+	if err != nil {
+		return charge, fmt.Errorf("finish charge: %w", err)
+	}
+	e.apply(&sub, charge)
+	err = e.store.Save(ctx, sub)
+	if err != nil {
+		return charge, fmt.Errorf("save subscription: %w", err)
+	}
+	if e.onCharge != nil {
+		e.onCharge(sub, charge)
+	}
+	return charge, nil
+}
+
+// recover handles a subscription whose current period already has a pending or
+// paid charge, e.g. after a crash between charging and saving the
+// subscription.
+func (e *Engine) recover(ctx context.Context, sub Subscription) (Charge, error) {
+	charges, err := e.store.Charges(ctx, sub.ID)
+	if err != nil {
+		return Charge{}, fmt.Errorf("get charges: %w", err)
+	}
+	i := slices.IndexFunc(charges, func(c Charge) bool {
+		return c.Period == sub.Period && (c.Status == ChargePending || c.Status == ChargePaid)
+	})
+	if i < 0 {
+		return Charge{}, fmt.Errorf("no existing charge for period %d", sub.Period)
+	}
+
+	charge := charges[i]
+	e.apply(&sub, charge)
+	err = e.store.Save(ctx, sub)
+	if err != nil {
+		return charge, fmt.Errorf("save subscription: %w", err)
+	}
+	return charge, nil
+}
+
+// apply updates the subscription after a charge attempt.
+func (e *Engine) apply(sub *Subscription, charge Charge) {
+	switch charge.Status {
+	case ChargePaid:
+		sub.Period = charge.Period + 1
+		sub.Failures = 0
+		sub.NextChargeAt = sub.Plan.ChargeAt(sub.Anchor, sub.Period)
+		sub.Status = StatusActive
+	case ChargeDeclined:
+		sub.Failures++
+		if sub.Failures > len(e.retries) {
+			sub.Status = StatusSuspended
+			return
+		}
+		sub.NextChargeAt = e.clock.Now().Add(e.retries[sub.Failures-1])
+		sub.Status = StatusPastDue
+	case ChargeFailed:
+		sub.Failures++
+		sub.Status = StatusSuspended
+	case ChargePending:
+		sub.Status = StatusNeedsReview
+	}
+}
+
+// executeRequest returns the ExecuteRecurring request that charges the
+// subscription.
+func executeRequest(sub Subscription) requests.ExecuteRecurring {
+	return requests.ExecuteRecurring{
+		Amount:          sub.Plan.Amount,
+		Currency:        sub.Plan.Currency,
+		ClientIPAddress: sub.ClientIPAddress,
+		Description:     sub.Plan.Description,
+		BillerClientID:  sub.BillerClientID,
+	}
+}
@@ -0,0 +1,314 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+	"github.com/NikSays/go-maib-ecomm/v2/clock"
+	"github.com/NikSays/go-maib-ecomm/v2/requests"
+)
+
+var anchor = time.Date(2025, 1, 15, 9, 0, 0, 0, time.UTC)
+
+type response struct {
+	res map[string]any
+	err error
+}
+
+// fakeSender returns the scripted responses in order. When they run out, it
+// approves every request.
+type fakeSender struct {
+	mu        sync.Mutex
+	responses []response
+	sent      []maib.Request
+}
+
+func (f *fakeSender) Send(_ context.Context, req maib.Request) (map[string]any, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sent = append(f.sent, req)
+
+	if len(f.responses) == 0 {
+		return map[string]any{
+			"TRANSACTION_ID": "abcdefghijklmnopqrstuvwxyz1=",
+			"RESULT":         "OK",
+			"RESULT_CODE":    0,
+		}, nil
+	}
+	r := f.responses[0]
+	f.responses = f.responses[1:]
+	return r.res, r.err
+}
+
+func declined(code int) response {
+	return response{res: map[string]any{"RESULT": "DECLINED", "RESULT_CODE": code}}
+}
+
+func setup(t *testing.T, sender *fakeSender) (*Engine, *MemoryStore, *clock.Fake) {
+	store := NewMemoryStore()
+	fakeClock := clock.NewFake(anchor)
+	engine, err := New(Config{
+		Sender:  sender,
+		Store:   store,
+		Clock:   fakeClock,
+		Retries: []time.Duration{24 * time.Hour, 48 * time.Hour},
+	})
+	assert.Nil(t, err)
+
+	err = engine.Subscribe(context.Background(), Subscription{
+		ID: "sub",
+		Plan: Plan{
+			ID:          "basic",
+			Amount:      1999,
+			Currency:    maib.CurrencyMDL,
+			Interval:    Monthly,
+			Description: "Basic plan",
+		},
+		BillerClientID:  "client-1",
+		ClientIPAddress: "127.0.0.1",
+		Anchor:          anchor,
+	})
+	assert.Nil(t, err)
+	return engine, store, fakeClock
+}
+
+func get(t *testing.T, store *MemoryStore) Subscription {
+	sub, err := store.Get(context.Background(), "sub")
+	assert.Nil(t, err)
+	return sub
+}
+
+func TestEngine_Subscribe(t *testing.T) {
+	engine, store, _ := setup(t, &fakeSender{})
+	ctx := context.Background()
+
+	sub := get(t, store)
+	assert.Equal(t, StatusActive, sub.Status)
+	assert.Equal(t, anchor, sub.NextChargeAt)
+
+	// Duplicate ID.
+	assert.Error(t, engine.Subscribe(ctx, sub))
+
+	// Invalid subscriptions.
+	assert.Error(t, engine.Subscribe(ctx, Subscription{}))
+	assert.Error(t, engine.Subscribe(ctx, Subscription{ID: "no interval", Plan: Plan{Amount: 100}}))
+	err := engine.Subscribe(ctx, Subscription{
+		ID:   "no biller",
+		Plan: Plan{Amount: 100, Interval: Monthly},
+	})
+	assert.ErrorAs(t, err, new(*maib.ValidationError))
+}
+
+func TestEngine_ChargeDue(t *testing.T) {
+	sender := &fakeSender{}
+	engine, store, fakeClock := setup(t, sender)
+	ctx := context.Background()
+
+	charges, err := engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, charges, 1)
+	assert.Equal(t, ChargePaid, charges[0].Status)
+	assert.Equal(t, "abcdefghijklmnopqrstuvwxyz1=", charges[0].TransactionID)
+	assert.Equal(t, requests.ExecuteRecurring{
+		Amount:          1999,
+		Currency:        maib.CurrencyMDL,
+		ClientIPAddress: "127.0.0.1",
+		Description:     "Basic plan",
+		BillerClientID:  "client-1",
+	}, sender.sent[0])
+
+	sub := get(t, store)
+	assert.Equal(t, 1, sub.Period)
+	assert.Equal(t, time.Date(2025, 2, 15, 9, 0, 0, 0, time.UTC), sub.NextChargeAt)
+
+	// Nothing is due until the next period.
+	charges, err = engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, charges)
+
+	fakeClock.Set(sub.NextChargeAt)
+	charges, err = engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, charges, 1)
+	assert.Equal(t, 1, charges[0].Period)
+	assert.Equal(t, 2, get(t, store).Period)
+}
+
+func TestEngine_ChargeDue_Dunning(t *testing.T) {
+	sender := &fakeSender{responses: []response{declined(116), declined(116), declined(116)}}
+	engine, store, fakeClock := setup(t, sender)
+	ctx := context.Background()
+
+	charges, err := engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, ChargeDeclined, charges[0].Status)
+	sub := get(t, store)
+	assert.Equal(t, StatusPastDue, sub.Status)
+	assert.Equal(t, anchor.Add(24*time.Hour), sub.NextChargeAt)
+
+	fakeClock.Set(sub.NextChargeAt)
+	charges, err = engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, charges[0].Attempt)
+	sub = get(t, store)
+	assert.Equal(t, StatusPastDue, sub.Status)
+	assert.Equal(t, anchor.Add(72*time.Hour), sub.NextChargeAt)
+
+	fakeClock.Set(sub.NextChargeAt)
+	_, err = engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, StatusSuspended, get(t, store).Status)
+
+	all, err := store.Charges(ctx, "sub")
+	assert.Nil(t, err)
+	assert.Len(t, all, 3)
+}
+
+func TestEngine_ChargeDue_Recovery(t *testing.T) {
+	sender := &fakeSender{responses: []response{declined(116)}}
+	engine, store, fakeClock := setup(t, sender)
+	ctx := context.Background()
+
+	_, err := engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+
+	// The retry is paid on time.
+	fakeClock.Set(get(t, store).NextChargeAt)
+	_, err = engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+
+	sub := get(t, store)
+	assert.Equal(t, StatusActive, sub.Status)
+	assert.Equal(t, 0, sub.Failures)
+	assert.Equal(t, time.Date(2025, 2, 15, 9, 0, 0, 0, time.UTC), sub.NextChargeAt)
+}
+
+func TestEngine_ChargeDue_HardDecline(t *testing.T) {
+	sender := &fakeSender{responses: []response{declined(101)}}
+	engine, store, _ := setup(t, sender)
+
+	charges, err := engine.ChargeDue(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, ChargeFailed, charges[0].Status)
+	assert.Equal(t, StatusSuspended, get(t, store).Status)
+}
+
+func TestEngine_ChargeDue_Ambiguous(t *testing.T) {
+	sender := &fakeSender{responses: []response{
+		{err: &url.Error{Op: "Post", Err: errors.New("connection reset")}},
+	}}
+	engine, store, _ := setup(t, sender)
+	ctx := context.Background()
+
+	charges, err := engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, ChargePending, charges[0].Status)
+	assert.NotEmpty(t, charges[0].Error)
+	assert.Equal(t, StatusNeedsReview, get(t, store).Status)
+
+	// The subscription is not charged again until resolved.
+	charges, err = engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, charges)
+	assert.Len(t, sender.sent, 1)
+
+	assert.Nil(t, engine.Resolve(ctx, "sub", true, "abcdefghijklmnopqrstuvwxyz1="))
+	sub := get(t, store)
+	assert.Equal(t, StatusActive, sub.Status)
+	assert.Equal(t, 1, sub.Period)
+
+	all, err := store.Charges(ctx, "sub")
+	assert.Nil(t, err)
+	assert.Equal(t, ChargePaid, all[0].Status)
+	assert.Equal(t, "abcdefghijklmnopqrstuvwxyz1=", all[0].TransactionID)
+
+	assert.Error(t, engine.Resolve(ctx, "sub", true, ""))
+}
+
+func TestEngine_ChargeDue_ECommError(t *testing.T) {
+	cases := []struct {
+		name           string
+		err            error
+		expectedCharge ChargeStatus
+		expectedStatus Status
+	}{
+		{
+			name:           "Rejected",
+			err:            &maib.ECommError{Code: http.StatusOK, Body: "error: declined"},
+			expectedCharge: ChargeDeclined,
+			expectedStatus: StatusPastDue,
+		},
+		{
+			name:           "Server error",
+			err:            &maib.ECommError{Code: http.StatusBadGateway, Body: "Bad Gateway"},
+			expectedCharge: ChargePending,
+			expectedStatus: StatusNeedsReview,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sender := &fakeSender{responses: []response{{err: c.err}}}
+			engine, store, _ := setup(t, sender)
+
+			charges, err := engine.ChargeDue(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, c.expectedCharge, charges[0].Status)
+			assert.Equal(t, c.expectedStatus, get(t, store).Status)
+		})
+	}
+}
+
+func TestEngine_ChargeDue_Idempotent(t *testing.T) {
+	sender := &fakeSender{}
+	engine, store, _ := setup(t, sender)
+	ctx := context.Background()
+
+	// Simulate a crash after the period was paid but before the subscription was
+	// saved.
+	assert.Nil(t, store.BeginCharge(ctx, Charge{SubscriptionID: "sub", Period: 0, Attempt: 1, Status: ChargePaid}))
+
+	charges, err := engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, ChargePaid, charges[0].Status)
+	assert.Empty(t, sender.sent)
+	assert.Equal(t, 1, get(t, store).Period)
+}
+
+func TestEngine_Cancel(t *testing.T) {
+	sender := &fakeSender{responses: []response{
+		{res: map[string]any{"RESULT": "FAILED"}},
+	}}
+	engine, store, _ := setup(t, sender)
+	ctx := context.Background()
+
+	assert.Error(t, engine.Cancel(ctx, "sub"))
+	assert.Equal(t, StatusActive, get(t, store).Status)
+
+	assert.Nil(t, engine.Cancel(ctx, "sub"))
+	assert.Equal(t, StatusCancelled, get(t, store).Status)
+	assert.Equal(t, requests.DeleteRecurring{BillerClientID: "client-1"}, sender.sent[1])
+
+	// Cancelled subscriptions are not charged, and cancelling again is a no-op.
+	assert.Nil(t, engine.Cancel(ctx, "sub"))
+	assert.Len(t, sender.sent, 2)
+	charges, err := engine.ChargeDue(ctx)
+	assert.Nil(t, err)
+	assert.Empty(t, charges)
+
+	assert.ErrorIs(t, engine.Cancel(ctx, "missing"), ErrNotFound)
+}
+
+func TestSoftDecline(t *testing.T) {
+	assert.True(t, SoftDecline(116))
+	assert.True(t, SoftDecline(911))
+	assert.False(t, SoftDecline(101))
+	assert.False(t, SoftDecline(0))
+}
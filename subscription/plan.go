@@ -0,0 +1,57 @@
+package subscription
+
+import (
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// Interval is the time between two charges. Months are added first, so a
+// monthly plan anchored on January 31 is charged on the last day of February.
+type Interval struct {
+	Months int
+	Days   int
+}
+
+// Monthly is an [Interval] of one calendar month.
+var Monthly = Interval{Months: 1}
+
+// Yearly is an [Interval] of twelve calendar months.
+var Yearly = Interval{Months: 12}
+
+// Plan describes what a subscriber is charged and how often.
+type Plan struct {
+	// Identifier of the plan, for the merchant's reference.
+	ID string
+
+	// Charge amount. Positive integer with last 2 digits being the cents.
+	Amount int
+
+	// Charge currency in ISO4217 3 digit format.
+	Currency maib.Currency
+
+	// Time between charges.
+	Interval Interval
+
+	// Transaction details sent with each charge. Optional.
+	Description string
+}
+
+// ChargeAt returns the time of the given billing period, where period 0 starts
+// at the anchor. Each period is computed from the anchor, so short months do
+// not shift later charges.
+func (p Plan) ChargeAt(anchor time.Time, period int) time.Time {
+	t := addMonths(anchor, p.Interval.Months*period)
+	return t.AddDate(0, 0, p.Interval.Days*period)
+}
+
+// addMonths adds calendar months to t, clamping the day to the end of the
+// resulting month.
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	hour, minute, second := t.Clock()
+
+	first := time.Date(year, month+time.Month(months), 1, hour, minute, second, t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, lastDay)-1)
+}
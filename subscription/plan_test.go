@@ -0,0 +1,35 @@
+package subscription
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlan_ChargeAt(t *testing.T) {
+	anchor := time.Date(2025, 1, 31, 10, 0, 0, 0, time.UTC)
+	cases := []struct {
+		name     string
+		interval Interval
+		period   int
+		expected time.Time
+	}{
+		{"Anchor", Monthly, 0, anchor},
+		{"Short month", Monthly, 1, time.Date(2025, 2, 28, 10, 0, 0, 0, time.UTC)},
+		{"No drift", Monthly, 2, time.Date(2025, 3, 31, 10, 0, 0, 0, time.UTC)},
+		{"30 day month", Monthly, 3, time.Date(2025, 4, 30, 10, 0, 0, 0, time.UTC)},
+		{"Leap year", Yearly, 3, time.Date(2028, 1, 31, 10, 0, 0, 0, time.UTC)},
+		{"Days", Interval{Days: 7}, 2, time.Date(2025, 2, 14, 10, 0, 0, 0, time.UTC)},
+		{"Months and days", Interval{Months: 1, Days: 1}, 1, time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			plan := Plan{Interval: c.interval}
+			assert.Equal(t, c.expected, plan.ChargeAt(anchor, c.period))
+		})
+	}
+
+	leapAnchor := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC), Plan{Interval: Yearly}.ChargeAt(leapAnchor, 1))
+}
@@ -0,0 +1,235 @@
+package subscription
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/NikSays/go-maib-ecomm/v2"
+)
+
+// Status is the state of a [Subscription].
+type Status string
+
+const (
+	// StatusActive - the subscription is charged on schedule.
+	StatusActive Status = "ACTIVE"
+
+	// StatusPastDue - the last charge was softly declined and will be retried.
+	StatusPastDue Status = "PAST_DUE"
+
+	// StatusSuspended - the last charge was declined permanently, or all retries
+	// have failed. Requires a new card registration.
+	StatusSuspended Status = "SUSPENDED"
+
+	// StatusNeedsReview - the outcome of the last charge is unknown, e.g. the
+	// connection was lost after sending ExecuteRecurring. Must be resolved with
+	// [Engine.Resolve].
+	StatusNeedsReview Status = "NEEDS_REVIEW"
+
+	// StatusCancelled - the recurring payment was deleted with DeleteRecurring.
+	StatusCancelled Status = "CANCELLED"
+)
+
+// Subscription binds a [Plan] to a card registered with RegisterRecurring.
+type Subscription struct {
+	// Unique identifier of the subscription.
+	ID string
+
+	// Charged plan.
+	Plan Plan
+
+	// Identifier of the recurring payment, as used in RegisterRecurring.
+	BillerClientID string
+
+	// Client's IP address in quad-dotted notation, like "127.0.0.1".
+	ClientIPAddress string
+
+	// Time of the period 0 charge. Every other charge is computed from it.
+	Anchor time.Time
+
+	// Next period to charge. Set it to 1 if the first payment was made during the
+	// card registration.
+	Period int
+
+	// Number of failed attempts to charge the current period.
+	Failures int
+
+	// When the next charge or retry is due.
+	NextChargeAt time.Time
+
+	// Current state.
+	Status Status
+}
+
+// ChargeStatus is the state of a [Charge].
+type ChargeStatus string
+
+const (
+	// ChargePending - ExecuteRecurring is being sent, or its outcome is unknown.
+	ChargePending ChargeStatus = "PENDING"
+
+	// ChargePaid - the charge was approved.
+	ChargePaid ChargeStatus = "PAID"
+
+	// ChargeDeclined - the charge was declined and may be retried.
+	ChargeDeclined ChargeStatus = "DECLINED"
+
+	// ChargeFailed - the charge was declined permanently.
+	ChargeFailed ChargeStatus = "FAILED"
+)
+
+// Charge is an attempt to charge a billing period.
+type Charge struct {
+	// Subscription being charged.
+	SubscriptionID string
+
+	// Charged period.
+	Period int
+
+	// Attempt number within the period, starting at 1.
+	Attempt int
+
+	// When the attempt was made.
+	At time.Time
+
+	// Charged amount.
+	Amount int
+
+	// Charged currency.
+	Currency maib.Currency
+
+	// State of the attempt.
+	Status ChargeStatus
+
+	// ID of the executed transaction, if the ECommerce system has returned it.
+	TransactionID string
+
+	// Result returned by the ECommerce system.
+	Result maib.ResultEnum
+
+	// Result code returned by the ECommerce system.
+	ResultCode int
+
+	// Error message, if the request has failed.
+	Error string
+}
+
+// ErrNotFound is returned by a [Store] when the subscription does not exist.
+var ErrNotFound = errors.New("subscription not found")
+
+// ErrChargeExists is returned by [Store.BeginCharge] when the period already
+// has a pending or paid charge.
+var ErrChargeExists = errors.New("charge already exists for the period")
+
+// Store persists subscriptions and their charges.
+type Store interface {
+	// Get returns the subscription with the given ID, or [ErrNotFound].
+	Get(ctx context.Context, id string) (Subscription, error)
+
+	// Save creates or replaces the subscription with the same ID.
+	Save(ctx context.Context, subscription Subscription) error
+
+	// Due returns the active and past due subscriptions whose NextChargeAt is not
+	// after now, ordered by NextChargeAt.
+	Due(ctx context.Context, now time.Time) ([]Subscription, error)
+
+	// BeginCharge records a pending charge. It must be atomic: if the same period
+	// of the subscription already has a pending or paid charge, it returns
+	// [ErrChargeExists] and records nothing.
+	BeginCharge(ctx context.Context, charge Charge) error
+
+	// FinishCharge replaces the pending charge with the same subscription, period
+	// and attempt.
+	FinishCharge(ctx context.Context, charge Charge) error
+
+	// Charges returns every charge of the subscription in order.
+	Charges(ctx context.Context, subscriptionID string) ([]Charge, error)
+}
+
+// MemoryStore is a [Store] that keeps everything in memory. It is safe for
+// concurrent use.
+type MemoryStore struct {
+	mu            sync.Mutex
+	subscriptions map[string]Subscription
+	charges       map[string][]Charge
+}
+
+// NewMemoryStore returns an empty *[MemoryStore].
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		subscriptions: make(map[string]Subscription),
+		charges:       make(map[string][]Charge),
+	}
+}
+
+func (s *MemoryStore) Get(_ context.Context, id string) (Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subscription, ok := s.subscriptions[id]
+	if !ok {
+		return Subscription{}, ErrNotFound
+	}
+	return subscription, nil
+}
+
+func (s *MemoryStore) Save(_ context.Context, subscription Subscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscriptions[subscription.ID] = subscription
+	return nil
+}
+
+func (s *MemoryStore) Due(_ context.Context, now time.Time) ([]Subscription, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []Subscription
+	for _, sub := range s.subscriptions {
+		chargeable := sub.Status == StatusActive || sub.Status == StatusPastDue
+		if chargeable && !sub.NextChargeAt.After(now) {
+			due = append(due, sub)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextChargeAt.Before(due[j].NextChargeAt)
+	})
+	return due, nil
+}
+
+func (s *MemoryStore) BeginCharge(_ context.Context, charge Charge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range s.charges[charge.SubscriptionID] {
+		if c.Period == charge.Period && (c.Status == ChargePending || c.Status == ChargePaid) {
+			return ErrChargeExists
+		}
+	}
+	s.charges[charge.SubscriptionID] = append(s.charges[charge.SubscriptionID], charge)
+	return nil
+}
+
+func (s *MemoryStore) FinishCharge(_ context.Context, charge Charge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	charges := s.charges[charge.SubscriptionID]
+	for i, c := range charges {
+		if c.Period == charge.Period && c.Attempt == charge.Attempt {
+			charges[i] = charge
+			return nil
+		}
+	}
+	return errors.New("pending charge not found")
+}
+
+func (s *MemoryStore) Charges(_ context.Context, subscriptionID string) ([]Charge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	charges := make([]Charge, len(s.charges[subscriptionID]))
+	copy(charges, s.charges[subscriptionID])
+	return charges, nil
+}
@@ -0,0 +1,71 @@
+package subscription
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore_Get(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	_, err := store.Get(ctx, "missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	sub := Subscription{ID: "sub", Status: StatusActive}
+	assert.Nil(t, store.Save(ctx, sub))
+	got, err := store.Get(ctx, "sub")
+	assert.Nil(t, err)
+	assert.Equal(t, sub, got)
+}
+
+func TestMemoryStore_Due(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	subs := []Subscription{
+		{ID: "later", Status: StatusActive, NextChargeAt: now.Add(time.Hour)},
+		{ID: "past due", Status: StatusPastDue, NextChargeAt: now},
+		{ID: "active", Status: StatusActive, NextChargeAt: now.Add(-time.Hour)},
+		{ID: "suspended", Status: StatusSuspended, NextChargeAt: now.Add(-time.Hour)},
+		{ID: "cancelled", Status: StatusCancelled, NextChargeAt: now.Add(-time.Hour)},
+		{ID: "review", Status: StatusNeedsReview, NextChargeAt: now.Add(-time.Hour)},
+	}
+	for _, sub := range subs {
+		assert.Nil(t, store.Save(ctx, sub))
+	}
+
+	due, err := store.Due(ctx, now)
+	assert.Nil(t, err)
+	assert.Equal(t, []Subscription{subs[2], subs[1]}, due)
+}
+
+func TestMemoryStore_Charges(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	first := Charge{SubscriptionID: "sub", Period: 0, Attempt: 1, Status: ChargePending}
+	assert.Nil(t, store.BeginCharge(ctx, first))
+	assert.ErrorIs(t, store.BeginCharge(ctx, first), ErrChargeExists)
+
+	// A declined attempt allows another one.
+	first.Status = ChargeDeclined
+	assert.Nil(t, store.FinishCharge(ctx, first))
+	second := Charge{SubscriptionID: "sub", Period: 0, Attempt: 2, Status: ChargePending}
+	assert.Nil(t, store.BeginCharge(ctx, second))
+
+	// A paid period can't be charged again.
+	second.Status = ChargePaid
+	assert.Nil(t, store.FinishCharge(ctx, second))
+	assert.ErrorIs(t, store.BeginCharge(ctx, Charge{SubscriptionID: "sub", Period: 0, Attempt: 3}), ErrChargeExists)
+
+	assert.Error(t, store.FinishCharge(ctx, Charge{SubscriptionID: "sub", Period: 5, Attempt: 1}))
+
+	charges, err := store.Charges(ctx, "sub")
+	assert.Nil(t, err)
+	assert.Equal(t, []Charge{first, second}, charges)
+}
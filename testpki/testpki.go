@@ -0,0 +1,259 @@
+/*
+Package testpki generates certificates for mutual TLS tests in memory, without
+OpenSSL or fixture files.
+
+[NewCA] creates a certificate authority, which issues server certificates with
+[CA.Server] and client certificates with [CA.Client]. [CA.ClientPFX] encodes a
+client certificate as PFX, the format issued by MAIB and read by
+maib.NewClient. [Options] produce broken certificates, to exercise the failure
+paths: expired, not yet valid, signed by another CA, or with the wrong key
+usage.
+*/
+package testpki
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+const validity = 24 * time.Hour
+
+// Options modify a generated certificate.
+type Options struct {
+	// Common name of the subject. Default depends on the certificate.
+	CommonName string
+
+	// DNS names and IP addresses of a server certificate. Default is
+	// "localhost", "127.0.0.1" and "::1". Ignored for other certificates.
+	Hosts []string
+
+	// The certificate expired an hour ago.
+	Expired bool
+
+	// The certificate becomes valid in an hour.
+	NotYetValid bool
+
+	// The certificate is signed by an unrelated CA, instead of the one issuing
+	// it. Ignored by [NewCA].
+	WrongCA bool
+
+	// A server certificate is issued for client authentication, and a client
+	// certificate for server authentication. Ignored by [NewCA].
+	WrongKeyUsage bool
+}
+
+// validity returns the validity period of the certificate.
+func (o Options) validity() (notBefore, notAfter time.Time) {
+	now := time.Now()
+	switch {
+	case o.Expired:
+		return now.Add(-validity), now.Add(-time.Hour)
+	case o.NotYetValid:
+		return now.Add(time.Hour), now.Add(validity)
+	default:
+		return now.Add(-time.Hour), now.Add(validity)
+	}
+}
+
+// CA is a certificate authority.
+//
+// Must be initiated with [NewCA].
+type CA struct {
+	// Self-signed certificate of the CA.
+	Certificate *x509.Certificate
+
+	key *ecdsa.PrivateKey
+}
+
+// NewCA generates a CA with a self-signed certificate. Only
+// Options.CommonName, Options.Expired and Options.NotYetValid apply.
+func NewCA(opts Options) (*CA, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return nil, err
+	}
+	if opts.CommonName == "" {
+		opts.CommonName = "testpki CA"
+	}
+	notBefore, notAfter := opts.validity()
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: opts.CommonName},
+		NotBefore:             notBefore,
+		NotAfter:              notAfter,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, fmt.Errorf("create certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, fmt.Errorf("parse certificate: %w", err)
+	}
+	return &CA{Certificate: cert, key: key}, nil
+}
+
+// Pool returns a pool that contains only the CA certificate, for
+// maib.Config.RootCAs or tls.Config.ClientCAs.
+func (ca *CA) Pool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Certificate)
+	return pool
+}
+
+// PEM returns the CA certificate in PEM format.
+func (ca *CA) PEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.Certificate.Raw})
+}
+
+// Server issues a server certificate.
+func (ca *CA) Server(opts Options) (tls.Certificate, error) {
+	if opts.CommonName == "" {
+		opts.CommonName = "testpki server"
+	}
+	if opts.Hosts == nil {
+		opts.Hosts = []string{"localhost", "127.0.0.1", "::1"}
+	}
+	usage := x509.ExtKeyUsageServerAuth
+	if opts.WrongKeyUsage {
+		usage = x509.ExtKeyUsageClientAuth
+	}
+	return ca.issue(opts, usage)
+}
+
+// Client issues a client certificate.
+func (ca *CA) Client(opts Options) (tls.Certificate, error) {
+	if opts.CommonName == "" {
+		opts.CommonName = "testpki merchant"
+	}
+	opts.Hosts = nil
+	usage := x509.ExtKeyUsageClientAuth
+	if opts.WrongKeyUsage {
+		usage = x509.ExtKeyUsageServerAuth
+	}
+	return ca.issue(opts, usage)
+}
+
+// ClientPFX issues a client certificate, and encodes it with the private key
+// and the CA certificate as PFX, protected by the passphrase.
+func (ca *CA) ClientPFX(passphrase string, opts Options) ([]byte, error) {
+	cert, err := ca.Client(opts)
+	if err != nil {
+		return nil, err
+	}
+	pfx, err := pkcs12.Modern.Encode(cert.PrivateKey, cert.Leaf, []*x509.Certificate{ca.Certificate}, passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("encode PFX: %w", err)
+	}
+	return pfx, nil
+}
+
+// ClientPFXFile writes [CA.ClientPFX] to a file in a temporary directory that
+// is removed when the test ends, and returns its path. Errors fail the test.
+func (ca *CA) ClientPFXFile(tb testing.TB, passphrase string, opts Options) string {
+	tb.Helper()
+	pfx, err := ca.ClientPFX(passphrase, opts)
+	if err != nil {
+		tb.Fatalf("testpki: %s", err)
+	}
+	path := filepath.Join(tb.TempDir(), "client.pfx")
+	err = os.WriteFile(path, pfx, 0o600)
+	if err != nil {
+		tb.Fatalf("testpki: write PFX: %s", err)
+	}
+	return path
+}
+
+// ServerTLSConfig returns the configuration of a server that presents the
+// certificate, and requires clients to present a certificate issued by the CA.
+func (ca *CA) ServerTLSConfig(cert tls.Certificate) *tls.Config {
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    ca.Pool(),
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+}
+
+// issue creates a leaf certificate.
+func (ca *CA) issue(opts Options, usage x509.ExtKeyUsage) (tls.Certificate, error) {
+	issuer := ca
+	if opts.WrongCA {
+		var err error
+		issuer, err = NewCA(Options{CommonName: "testpki wrong CA"})
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("generate wrong CA: %w", err)
+		}
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("generate key: %w", err)
+	}
+	serial, err := serialNumber()
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	notBefore, notAfter := opts.validity()
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: opts.CommonName},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	for _, host := range opts.Hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer.Certificate, &key.PublicKey, issuer.key)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("create certificate: %w", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("parse certificate: %w", err)
+	}
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  key,
+		Leaf:        cert,
+	}, nil
+}
+
+// serialNumber returns a random 128 bit serial number.
+func serialNumber() (*big.Int, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("generate serial number: %w", err)
+	}
+	return serial, nil
+}
@@ -0,0 +1,116 @@
+package testpki
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func verify(ca *CA, cert *x509.Certificate, usage x509.ExtKeyUsage) error {
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:     ca.Pool(),
+		KeyUsages: []x509.ExtKeyUsage{usage},
+	})
+	return err
+}
+
+func TestCA(t *testing.T) {
+	ca, err := NewCA(Options{CommonName: "Test CA"})
+	assert.Nil(t, err)
+	assert.True(t, ca.Certificate.IsCA)
+	assert.Equal(t, "Test CA", ca.Certificate.Subject.CommonName)
+
+	block, _ := pem.Decode(ca.PEM())
+	assert.Equal(t, ca.Certificate.Raw, block.Bytes)
+}
+
+func TestCA_Server(t *testing.T) {
+	ca, err := NewCA(Options{})
+	assert.Nil(t, err)
+
+	cert, err := ca.Server(Options{})
+	assert.Nil(t, err)
+	assert.Nil(t, verify(ca, cert.Leaf, x509.ExtKeyUsageServerAuth))
+	assert.Nil(t, cert.Leaf.VerifyHostname("localhost"))
+	assert.Nil(t, cert.Leaf.VerifyHostname("127.0.0.1"))
+	assert.Nil(t, cert.Leaf.VerifyHostname("::1"))
+
+	cert, err = ca.Server(Options{Hosts: []string{"ecomm.maib.md"}})
+	assert.Nil(t, err)
+	assert.Nil(t, cert.Leaf.VerifyHostname("ecomm.maib.md"))
+	assert.Error(t, cert.Leaf.VerifyHostname("localhost"))
+}
+
+func TestCA_Options(t *testing.T) {
+	cases := []struct {
+		name    string
+		opts    Options
+		invalid x509.InvalidReason
+		unknown bool
+	}{
+		{name: "Expired", opts: Options{Expired: true}, invalid: x509.Expired},
+		{name: "NotYetValid", opts: Options{NotYetValid: true}, invalid: x509.Expired},
+		{name: "WrongCA", opts: Options{WrongCA: true}, unknown: true},
+		{name: "WrongKeyUsage", opts: Options{WrongKeyUsage: true}, invalid: x509.IncompatibleUsage},
+	}
+
+	ca, err := NewCA(Options{})
+	assert.Nil(t, err)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server, err := ca.Server(c.opts)
+			assert.Nil(t, err)
+			client, err := ca.Client(c.opts)
+			assert.Nil(t, err)
+
+			for _, err := range []error{
+				verify(ca, server.Leaf, x509.ExtKeyUsageServerAuth),
+				verify(ca, client.Leaf, x509.ExtKeyUsageClientAuth),
+			} {
+				if c.unknown {
+					assert.ErrorAs(t, err, new(x509.UnknownAuthorityError))
+					continue
+				}
+				var invalid x509.CertificateInvalidError
+				assert.ErrorAs(t, err, &invalid)
+				assert.Equal(t, c.invalid, invalid.Reason)
+			}
+		})
+	}
+}
+
+func TestNewCA_Validity(t *testing.T) {
+	ca, err := NewCA(Options{Expired: true})
+	assert.Nil(t, err)
+	assert.True(t, ca.Certificate.NotAfter.Before(time.Now()))
+
+	cert, err := ca.Client(Options{})
+	assert.Nil(t, err)
+	var invalid x509.CertificateInvalidError
+	assert.ErrorAs(t, verify(ca, cert.Leaf, x509.ExtKeyUsageClientAuth), &invalid)
+}
+
+func TestCA_ClientPFX(t *testing.T) {
+	ca, err := NewCA(Options{})
+	assert.Nil(t, err)
+
+	path := ca.ClientPFXFile(t, "secret", Options{CommonName: "Merchant"})
+	pfx, err := os.ReadFile(path)
+	assert.Nil(t, err)
+
+	key, cert, caCerts, err := pkcs12.DecodeChain(pfx, "secret")
+	assert.Nil(t, err)
+	assert.NotNil(t, key)
+	assert.Equal(t, "Merchant", cert.Subject.CommonName)
+	assert.Nil(t, verify(ca, cert, x509.ExtKeyUsageClientAuth))
+	assert.Len(t, caCerts, 1)
+	assert.Equal(t, ca.Certificate.Raw, caCerts[0].Raw)
+
+	_, _, _, err = pkcs12.DecodeChain(pfx, "wrong")
+	assert.ErrorIs(t, err, pkcs12.ErrIncorrectPassword)
+}